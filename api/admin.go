@@ -4,6 +4,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/kuleuven/iron/msg"
 	"github.com/kuleuven/iron/scramble"
@@ -54,6 +56,33 @@ func (api *API) ModifyReplicaAttribute(ctx context.Context, path string, replica
 	return api.Request(ctx, msg.MOD_DATA_OBJ_META_AN, request, &msg.EmptyResponse{})
 }
 
+// ReplicaStatus is the status of a replica, as recorded in the catalog.
+type ReplicaStatus int
+
+const (
+	ReplicaStale ReplicaStatus = 0
+	ReplicaGood  ReplicaStatus = 1
+)
+
+func (s ReplicaStatus) String() string {
+	switch s {
+	case ReplicaStale:
+		return "stale"
+	case ReplicaGood:
+		return "good"
+	default:
+		return strconv.Itoa(int(s))
+	}
+}
+
+// ModifyReplicaStatus marks a replica of a data object as good or stale, e.g. to fix
+// the replication state after a manual intervention. It is equivalent to
+// iadmin modrepl logical_path <path> replica_number <num> DATA_REPL_STATUS <status>.
+// This is an administrative call, a connection using a rodsadmin is required.
+func (api *API) ModifyReplicaStatus(ctx context.Context, path string, replNum int, status ReplicaStatus) error {
+	return api.ModifyReplicaAttribute(ctx, path, Replica{Number: replNum}, msg.REPL_STATUS_KW, strconv.Itoa(int(status)))
+}
+
 // RegisterReplica registers a replica of a data object.
 // This is an administrative call, a connection using a rodsadmin is required.
 func (api *API) RegisterReplica(ctx context.Context, path, resource, physicalPath string) error {
@@ -315,3 +344,36 @@ func (api *API) ExecuteExternalRule(ctx context.Context, rule string, params map
 
 	return result, nil
 }
+
+// ChangeOwner transfers the ownership of a data object or collection from the
+// current owner to the given user. The user is granted own access, after which
+// the access of the current owner is removed. iRODS has no API to rewrite the
+// owner recorded in the catalog, so this is the closest equivalent.
+// If a zone needs to be specified, use the username#zone format, both for
+// the current owner and for the new owner.
+// This is an administrative call, a connection using a rodsadmin is required.
+func (api *API) ChangeOwner(ctx context.Context, path, owner, user string) error {
+	if !api.Admin {
+		return ErrRequiresAdmin
+	}
+
+	if err := api.ModifyAccess(ctx, path, user, "own", false); err != nil {
+		return err
+	}
+
+	if owner == "" || api.qualifiedUser(owner) == api.qualifiedUser(user) {
+		return nil
+	}
+
+	return api.ModifyAccess(ctx, path, owner, "null", false)
+}
+
+// qualifiedUser returns the username#zone form of a user, using the zone
+// of the connection if no zone is specified.
+func (api *API) qualifiedUser(user string) string {
+	if strings.Contains(user, "#") {
+		return user
+	}
+
+	return user + "#" + api.Zone
+}
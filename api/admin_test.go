@@ -50,6 +50,31 @@ func TestModifyReplicaAttribute(t *testing.T) {
 	}
 }
 
+func TestModifyReplicaStatus(t *testing.T) {
+	testAPI := newAPI()
+
+	kv := msg.SSKeyVal{}
+
+	kv.Add("replStatus", "0")
+	kv.Add("irodsAdmin", "")
+
+	testAPI.Add(msg.MOD_DATA_OBJ_META_AN, msg.ModDataObjMetaRequest{
+		DataObj: msg.DataObjectInfo{
+			ObjPath: "test",
+			ReplNum: 2,
+		},
+		KeyVals: kv,
+	}, msg.EmptyResponse{})
+
+	if err := testAPI.ModifyReplicaStatus(t.Context(), "test", 2, ReplicaStale); err != ErrRequiresAdmin {
+		t.Error(err)
+	}
+
+	if err := testAPI.AsAdmin().ModifyReplicaStatus(t.Context(), "test", 2, ReplicaStale); err != nil {
+		t.Error(err)
+	}
+}
+
 func TestRegisterReplica(t *testing.T) {
 	testAPI := newAPI()
 
@@ -120,3 +145,42 @@ func TestExecuteRule(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestChangeOwner(t *testing.T) {
+	testAPI := newAPI()
+
+	if err := testAPI.ChangeOwner(t.Context(), "/testzone/home/test", "olduser", "newuser"); err != ErrRequiresAdmin {
+		t.Errorf("expected ErrRequiresAdmin, got %v", err)
+	}
+
+	testAPI.Add(msg.MOD_ACCESS_CONTROL_AN, msg.ModifyAccessRequest{
+		Path:        "/testzone/home/test",
+		UserName:    "newuser",
+		AccessLevel: "admin:own",
+	}, msg.EmptyResponse{})
+
+	testAPI.Add(msg.MOD_ACCESS_CONTROL_AN, msg.ModifyAccessRequest{
+		Path:        "/testzone/home/test",
+		UserName:    "olduser",
+		Zone:        "otherzone",
+		AccessLevel: "admin:null",
+	}, msg.EmptyResponse{})
+
+	// The same owner, specified with the zone of the connection, keeps its access
+	testAPI.Add(msg.MOD_ACCESS_CONTROL_AN, msg.ModifyAccessRequest{
+		Path:        "/testzone/home/test",
+		UserName:    "newuser",
+		Zone:        "testzone",
+		AccessLevel: "admin:own",
+	}, msg.EmptyResponse{})
+
+	api := testAPI.AsAdmin()
+
+	if err := api.ChangeOwner(t.Context(), "/testzone/home/test", "olduser#otherzone", "newuser"); err != nil {
+		t.Error(err)
+	}
+
+	if err := api.ChangeOwner(t.Context(), "/testzone/home/test", "newuser", "newuser#testzone"); err != nil {
+		t.Error(err)
+	}
+}
@@ -2,14 +2,19 @@ package api
 
 import (
 	"context"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/kuleuven/iron/msg"
+	"go.uber.org/multierr"
 
 	"github.com/sirupsen/logrus"
 )
 
+// logger logs the messages of this package, see iron.LogComponentField
+var logger = logrus.WithField("component", "api")
+
 // API provides the interface to IRODS using the provided connection function.
 // Each time an API method is called, the Connect function is called to obtain
 // a connection, and it is closed afterwards. If used together with an instance
@@ -18,13 +23,24 @@ import (
 // The Username and Zone must match the username and zone of the connection.
 // If Admin is true, the API will send the admin keyword with each request.
 // The DefaultResource is the resource to use when creating data objects.
+// If it is empty, DefaultResourceFunc is consulted, if set.
 type API struct {
-	Username, Zone  string
-	Connect         func(context.Context) (Conn, error) // Handler to obtain a connection to perform requests on
-	Admin           bool                                // Whether to act as admin by sending the admin keyword
-	DefaultResource string                              // Default resource to use when creating data objects
-	ReplicaNumber   *int                                // Replica number to use for open/checksum operations
-	NumThreads      int                                 // Number of threads to use for server-side copies
+	Username, Zone      string
+	Connect             func(context.Context) (Conn, error) // Handler to obtain a connection to perform requests on
+	Admin               bool                                // Whether to act as admin by sending the admin keyword
+	DefaultResource     string                              // Default resource to use when creating data objects
+	DefaultResourceFunc func() string                       // Optional handler to obtain the default resource if DefaultResource is empty
+	ReplicaNumber       *int                                // Replica number to use for open/checksum operations
+	NumThreads          int                                 // Number of threads to use for server-side copies
+	Keywords            []Keyword                           // Additional keywords for open/copy/delete/checksum operations
+	QueryBatchSize      int                                 // Number of rows to fetch per round trip in queries, DefaultQueryBatchSize if zero
+	URLTemplate         string                              // Template to map paths to URLs of a web gateway, see GatewayURL
+}
+
+// Keyword is an additional keyword that is passed in the KeyVals of a request.
+type Keyword struct {
+	Key   msg.KeyWord
+	Value string
 }
 
 // Conn is a limited interface to an iRODS connection to avoid dependency cycles.
@@ -135,6 +151,19 @@ type File interface {
 	Reopen(conn Conn, mode int) (File, error)
 }
 
+// KeepAliveFile is implemented by the files returned by OpenDataObject and
+// CreateDataObject. Use a type assertion to check whether a File supports it.
+type KeepAliveFile interface {
+	File
+
+	// KeepAlive starts a background goroutine that refreshes the file handle with a no-op
+	// seek whenever it has been idle for the given interval, until the file is closed.
+	// This avoids that the server drops the handle during long idle periods between writes,
+	// which would result in SYS_BAD_FILE_DESCRIPTOR errors. A subsequent call replaces the
+	// interval, and an interval of zero stops the refreshing.
+	KeepAlive(interval time.Duration)
+}
+
 // AsAdmin returns a new API with the admin keyword set
 func (api API) AsAdmin() *API {
 	api.Admin = true
@@ -149,6 +178,15 @@ func (api API) WithDefaultResource(resource string) *API {
 	return &api
 }
 
+// ResolveDefaultResource returns the resource to use when creating data objects
+func (api *API) ResolveDefaultResource() string {
+	if api.DefaultResource == "" && api.DefaultResourceFunc != nil {
+		return api.DefaultResourceFunc()
+	}
+
+	return api.DefaultResource
+}
+
 // WithNumThreads returns a new API with the number of threads set
 func (api API) WithNumThreads(n int) *API {
 	api.NumThreads = n
@@ -163,6 +201,23 @@ func (api API) WithReplicaNumber(n int) *API {
 	return &api
 }
 
+// WithKeyword returns a new API that passes the given keyword with open, copy, delete
+// and checksum operations. This is meant for advanced use, to pass keywords that
+// have no dedicated option, such as a keyword interpreted by a server-side policy.
+// The keyword is sent as is, without any validation.
+func (api API) WithKeyword(key msg.KeyWord, value string) *API {
+	api.Keywords = append(slices.Clone(api.Keywords), Keyword{key, value})
+
+	return &api
+}
+
+// addKeywords adds the keywords configured using WithKeyword
+func (api *API) addKeywords(ptr *msg.SSKeyVal) {
+	for _, kw := range api.Keywords {
+		ptr.Add(kw.Key, kw.Value)
+	}
+}
+
 func (api *API) setFlags(ptr *msg.SSKeyVal) {
 	if api.Admin {
 		ptr.Add(msg.ADMIN_KW, "")
@@ -188,6 +243,34 @@ func (api *API) RequestWithBuffers(ctx context.Context, apiNumber msg.APINumber,
 	return conn.RequestWithBuffers(ctx, apiNumber, request, response, requestBuf, responseBuf)
 }
 
+// WithConn calls fn with a copy of the API that performs all its requests on the
+// given connection, e.g. to bind a connection that was obtained in advance to a
+// sequence of operations. The connection is closed, i.e. returned to the pool,
+// when fn returns, also if fn fails or panics. Closing the connection within fn
+// has no effect. The API passed to fn must not be used after fn returns, and
+// like a connection, it must not be used concurrently.
+func (api *API) WithConn(conn Conn, fn func(api *API) error) (err error) {
+	defer func() {
+		err = multierr.Append(err, conn.Close())
+	}()
+
+	bound := *api
+	bound.Connect = func(context.Context) (Conn, error) {
+		return boundConn{conn}, nil
+	}
+
+	return fn(&bound)
+}
+
+// boundConn is a connection that is bound by WithConn, and can't be closed by the API methods.
+type boundConn struct {
+	Conn
+}
+
+func (boundConn) Close() error {
+	return nil
+}
+
 // ElevateRequest is a wrapper around api.Request, that elevates permissions on the given path if the request
 // fails with CAT_NO_ACCESS_PERMISSION, if the admin flag is set; for operations that ignore the admin
 // keyword. If giving permissions fails with CAT_NO_ROWS_FOUND, it will try to elevate permissions
@@ -236,13 +319,13 @@ func (api *API) gainAccess(ctx context.Context, conn Conn, path string) error {
 
 	err := conn.Request(ctx, msg.MOD_ACCESS_CONTROL_AN, request, &msg.EmptyResponse{})
 	if err == nil {
-		logrus.Infof("Admin keyword not supported. Elevated permissions on directory %s", path)
+		logger.Infof("Admin keyword not supported. Elevated permissions on directory %s", path)
 
 		return nil
 	}
 
 	if !Is(err, msg.CAT_NO_ROWS_FOUND) && !Is(err, msg.INVALID_OBJECT_TYPE) {
-		logrus.Warnf("Admin keyword not supported. Failed to elevate permissions on directory %s: %s", path, err)
+		logger.Warnf("Admin keyword not supported. Failed to elevate permissions on directory %s: %s", path, err)
 
 		return err
 	}
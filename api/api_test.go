@@ -2,6 +2,7 @@ package api
 
 import (
 	"context"
+	"testing"
 
 	"github.com/kuleuven/iron/msg"
 )
@@ -42,3 +43,62 @@ func (a *testAPI) AddResponse(response any) {
 func (a *testAPI) AddResponses(responses []any) {
 	a.conn.AddResponses(responses)
 }
+
+// closeCountingConn counts the number of times it is closed
+type closeCountingConn struct {
+	*MockConn
+	closed int
+}
+
+func (c *closeCountingConn) Close() error {
+	c.closed++
+
+	return nil
+}
+
+func TestWithConn(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.Connect = func(context.Context) (Conn, error) {
+		t.Fatal("expected no new connection to be obtained")
+
+		return nil, nil
+	}
+
+	conn := &closeCountingConn{MockConn: &MockConn{}}
+
+	conn.AddResponses([]any{
+		msg.EmptyResponse{},
+		msg.EmptyResponse{},
+	})
+
+	err := testAPI.WithConn(conn, func(api *API) error {
+		if err := api.RenameDataObject(t.Context(), "/test/a", "/test/b"); err != nil {
+			return err
+		}
+
+		return api.RenameDataObject(t.Context(), "/test/b", "/test/c")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conn.closed != 1 {
+		t.Fatalf("expected connection to be closed once, got %d", conn.closed)
+	}
+
+	conn = &closeCountingConn{MockConn: &MockConn{}}
+
+	conn.AddResponse(&msg.IRODSError{Code: msg.CAT_NO_ROWS_FOUND})
+
+	err = testAPI.WithConn(conn, func(api *API) error {
+		return api.RenameDataObject(t.Context(), "/test/a", "/test/b")
+	})
+	if !Is(err, msg.CAT_NO_ROWS_FOUND) {
+		t.Fatalf("expected CAT_NO_ROWS_FOUND, got %v", err)
+	}
+
+	if conn.closed != 1 {
+		t.Fatalf("expected connection to be closed once, got %d", conn.closed)
+	}
+}
@@ -18,7 +18,8 @@ type Attributes struct {
 		UserID     int64
 		Permission string
 	}
-	CollectionSize int64
+	CollectionSize  int64
+	CollectionCount int64
 }
 
 type pathObject interface {
@@ -28,10 +29,11 @@ type pathObject interface {
 
 func (b bulk) Record(object pathObject) Record {
 	return &record{
-		FileInfo:       object,
-		metadata:       b.Metadata(object.Identifier()),
-		access:         b.Access(object.Identifier()),
-		collectionSize: b.CollectionSize(object.Identifier()),
+		FileInfo:        object,
+		metadata:        b.Metadata(object.Identifier()),
+		access:          b.Access(object.Identifier()),
+		collectionSize:  b.CollectionSize(object.Identifier()),
+		collectionCount: b.CollectionCount(object.Identifier()),
 	}
 }
 
@@ -76,6 +78,15 @@ func (b bulk) CollectionSize(key int64) int64 {
 	return cached.CollectionSize
 }
 
+func (b bulk) CollectionCount(key int64) int64 {
+	cached, ok := b[key]
+	if !ok {
+		return 0
+	}
+
+	return cached.CollectionCount
+}
+
 func (b bulk) PrefetchCollections(ctx context.Context, api *API, keys []int64, opts ...WalkOption) error {
 	if len(keys) == 0 {
 		return nil
@@ -97,6 +108,12 @@ func (b bulk) PrefetchCollections(ctx context.Context, api *API, keys []int64, o
 		}
 	}
 
+	if slices.Contains(opts, FetchCollectionCount) {
+		if err := b.prefetchCountsForCollections(ctx, api, keys...); err != nil {
+			return err
+		}
+	}
+
 	if !slices.Contains(opts, FetchMetadata) {
 		return nil
 	}
@@ -326,6 +343,40 @@ func (b bulk) collectSizes(result *Result) error {
 	return result.Err()
 }
 
+func (b bulk) prefetchCountsForCollections(ctx context.Context, api *API, keys ...int64) error {
+	for _, batch := range makeBatches(keys) {
+		// Count(ICAT_COLUMN_D_DATA_ID) would count each replica, so list the distinct
+		// pairs of data object and collection instead, and count them here
+		result := api.Query(msg.ICAT_COLUMN_D_DATA_ID, msg.ICAT_COLUMN_D_COLL_ID).With(In(msg.ICAT_COLUMN_D_COLL_ID, batch)).Execute(ctx)
+
+		if err := b.collectCounts(result); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (b bulk) collectCounts(result *Result) error {
+	defer result.Close()
+
+	for result.Next() {
+		var dataID, objectID int64
+
+		if err := result.Scan(&dataID, &objectID); err != nil {
+			return err
+		}
+
+		if _, ok := b[objectID]; !ok {
+			b[objectID] = &Attributes{}
+		}
+
+		b[objectID].CollectionCount++
+	}
+
+	return result.Err()
+}
+
 const batchSize = 100
 
 func makeBatches(keys []int64) [][]int64 {
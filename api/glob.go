@@ -138,6 +138,13 @@ func hasMeta(s string) bool {
 	return strings.ContainsAny(s, `*?[\`)
 }
 
+// LikeGlob creates a Condition that selects the values of the specified column that may
+// match the given glob pattern, see globToLike. As character classes can't be expressed
+// in SQL LIKE, the results should be refined with filepath.Match.
+func LikeGlob(column msg.ColumnNumber, pattern string) Condition {
+	return Like(column, globToLike(pattern))
+}
+
 // globToLike converts a glob pattern to a SQL LIKE pattern.
 // Glob metacharacters are translated as follows:
 //   - * → %
@@ -178,6 +185,17 @@ func globToLike(pattern string) string {
 	return b.String()
 }
 
+// escapeLike escapes the LIKE meta characters in s, so that it is matched literally.
+func escapeLike(s string) string {
+	var b strings.Builder
+
+	for i := range len(s) {
+		writeLikeLiteral(&b, s[i])
+	}
+
+	return b.String()
+}
+
 func writeLikeLiteral(b *strings.Builder, ch byte) {
 	if ch == '%' || ch == '_' {
 		b.WriteByte('\\')
@@ -9,7 +9,6 @@ import (
 	"time"
 
 	"github.com/kuleuven/iron/msg"
-	"github.com/sirupsen/logrus"
 	"go.uber.org/multierr"
 )
 
@@ -77,8 +76,13 @@ type handle struct {
 	reopened                  bool
 	conn                      Conn
 	fileDescriptor            msg.FileDescriptor
-	curOffset                 int64  // Current offset of the file
-	unregisterEmergencyCloser func() // Function to unregister the emergency closer
+	curOffset                 int64     // Current offset of the file
+	lastActivity              time.Time // Time of the last request that used the file descriptor
+	unregisterEmergencyCloser func()    // Function to unregister the emergency closer
+	keepAlive                 struct {
+		stop func() // Function to stop the keepalive goroutine, if running
+		sync.Mutex
+	}
 	sync.Mutex
 }
 
@@ -115,6 +119,8 @@ func (h *handle) Close() error {
 func (h *handle) CloseReturnConnection() (Conn, error) {
 	h.unregisterEmergencyCloser()
 
+	h.KeepAlive(0)
+
 	if h.reopened {
 		h.Lock()
 		defer h.Unlock()
@@ -242,6 +248,8 @@ func (h *handle) seek(offset int64, whence int) (int64, error) {
 
 	var response msg.SeekResponse
 
+	h.lastActivity = time.Now()
+
 	if err := h.conn.Request(h.object.ctx, msg.DATA_OBJ_LSEEK_AN, request, &response); err != nil {
 		return response.Offset, err
 	}
@@ -282,6 +290,8 @@ func (h *handle) Read(b []byte) (int, error) {
 
 	var response msg.ReadResponse
 
+	h.lastActivity = time.Now()
+
 	if err := h.conn.RequestWithBuffers(h.object.ctx, msg.DATA_OBJ_READ_AN, request, &response, nil, b); err != nil {
 		return 0, err
 	}
@@ -311,6 +321,8 @@ func (h *handle) Write(b []byte) (int, error) {
 
 	h.object.api.setFlags(&request.KeyVals)
 
+	h.lastActivity = time.Now()
+
 	if err := h.conn.RequestWithBuffers(h.object.ctx, msg.DATA_OBJ_WRITE_AN, request, &msg.EmptyResponse{}, b, nil); err != nil {
 		return 0, err
 	}
@@ -322,6 +334,74 @@ func (h *handle) Write(b []byte) (int, error) {
 	return len(b), nil
 }
 
+var _ KeepAliveFile = &handle{}
+
+func (h *handle) KeepAlive(interval time.Duration) {
+	h.keepAlive.Lock()
+	defer h.keepAlive.Unlock()
+
+	if h.keepAlive.stop != nil {
+		h.keepAlive.stop()
+		h.keepAlive.stop = nil
+	}
+
+	if interval <= 0 {
+		return
+	}
+
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		// Check twice per interval, so that the handle is never idle for more than 1.5 intervals
+		ticker := time.NewTicker(interval / 2)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+
+			if err := h.refresh(interval); err != nil {
+				logger.Warnf("Failed to keep %s alive: %s", h.object.path, err)
+
+				return
+			}
+		}
+	}()
+
+	h.keepAlive.stop = func() {
+		close(stop)
+		<-done
+	}
+}
+
+// refresh sends a no-op seek if the handle has been idle for the given interval
+func (h *handle) refresh(interval time.Duration) error {
+	h.Lock()
+	defer h.Unlock()
+
+	if time.Since(h.lastActivity) < interval {
+		return nil
+	}
+
+	// Don't use seek(), as it doesn't send a request for a no-op seek
+	request := msg.OpenedDataObjectRequest{
+		FileDescriptor: h.fileDescriptor,
+		Whence:         1,
+	}
+
+	h.object.api.setFlags(&request.KeyVals)
+
+	h.lastActivity = time.Now()
+
+	return h.conn.Request(h.object.ctx, msg.DATA_OBJ_LSEEK_AN, request, &msg.SeekResponse{})
+}
+
 var ErrInvalidSize = errors.New("invalid size")
 
 func (h *handle) Truncate(size int64) error {
@@ -406,7 +486,7 @@ func (h *handle) Reopen(conn Conn, mode int) (File, error) {
 	h.object.wg.Add(1) // Add to waitgroup
 
 	h2.unregisterEmergencyCloser = conn.RegisterCloseHandler(func() error {
-		logrus.Warnf("Emergency close of %s", h.object.path)
+		logger.Warnf("Emergency close of %s", h.object.path)
 
 		return h2.Close()
 	})
@@ -0,0 +1,100 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+// ResolveID returns the name of the data object, collection, user or resource
+// with the given ID. For data objects and collections this is the absolute path,
+// for users it is in the form name#zone.
+func (api *API) ResolveID(ctx context.Context, id int64, itemType ObjectType) (string, error) {
+	condition := fmt.Sprintf(equalTo, strconv.FormatInt(id, 10))
+
+	var (
+		coll, name, zone string
+		err              error
+	)
+
+	switch itemType {
+	case DataObjectType:
+		err = api.QueryRow(
+			msg.ICAT_COLUMN_COLL_NAME,
+			msg.ICAT_COLUMN_DATA_NAME,
+		).Where(
+			msg.ICAT_COLUMN_D_DATA_ID,
+			condition,
+		).Execute(ctx).Scan(&coll, &name)
+
+		name = coll + "/" + name
+	case CollectionType:
+		err = api.QueryRow(
+			msg.ICAT_COLUMN_COLL_NAME,
+		).Where(
+			msg.ICAT_COLUMN_COLL_ID,
+			condition,
+		).Execute(ctx).Scan(&name)
+	case ResourceType:
+		err = api.QueryRow(
+			msg.ICAT_COLUMN_R_RESC_NAME,
+		).Where(
+			msg.ICAT_COLUMN_R_RESC_ID,
+			condition,
+		).Execute(ctx).Scan(&name)
+	case UserType:
+		err = api.QueryRow(
+			msg.ICAT_COLUMN_USER_NAME,
+			msg.ICAT_COLUMN_USER_ZONE,
+		).Where(
+			msg.ICAT_COLUMN_USER_ID,
+			condition,
+		).Execute(ctx).Scan(&name, &zone)
+
+		name = name + "#" + zone
+	default:
+		return "", ErrInvalidItemType
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	return name, nil
+}
+
+// OpenDataObjectID opens the data object with the given ID.
+// The ID is resolved to a path, after which OpenDataObject is called.
+func (api *API) OpenDataObjectID(ctx context.Context, id int64, mode int) (File, error) {
+	path, err := api.ResolveID(ctx, id, DataObjectType)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.OpenDataObject(ctx, path, mode)
+}
+
+// DeleteDataObjectID deletes the data object with the given ID.
+// The ID is resolved to a path, after which DeleteDataObject is called.
+func (api *API) DeleteDataObjectID(ctx context.Context, id int64, skipTrash bool) error {
+	path, err := api.ResolveID(ctx, id, DataObjectType)
+	if err != nil {
+		return err
+	}
+
+	return api.DeleteDataObject(ctx, path, skipTrash)
+}
+
+// AddMetadataID adds a single metadata value to the data object, collection,
+// user or resource with the given ID. The ID is resolved to a name, after
+// which AddMetadata is called.
+func (api *API) AddMetadataID(ctx context.Context, id int64, itemType ObjectType, value Metadata) error {
+	name, err := api.ResolveID(ctx, id, itemType)
+	if err != nil {
+		return err
+	}
+
+	return api.AddMetadata(ctx, name, itemType, value)
+}
@@ -0,0 +1,130 @@
+package api
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestResolveID(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses([]any{
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 2,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: msg.ICAT_COLUMN_COLL_NAME, ResultLen: 1, Values: []string{"/test/coll"}},
+				{AttributeIndex: msg.ICAT_COLUMN_DATA_NAME, ResultLen: 1, Values: []string{"file"}},
+			},
+		},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 1,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: msg.ICAT_COLUMN_COLL_NAME, ResultLen: 1, Values: []string{"/test/coll"}},
+			},
+		},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 2,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: msg.ICAT_COLUMN_USER_NAME, ResultLen: 1, Values: []string{"user"}},
+				{AttributeIndex: msg.ICAT_COLUMN_USER_ZONE, ResultLen: 1, Values: []string{"testzone"}},
+			},
+		},
+	})
+
+	for _, test := range []struct {
+		itemType ObjectType
+		expected string
+	}{
+		{DataObjectType, "/test/coll/file"},
+		{CollectionType, "/test/coll"},
+		{UserType, "user#testzone"},
+	} {
+		name, err := testAPI.ResolveID(t.Context(), 10001, test.itemType)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if name != test.expected {
+			t.Errorf("expected %s, got %s", test.expected, name)
+		}
+	}
+
+	testAPI.AddResponse(msg.QueryResponse{})
+
+	if _, err := testAPI.ResolveID(t.Context(), 10001, ResourceType); !errors.Is(err, ErrNoRowFound) {
+		t.Errorf("expected ErrNoRowFound, got %v", err)
+	}
+
+	if _, err := testAPI.ResolveID(t.Context(), 10001, "x"); !errors.Is(err, ErrInvalidItemType) {
+		t.Errorf("expected ErrInvalidItemType, got %v", err)
+	}
+}
+
+func TestDeleteDataObjectID(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 2,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: msg.ICAT_COLUMN_COLL_NAME, ResultLen: 1, Values: []string{"/test"}},
+			{AttributeIndex: msg.ICAT_COLUMN_DATA_NAME, ResultLen: 1, Values: []string{"file"}},
+		},
+	})
+
+	request := msg.DataObjectRequest{
+		Path: "/test/file",
+	}
+
+	request.KeyVals.Add(msg.FORCE_FLAG_KW, "")
+
+	testAPI.Add(msg.DATA_OBJ_UNLINK_AN, request, msg.EmptyResponse{})
+
+	if err := testAPI.DeleteDataObjectID(t.Context(), 10001, true); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAddMetadataID(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 1,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: msg.ICAT_COLUMN_COLL_NAME, ResultLen: 1, Values: []string{"/test/coll"}},
+		},
+	})
+
+	testAPI.Add(msg.MOD_AVU_METADATA_AN, &msg.ModifyMetadataRequest{
+		Operation: "add",
+		ItemType:  "-C",
+		ItemName:  "/test/coll",
+		AttrName:  "a",
+		AttrValue: "b",
+	}, msg.EmptyResponse{})
+
+	if err := testAPI.AddMetadataID(t.Context(), 10001, CollectionType, Metadata{Name: "a", Value: "b"}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestOpenDataObjectIDNotFound(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.QueryResponse{})
+
+	if _, err := testAPI.OpenDataObjectID(t.Context(), 10001, O_RDONLY); !errors.Is(err, ErrNoRowFound) {
+		t.Fatalf("expected ErrNoRowFound, got %v", err)
+	}
+}
@@ -6,13 +6,16 @@ import (
 	"encoding/base64"
 	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/kuleuven/iron/msg"
-	"github.com/sirupsen/logrus"
 	"go.uber.org/multierr"
+	"golang.org/x/sync/errgroup"
 )
 
 // CreateCollection creates a collection.
@@ -31,6 +34,11 @@ func (api *API) CreateCollection(ctx context.Context, name string) error {
 
 // CreateCollectionAll creates a collection and its parents recursively.
 // If the collection already exists, nothing happens.
+// It is safe to call CreateCollectionAll concurrently for overlapping paths:
+// if another caller creates one of the parents in the meantime, the server
+// reports CATALOG_ALREADY_HAS_ITEM_BY_THAT_NAME and the request is retried.
+// Each retry finds at least one more existing parent, so the number of
+// attempts is bounded by the depth of the path.
 func (api *API) CreateCollectionAll(ctx context.Context, name string) error {
 	request := msg.CreateCollectionRequest{
 		Name: name,
@@ -40,7 +48,53 @@ func (api *API) CreateCollectionAll(ctx context.Context, name string) error {
 
 	api.setFlags(&request.KeyVals)
 
-	return api.ElevateRequest(ctx, msg.COLL_CREATE_AN, request, &msg.EmptyResponse{}, name)
+	attempts := strings.Count(strings.TrimSuffix(name, "/"), "/") + 1
+
+	for {
+		err := api.ElevateRequest(ctx, msg.COLL_CREATE_AN, request, &msg.EmptyResponse{}, name)
+
+		attempts--
+
+		if !Is(err, msg.CATALOG_ALREADY_HAS_ITEM_BY_THAT_NAME) || attempts == 0 {
+			return err
+		}
+	}
+}
+
+// CreateCollectionParents creates a collection and its parents recursively,
+// like CreateCollectionAll, and returns the paths of the collections that
+// were created by this call, parents first. Collections that already existed,
+// or that were created concurrently by another caller, are not returned.
+func (api *API) CreateCollectionParents(ctx context.Context, name string) ([]string, error) {
+	var missing []string
+
+	for p := name; p != "/" && p != ""; p, _ = Split(p) {
+		_, err := api.GetCollection(ctx, p)
+		if err == nil {
+			break
+		}
+
+		if !Is(err, msg.CAT_NO_ROWS_FOUND) {
+			return nil, err
+		}
+
+		missing = append(missing, p)
+	}
+
+	var created []string
+
+	for _, p := range slices.Backward(missing) {
+		err := api.CreateCollection(ctx, p)
+		if Is(err, msg.CATALOG_ALREADY_HAS_ITEM_BY_THAT_NAME) {
+			continue
+		} else if err != nil {
+			return created, err
+		}
+
+		created = append(created, p)
+	}
+
+	return created, nil
 }
 
 // DeleteCollection deletes a collection.
@@ -56,6 +110,7 @@ func (api *API) DeleteCollection(ctx context.Context, name string, skipTrash boo
 	}
 
 	api.setFlags(&request.KeyVals)
+	api.addKeywords(&request.KeyVals)
 
 	return api.Request(ctx, msg.RM_COLL_AN, request, &msg.CollectionOperationStat{})
 }
@@ -74,6 +129,7 @@ func (api *API) DeleteCollectionAll(ctx context.Context, name string, skipTrash
 	}
 
 	api.setFlags(&request.KeyVals)
+	api.addKeywords(&request.KeyVals)
 
 	parent, _ := Split(name)
 
@@ -117,10 +173,45 @@ func (api *API) DeleteDataObject(ctx context.Context, path string, skipTrash boo
 	}
 
 	api.setFlags(&request.KeyVals)
+	api.addKeywords(&request.KeyVals)
 
 	return api.ElevateRequest(ctx, msg.DATA_OBJ_UNLINK_AN, request, &msg.EmptyResponse{}, path)
 }
 
+// maxParallelDeletes is the maximum number of concurrent requests of DeleteDataObjects
+const maxParallelDeletes = 8
+
+// DeleteDataObjects deletes multiple data objects. As iRODS has no bulk delete,
+// the deletions are spread over multiple connections. A failure to delete a data
+// object, e.g. because of SYS_DELETE_DISALLOWED or a missing permission, does not
+// stop the deletion of the others. The returned slice holds the outcome for each
+// of the paths, in the same order, and is nil for data objects that were deleted.
+func (api *API) DeleteDataObjects(ctx context.Context, paths []string, skipTrash bool) []error {
+	errs := make([]error, len(paths))
+
+	var wg errgroup.Group
+
+	wg.SetLimit(maxParallelDeletes)
+
+	for i, path := range paths {
+		wg.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+
+				return nil
+			}
+
+			errs[i] = api.DeleteDataObject(ctx, path, skipTrash)
+
+			return nil
+		})
+	}
+
+	wg.Wait() //nolint:errcheck
+
+	return errs
+}
+
 // ReplicateDataObject replicates a data object to the specified resource.
 func (api *API) ReplicateDataObject(ctx context.Context, path, resource string) error {
 	request := msg.DataObjectRequest{
@@ -138,6 +229,23 @@ func (api *API) ReplicateDataObject(ctx context.Context, path, resource string)
 	return api.ElevateRequest(ctx, msg.DATA_OBJ_REPL_AN, request, &msg.EmptyResponse{}, path)
 }
 
+// ReplicateReplica replicates the given replica of a data object to the specified resource.
+// If the resource holds a stale replica of the data object, it is updated.
+func (api *API) ReplicateReplica(ctx context.Context, path string, replicaNumber int, resource string) error {
+	request := msg.DataObjectRequest{
+		Path:          path,
+		OperationType: msg.OPER_TYPE_REPLICATE_DATA_OBJ,
+		Threads:       api.NumThreads,
+	}
+
+	request.KeyVals.Add(msg.REPL_NUM_KW, strconv.Itoa(replicaNumber))
+	request.KeyVals.Add(msg.DEST_RESC_NAME_KW, resource)
+
+	api.setFlags(&request.KeyVals)
+
+	return api.ElevateRequest(ctx, msg.DATA_OBJ_REPL_AN, request, &msg.EmptyResponse{}, path)
+}
+
 // TrimDataObject removes a data object from the specified resource.
 func (api *API) TrimDataObject(ctx context.Context, path, resource string) error {
 	request := msg.DataObjectRequest{
@@ -196,6 +304,70 @@ func (api *API) RenameDataObject(ctx context.Context, oldPath, newPath string) e
 	return api.ElevateRequest(ctx, msg.DATA_OBJ_RENAME_AN, request, &msg.EmptyResponse{}, oldPath, parentNew)
 }
 
+// Rename is a pair of a source and target path for RenameMany.
+type Rename struct {
+	Source, Target string
+}
+
+// ErrTargetExists is returned by RenameMany if the target of a rename already exists.
+var ErrTargetExists = errors.New("target already exists")
+
+// maxParallelRenames is the maximum number of concurrent renames of RenameMany
+const maxParallelRenames = 8
+
+// RenameMany renames multiple data objects or collections. For each pair, the source
+// is looked up to determine whether a data object or a collection is renamed, and the
+// pair fails with ErrTargetExists if something already exists at the target path.
+// The renames are spread over multiple connections, and a failing pair does not stop
+// the others. The returned slice holds the outcome for each of the pairs, in the same
+// order, and is nil for pairs that were renamed. Renames that depend on each other,
+// e.g. renaming a collection and a data object inside it, should not be combined in
+// a single call, as the order in which they are executed is not defined.
+func (api *API) RenameMany(ctx context.Context, renames []Rename) []error {
+	errs := make([]error, len(renames))
+
+	var wg errgroup.Group
+
+	wg.SetLimit(maxParallelRenames)
+
+	for i, rename := range renames {
+		wg.Go(func() error {
+			if err := ctx.Err(); err != nil {
+				errs[i] = err
+
+				return nil
+			}
+
+			errs[i] = api.rename(ctx, rename)
+
+			return nil
+		})
+	}
+
+	wg.Wait() //nolint:errcheck
+
+	return errs
+}
+
+func (api *API) rename(ctx context.Context, rename Rename) error {
+	record, err := api.GetRecord(ctx, rename.Source)
+	if err != nil {
+		return err
+	}
+
+	if _, err = api.GetRecord(ctx, rename.Target); err == nil {
+		return fmt.Errorf("%w: %s", ErrTargetExists, rename.Target)
+	} else if !Is(err, msg.CAT_NO_ROWS_FOUND) {
+		return err
+	}
+
+	if record.IsDir() {
+		return api.RenameCollection(ctx, rename.Source, rename.Target)
+	}
+
+	return api.RenameDataObject(ctx, rename.Source, rename.Target)
+}
+
 // CopyDataObject copies a data object.
 // A target resource can be specified with WithDefaultResource() first if needed.
 // It will fail if the target already exists.
@@ -217,10 +389,11 @@ func (api *API) CopyDataObject(ctx context.Context, oldPath, newPath string) err
 
 	api.setFlags(&request.Paths[0].KeyVals)
 	api.setFlags(&request.Paths[1].KeyVals)
+	api.addKeywords(&request.Paths[1].KeyVals)
 
 	// Add the default resource if needed
-	if api.DefaultResource != "" {
-		request.Paths[1].KeyVals.Add(msg.DEST_RESC_NAME_KW, api.DefaultResource)
+	if resource := api.ResolveDefaultResource(); resource != "" {
+		request.Paths[1].KeyVals.Add(msg.DEST_RESC_NAME_KW, resource)
 	}
 
 	parentNew, _ := Split(newPath)
@@ -228,6 +401,38 @@ func (api *API) CopyDataObject(ctx context.Context, oldPath, newPath string) err
 	return api.ElevateRequest(ctx, msg.DATA_OBJ_COPY_AN, request, &msg.EmptyResponse{}, oldPath, parentNew)
 }
 
+// CrossZoneCopyBufferSize is the size of the buffer used by CrossZoneCopy,
+// which bounds the amount of data that is held in memory during the copy.
+var CrossZoneCopyBufferSize = 8 * 1024 * 1024
+
+// CrossZoneCopy copies a data object to a data object that is accessed through another API,
+// e.g. in another federated zone, for cases where a server-side copy is not available.
+// The contents are streamed through the client, without using a local temporary file.
+// The target is created or truncated, and removed again if the copy fails.
+// A target resource can be specified with WithDefaultResource() on dst first if needed.
+// This method blocks a connection of both APIs until the copy has finished.
+func (api *API) CrossZoneCopy(ctx context.Context, srcPath string, dst *API, dstPath string) error {
+	r, err := api.OpenDataObject(ctx, srcPath, O_RDONLY)
+	if err != nil {
+		return err
+	}
+
+	w, err := dst.OpenDataObject(ctx, dstPath, O_WRONLY|O_CREAT|O_TRUNC)
+	if err != nil {
+		return multierr.Append(err, r.Close())
+	}
+
+	_, err = io.CopyBuffer(w, r, make([]byte, CrossZoneCopyBufferSize))
+
+	err = multierr.Combine(err, w.Close(), r.Close())
+	if err != nil {
+		// Don't leave a partial target behind
+		err = multierr.Append(err, dst.DeleteDataObject(ctx, dstPath, true))
+	}
+
+	return err
+}
+
 const (
 	O_RDONLY = 0
 	O_WRONLY = 1
@@ -255,11 +460,12 @@ func (api *API) CreateDataObject(ctx context.Context, path string, mode int) (Fi
 		request.KeyVals.Add(msg.FORCE_FLAG_KW, "")
 	}
 
-	if api.DefaultResource != "" {
-		request.KeyVals.Add(msg.DEST_RESC_NAME_KW, api.DefaultResource)
+	if resource := api.ResolveDefaultResource(); resource != "" {
+		request.KeyVals.Add(msg.DEST_RESC_NAME_KW, resource)
 	}
 
 	api.setFlags(&request.KeyVals)
+	api.addKeywords(&request.KeyVals)
 
 	conn, err := api.Connect(ctx)
 	if err != nil {
@@ -284,7 +490,7 @@ func (api *API) CreateDataObject(ctx context.Context, path string, mode int) (Fi
 	}
 
 	h.unregisterEmergencyCloser = conn.RegisterCloseHandler(func() error { //nolint:contextcheck
-		logrus.Warnf("Emergency close of %s", path)
+		logger.Warnf("Emergency close of %s", path)
 
 		return h.Close()
 	})
@@ -306,8 +512,8 @@ func (api *API) OpenDataObject(ctx context.Context, path string, mode int) (File
 
 	request.KeyVals.Add(msg.DATA_TYPE_KW, "generic")
 
-	if api.DefaultResource != "" {
-		request.KeyVals.Add(msg.DEST_RESC_NAME_KW, api.DefaultResource)
+	if resource := api.ResolveDefaultResource(); resource != "" {
+		request.KeyVals.Add(msg.DEST_RESC_NAME_KW, resource)
 	}
 
 	if api.ReplicaNumber != nil {
@@ -315,6 +521,7 @@ func (api *API) OpenDataObject(ctx context.Context, path string, mode int) (File
 	}
 
 	api.setFlags(&request.KeyVals)
+	api.addKeywords(&request.KeyVals)
 
 	conn, err := api.Connect(ctx)
 	if err != nil {
@@ -350,7 +557,7 @@ func (api *API) OpenDataObject(ctx context.Context, path string, mode int) (File
 	}
 
 	h.unregisterEmergencyCloser = conn.RegisterCloseHandler(func() error { //nolint:contextcheck
-		logrus.Warnf("Emergency close of %s", path)
+		logger.Warnf("Emergency close of %s", path)
 
 		return h.Close()
 	})
@@ -372,6 +579,40 @@ func (api *API) ModifyModificationTime(ctx context.Context, path string, t time.
 	return api.Request(ctx, msg.TOUCH_APN, request, &msg.EmptyResponse{})
 }
 
+// SetComment sets the comment of a data object, on all of its replicas.
+// An empty comment clears the comment.
+func (api *API) SetComment(ctx context.Context, path, comment string) error {
+	request := msg.ModDataObjMetaRequest{
+		DataObj: msg.DataObjectInfo{
+			ObjPath: path,
+		},
+	}
+
+	request.KeyVals.Add(msg.DATA_COMMENTS_KW, comment)
+	request.KeyVals.Add(msg.ALL_KW, "")
+
+	api.setFlags(&request.KeyVals)
+
+	return api.ElevateRequest(ctx, msg.MOD_DATA_OBJ_META_AN, request, &msg.EmptyResponse{}, path)
+}
+
+// SetExpiry sets the expiry of a data object, on all of its replicas.
+// The zero time clears the expiry.
+func (api *API) SetExpiry(ctx context.Context, path string, t time.Time) error {
+	request := msg.ModDataObjMetaRequest{
+		DataObj: msg.DataObjectInfo{
+			ObjPath: path,
+		},
+	}
+
+	request.KeyVals.Add(msg.DATA_EXPIRY_KW, formatExpiry(t))
+	request.KeyVals.Add(msg.ALL_KW, "")
+
+	api.setFlags(&request.KeyVals)
+
+	return api.ElevateRequest(ctx, msg.MOD_DATA_OBJ_META_AN, request, &msg.EmptyResponse{}, path)
+}
+
 const shaPrefix = "sha2:"
 
 var ErrChecksumNotFound = errors.New("checksum not found")
@@ -386,8 +627,8 @@ func (api *API) Checksum(ctx context.Context, path string, force bool) ([]byte,
 		Path: path,
 	}
 
-	if api.DefaultResource != "" {
-		request.KeyVals.Add(msg.DEST_RESC_NAME_KW, api.DefaultResource)
+	if resource := api.ResolveDefaultResource(); resource != "" {
+		request.KeyVals.Add(msg.DEST_RESC_NAME_KW, resource)
 	}
 
 	if api.ReplicaNumber != nil {
@@ -399,6 +640,7 @@ func (api *API) Checksum(ctx context.Context, path string, force bool) ([]byte,
 	}
 
 	api.setFlags(&request.KeyVals)
+	api.addKeywords(&request.KeyVals)
 
 	conn, err := api.Connect(ctx)
 	if err != nil {
@@ -435,8 +677,8 @@ func (api *API) VerifyChecksum(ctx context.Context, path string) error {
 		Path: path,
 	}
 
-	if api.DefaultResource != "" {
-		request.KeyVals.Add(msg.DEST_RESC_NAME_KW, api.DefaultResource)
+	if resource := api.ResolveDefaultResource(); resource != "" {
+		request.KeyVals.Add(msg.DEST_RESC_NAME_KW, resource)
 	}
 
 	if api.ReplicaNumber != nil {
@@ -446,6 +688,7 @@ func (api *API) VerifyChecksum(ctx context.Context, path string) error {
 	request.KeyVals.Add(msg.VERIFY_CHKSUM_KW, "")
 
 	api.setFlags(&request.KeyVals)
+	api.addKeywords(&request.KeyVals)
 
 	conn, err := api.Connect(ctx)
 	if err != nil {
@@ -457,6 +700,47 @@ func (api *API) VerifyChecksum(ctx context.Context, path string) error {
 	return api.connElevateRequest(ctx, conn, msg.DATA_OBJ_CHKSUM_AN, request, &msg.EmptyResponse{}, path)
 }
 
+// ReplicaChecksum is the sha256 checksum of a single replica of a data object
+type ReplicaChecksum struct {
+	Number            int
+	ResourceHierarchy string
+	Checksum          []byte
+}
+
+// ChecksumAll returns the sha256 checksum of each replica of a data object, as stored in the catalog.
+// Missing checksums are calculated on the fly. Replicas of which the checksums differ are divergent,
+// which is a sign that one of them is corrupted.
+func (api *API) ChecksumAll(ctx context.Context, path string) ([]ReplicaChecksum, error) {
+	obj, err := api.GetDataObject(ctx, path)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ReplicaChecksum, 0, len(obj.Replicas))
+
+	for _, replica := range obj.Replicas {
+		checksum, err := ParseIrodsChecksum(replica.Checksum)
+		if err != nil {
+			// The replica number determines the replica, don't pass the default resource
+			replicaAPI := api.WithReplicaNumber(replica.Number)
+			replicaAPI.DefaultResource = ""
+			replicaAPI.DefaultResourceFunc = nil
+
+			if checksum, err = replicaAPI.Checksum(ctx, path, false); err != nil {
+				return nil, fmt.Errorf("replica %d: %w", replica.Number, err)
+			}
+		}
+
+		result = append(result, ReplicaChecksum{
+			Number:            replica.Number,
+			ResourceHierarchy: replica.ResourceHierarchy,
+			Checksum:          checksum,
+		})
+	}
+
+	return result, nil
+}
+
 // ModifyAccess modifies the access level of a data object or collection.
 // For users of federated zones, specify <name>#<zone> as user.
 func (api *API) ModifyAccess(ctx context.Context, path, user, accessLevel string, recursive bool) error {
@@ -591,3 +875,87 @@ func (api *API) ModifyMetadata(ctx context.Context, name string, itemType Object
 
 	return api.Request(ctx, msg.ATOMIC_APPLY_METADATA_OPERATIONS_APN, request, &msg.EmptyResponse{})
 }
+
+// ErrMetadataChanged is returned by AtomicMetadataCAS if the current metadata doesn't match
+// the expected metadata. The caller can read the metadata again and retry.
+var ErrMetadataChanged = errors.New("metadata has been changed concurrently")
+
+// AtomicMetadataCAS applies a bulk update of metadata like ModifyMetadata, but only if the
+// current metadata matches the expected metadata (compare-and-swap), so that clients that
+// curate the same item concurrently don't overwrite each other's changes. Only the triplets
+// with the attribute names that occur in expected, add or remove are compared, disregarding
+// their order; e.g. to assert that no value is set yet for an attribute that is added, leave
+// it out of expected. If the metadata doesn't match, nothing is modified and an error wrapping
+// ErrMetadataChanged is returned.
+// The operations are applied atomically by the server, but the metadata is read before; a
+// concurrent modification in the narrow window between both is not detected.
+func (api *API) AtomicMetadataCAS(ctx context.Context, name string, itemType ObjectType, expected, add, remove []Metadata) error {
+	names := map[string]bool{}
+
+	for _, list := range [][]Metadata{expected, add, remove} {
+		for _, m := range list {
+			names[m.Name] = true
+		}
+	}
+
+	current, err := api.ListMetadata(ctx, name, itemType)
+	if err != nil {
+		return err
+	}
+
+	have := map[Metadata]bool{}
+
+	for _, m := range current {
+		if names[m.Name] {
+			have[m] = true
+		}
+	}
+
+	want := map[Metadata]bool{}
+
+	for _, m := range expected {
+		want[m] = true
+	}
+
+	if !maps.Equal(have, want) {
+		return fmt.Errorf("%w: %s", ErrMetadataChanged, name)
+	}
+
+	return api.ModifyMetadata(ctx, name, itemType, add, remove)
+}
+
+// DiffMetadata computes the minimal set of operations needed to transform
+// the current metadata into the desired metadata. Only attribute names that
+// occur in desired are taken into account; triplets with other names are left
+// untouched. Applying the result twice is a no-op.
+func DiffMetadata(current, desired []Metadata) (add, remove []Metadata) {
+	names := map[string]bool{}
+	wanted := map[Metadata]bool{}
+
+	for _, m := range desired {
+		names[m.Name] = true
+		wanted[m] = true
+	}
+
+	present := map[Metadata]bool{}
+
+	for _, m := range current {
+		present[m] = true
+
+		if names[m.Name] && !wanted[m] {
+			remove = append(remove, m)
+		}
+	}
+
+	for _, m := range desired {
+		if present[m] {
+			continue
+		}
+
+		present[m] = true
+
+		add = append(add, m)
+	}
+
+	return add, remove
+}
@@ -1,8 +1,16 @@
 package api
 
 import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"runtime"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -93,6 +101,134 @@ func TestCreateCollectionAllAdminFailure(t *testing.T) {
 	}
 }
 
+// collectionServer simulates the server side of a recursive COLL_CREATE_AN,
+// where each missing parent is looked up and created in a separate step, so
+// that concurrent requests for overlapping paths race with each other.
+type collectionServer struct {
+	MockConn
+	sync.Mutex
+	collections map[string]bool
+}
+
+func (s *collectionServer) exists(name string) bool {
+	s.Lock()
+	defer s.Unlock()
+
+	return s.collections[name]
+}
+
+func (s *collectionServer) create(name string) error {
+	s.Lock()
+	defer s.Unlock()
+
+	if s.collections[name] {
+		return &msg.IRODSError{Code: msg.CATALOG_ALREADY_HAS_ITEM_BY_THAT_NAME}
+	}
+
+	s.collections[name] = true
+
+	return nil
+}
+
+func (s *collectionServer) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	name := request.(msg.CreateCollectionRequest).Name
+
+	var missing []string
+
+	for p := name; p != "/"; p, _ = Split(p) {
+		if !s.exists(p) {
+			missing = append(missing, p)
+		}
+	}
+
+	runtime.Gosched()
+
+	for _, p := range slices.Backward(missing) {
+		if err := s.create(p); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func TestCreateCollectionAllConcurrent(t *testing.T) {
+	server := &collectionServer{
+		collections: map[string]bool{},
+	}
+
+	testAPI := &API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (Conn, error) {
+			return server, nil
+		},
+	}
+
+	var (
+		wg   sync.WaitGroup
+		errs = make(chan error, 100)
+	)
+
+	for i := range 100 {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			errs <- testAPI.CreateCollectionAll(t.Context(), fmt.Sprintf("/testzone/home/testuser/a/b%d/c", i%5))
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for i := range 5 {
+		if !server.exists(fmt.Sprintf("/testzone/home/testuser/a/b%d/c", i)) {
+			t.Fatalf("collection %d was not created", i)
+		}
+	}
+}
+
+func TestCreateCollectionParents(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses([]any{
+		msg.QueryResponse{},
+		msg.QueryResponse{},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 6,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 503, ResultLen: 1, Values: []string{"testuser"}},
+				{AttributeIndex: 504, ResultLen: 1, Values: []string{"testzone"}},
+				{AttributeIndex: 508, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 509, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 506, ResultLen: 1, Values: []string{"0"}},
+			},
+		},
+		&msg.IRODSError{Code: msg.CATALOG_ALREADY_HAS_ITEM_BY_THAT_NAME},
+		msg.EmptyResponse{},
+	})
+
+	created, err := testAPI.CreateCollectionParents(t.Context(), "/testzone/home/a/b")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(created, []string{"/testzone/home/a/b"}) {
+		t.Fatalf("unexpected created collections: %v", created)
+	}
+}
+
 func TestDeleteCollection(t *testing.T) {
 	testAPI := newAPI()
 
@@ -133,6 +269,107 @@ func TestDeleteDataObject(t *testing.T) {
 	}
 }
 
+func TestDeleteDataObjectWithKeyword(t *testing.T) {
+	testAPI := newAPI()
+
+	request := msg.DataObjectRequest{
+		Path: "/test/file",
+	}
+
+	request.KeyVals.Add(msg.FORCE_FLAG_KW, "")
+	request.KeyVals.Add("custom_kw", "value")
+
+	testAPI.Add(msg.DATA_OBJ_UNLINK_AN, request, msg.EmptyResponse{})
+
+	api := testAPI.WithKeyword("custom_kw", "value")
+
+	if err := api.DeleteDataObject(t.Context(), "/test/file", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(testAPI.Keywords) != 0 {
+		t.Fatal("expected original API to be unchanged")
+	}
+}
+
+// unlinkServer simulates the server side of DATA_OBJ_UNLINK_AN for concurrent requests
+type unlinkServer struct {
+	MockConn
+	sync.Mutex
+	objects map[string]error
+}
+
+func (s *unlinkServer) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	s.Lock()
+	defer s.Unlock()
+
+	path := request.(msg.DataObjectRequest).Path
+
+	err, ok := s.objects[path]
+	if !ok {
+		return &msg.IRODSError{Code: msg.CAT_NO_ROWS_FOUND}
+	}
+
+	if err == nil {
+		delete(s.objects, path)
+	}
+
+	return err
+}
+
+func TestDeleteDataObjects(t *testing.T) {
+	server := &unlinkServer{
+		objects: map[string]error{},
+	}
+
+	var paths []string
+
+	for i := range 50 {
+		path := fmt.Sprintf("/testzone/home/testuser/file%d", i)
+		paths = append(paths, path)
+
+		server.objects[path] = nil
+	}
+
+	server.objects[paths[10]] = &msg.IRODSError{Code: msg.SYS_DELETE_DISALLOWED}
+	server.objects[paths[20]] = &msg.IRODSError{Code: msg.CAT_NO_ACCESS_PERMISSION}
+
+	testAPI := &API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (Conn, error) {
+			return server, nil
+		},
+	}
+
+	errs := testAPI.DeleteDataObjects(t.Context(), paths, true)
+
+	if len(errs) != len(paths) {
+		t.Fatalf("expected %d results, got %d", len(paths), len(errs))
+	}
+
+	for i, err := range errs {
+		switch i {
+		case 10:
+			if !Is(err, msg.SYS_DELETE_DISALLOWED) {
+				t.Errorf("expected SYS_DELETE_DISALLOWED for %s, got %v", paths[i], err)
+			}
+		case 20:
+			if !Is(err, msg.CAT_NO_ACCESS_PERMISSION) {
+				t.Errorf("expected CAT_NO_ACCESS_PERMISSION for %s, got %v", paths[i], err)
+			}
+		default:
+			if err != nil {
+				t.Errorf("unexpected error for %s: %v", paths[i], err)
+			}
+		}
+	}
+
+	if len(server.objects) != 2 {
+		t.Fatalf("expected 2 remaining objects, got %d", len(server.objects))
+	}
+}
+
 func TestReplicateDataObject(t *testing.T) {
 	testAPI := newAPI()
 
@@ -143,6 +380,24 @@ func TestReplicateDataObject(t *testing.T) {
 	}
 }
 
+func TestReplicateReplica(t *testing.T) {
+	testAPI := newAPI()
+
+	request := msg.DataObjectRequest{
+		Path:          "/test/file",
+		OperationType: msg.OPER_TYPE_REPLICATE_DATA_OBJ,
+	}
+
+	request.KeyVals.Add(msg.REPL_NUM_KW, "1")
+	request.KeyVals.Add(msg.DEST_RESC_NAME_KW, "otherResource")
+
+	testAPI.Add(msg.DATA_OBJ_REPL_AN, request, msg.EmptyResponse{})
+
+	if err := testAPI.ReplicateReplica(t.Context(), "/test/file", 1, "otherResource"); err != nil {
+		t.Fatal(err)
+	}
+}
+
 func TestTrimDataObject(t *testing.T) {
 	testAPI := newAPI()
 
@@ -195,6 +450,129 @@ func TestChecksum(t *testing.T) {
 	}
 }
 
+func TestChecksumAll(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.QueryResponse{
+		RowCount:       2,
+		AttributeCount: 14,
+		TotalRowCount:  2,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 401, ResultLen: 2, Values: []string{"1", "1"}},
+			{AttributeIndex: 500, ResultLen: 2, Values: []string{"1", "1"}},
+			{AttributeIndex: 406, ResultLen: 2, Values: []string{"generic", "generic"}},
+			{AttributeIndex: 404, ResultLen: 2, Values: []string{"0", "1"}},
+			{AttributeIndex: 407, ResultLen: 2, Values: []string{"1024", "1024"}},
+			{AttributeIndex: 411, ResultLen: 2, Values: []string{"rods", "rods"}},
+			{AttributeIndex: 412, ResultLen: 2, Values: []string{"zone", "zone"}},
+			{AttributeIndex: 415, ResultLen: 2, Values: []string{"sha2:qqqq", ""}},
+			{AttributeIndex: 413, ResultLen: 2, Values: []string{"1", "1"}},
+			{AttributeIndex: 409, ResultLen: 2, Values: []string{"resc1", "resc2"}},
+			{AttributeIndex: 410, ResultLen: 2, Values: []string{"/path1", "/path2"}},
+			{AttributeIndex: 422, ResultLen: 2, Values: []string{"demoResc;resc1", "demoResc;resc2"}},
+			{AttributeIndex: 419, ResultLen: 2, Values: []string{"10000", "10000"}},
+			{AttributeIndex: 420, ResultLen: 2, Values: []string{"10000", "10000"}},
+		},
+	})
+
+	request := msg.DataObjectRequest{
+		Path: "/test/file",
+	}
+
+	request.KeyVals.Add(msg.REPL_NUM_KW, "1")
+
+	testAPI.Add(msg.DATA_OBJ_CHKSUM_AN, request, msg.String{String: "sha2:qqqr"})
+
+	checksums, err := testAPI.ChecksumAll(t.Context(), "/test/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(checksums) != 2 {
+		t.Fatalf("expected 2 checksums, got %d", len(checksums))
+	}
+
+	if checksums[0].Number != 0 || checksums[0].ResourceHierarchy != "demoResc;resc1" || !bytes.Equal(checksums[0].Checksum, []byte{0xaa, 0xaa, 0xaa}) {
+		t.Errorf("unexpected checksum: %+v", checksums[0])
+	}
+
+	if checksums[1].Number != 1 || !bytes.Equal(checksums[1].Checksum, []byte{0xaa, 0xaa, 0xab}) {
+		t.Errorf("unexpected checksum: %+v", checksums[1])
+	}
+}
+
+func TestSetComment(t *testing.T) {
+	testAPI := newAPI()
+
+	kv := msg.SSKeyVal{}
+	kv.Add(msg.DATA_COMMENTS_KW, "a comment")
+	kv.Add(msg.ALL_KW, "")
+
+	testAPI.Add(msg.MOD_DATA_OBJ_META_AN, msg.ModDataObjMetaRequest{
+		DataObj: msg.DataObjectInfo{
+			ObjPath: "/test/file",
+		},
+		KeyVals: kv,
+	}, msg.EmptyResponse{})
+
+	if err := testAPI.SetComment(t.Context(), "/test/file", "a comment"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSetExpiry(t *testing.T) {
+	testAPI := newAPI()
+
+	for _, expiry := range []string{"01700000000", "00000000000"} {
+		kv := msg.SSKeyVal{}
+		kv.Add(msg.DATA_EXPIRY_KW, expiry)
+		kv.Add(msg.ALL_KW, "")
+
+		testAPI.Add(msg.MOD_DATA_OBJ_META_AN, msg.ModDataObjMetaRequest{
+			DataObj: msg.DataObjectInfo{
+				ObjPath: "/test/file",
+			},
+			KeyVals: kv,
+		}, msg.EmptyResponse{})
+	}
+
+	if err := testAPI.SetExpiry(t.Context(), "/test/file", time.Unix(1700000000, 0)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := testAPI.SetExpiry(t.Context(), "/test/file", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestDefaultResourceFunc(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.DefaultResource = ""
+	testAPI.DefaultResourceFunc = func() string {
+		return "otherResc"
+	}
+
+	kv := msg.SSKeyVal{}
+	kv.Add(msg.DEST_RESC_NAME_KW, "otherResc")
+
+	testAPI.Add(msg.DATA_OBJ_CHKSUM_AN, msg.DataObjectRequest{
+		Path:    "test",
+		KeyVals: kv,
+	}, msg.String{
+		String: "sha2:aabbaabbaabbaabbaabb",
+	})
+
+	if _, err := testAPI.Checksum(t.Context(), "test", false); err != nil {
+		t.Fatal(err)
+	}
+
+	// An explicit default resource takes precedence
+	if resource := testAPI.WithDefaultResource("demoResc").ResolveDefaultResource(); resource != "demoResc" {
+		t.Errorf("expected demoResc, got %s", resource)
+	}
+}
+
 func TestOpenDataObject(t *testing.T) {
 	testAPI := newAPI()
 
@@ -231,6 +609,88 @@ func TestOpenDataObject(t *testing.T) {
 	}
 }
 
+// keepAliveConn answers the seeks that refresh a file handle, and counts them
+type keepAliveConn struct {
+	*MockConn
+	seeks atomic.Int32
+}
+
+func (c *keepAliveConn) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	if apiNumber == msg.DATA_OBJ_LSEEK_AN {
+		c.seeks.Add(1)
+
+		return nil
+	}
+
+	return c.MockConn.Request(ctx, apiNumber, request, response)
+}
+
+func TestKeepAlive(t *testing.T) {
+	conn := &keepAliveConn{MockConn: &MockConn{}}
+
+	testAPI := &API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (Conn, error) {
+			return conn, nil
+		},
+	}
+
+	conn.AddResponse(msg.FileDescriptor(1))
+
+	for range 2 {
+		conn.AddBuffer(msg.DATA_OBJ_WRITE_AN, msg.OpenedDataObjectRequest{
+			FileDescriptor: 1,
+			Size:           4,
+		}, msg.EmptyResponse{}, []byte("test"), nil)
+	}
+
+	conn.AddResponse(msg.EmptyResponse{}) // close
+
+	file, err := testAPI.OpenDataObject(t.Context(), "test", O_WRONLY|O_CREAT)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	keepAlive, ok := file.(KeepAliveFile)
+	if !ok {
+		t.Fatal("expected the file to support KeepAlive")
+	}
+
+	keepAlive.KeepAlive(10 * time.Millisecond)
+
+	if _, err = file.Write([]byte("test")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Idle period
+	time.Sleep(100 * time.Millisecond)
+
+	if conn.seeks.Load() == 0 {
+		t.Fatal("expected the handle to be refreshed while idle")
+	}
+
+	if _, err = file.Write([]byte("test")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = file.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	seeks := conn.seeks.Load()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if conn.seeks.Load() != seeks {
+		t.Fatal("expected the refreshing to stop after closing")
+	}
+
+	if len(conn.Dialog) > 0 {
+		t.Fatal("expected all requests to be consumed")
+	}
+}
+
 func TestTouchDataObject(t *testing.T) {
 	testAPI := newAPI()
 
@@ -531,3 +991,172 @@ func TestModifyMetadata(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestAtomicMetadataCAS(t *testing.T) {
+	testAPI := newAPI()
+
+	current := msg.QueryResponse{
+		RowCount:       3,
+		AttributeCount: 3,
+		TotalRowCount:  3,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 600, ResultLen: 3, Values: []string{"key", "key", "other"}},
+			{AttributeIndex: 601, ResultLen: 3, Values: []string{"value", "1", "x"}},
+			{AttributeIndex: 602, ResultLen: 3, Values: []string{"unit", "", ""}},
+		},
+	}
+
+	testAPI.AddResponses([]any{current, msg.EmptyResponse{}})
+
+	expected := []Metadata{
+		{Name: "key", Value: "1"},
+		{Name: "key", Value: "value", Units: "unit"},
+	}
+
+	add := []Metadata{{Name: "key", Value: "2"}}
+	remove := []Metadata{{Name: "key", Value: "1"}}
+
+	if err := testAPI.AtomicMetadataCAS(t.Context(), "/test/object", DataObjectType, expected, add, remove); err != nil {
+		t.Fatal(err)
+	}
+
+	// A concurrent client has removed key=1 in the meantime
+	testAPI.AddResponse(current)
+
+	err := testAPI.AtomicMetadataCAS(t.Context(), "/test/object", DataObjectType, expected[1:], add, remove)
+	if !errors.Is(err, ErrMetadataChanged) {
+		t.Fatalf("expected ErrMetadataChanged, got %v", err)
+	}
+}
+
+func TestDiffMetadata(t *testing.T) {
+	current := []Metadata{
+		{Name: "a", Value: "1"},
+		{Name: "a", Value: "2"},
+		{Name: "b", Value: "1"},
+	}
+
+	desired := []Metadata{
+		{Name: "a", Value: "1"},
+		{Name: "a", Value: "3", Units: "u"},
+		{Name: "a", Value: "3", Units: "u"},
+	}
+
+	add, remove := DiffMetadata(current, desired)
+
+	if len(add) != 1 || add[0] != (Metadata{Name: "a", Value: "3", Units: "u"}) {
+		t.Errorf("unexpected add: %v", add)
+	}
+
+	if len(remove) != 1 || remove[0] != (Metadata{Name: "a", Value: "2"}) {
+		t.Errorf("unexpected remove: %v", remove)
+	}
+
+	add, remove = DiffMetadata(desired, desired)
+
+	if len(add) != 0 || len(remove) != 0 {
+		t.Errorf("expected no-op, got add %v and remove %v", add, remove)
+	}
+}
+
+func TestCrossZoneCopy(t *testing.T) {
+	src := newAPI()
+	dst := newAPI()
+
+	defer func(size int) {
+		CrossZoneCopyBufferSize = size
+	}(CrossZoneCopyBufferSize)
+
+	CrossZoneCopyBufferSize = 15
+
+	src.AddResponse(msg.FileDescriptor(1))
+	src.AddBuffer(msg.DATA_OBJ_READ_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+		Size:           15,
+	}, msg.ReadResponse(11), nil, []byte("testcontent"))
+	src.AddResponse(msg.EmptyResponse{})
+
+	dst.AddResponse(msg.FileDescriptor(2))
+	dst.AddBuffer(msg.DATA_OBJ_WRITE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 2,
+		Size:           11,
+	}, msg.EmptyResponse{}, []byte("testcontent"), nil)
+	dst.AddResponse(msg.EmptyResponse{})
+
+	if err := src.CrossZoneCopy(t.Context(), "/zone1/test", dst.API, "/zone2/test"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(src.conn.Dialog) > 0 || len(dst.conn.Dialog) > 0 {
+		t.Fatal("expected all requests to be consumed")
+	}
+}
+
+func TestCrossZoneCopyFailure(t *testing.T) {
+	src := newAPI()
+	dst := newAPI()
+
+	errTest := errors.New("test error")
+
+	src.AddResponses([]any{msg.FileDescriptor(1), errTest, msg.EmptyResponse{}})
+	dst.AddResponses([]any{msg.FileDescriptor(2), msg.EmptyResponse{}})
+
+	request := msg.DataObjectRequest{
+		Path: "/zone2/test",
+	}
+
+	request.KeyVals.Add(msg.FORCE_FLAG_KW, "")
+
+	dst.conn.Add(msg.DATA_OBJ_UNLINK_AN, request, msg.EmptyResponse{})
+
+	if err := src.CrossZoneCopy(t.Context(), "/zone1/test", dst.API, "/zone2/test"); !errors.Is(err, errTest) {
+		t.Fatalf("expected test error, got %v", err)
+	}
+
+	if len(src.conn.Dialog) > 0 || len(dst.conn.Dialog) > 0 {
+		t.Fatal("expected all requests to be consumed")
+	}
+}
+
+func TestRenameMany(t *testing.T) {
+	testAPI := newAPI()
+
+	collection := msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 6,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 500, ResultLen: 1, Values: []string{"3"}},
+			{AttributeIndex: 503, ResultLen: 1, Values: []string{"rods"}},
+			{AttributeIndex: 504, ResultLen: 1, Values: []string{"zone"}},
+			{AttributeIndex: 508, ResultLen: 1, Values: []string{"10000"}},
+			{AttributeIndex: 509, ResultLen: 1, Values: []string{"10000"}},
+			{AttributeIndex: 506, ResultLen: 1, Values: []string{"0"}},
+		},
+	}
+
+	testAPI.AddResponses([]any{
+		msg.QueryResponse{}, // Source is no data object
+		collection,
+		msg.QueryResponse{}, // Target does not exist
+		msg.QueryResponse{},
+		msg.EmptyResponse{},
+	})
+
+	errs := testAPI.RenameMany(t.Context(), []Rename{{Source: "/testzone/a", Target: "/testzone/b"}})
+	if len(errs) != 1 || errs[0] != nil {
+		t.Fatalf("expected success, got %v", errs)
+	}
+
+	testAPI.AddResponses([]any{
+		msg.QueryResponse{}, // Source is no data object
+		collection,
+		msg.QueryResponse{}, // Target is a collection
+		collection,
+	})
+
+	errs = testAPI.RenameMany(t.Context(), []Rename{{Source: "/testzone/a", Target: "/testzone/b"}})
+	if len(errs) != 1 || !errors.Is(errs[0], ErrTargetExists) {
+		t.Fatalf("expected ErrTargetExists, got %v", errs)
+	}
+}
@@ -0,0 +1,157 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/kuleuven/iron/msg"
+	"go.uber.org/multierr"
+)
+
+// Values of the lockType and lockCmd keywords of the iRODS advisory locks
+const (
+	readLockType  = "readLockType"
+	writeLockType = "writeLockType"
+	unlockType    = "unlockType"
+	setLockCmd    = "setLockCmd"
+	getLockCmd    = "getLockCmd"
+)
+
+// Lock types returned by the getLockCmd command
+const (
+	lockStateRead  = 0 // F_RDLCK
+	lockStateWrite = 1 // F_WRLCK
+)
+
+// ErrLocked is returned by Lock if a conflicting lock is held by another client.
+var ErrLocked = errors.New("data object is locked by another client")
+
+// LockState describes the advisory lock held on a data object
+type LockState int
+
+const (
+	Unlocked LockState = iota
+	ReadLocked
+	WriteLocked
+)
+
+func (l LockState) String() string {
+	switch l {
+	case ReadLocked:
+		return "read locked"
+	case WriteLocked:
+		return "write locked"
+	default:
+		return "unlocked"
+	}
+}
+
+// Lock acquires an advisory lock on a data object, so that cooperating clients can
+// coordinate their writes. An exclusive lock conflicts with any other lock, a shared
+// lock only with exclusive locks. The call does not wait: if a conflicting lock is held,
+// ErrLocked is returned. The locks are advisory, clients that don't call Lock are not
+// affected by them.
+// As the lock is held by the server process of the connection, this method blocks an
+// irods connection until the returned unlock function is called. The lock is released
+// as well when the context is canceled. Calling unlock more than once is a no-op.
+func (api *API) Lock(ctx context.Context, path string, exclusive bool) (func() error, error) {
+	lockType := readLockType
+
+	if exclusive {
+		lockType = writeLockType
+	}
+
+	request := msg.DataObjectRequest{
+		Path: path,
+	}
+
+	request.KeyVals.Add(msg.LOCK_TYPE_KW, lockType)
+	request.KeyVals.Add(msg.LOCK_CMD_KW, setLockCmd)
+
+	api.setFlags(&request.KeyVals)
+
+	conn, err := api.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var fd msg.FileDescriptor
+
+	if err = conn.Request(ctx, msg.DATA_OBJ_LOCK_AN, request, &fd); err != nil {
+		return nil, multierr.Append(lockError(path, err), conn.Close())
+	}
+
+	var (
+		once      sync.Once
+		unlockErr error
+	)
+
+	unlock := func() error {
+		once.Do(func() {
+			request := msg.DataObjectRequest{
+				Path: path,
+			}
+
+			request.KeyVals.Add(msg.LOCK_TYPE_KW, unlockType)
+			request.KeyVals.Add(msg.LOCK_FD_KW, strconv.Itoa(int(fd)))
+
+			// The lock must be released, even if the context has been canceled
+			unlockErr = conn.Request(context.WithoutCancel(ctx), msg.DATA_OBJ_UNLOCK_AN, request, &msg.EmptyResponse{})
+			unlockErr = multierr.Append(unlockErr, conn.Close())
+		})
+
+		return unlockErr
+	}
+
+	stop := context.AfterFunc(ctx, func() {
+		unlock() //nolint:errcheck
+	})
+
+	return func() error {
+		stop()
+
+		return unlock()
+	}, nil
+}
+
+// GetLockState returns the advisory lock that is held on a data object, if any.
+func (api *API) GetLockState(ctx context.Context, path string) (LockState, error) {
+	request := msg.DataObjectRequest{
+		Path: path,
+	}
+
+	request.KeyVals.Add(msg.LOCK_TYPE_KW, writeLockType)
+	request.KeyVals.Add(msg.LOCK_CMD_KW, getLockCmd)
+
+	api.setFlags(&request.KeyVals)
+
+	var state msg.FileDescriptor
+
+	if err := api.Request(ctx, msg.DATA_OBJ_LOCK_AN, request, &state); err != nil {
+		return Unlocked, lockError(path, err)
+	}
+
+	switch state {
+	case lockStateRead:
+		return ReadLocked, nil
+	case lockStateWrite:
+		return WriteLocked, nil
+	default:
+		return Unlocked, nil
+	}
+}
+
+// lockError maps the error codes of the lock API to clear errors
+func lockError(path string, err error) error {
+	switch {
+	case Is(err, msg.SYS_FS_LOCK_ERR):
+		return fmt.Errorf("%w: %s: %w", ErrLocked, path, err)
+	case Is(err, msg.SYS_LOCK_TYPE_INP_ERR), Is(err, msg.SYS_LOCK_CMD_INP_ERR):
+		return fmt.Errorf("lock %s: server rejected the lock request: %w", path, err)
+	default:
+		return err
+	}
+}
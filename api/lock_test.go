@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestLock(t *testing.T) {
+	testAPI := newAPI()
+
+	request := msg.DataObjectRequest{
+		Path: "/testzone/home/test",
+	}
+
+	request.KeyVals.Add(msg.LOCK_TYPE_KW, "writeLockType")
+	request.KeyVals.Add(msg.LOCK_CMD_KW, "setLockCmd")
+
+	unlockRequest := msg.DataObjectRequest{
+		Path: "/testzone/home/test",
+	}
+
+	unlockRequest.KeyVals.Add(msg.LOCK_TYPE_KW, "unlockType")
+	unlockRequest.KeyVals.Add(msg.LOCK_FD_KW, "3")
+
+	testAPI.Add(msg.DATA_OBJ_LOCK_AN, request, msg.FileDescriptor(3))
+	testAPI.Add(msg.DATA_OBJ_UNLOCK_AN, unlockRequest, msg.EmptyResponse{})
+
+	unlock, err := testAPI.Lock(t.Context(), "/testzone/home/test", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A second call is a no-op
+	if err = unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	testAPI.AddResponse(&msg.IRODSError{Code: msg.SYS_FS_LOCK_ERR})
+
+	if _, err = testAPI.Lock(t.Context(), "/testzone/home/test", false); !errors.Is(err, ErrLocked) {
+		t.Fatalf("expected ErrLocked, got %v", err)
+	}
+}
+
+func TestLockCancel(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses([]any{
+		msg.FileDescriptor(3),
+		msg.EmptyResponse{},
+	})
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	unlock, err := testAPI.Lock(ctx, "/testzone/home/test", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cancel()
+
+	// Waits for the unlock triggered by the cancellation to finish
+	if err = unlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(testAPI.conn.Dialog) != 0 {
+		t.Fatalf("expected the lock to be released, %d responses left", len(testAPI.conn.Dialog))
+	}
+}
+
+func TestGetLockState(t *testing.T) {
+	testAPI := newAPI()
+
+	for state, expected := range map[msg.FileDescriptor]LockState{
+		0: ReadLocked,
+		1: WriteLocked,
+		2: Unlocked,
+	} {
+		testAPI.AddResponse(state)
+
+		result, err := testAPI.GetLockState(t.Context(), "/testzone/home/test")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if result != expected {
+			t.Errorf("%d: expected %s, got %s", state, expected, result)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"path"
+	"strconv"
 	"strings"
 	"time"
 
@@ -140,6 +142,12 @@ func (d *DataObject) Sys() any {
 	return d
 }
 
+// Possible resource statuses, see ResourceStatus
+const (
+	ResourceUp   = "up"
+	ResourceDown = "down"
+)
+
 type Resource struct {
 	ID         int64
 	ParentID   int64
@@ -290,6 +298,84 @@ func (api *API) GetDataObject(ctx context.Context, path string) (*DataObject, er
 	return &d, nil
 }
 
+// GetComment returns the comment of a data object.
+// If the replicas carry different comments, the first one is returned.
+func (api *API) GetComment(ctx context.Context, path string) (string, error) {
+	var comment string
+
+	coll, name := Split(path)
+
+	err := api.QueryRow(
+		msg.ICAT_COLUMN_D_COMMENTS,
+	).Where(
+		msg.ICAT_COLUMN_COLL_NAME,
+		fmt.Sprintf(equalTo, coll),
+	).Where(
+		msg.ICAT_COLUMN_DATA_NAME,
+		fmt.Sprintf(equalTo, name),
+	).Execute(ctx).Scan(
+		&comment,
+	)
+
+	return comment, err
+}
+
+// GetExpiry returns the expiry of a data object.
+// If no expiry is set, the zero time is returned.
+func (api *API) GetExpiry(ctx context.Context, path string) (time.Time, error) {
+	var expiry string
+
+	coll, name := Split(path)
+
+	err := api.QueryRow(
+		msg.ICAT_COLUMN_D_EXPIRY,
+	).Where(
+		msg.ICAT_COLUMN_COLL_NAME,
+		fmt.Sprintf(equalTo, coll),
+	).Where(
+		msg.ICAT_COLUMN_DATA_NAME,
+		fmt.Sprintf(equalTo, name),
+	).Execute(ctx).Scan(
+		&expiry,
+	)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return parseExpiry(expiry)
+}
+
+// The catalog stores timestamps as a zero-padded number of seconds since epoch,
+// so that they can be compared as strings in queries.
+const timestampFormat = "%011d"
+
+func formatTimestamp(t time.Time) string {
+	return fmt.Sprintf(timestampFormat, t.Unix())
+}
+
+func formatExpiry(t time.Time) string {
+	if t.IsZero() {
+		return fmt.Sprintf(timestampFormat, 0)
+	}
+
+	return formatTimestamp(t)
+}
+
+func parseExpiry(s string) (time.Time, error) {
+	s = strings.TrimSpace(s)
+
+	if s == "" {
+		return time.Time{}, nil
+	}
+
+	seconds, err := strconv.ParseInt(s, 10, 64)
+	if err != nil || seconds == 0 {
+		return time.Time{}, err
+	}
+
+	return time.Unix(seconds, 0), nil
+}
+
 // Split splits the path into dir and file
 func Split(path string) (string, string) {
 	for i := len(path) - 1; i > 0; i-- {
@@ -305,6 +391,14 @@ func Split(path string) (string, string) {
 	return "", path
 }
 
+// IsSubPath reports whether the path p lies beneath dir, i.e. whether dir is
+// one of its parents. Both paths are cleaned before they are compared.
+func IsSubPath(p, dir string) bool {
+	p, dir = path.Clean(p), path.Clean(dir)
+
+	return p != dir && strings.HasPrefix(p, strings.TrimSuffix(dir, "/")+"/")
+}
+
 // GetResource returns information about a resource, identified by its name
 func (api *API) GetResource(ctx context.Context, name string) (*Resource, error) {
 	var r Resource
@@ -344,6 +438,25 @@ func (api *API) GetResource(ctx context.Context, name string) (*Resource, error)
 	return &r, nil
 }
 
+// ResourceStatus returns the status of a resource, identified by its name.
+// The status is set by the administrator using iadmin modresc, and is either
+// ResourceUp or ResourceDown. Resources whose status was never set return
+// an empty status, and are considered to be up by the server.
+func (api *API) ResourceStatus(ctx context.Context, name string) (string, error) {
+	var status string
+
+	err := api.QueryRow(
+		msg.ICAT_COLUMN_R_RESC_STATUS,
+	).Where(
+		msg.ICAT_COLUMN_R_RESC_NAME,
+		fmt.Sprintf(equalTo, name),
+	).Execute(ctx).Scan(
+		&status,
+	)
+
+	return status, err
+}
+
 // GetUser returns information about a user, identified by its name
 // If a zone needs to be specified, use the username#zone format.
 func (api *API) GetUser(ctx context.Context, name string) (*User, error) {
@@ -476,10 +589,80 @@ func (api *API) ListSubCollections(ctx context.Context, collectionPath string) (
 	return api.ListCollections(ctx, Equal(msg.ICAT_COLUMN_COLL_PARENT_NAME, collectionPath))
 }
 
+// ForEachDataObject calls fn for each data object in the given collection. The data objects
+// are streamed as the results arrive from the server, so that memory usage stays bounded for
+// huge collections. Iteration stops at the first error returned by fn, which is returned.
+// The connection is blocked until all data objects have been processed.
+func (api *API) ForEachDataObject(ctx context.Context, collectionPath string, fn func(DataObject) error) error {
+	return api.forEachDataObject(ctx, fn, Equal(msg.ICAT_COLUMN_COLL_NAME, collectionPath))
+}
+
 // ListDataObjects returns a list of data objects satisfying the given conditions
-func (api *API) ListDataObjects(ctx context.Context, conditions ...Condition) ([]DataObject, error) { //nolint:funlen
+func (api *API) ListDataObjects(ctx context.Context, conditions ...Condition) ([]DataObject, error) {
 	result := []DataObject{}
-	mapping := map[int64]*DataObject{}
+	mapping := map[int64]int{}
+
+	err := api.forEachDataObject(ctx, func(object DataObject) error {
+		// Merge replicas that were not returned consecutively
+		if i, ok := mapping[object.ID]; ok {
+			result[i].Replicas = append(result[i].Replicas, object.Replicas...)
+
+			return nil
+		}
+
+		mapping[object.ID] = len(result)
+		result = append(result, object)
+
+		return nil
+	}, conditions...)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// ListReplicasOnResource returns the data objects in the given collection and its subcollections
+// that have a replica on the given resource, e.g. to find out which data objects need to be
+// migrated before a resource is decommissioned. The resource can be the root, an intermediate
+// or a leaf resource of a resource hierarchy. Only the replicas on the resource are included
+// in the returned data objects.
+func (api *API) ListReplicasOnResource(ctx context.Context, collection, resource string) ([]DataObject, error) {
+	escaped := escapeLike(resource)
+
+	onResource := Condition{
+		Column: msg.ICAT_COLUMN_D_RESC_HIER,
+		Op:     "=",
+		Value:  fmt.Sprintf("'%s' || LIKE '%s;%%' || LIKE '%%;%s' || LIKE '%%;%s;%%'", resource, escaped, escaped, escaped),
+	}
+
+	var result []DataObject
+
+	for _, filter := range []Condition{
+		Equal(msg.ICAT_COLUMN_COLL_NAME, collection),
+		Like(msg.ICAT_COLUMN_COLL_NAME, escapeLike(strings.TrimSuffix(collection, "/"))+"/%"),
+	} {
+		objects, err := api.ListDataObjects(ctx, filter, onResource)
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, objects...)
+	}
+
+	return result, nil
+}
+
+// forEachDataObject calls fn for each data object satisfying the given conditions.
+// The catalog sorts the results by the selected columns, starting with the data object ID,
+// so the replicas of a data object are returned consecutively and fn can be called as soon
+// as the rows of the next data object start.
+func (api *API) forEachDataObject(ctx context.Context, fn func(DataObject) error, conditions ...Condition) error { //nolint:funlen
+	var (
+		current DataObject
+		found   bool
+	)
+
 	results := api.Query(
 		msg.ICAT_COLUMN_D_DATA_ID,
 		msg.ICAT_COLUMN_COLL_NAME,
@@ -527,23 +710,32 @@ func (api *API) ListDataObjects(ctx context.Context, conditions ...Condition) ([
 			&replica.ModifiedAt,
 		)
 		if err != nil {
-			return nil, err
+			return err
 		}
 
-		object.Path = coll + "/" + name
-
-		if prev, ok := mapping[object.ID]; ok {
-			prev.Replicas = append(prev.Replicas, replica)
+		if found && current.ID == object.ID {
+			current.Replicas = append(current.Replicas, replica)
 
 			continue
 		}
 
-		object.Replicas = append(object.Replicas, replica)
-		result = append(result, object)
-		mapping[object.ID] = &result[len(result)-1]
+		if found {
+			if err := fn(current); err != nil {
+				return err
+			}
+		}
+
+		object.Path = coll + "/" + name
+		object.Replicas = []Replica{replica}
+
+		current, found = object, true
 	}
 
-	return result, results.Err()
+	if err := results.Err(); err != nil || !found {
+		return err
+	}
+
+	return fn(current)
 }
 
 // ListCollections returns a list of collections satisfying the given conditions
@@ -1,7 +1,10 @@
 package api
 
 import (
+	"context"
+	"errors"
 	"os"
+	"slices"
 	"testing"
 	"time"
 
@@ -120,6 +123,64 @@ func TestGetDataObject(t *testing.T) {
 	}
 }
 
+func TestGetComment(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.QueryResponse{
+		RowCount:       2,
+		AttributeCount: 1,
+		TotalRowCount:  2,
+		ContinueIndex:  0,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 418, ResultLen: 2, Values: []string{"a comment", "other comment"}},
+		},
+	})
+
+	comment, err := testAPI.GetComment(t.Context(), "/test/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if comment != "a comment" {
+		t.Errorf("expected comment %q, got %q", "a comment", comment)
+	}
+}
+
+func TestGetExpiry(t *testing.T) {
+	testAPI := newAPI()
+
+	for _, value := range []string{"01700000000", "00000000000", ""} {
+		testAPI.AddResponse(msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 1,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 416, ResultLen: 1, Values: []string{value}},
+			},
+		})
+	}
+
+	expiry, err := testAPI.GetExpiry(t.Context(), "/test/file")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !expiry.Equal(time.Unix(1700000000, 0)) {
+		t.Errorf("unexpected expiry %s", expiry)
+	}
+
+	for range 2 {
+		expiry, err = testAPI.GetExpiry(t.Context(), "/test/file")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !expiry.IsZero() {
+			t.Errorf("expected no expiry, got %s", expiry)
+		}
+	}
+}
+
 func TestGetResource(t *testing.T) {
 	testAPI := newAPI()
 
@@ -149,6 +210,28 @@ func TestGetResource(t *testing.T) {
 	}
 }
 
+func TestResourceStatus(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 1,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 313, ResultLen: 1, Values: []string{"down"}},
+		},
+	})
+
+	status, err := testAPI.ResourceStatus(t.Context(), "resc_name")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if status != ResourceDown {
+		t.Fatalf("expected %s, got %s", ResourceDown, status)
+	}
+}
+
 func TestGetUser(t *testing.T) {
 	testAPI := newAPI()
 
@@ -293,6 +376,123 @@ func TestListDataObjects(t *testing.T) {
 	}
 }
 
+func replicasResponse(ids, names, replicas []string, continueIndex int) msg.QueryResponse {
+	n := len(ids)
+
+	repeat := func(value string) []string {
+		values := make([]string, n)
+
+		for i := range values {
+			values[i] = value
+		}
+
+		return values
+	}
+
+	return msg.QueryResponse{
+		RowCount:       n,
+		AttributeCount: 16,
+		TotalRowCount:  n,
+		ContinueIndex:  continueIndex,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 401, ResultLen: n, Values: ids},
+			{AttributeIndex: 501, ResultLen: n, Values: repeat("/test")},
+			{AttributeIndex: 403, ResultLen: n, Values: names},
+			{AttributeIndex: 500, ResultLen: n, Values: repeat("1")},
+			{AttributeIndex: 406, ResultLen: n, Values: repeat("generic")},
+			{AttributeIndex: 404, ResultLen: n, Values: replicas},
+			{AttributeIndex: 407, ResultLen: n, Values: repeat("1024")},
+			{AttributeIndex: 411, ResultLen: n, Values: repeat("rods")},
+			{AttributeIndex: 412, ResultLen: n, Values: repeat("zone")},
+			{AttributeIndex: 415, ResultLen: n, Values: repeat("")},
+			{AttributeIndex: 413, ResultLen: n, Values: repeat("1")},
+			{AttributeIndex: 409, ResultLen: n, Values: repeat("demoResc")},
+			{AttributeIndex: 410, ResultLen: n, Values: repeat("/path")},
+			{AttributeIndex: 422, ResultLen: n, Values: repeat("demoResc")},
+			{AttributeIndex: 419, ResultLen: n, Values: repeat("10000")},
+			{AttributeIndex: 420, ResultLen: n, Values: repeat("10000")},
+		},
+	}
+}
+
+func TestForEachDataObject(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses([]any{
+		replicasResponse([]string{"1", "1", "2"}, []string{"a", "a", "b"}, []string{"0", "1", "0"}, 1),
+		replicasResponse([]string{"2", "3"}, []string{"b", "c"}, []string{"1", "0"}, 2),
+		replicasResponse([]string{"4"}, []string{"d"}, []string{"0"}, 0),
+	})
+
+	var (
+		paths    []string
+		replicas []int
+	)
+
+	err := testAPI.ForEachDataObject(t.Context(), "/test", func(obj DataObject) error {
+		paths = append(paths, obj.Path)
+		replicas = append(replicas, len(obj.Replicas))
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(paths, []string{"/test/a", "/test/b", "/test/c", "/test/d"}) {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+
+	if !slices.Equal(replicas, []int{2, 2, 1, 1}) {
+		t.Errorf("unexpected replica counts: %v", replicas)
+	}
+}
+
+func TestForEachDataObjectStop(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses([]any{
+		replicasResponse([]string{"1", "2"}, []string{"a", "b"}, []string{"0", "0"}, 1),
+		// Remaining results are discarded when the result is closed
+		msg.QueryResponse{},
+	})
+
+	errStop := errors.New("stop")
+
+	var calls int
+
+	err := testAPI.ForEachDataObject(t.Context(), "/test", func(obj DataObject) error {
+		calls++
+
+		return errStop
+	})
+	if err != errStop {
+		t.Fatalf("expected stop error, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 call, got %d", calls)
+	}
+}
+
+func TestListDataObjectsContinuation(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses([]any{
+		replicasResponse([]string{"1", "2"}, []string{"a", "b"}, []string{"0", "0"}, 1),
+		replicasResponse([]string{"1", "2"}, []string{"a", "b"}, []string{"1", "1"}, 0),
+	})
+
+	objs, err := testAPI.ListDataObjectsInCollection(t.Context(), "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(objs) != 2 || len(objs[0].Replicas) != 2 || len(objs[1].Replicas) != 2 {
+		t.Errorf("unexpected data objects: %+v", objs)
+	}
+}
+
 func TestListMetadataDataObject(t *testing.T) {
 	testAPI := newAPI()
 
@@ -464,3 +664,57 @@ func TestListAccessDataObject(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+// conditionConn records the conditions of the queries it serves
+type conditionConn struct {
+	MockConn
+	conditions []map[int]string
+}
+
+func (c *conditionConn) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	if query, ok := request.(*msg.QueryRequest); ok {
+		conditions := map[int]string{}
+
+		for i, key := range query.Conditions.Keys {
+			conditions[key] = query.Conditions.Values[i]
+		}
+
+		c.conditions = append(c.conditions, conditions)
+	}
+
+	return c.MockConn.Request(ctx, apiNumber, request, response)
+}
+
+func TestListReplicasOnResource(t *testing.T) {
+	conn := &conditionConn{}
+
+	testAPI := &API{
+		Connect: func(context.Context) (Conn, error) {
+			return conn, nil
+		},
+	}
+
+	conn.AddResponses([]any{
+		replicasResponse([]string{"1"}, []string{"a"}, []string{"0"}, 0),
+		replicasResponse([]string{"2", "3"}, []string{"b", "c"}, []string{"1", "0"}, 0),
+	})
+
+	objects, err := testAPI.ListReplicasOnResource(t.Context(), "/test", "old_resc")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(objects) != 3 || objects[0].Path != "/test/a" || objects[1].Replicas[0].Number != 1 {
+		t.Fatalf("unexpected objects: %v", objects)
+	}
+
+	expected := `= 'old_resc' || LIKE 'old\_resc;%' || LIKE '%;old\_resc' || LIKE '%;old\_resc;%'`
+
+	if len(conn.conditions) != 2 || conn.conditions[0][int(msg.ICAT_COLUMN_D_RESC_HIER)] != expected {
+		t.Fatalf("unexpected conditions: %v", conn.conditions)
+	}
+
+	if conn.conditions[0][int(msg.ICAT_COLUMN_COLL_NAME)] != "= '/test'" || conn.conditions[1][int(msg.ICAT_COLUMN_COLL_NAME)] != "LIKE '/test/%'" {
+		t.Fatalf("unexpected conditions: %v", conn.conditions)
+	}
+}
@@ -22,6 +22,7 @@ func (api *API) Procs(ctx context.Context) *Result {
 	result := &Result{
 		Context: ctx,
 		result:  &resp,
+		row:     -1, // Next advances to the first row
 	}
 
 	// Initialize columns after the fact,
@@ -39,3 +39,42 @@ func TestProcs(t *testing.T) {
 		t.Error(err)
 	}
 }
+
+func TestProcsRows(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.QueryResponse{
+		AttributeCount: 2,
+		RowCount:       2,
+		ContinueIndex:  -1,
+		TotalRowCount:  2,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 1000001, ResultLen: 2, Values: []string{"10", "11"}},
+			{AttributeIndex: 1000009, ResultLen: 2, Values: []string{"iron", "icommands"}},
+		},
+	})
+
+	result := testAPI.Procs(t.Context())
+
+	var pids, programs []string
+
+	for result.Next() {
+		var pid, program string
+
+		if err := result.Scan(&pid, &program); err != nil {
+			t.Fatal(err)
+		}
+
+		pids = append(pids, pid)
+		programs = append(programs, program)
+	}
+
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first row must not be skipped
+	if len(pids) != 2 || pids[0] != "10" || programs[1] != "icommands" {
+		t.Errorf("unexpected rows: %v %v", pids, programs)
+	}
+}
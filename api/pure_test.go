@@ -209,3 +209,27 @@ func TestParseIrodsChecksumEmpty(t *testing.T) {
 		t.Fatal("expected error for empty string")
 	}
 }
+
+func TestIsSubPath(t *testing.T) {
+	tests := []struct {
+		path string
+		dir  string
+		want bool
+	}{
+		{"/zone/a/b", "/zone/a", true},
+		{"/zone/a/b/c", "/zone/a/", true},
+		{"/zone/a/./b", "/zone/a", true},
+		{"/zone/a", "/zone/a", false},
+		{"/zone/a/", "/zone/a", false},
+		{"/zone/ab", "/zone/a", false},
+		{"/zone/a/../b", "/zone/a", false},
+		{"/zone", "/zone/a", false},
+		{"/zone", "/", true},
+	}
+
+	for _, tt := range tests {
+		if got := IsSubPath(tt.path, tt.dir); got != tt.want {
+			t.Errorf("IsSubPath(%q, %q) = %v, want %v", tt.path, tt.dir, got, tt.want)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package api
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"maps"
 	"reflect"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -18,8 +21,31 @@ type PreparedQuery struct {
 	maxRows     int
 	columns     []Column
 	conditions  map[msg.ColumnNumber]string
+	noDistinct  bool
+	groupBy     []msg.ColumnNumber
 }
 
+// Options of a general query
+const (
+	returnTotalRowCount = 0x20
+	noDistinct          = 0x40
+)
+
+// Aggregation levels of a selected column
+const (
+	aggregateNone  = 1
+	aggregateMin   = 2
+	aggregateMax   = 3
+	aggregateSum   = 4
+	aggregateAvg   = 5
+	aggregateCount = 6
+)
+
+// Column is a selected column of a query. Plain column numbers select the
+// values of the column, Min, Max, Sum, Avg and Count select an aggregate.
+// If aggregates and plain columns are combined, the aggregates are computed
+// for every distinct combination of values of the plain columns, like a SQL
+// GROUP BY on the plain columns. Use PreparedQuery.GroupBy to make this explicit.
 type Column interface {
 	Int() int
 	AggregationLevel() int
@@ -38,38 +64,43 @@ func (c col) AggregationLevel() int {
 	return c.aggregationLevel
 }
 
+// Min selects the minimum value of a column.
 func Min(column msg.ColumnNumber) Column {
 	return col{
 		columnNumber:     column,
-		aggregationLevel: 2,
+		aggregationLevel: aggregateMin,
 	}
 }
 
+// Max selects the maximum value of a column.
 func Max(column msg.ColumnNumber) Column {
 	return col{
 		columnNumber:     column,
-		aggregationLevel: 3,
+		aggregationLevel: aggregateMax,
 	}
 }
 
+// Sum selects the sum of the values of a column.
 func Sum(column msg.ColumnNumber) Column {
 	return col{
 		columnNumber:     column,
-		aggregationLevel: 4,
+		aggregationLevel: aggregateSum,
 	}
 }
 
+// Avg selects the average of the values of a column.
 func Avg(column msg.ColumnNumber) Column {
 	return col{
 		columnNumber:     column,
-		aggregationLevel: 5,
+		aggregationLevel: aggregateAvg,
 	}
 }
 
+// Count selects the number of values of a column.
 func Count(column msg.ColumnNumber) Column {
 	return col{
 		columnNumber:     column,
-		aggregationLevel: 6,
+		aggregationLevel: aggregateCount,
 	}
 }
 
@@ -107,6 +138,16 @@ func Like(column msg.ColumnNumber, value string) Condition {
 	}
 }
 
+// After creates a Condition that checks if the specified timestamp column is later than t.
+// As the catalog stores timestamps with a precision of seconds, t is truncated to seconds.
+func After(column msg.ColumnNumber, t time.Time) Condition {
+	return Condition{
+		Column: column,
+		Op:     ">",
+		Value:  fmt.Sprintf("'%s'", formatTimestamp(t)),
+	}
+}
+
 // In creates a Condition that checks if the specified column is in the given list of values.
 // Note that it is not safe to use this method if one of the values contains a ' character,
 // and at least two values are provided.
@@ -130,26 +171,107 @@ func In[V string | int | int64](column msg.ColumnNumber, values []V) Condition {
 	}
 }
 
+// Between creates a Condition that checks if the specified column is between lo and hi, inclusive.
+// The values are escaped with quoteValue.
+func Between[V string | int | int64](column msg.ColumnNumber, lo, hi V) Condition {
+	// Use small caps BETWEEN condition for the same reason as in In
+	return Condition{
+		Column: column,
+		Op:     "between",
+		Value:  fmt.Sprintf("%s %s", quoteValue(lo), quoteValue(hi)),
+	}
+}
+
+// quoteValue quotes a value of a condition that consists of several values, escaping
+// single quotes and backslashes with a backslash, so that the server splits the condition
+// at the right place. Conditions with a single value such as Equal don't need escaping,
+// as the server passes everything between the first and the last quote as bind variable.
+func quoteValue[V string | int | int64](value V) string {
+	var b strings.Builder
+
+	b.WriteByte('\'')
+
+	for _, r := range fmt.Sprintf("%v", value) {
+		if r == '\'' || r == '\\' {
+			b.WriteByte('\\')
+		}
+
+		b.WriteRune(r)
+	}
+
+	b.WriteByte('\'')
+
+	return b.String()
+}
+
+// DefaultQueryBatchSize is the number of rows that is fetched per round trip
+// in queries, unless API.QueryBatchSize or PreparedQuery.BatchSize is set.
+const DefaultQueryBatchSize = 500
+
 // Query prepares a query to read from the irods catalog,
 // with the specified columns and their aggregation levels.
 func (api *API) Query(columns ...Column) PreparedQuery {
+	maxRows := api.QueryBatchSize
+
+	if maxRows <= 0 {
+		maxRows = DefaultQueryBatchSize
+	}
+
 	return PreparedQuery{
 		api:        api,
 		columns:    columns,
-		maxRows:    500,
+		maxRows:    maxRows,
 		conditions: make(map[msg.ColumnNumber]string),
 	}
 }
 
-// Where adds a condition to the query for the specified column.
-// The condition is a string that will be used to filter results
-// based on the specified column.
+// Where adds a raw condition to the query for the specified column, e.g. "= 'value'".
+// The condition is passed as is to the server, so values must be quoted by the caller.
+// Prefer WhereEqual, WhereIn, WhereLike and WhereBetween, or With, which build the condition.
 func (q PreparedQuery) Where(column msg.ColumnNumber, condition string) PreparedQuery {
 	q.conditions[column] = condition
 
 	return q
 }
 
+// WhereEqual adds a condition to the query that the specified column is equal to value, see Equal.
+func (q PreparedQuery) WhereEqual(column msg.ColumnNumber, value string) PreparedQuery {
+	return q.With(Equal(column, value))
+}
+
+// WhereIn adds a condition to the query that the specified column is one of the values.
+// In contrast to In, the values may contain ' characters, as they are escaped with quoteValue.
+func (q PreparedQuery) WhereIn(column msg.ColumnNumber, values ...string) PreparedQuery {
+	if len(values) == 1 {
+		return q.WhereEqual(column, values[0])
+	}
+
+	quoted := make([]string, len(values))
+
+	for i, v := range values {
+		quoted[i] = quoteValue(v)
+	}
+
+	// Use small caps IN condition for the same reason as in In
+	return q.With(Condition{
+		Column: column,
+		Op:     "in",
+		Value:  fmt.Sprintf("(%s)", strings.Join(quoted, ",")),
+	})
+}
+
+// WhereLike adds a condition to the query that the specified column matches the
+// SQL LIKE pattern, see Like.
+func (q PreparedQuery) WhereLike(column msg.ColumnNumber, pattern string) PreparedQuery {
+	return q.With(Like(column, pattern))
+}
+
+// WhereBetween adds a condition to the query that the specified column is between lo and hi,
+// inclusive, see Between. For numeric columns, use With(Between(column, lo, hi)) with integers.
+func (q PreparedQuery) WhereBetween(column msg.ColumnNumber, lo, hi string) PreparedQuery {
+	return q.With(Between(column, lo, hi))
+}
+
 // With adds a list of conditions to the query.
 func (q PreparedQuery) With(condition ...Condition) PreparedQuery {
 	for _, c := range condition {
@@ -159,6 +281,50 @@ func (q PreparedQuery) With(condition ...Condition) PreparedQuery {
 	return q
 }
 
+// Distinct only returns distinct rows. This is the default for general queries.
+func (q PreparedQuery) Distinct() PreparedQuery {
+	q.noDistinct = false
+
+	return q
+}
+
+// NoDistinct returns all rows, including duplicates. The server refuses
+// to combine it with aggregates, such queries fail with ErrInvalidQuery.
+func (q PreparedQuery) NoDistinct() PreparedQuery {
+	q.noDistinct = true
+
+	return q
+}
+
+// GroupBy groups the results by the given columns, so that the aggregates
+// are computed per group, e.g. the number and total size of the data objects
+// per resource. Grouped columns that are not selected yet are appended to the
+// selected columns, in the given order, so they can be scanned after the
+// other columns.
+// The catalog implicitly groups by all selected columns that are not
+// aggregated, so if GroupBy is used, every selected plain column must be
+// grouped; otherwise the query is refused with ErrInvalidQuery.
+func (q PreparedQuery) GroupBy(columns ...msg.ColumnNumber) PreparedQuery {
+	q.columns = slices.Clone(q.columns)
+	q.groupBy = slices.Clone(q.groupBy)
+
+	for _, column := range columns {
+		if slices.Contains(q.groupBy, column) {
+			continue
+		}
+
+		q.groupBy = append(q.groupBy, column)
+
+		if !slices.ContainsFunc(q.columns, func(c Column) bool {
+			return c.Int() == column.Int() && c.AggregationLevel() == aggregateNone
+		}) {
+			q.columns = append(q.columns, column)
+		}
+	}
+
+	return q
+}
+
 // Limit limits the number of results.
 func (q PreparedQuery) Limit(limit int) PreparedQuery {
 	q.resultLimit = limit
@@ -170,6 +336,59 @@ func (q PreparedQuery) Limit(limit int) PreparedQuery {
 	return q
 }
 
+// BatchSize sets the number of rows that is fetched per round trip.
+// Larger batches reduce the number of round trips on high-latency links.
+// If the server refuses the batch size with SYS_TOO_MANY_QUERY_RESULT,
+// the query is retried with DefaultQueryBatchSize.
+func (q PreparedQuery) BatchSize(size int) PreparedQuery {
+	if size <= 0 {
+		size = DefaultQueryBatchSize
+	}
+
+	if q.resultLimit > 0 && size > q.resultLimit {
+		size = q.resultLimit
+	}
+
+	q.maxRows = size
+
+	return q
+}
+
+// ErrInvalidQuery is returned if a query cannot be sent to the server
+var ErrInvalidQuery = errors.New("invalid query")
+
+// validate checks that the selected columns can be combined in a query,
+// and rejects the combinations that the server refuses.
+func (q PreparedQuery) validate() error {
+	if len(q.columns) == 0 {
+		return fmt.Errorf("%w: no columns selected", ErrInvalidQuery)
+	}
+
+	for _, col := range q.columns {
+		switch col.AggregationLevel() {
+		case aggregateNone, aggregateMin, aggregateMax, aggregateSum, aggregateAvg, aggregateCount:
+		default:
+			return fmt.Errorf("%w: unknown aggregation level %d for column %s", ErrInvalidQuery, col.AggregationLevel(), ColumnName(msg.ColumnNumber(col.Int())))
+		}
+
+		if q.noDistinct && col.AggregationLevel() != aggregateNone {
+			return fmt.Errorf("%w: aggregated column %s cannot be combined with NoDistinct", ErrInvalidQuery, ColumnName(msg.ColumnNumber(col.Int())))
+		}
+	}
+
+	if len(q.groupBy) == 0 {
+		return nil
+	}
+
+	for _, col := range q.columns {
+		if col.AggregationLevel() == aggregateNone && !slices.Contains(q.groupBy, msg.ColumnNumber(col.Int())) {
+			return fmt.Errorf("%w: column %s is neither grouped nor aggregated", ErrInvalidQuery, ColumnName(msg.ColumnNumber(col.Int())))
+		}
+	}
+
+	return nil
+}
+
 type QueryResult interface {
 	Err() error
 	Next() bool
@@ -181,6 +400,10 @@ type QueryResult interface {
 // This method blocks an irods connection until the result has been closed.
 // If the context is closed, no more results will be returned.
 func (q PreparedQuery) Execute(ctx context.Context) *Result {
+	if err := q.validate(); err != nil {
+		return &Result{err: err, Query: q}
+	}
+
 	conn, err := q.api.Connect(ctx)
 	if err != nil {
 		return &Result{err: err}
@@ -262,7 +485,20 @@ var ErrNoSQLResults = fmt.Errorf("no sql results")
 
 var ErrAttributeIndexMismatch = fmt.Errorf("attribute index mismatch")
 
+// Columns returns the column numbers of the result, in the order in which
+// they are scanned. If the server did not return any rows, the columns of the
+// query are returned.
 func (r *Result) Columns() []msg.ColumnNumber {
+	if r.result == nil || r.result.AttributeCount == 0 {
+		columns := make([]msg.ColumnNumber, len(r.Query.columns))
+
+		for i, col := range r.Query.columns {
+			columns[i] = msg.ColumnNumber(col.Int())
+		}
+
+		return columns
+	}
+
 	columns := make([]msg.ColumnNumber, r.result.AttributeCount)
 
 	for i := range r.result.AttributeCount {
@@ -272,6 +508,43 @@ func (r *Result) Columns() []msg.ColumnNumber {
 	return columns
 }
 
+// ColumnNames returns the canonical names of the columns of the result,
+// as returned by ColumnName, in the order in which they are scanned.
+func (r *Result) ColumnNames() []string {
+	columns := r.Columns()
+	names := make([]string, len(columns))
+
+	for i, column := range columns {
+		names[i] = ColumnName(column)
+	}
+
+	return names
+}
+
+// ScanMap reads the values in the current row into a map, keyed by
+// the names of the columns as returned by ColumnNames.
+func (r *Result) ScanMap() (map[string]string, error) {
+	names := r.ColumnNames()
+	values := make([]string, len(names))
+	dest := make([]any, len(names))
+
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	if err := r.Scan(dest...); err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]string, len(names))
+
+	for i, name := range names {
+		m[name] = values[i]
+	}
+
+	return m, nil
+}
+
 // Scan reads the values in the current row into the values pointed
 // to by dest, in order.  If an error occurs during scanning, the
 // error is returned. The values pointed to by dest before the error
@@ -324,15 +597,20 @@ func (r *Result) Close() error {
 func (r *Result) buildQuery() {
 	r.query = &msg.QueryRequest{
 		MaxRows: r.Query.maxRows,
-		Options: 0x20,
+		Options: returnTotalRowCount,
+	}
+
+	if r.Query.noDistinct {
+		r.query.Options |= noDistinct
 	}
 
 	for _, col := range r.Query.columns {
 		r.query.Selects.Add(col.Int(), col.AggregationLevel())
 	}
 
-	for col, condition := range r.Query.conditions {
-		r.query.Conditions.Add(col.Int(), condition)
+	// Add the conditions in a fixed order, so that requests are reproducible
+	for _, col := range slices.Sorted(maps.Keys(r.Query.conditions)) {
+		r.query.Conditions.Add(col.Int(), r.Query.conditions[col])
 	}
 
 	r.Query.api.setFlags(&r.query.KeyVals)
@@ -344,6 +622,15 @@ func (r *Result) executeQuery() {
 	r.err = r.Conn.Request(r.Context, msg.GEN_QUERY_AN, r.query, r.result)
 	r.row = -1
 
+	// Fall back to the default batch size if the server refuses the requested one
+	if Is(r.err, msg.SYS_TOO_MANY_QUERY_RESULT) && r.query.MaxRows > DefaultQueryBatchSize {
+		r.query.MaxRows = DefaultQueryBatchSize
+
+		r.executeQuery()
+
+		return
+	}
+
 	if Is(r.err, msg.CAT_NO_ROWS_FOUND) {
 		r.err = nil
 	}
@@ -479,6 +766,20 @@ func (r PreparedSingleRowQuery) With(condition ...Condition) PreparedSingleRowQu
 	return r
 }
 
+// Distinct only returns distinct rows. This is the default for general queries.
+func (r PreparedSingleRowQuery) Distinct() PreparedSingleRowQuery {
+	r.noDistinct = false
+
+	return r
+}
+
+// NoDistinct returns all rows, including duplicates. It cannot be combined with aggregates.
+func (r PreparedSingleRowQuery) NoDistinct() PreparedSingleRowQuery {
+	r.noDistinct = true
+
+	return r
+}
+
 // Execute executes the query.
 func (r PreparedSingleRowQuery) Execute(ctx context.Context) *SingleRowResult {
 	result := PreparedQuery(r).Execute(ctx)
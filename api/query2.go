@@ -0,0 +1,298 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+// Query2 executes a query written in the GenQuery2 syntax, e.g.
+//
+//	SELECT DATA_NAME, DATA_SIZE WHERE COLL_NAME = '/zone/home' AND DATA_SIZE > '1000' LIMIT 10
+//
+// and returns a generic row iterator. GenQuery2 is used if the server supports it
+// (iRODS 4.3.2 or later). Otherwise, the query is translated to a regular GenQuery.
+// In the latter case only a subset of the syntax is supported: a list of columns,
+// optionally wrapped in COUNT, SUM, MIN, MAX or AVG, conditions joined by AND,
+// with at most one condition per column, and a LIMIT clause.
+func (api *API) Query2(ctx context.Context, query string) *GenericResult {
+	supported, err := api.supportsGenQuery2(ctx)
+	if err != nil {
+		return &GenericResult{err: err}
+	}
+
+	if supported {
+		result := api.GenericQuery(query).Execute(ctx)
+
+		if code, ok := ErrorCode(result.err); !ok || code != msg.SYS_UNMATCHED_API_NUM {
+			return result
+		}
+	}
+
+	return api.executeGenQuery1(ctx, query)
+}
+
+type serverVersioner interface {
+	ServerVersion() string
+}
+
+// supportsGenQuery2 checks the version of the server. If the connection
+// does not expose the server version, GenQuery2 is assumed to be available.
+func (api *API) supportsGenQuery2(ctx context.Context) (bool, error) {
+	conn, err := api.Connect(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	defer conn.Close()
+
+	v, ok := conn.(serverVersioner)
+	if !ok {
+		return true, nil
+	}
+
+	return versionAtLeast(v.ServerVersion(), 4, 3, 2), nil
+}
+
+func versionAtLeast(version string, want ...int) bool {
+	parts := strings.Split(strings.TrimPrefix(version, "rods"), ".")
+
+	for i, w := range want {
+		if i >= len(parts) {
+			return false
+		}
+
+		n, err := strconv.Atoi(parts[i])
+		if err != nil {
+			return false
+		}
+
+		if n != w {
+			return n > w
+		}
+	}
+
+	return true
+}
+
+// ErrUnsupportedQuery is returned if a GenQuery2 query cannot be translated to a regular GenQuery
+var ErrUnsupportedQuery = errors.New("query cannot be translated to genquery")
+
+// PrepareQuery2 translates a query written in the GenQuery2 syntax to a regular GenQuery,
+// with the same restrictions as the fallback of Query2. In contrast to the result of Query2,
+// the result of the prepared query knows its columns, see Result.ScanMap.
+func (api *API) PrepareQuery2(query string) (PreparedQuery, error) {
+	return api.parseGenQuery1(query)
+}
+
+func (api *API) executeGenQuery1(ctx context.Context, query string) *GenericResult {
+	q, err := api.parseGenQuery1(query)
+	if err != nil {
+		return &GenericResult{err: err}
+	}
+
+	result := q.Execute(ctx)
+
+	defer result.Close()
+
+	rows := [][]string{}
+
+	for result.Next() {
+		row := make([]string, len(q.columns))
+		ptrs := make([]any, len(q.columns))
+
+		for i := range row {
+			ptrs[i] = &row[i]
+		}
+
+		if err := result.Scan(ptrs...); err != nil {
+			return &GenericResult{err: err}
+		}
+
+		rows = append(rows, row)
+	}
+
+	if err := result.Err(); err != nil {
+		return &GenericResult{err: err}
+	}
+
+	return &GenericResult{
+		rows: rows,
+	}
+}
+
+var aggregates = map[string]func(msg.ColumnNumber) Column{
+	"MIN":   Min,
+	"MAX":   Max,
+	"SUM":   Sum,
+	"AVG":   Avg,
+	"COUNT": Count,
+}
+
+func (api *API) parseGenQuery1(query string) (PreparedQuery, error) { //nolint:funlen
+	query = strings.TrimSpace(query)
+
+	if !strings.HasPrefix(strings.ToUpper(query), "SELECT ") {
+		return PreparedQuery{}, fmt.Errorf("%w: expected SELECT", ErrUnsupportedQuery)
+	}
+
+	query = query[len("SELECT "):]
+
+	limit := 0
+
+	if parts := splitKeyword(query, "LIMIT"); len(parts) == 2 {
+		n, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil {
+			return PreparedQuery{}, fmt.Errorf("%w: invalid limit %s", ErrUnsupportedQuery, parts[1])
+		}
+
+		query, limit = parts[0], n
+	} else if len(parts) > 2 {
+		return PreparedQuery{}, fmt.Errorf("%w: multiple LIMIT clauses", ErrUnsupportedQuery)
+	}
+
+	for _, keyword := range []string{"ORDER", "GROUP", "OFFSET", "OR"} {
+		if len(splitKeyword(query, keyword)) > 1 {
+			return PreparedQuery{}, fmt.Errorf("%w: %s is not supported", ErrUnsupportedQuery, keyword)
+		}
+	}
+
+	selectPart, wherePart := query, ""
+
+	if parts := splitKeyword(query, "WHERE"); len(parts) == 2 {
+		selectPart, wherePart = parts[0], parts[1]
+	} else if len(parts) > 2 {
+		return PreparedQuery{}, fmt.Errorf("%w: multiple WHERE clauses", ErrUnsupportedQuery)
+	}
+
+	selectPart = strings.TrimSpace(selectPart)
+
+	if strings.HasPrefix(strings.ToUpper(selectPart), "DISTINCT ") {
+		selectPart = selectPart[len("DISTINCT "):]
+	}
+
+	var columns []Column
+
+	for _, field := range strings.Split(selectPart, ",") {
+		column, err := parseGenQuery1Column(strings.TrimSpace(field))
+		if err != nil {
+			return PreparedQuery{}, err
+		}
+
+		columns = append(columns, column)
+	}
+
+	q := api.Query(columns...).Limit(limit)
+
+	if strings.TrimSpace(wherePart) == "" {
+		return q, nil
+	}
+
+	for _, cond := range splitKeyword(wherePart, "AND") {
+		name, condition, ok := strings.Cut(strings.TrimSpace(cond), " ")
+		if !ok {
+			return PreparedQuery{}, fmt.Errorf("%w: invalid condition %s", ErrUnsupportedQuery, cond)
+		}
+
+		column, ok := msg.ColumnByName(name)
+		if !ok {
+			return PreparedQuery{}, fmt.Errorf("%w: unknown column %s", ErrUnsupportedQuery, name)
+		}
+
+		if _, ok := q.conditions[column]; ok {
+			return PreparedQuery{}, fmt.Errorf("%w: multiple conditions on %s", ErrUnsupportedQuery, name)
+		}
+
+		condition = strings.TrimSpace(condition)
+
+		switch {
+		case strings.HasPrefix(condition, "!="):
+			condition = "<>" + condition[2:]
+		case strings.HasPrefix(strings.ToUpper(condition), "IN "), strings.HasPrefix(strings.ToUpper(condition), "IN("):
+			// Use small caps, see In()
+			condition = "in" + condition[2:]
+		}
+
+		q = q.Where(column, condition)
+	}
+
+	return q, nil
+}
+
+func parseGenQuery1Column(field string) (Column, error) {
+	fn, rest, ok := strings.Cut(field, "(")
+	if !ok {
+		column, ok := msg.ColumnByName(field)
+		if !ok {
+			return nil, fmt.Errorf("%w: unknown column %s", ErrUnsupportedQuery, field)
+		}
+
+		return column, nil
+	}
+
+	aggregate, ok := aggregates[strings.ToUpper(strings.TrimSpace(fn))]
+	if !ok || !strings.HasSuffix(rest, ")") {
+		return nil, fmt.Errorf("%w: unsupported function %s", ErrUnsupportedQuery, field)
+	}
+
+	column, err := parseGenQuery1Column(strings.TrimSpace(strings.TrimSuffix(rest, ")")))
+	if err != nil {
+		return nil, err
+	}
+
+	number, ok := column.(msg.ColumnNumber)
+	if !ok {
+		return nil, fmt.Errorf("%w: nested function %s", ErrUnsupportedQuery, field)
+	}
+
+	return aggregate(number), nil
+}
+
+// splitKeyword splits the query on the given keyword, ignoring
+// occurrences inside quoted strings or parentheses.
+func splitKeyword(query, keyword string) []string {
+	var (
+		parts  []string
+		start  int
+		depth  int
+		quoted bool
+	)
+
+	upper := strings.ToUpper(query)
+
+	for i := 0; i < len(query); i++ {
+		switch query[i] {
+		case '\'':
+			quoted = !quoted
+		case '(':
+			if !quoted {
+				depth++
+			}
+		case ')':
+			if !quoted {
+				depth--
+			}
+		case ' ':
+			if quoted || depth > 0 || !strings.HasPrefix(upper[i+1:], keyword+" ") {
+				continue
+			}
+
+			parts = append(parts, query[start:i])
+			i += len(keyword) + 1
+			start = i
+		}
+	}
+
+	return append(parts, query[start:])
+}
+
+// ColumnName returns the canonical name of a column, as used by iquest and
+// GenQuery2, e.g. DATA_NAME for msg.ICAT_COLUMN_DATA_NAME. Columns without
+// a name are returned as their number.
+func ColumnName(column msg.ColumnNumber) string {
+	return column.String()
+}
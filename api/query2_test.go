@@ -0,0 +1,122 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+type versionedConn struct {
+	*MockConn
+	version string
+}
+
+func (c *versionedConn) ServerVersion() string {
+	return c.version
+}
+
+func TestQuery2(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.String{
+		String: `[["test", "1"]]`,
+	})
+
+	result := testAPI.Query2(t.Context(), "SELECT DATA_NAME, DATA_SIZE")
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if rows := result.Rows(); len(rows) != 1 || rows[0][0] != "test" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
+
+func TestQuery2Fallback(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(&msg.IRODSError{
+		Code: msg.SYS_UNMATCHED_API_NUM,
+	})
+	testAPI.AddResponse(msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 2,
+		TotalRowCount:  1,
+		ContinueIndex:  0,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 403, ResultLen: 1, Values: []string{"test"}},
+			{AttributeIndex: 407, ResultLen: 1, Values: []string{"100"}},
+		},
+	})
+
+	result := testAPI.Query2(t.Context(), "SELECT DATA_NAME, SUM(DATA_SIZE) WHERE COLL_NAME = '/test' AND DATA_NAME in ('a', 'b') LIMIT 1")
+	if err := result.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if rows := result.Rows(); len(rows) != 1 || rows[0][1] != "100" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
+
+func TestQuery2OldServer(t *testing.T) {
+	testConn := &versionedConn{
+		MockConn: &MockConn{},
+		version:  "4.2.11",
+	}
+
+	testAPI := &API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (Conn, error) {
+			return testConn, nil
+		},
+	}
+
+	testConn.AddResponse(msg.QueryResponse{})
+
+	if err := testAPI.Query2(t.Context(), "select COLL_NAME where COLL_NAME like '/test/%'").Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, query := range []string{
+		"DATA_NAME",
+		"SELECT UNKNOWN_COLUMN",
+		"SELECT DATA_NAME WHERE DATA_SIZE > '1' AND DATA_SIZE < '5'",
+		"SELECT DATA_NAME WHERE DATA_SIZE > '1' OR DATA_SIZE < '5'",
+		"SELECT DATA_NAME ORDER BY DATA_NAME",
+		"SELECT CONCAT(DATA_NAME)",
+		"SELECT DATA_NAME LIMIT x",
+	} {
+		if err := testAPI.Query2(t.Context(), query).Err(); !errors.Is(err, ErrUnsupportedQuery) {
+			t.Errorf("%s: expected %v, got %v", query, ErrUnsupportedQuery, err)
+		}
+	}
+}
+
+func TestSplitKeyword(t *testing.T) {
+	parts := splitKeyword("A = 'x and y' AND B in ('a', 'b') and C = 'z'", "AND")
+
+	if len(parts) != 3 {
+		t.Fatalf("expected 3 parts, got %v", parts)
+	}
+}
+
+func TestVersionAtLeast(t *testing.T) {
+	for version, expected := range map[string]bool{
+		"4.3.2":     true,
+		"4.3.4":     true,
+		"5.0.1":     true,
+		"rods5.0.1": true,
+		"4.3.1":     false,
+		"4.2.12":    false,
+		"4.3":       false,
+		"bad":       false,
+	} {
+		if versionAtLeast(version, 4, 3, 2) != expected {
+			t.Errorf("%s: expected %v", version, expected)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package api
 
 import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
 	"testing"
 	"time"
 
@@ -114,3 +118,383 @@ func TestQueryRow(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestQueryDistinct(t *testing.T) {
+	testAPI := newAPI()
+
+	request := msg.QueryRequest{
+		MaxRows: DefaultQueryBatchSize,
+		Options: 0x20 | 0x40,
+	}
+
+	request.Selects.Add(int(msg.ICAT_COLUMN_DATA_SIZE), 1)
+	request.Selects.Add(int(msg.ICAT_COLUMN_D_OWNER_NAME), 1)
+
+	testAPI.Add(msg.GEN_QUERY_AN, request, msg.QueryResponse{})
+
+	request.Options = 0x20
+	request.Selects = msg.IIKeyVal{}
+
+	request.Selects.Add(int(msg.ICAT_COLUMN_D_MODIFY_TIME), 3)
+	request.Selects.Add(int(msg.ICAT_COLUMN_COLL_NAME), 1)
+
+	testAPI.Add(msg.GEN_QUERY_AN, request, msg.QueryResponse{})
+
+	results := testAPI.Query(msg.ICAT_COLUMN_DATA_SIZE, msg.ICAT_COLUMN_D_OWNER_NAME).NoDistinct().Execute(t.Context())
+
+	if results.Next() {
+		t.Fatal("expected no results")
+	}
+
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	results = testAPI.Query(Max(msg.ICAT_COLUMN_D_MODIFY_TIME), msg.ICAT_COLUMN_COLL_NAME).NoDistinct().Distinct().Execute(t.Context())
+
+	if results.Next() {
+		t.Fatal("expected no results")
+	}
+
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestQueryWhereHelpers(t *testing.T) {
+	testAPI := newAPI()
+
+	request := msg.QueryRequest{
+		MaxRows: DefaultQueryBatchSize,
+		Options: 0x20,
+	}
+
+	request.Selects.Add(int(msg.ICAT_COLUMN_D_DATA_ID), 1)
+	request.Conditions.Add(int(msg.ICAT_COLUMN_DATA_NAME), `in ('it\'s','back\\slash','plain')`)
+	request.Conditions.Add(int(msg.ICAT_COLUMN_DATA_SIZE), "between '1024' '4096'")
+	request.Conditions.Add(int(msg.ICAT_COLUMN_D_OWNER_NAME), "LIKE 'rods%'")
+	request.Conditions.Add(int(msg.ICAT_COLUMN_COLL_NAME), "= '/testzone/it's'")
+
+	testAPI.Add(msg.GEN_QUERY_AN, request, msg.QueryResponse{})
+
+	request.Conditions = msg.ISKeyVal{}
+
+	request.Conditions.Add(int(msg.ICAT_COLUMN_D_MODIFY_TIME), "between '01700000000' '01800000000'")
+
+	testAPI.Add(msg.GEN_QUERY_AN, request, msg.QueryResponse{})
+
+	results := testAPI.Query(msg.ICAT_COLUMN_D_DATA_ID).
+		WhereIn(msg.ICAT_COLUMN_DATA_NAME, "it's", `back\slash`, "plain").
+		With(Between(msg.ICAT_COLUMN_DATA_SIZE, 1024, 4096)).
+		WhereLike(msg.ICAT_COLUMN_D_OWNER_NAME, "rods%").
+		WhereEqual(msg.ICAT_COLUMN_COLL_NAME, "/testzone/it's").
+		Execute(t.Context())
+
+	if results.Next() {
+		t.Fatal("expected no results")
+	}
+
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	results = testAPI.Query(msg.ICAT_COLUMN_D_DATA_ID).
+		WhereBetween(msg.ICAT_COLUMN_D_MODIFY_TIME, "01700000000", "01800000000").
+		Execute(t.Context())
+
+	if results.Next() {
+		t.Fatal("expected no results")
+	}
+
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestInUnescaped(t *testing.T) {
+	// In is used by existing callers with values that are passed as is
+	if c := In(msg.ICAT_COLUMN_DATA_NAME, []string{"it's", `a\b`}); c.Op != "in" || c.Value != `('it's','a\b')` {
+		t.Errorf("unexpected condition: %s %s", c.Op, c.Value)
+	}
+
+	if c := In(msg.ICAT_COLUMN_D_DATA_ID, []int64{1, 2}); c.Value != "('1','2')" {
+		t.Errorf("unexpected condition: %s %s", c.Op, c.Value)
+	}
+}
+
+func TestQuoteValue(t *testing.T) {
+	for value, expected := range map[string]string{
+		"plain": "'plain'",
+		"it's":  `'it\'s'`,
+		`a\b`:   `'a\\b'`,
+		`\'`:    `'\\\''`,
+		"":      "''",
+	} {
+		if quoted := quoteValue(value); quoted != expected {
+			t.Errorf("expected %s, got %s", expected, quoted)
+		}
+	}
+
+	if quoted := quoteValue(int64(42)); quoted != "'42'" {
+		t.Errorf("expected '42', got %s", quoted)
+	}
+}
+
+func TestQueryInvalid(t *testing.T) {
+	testAPI := newAPI()
+
+	if err := testAPI.Query().Execute(t.Context()).Err(); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("expected ErrInvalidQuery, got %v", err)
+	}
+
+	invalid := col{columnNumber: msg.ICAT_COLUMN_DATA_SIZE, aggregationLevel: 7}
+
+	if err := testAPI.QueryRow(invalid).Execute(t.Context()).Scan(new(int64)); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("expected ErrInvalidQuery, got %v", err)
+	}
+
+	// The server refuses aggregates without distinct
+	if err := testAPI.Query(Sum(msg.ICAT_COLUMN_DATA_SIZE), msg.ICAT_COLUMN_D_OWNER_NAME).NoDistinct().Execute(t.Context()).Err(); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("expected ErrInvalidQuery, got %v", err)
+	}
+
+	if err := testAPI.QueryRow(Count(msg.ICAT_COLUMN_D_DATA_ID)).NoDistinct().Execute(t.Context()).Scan(new(int64)); !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("expected ErrInvalidQuery, got %v", err)
+	}
+}
+
+func TestQueryGroupBy(t *testing.T) {
+	testAPI := newAPI()
+
+	request := msg.QueryRequest{
+		MaxRows: DefaultQueryBatchSize,
+		Options: 0x20,
+	}
+
+	request.Selects.Add(int(msg.ICAT_COLUMN_D_DATA_ID), 6)
+	request.Selects.Add(int(msg.ICAT_COLUMN_DATA_SIZE), 4)
+	request.Selects.Add(int(msg.ICAT_COLUMN_D_RESC_NAME), 1)
+
+	testAPI.Add(msg.GEN_QUERY_AN, request, msg.QueryResponse{
+		RowCount:       2,
+		AttributeCount: 3,
+		TotalRowCount:  2,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: msg.ICAT_COLUMN_D_DATA_ID, ResultLen: 2, Values: []string{"3", "1"}},
+			{AttributeIndex: msg.ICAT_COLUMN_DATA_SIZE, ResultLen: 2, Values: []string{"300", "5"}},
+			{AttributeIndex: msg.ICAT_COLUMN_D_RESC_NAME, ResultLen: 2, Values: []string{"demoResc", "archive"}},
+		},
+	})
+
+	query := testAPI.Query(Count(msg.ICAT_COLUMN_D_DATA_ID), Sum(msg.ICAT_COLUMN_DATA_SIZE))
+
+	results := query.GroupBy(msg.ICAT_COLUMN_D_RESC_NAME, msg.ICAT_COLUMN_D_RESC_NAME).Execute(t.Context())
+
+	totals := map[string]int64{}
+
+	for results.Next() {
+		var (
+			count, size int64
+			resc        string
+		)
+
+		if err := results.Scan(&count, &size, &resc); err != nil {
+			t.Fatal(err)
+		}
+
+		totals[resc] = size
+	}
+
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if totals["demoResc"] != 300 || totals["archive"] != 5 {
+		t.Fatalf("unexpected totals: %v", totals)
+	}
+
+	if len(query.columns) != 2 {
+		t.Fatal("expected GroupBy not to modify the original query")
+	}
+
+	err := testAPI.Query(Count(msg.ICAT_COLUMN_D_DATA_ID), msg.ICAT_COLUMN_COLL_NAME).GroupBy(msg.ICAT_COLUMN_D_RESC_NAME).Execute(t.Context()).Err()
+	if !errors.Is(err, ErrInvalidQuery) {
+		t.Fatalf("expected ErrInvalidQuery, got %v", err)
+	}
+}
+
+func TestResultScanMap(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.QueryResponse{
+		RowCount:       2,
+		AttributeCount: 3,
+		TotalRowCount:  2,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 500, ResultLen: 2, Values: []string{"1", "2"}},
+			{AttributeIndex: 501, ResultLen: 2, Values: []string{"/test", "/test/coll"}},
+			{AttributeIndex: 999, ResultLen: 2, Values: []string{"a", "b"}},
+		},
+	})
+
+	results := testAPI.Query(msg.ICAT_COLUMN_COLL_ID, msg.ICAT_COLUMN_COLL_NAME, msg.ColumnNumber(999)).Execute(t.Context())
+
+	defer results.Close()
+
+	if !results.Next() {
+		t.Fatal(results.Err())
+	}
+
+	if columns := results.Columns(); !slices.Equal(columns, []msg.ColumnNumber{msg.ICAT_COLUMN_COLL_ID, msg.ICAT_COLUMN_COLL_NAME, 999}) {
+		t.Fatalf("unexpected columns: %v", columns)
+	}
+
+	if names := results.ColumnNames(); !slices.Equal(names, []string{"COLL_ID", "COLL_NAME", "999"}) {
+		t.Fatalf("unexpected column names: %v", names)
+	}
+
+	var rows []map[string]string
+
+	for {
+		row, err := results.ScanMap()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rows = append(rows, row)
+
+		if !results.Next() {
+			break
+		}
+	}
+
+	if len(rows) != 2 || rows[1]["COLL_NAME"] != "/test/coll" || rows[1]["COLL_ID"] != "2" || rows[0]["999"] != "a" {
+		t.Fatalf("unexpected rows: %v", rows)
+	}
+}
+
+func TestResultColumnsWithoutRows(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.QueryResponse{})
+
+	results := testAPI.Query(msg.ICAT_COLUMN_DATA_NAME, msg.ICAT_COLUMN_DATA_SIZE).Execute(t.Context())
+
+	if results.Next() {
+		t.Fatal("expected no results")
+	}
+
+	if names := results.ColumnNames(); !slices.Equal(names, []string{"DATA_NAME", "DATA_SIZE"}) {
+		t.Fatalf("unexpected column names: %v", names)
+	}
+}
+
+func TestQueryBatchSize(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.QueryBatchSize = 2000
+
+	request := msg.QueryRequest{
+		MaxRows: 2000,
+		Options: 0x20,
+	}
+
+	request.Selects.Add(int(msg.ICAT_COLUMN_COLL_ID), 1)
+
+	testAPI.Add(msg.GEN_QUERY_AN, request, &msg.IRODSError{Code: msg.SYS_TOO_MANY_QUERY_RESULT})
+
+	request.MaxRows = DefaultQueryBatchSize
+
+	testAPI.Add(msg.GEN_QUERY_AN, request, msg.QueryResponse{})
+
+	results := testAPI.Query(msg.ICAT_COLUMN_COLL_ID).Execute(t.Context())
+
+	if results.Next() {
+		t.Fatal("expected no results")
+	}
+
+	if err := results.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if q := testAPI.Query(msg.ICAT_COLUMN_COLL_ID).Limit(10).BatchSize(100); q.maxRows != 10 {
+		t.Fatalf("expected batch size to be capped by the limit, got %d", q.maxRows)
+	}
+}
+
+// pagingConn serves a query result of the given number of rows,
+// in batches of the requested size, and counts the round trips.
+type pagingConn struct {
+	MockConn
+	rows       int
+	roundTrips int
+}
+
+func (c *pagingConn) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	c.roundTrips++
+
+	query := request.(*msg.QueryRequest)
+
+	offset := query.ContinueIndex
+	n := min(query.MaxRows, c.rows-offset)
+
+	if query.MaxRows == 0 {
+		n = 0
+	}
+
+	values := make([]string, n)
+
+	for i := range n {
+		values[i] = strconv.Itoa(offset + i)
+	}
+
+	result := response.(*msg.QueryResponse)
+
+	*result = msg.QueryResponse{
+		RowCount:       n,
+		AttributeCount: 1,
+		TotalRowCount:  c.rows,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 500, ResultLen: n, Values: values},
+		},
+	}
+
+	if offset+n < c.rows && n > 0 {
+		result.ContinueIndex = offset + n
+	}
+
+	return nil
+}
+
+func BenchmarkQueryBatchSize(b *testing.B) {
+	for _, size := range []int{DefaultQueryBatchSize, 2500, 10000} {
+		b.Run(strconv.Itoa(size), func(b *testing.B) {
+			conn := &pagingConn{rows: 10000}
+
+			testAPI := &API{
+				Connect: func(context.Context) (Conn, error) {
+					return conn, nil
+				},
+				QueryBatchSize: size,
+			}
+
+			for b.Loop() {
+				results := testAPI.Query(msg.ICAT_COLUMN_COLL_ID).Execute(b.Context())
+
+				for results.Next() {
+					var id int64
+
+					if err := results.Scan(&id); err != nil {
+						b.Fatal(err)
+					}
+				}
+
+				if err := results.Close(); err != nil {
+					b.Fatal(err)
+				}
+			}
+
+			b.ReportMetric(float64(conn.roundTrips)/float64(b.N), "roundtrips/op")
+		})
+	}
+}
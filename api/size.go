@@ -0,0 +1,87 @@
+package api
+
+import (
+	"context"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+// aggregationUnsupported lists the error codes with which a server refuses aggregate queries
+var aggregationUnsupported = []msg.ErrorCode{
+	msg.CAT_SQL_ERR,
+	msg.CAT_INVALID_ARGUMENT,
+	msg.SYS_INVALID_INPUT_PARAM,
+}
+
+// CollectionSize returns the total size and the number of replicas of the
+// data objects in the given collection and all of its subcollections.
+// A data object with two replicas counts twice, in both numbers.
+// The aggregation is done server side using SUM and COUNT queries.
+// If the server refuses to aggregate, the collection is walked and
+// the sizes are summed client side.
+func (api *API) CollectionSize(ctx context.Context, path string) (int64, int64, error) {
+	size, count, err := api.aggregateCollectionSize(ctx, path)
+
+	for _, code := range aggregationUnsupported {
+		if Is(err, code) {
+			return api.walkCollectionSize(ctx, path)
+		}
+	}
+
+	return size, count, err
+}
+
+func (api *API) aggregateCollectionSize(ctx context.Context, path string) (int64, int64, error) {
+	conditions := []Condition{
+		Equal(msg.ICAT_COLUMN_COLL_NAME, path),
+		Like(msg.ICAT_COLUMN_COLL_NAME, escapeLike(path)+"/%"),
+	}
+
+	if path == "/" {
+		conditions = []Condition{
+			Like(msg.ICAT_COLUMN_COLL_NAME, "/%"),
+		}
+	}
+
+	var totalSize, totalCount int64
+
+	for _, condition := range conditions {
+		var size, count int64
+
+		err := api.QueryRow(Sum(msg.ICAT_COLUMN_DATA_SIZE), Count(msg.ICAT_COLUMN_D_DATA_ID)).With(condition).Execute(ctx).Scan(&size, &count)
+		if code, ok := ErrorCode(err); ok && code == msg.CAT_NO_ROWS_FOUND {
+			continue
+		} else if err != nil {
+			return 0, 0, err
+		}
+
+		totalSize += size
+		totalCount += count
+	}
+
+	return totalSize, totalCount, nil
+}
+
+func (api *API) walkCollectionSize(ctx context.Context, path string) (int64, int64, error) {
+	var size, count int64
+
+	err := api.Walk(ctx, path, func(_ string, record Record, err error) error {
+		if err != nil {
+			return err
+		}
+
+		obj, ok := record.Sys().(*DataObject)
+		if !ok {
+			return nil
+		}
+
+		for _, replica := range obj.Replicas {
+			size += replica.Size
+			count++
+		}
+
+		return nil
+	})
+
+	return size, count, err
+}
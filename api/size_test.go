@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestCollectionSize(t *testing.T) {
+	testAPI := newAPI()
+
+	for range 2 {
+		testAPI.AddResponse(msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 2,
+			TotalRowCount:  1,
+			ContinueIndex:  0,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"100"}},
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"2"}},
+			},
+		})
+	}
+
+	size, count, err := testAPI.CollectionSize(t.Context(), "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size != 200 || count != 4 {
+		t.Fatalf("expected 200 bytes in 4 replicas, got %d bytes in %d replicas", size, count)
+	}
+}
+
+func TestCollectionSizeFallback(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(&msg.IRODSError{
+		Code: msg.CAT_SQL_ERR,
+	})
+	testAPI.AddResponses([]any{
+		responses[0],
+		msg.QueryResponse{},
+		responses[2],
+	})
+
+	size, count, err := testAPI.CollectionSize(t.Context(), "/test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if size != 2048000 || count != 2 {
+		t.Fatalf("expected 2048000 bytes in 2 objects, got %d bytes in %d objects", size, count)
+	}
+}
+
+func TestCollectionSizeEscape(t *testing.T) {
+	conn := &conditionConn{}
+
+	testAPI := &API{
+		Connect: func(context.Context) (Conn, error) {
+			return conn, nil
+		},
+	}
+
+	conn.AddResponses([]any{msg.QueryResponse{}, msg.QueryResponse{}})
+
+	if _, _, err := testAPI.CollectionSize(t.Context(), "/test/my_coll"); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(conn.conditions) != 2 || conn.conditions[1][int(msg.ICAT_COLUMN_COLL_NAME)] != `LIKE '/test/my\_coll/%'` {
+		t.Fatalf("unexpected conditions: %v", conn.conditions)
+	}
+}
+
+func TestCollectionSizeError(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(&msg.IRODSError{
+		Code: msg.CAT_NO_ACCESS_PERMISSION,
+	})
+
+	if _, _, err := testAPI.CollectionSize(t.Context(), "/test"); !Is(err, msg.CAT_NO_ACCESS_PERMISSION) {
+		t.Fatalf("expected CAT_NO_ACCESS_PERMISSION, got %v", err)
+	}
+}
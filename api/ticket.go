@@ -0,0 +1,176 @@
+package api
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kuleuven/iron/msg"
+	"go.uber.org/multierr"
+)
+
+var ErrNoURLTemplate = errors.New("no URL template configured, set iron_url_template in the irods environment file")
+
+// TicketType is the type of access that a ticket grants
+type TicketType string
+
+const (
+	ReadTicket  TicketType = "read"
+	WriteTicket TicketType = "write"
+)
+
+const (
+	ticketLength   = 15
+	ticketAlphabet = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+)
+
+// CreateTicket creates a ticket for the given data object or collection,
+// and returns the ticket string. The ticket string is generated client side,
+// as iticket does.
+func (api *API) CreateTicket(ctx context.Context, path string, ticketType TicketType) (string, error) {
+	ticket := generateTicket()
+
+	request := msg.TicketAdminRequest{
+		Arg1: "create",
+		Arg2: ticket,
+		Arg3: string(ticketType),
+		Arg4: path,
+		Arg5: ticket,
+	}
+
+	api.setFlags(&request.KeyVals)
+
+	if err := api.Request(ctx, msg.TICKET_ADMIN_AN, request, &msg.EmptyResponse{}); err != nil {
+		return "", err
+	}
+
+	return ticket, nil
+}
+
+// SetTicketUses limits the number of times a ticket can be used.
+// A value of zero means unlimited.
+func (api *API) SetTicketUses(ctx context.Context, ticket string, uses int) error {
+	return api.modifyTicket(ctx, ticket, "uses", strconv.Itoa(uses))
+}
+
+// SetTicketExpiry sets the time after which a ticket can no longer be used.
+func (api *API) SetTicketExpiry(ctx context.Context, ticket string, expiry time.Time) error {
+	return api.modifyTicket(ctx, ticket, "expire", strconv.FormatInt(expiry.Unix(), 10))
+}
+
+func (api *API) modifyTicket(ctx context.Context, ticket, attribute, value string) error {
+	request := msg.TicketAdminRequest{
+		Arg1: "mod",
+		Arg2: ticket,
+		Arg3: attribute,
+		Arg4: value,
+	}
+
+	api.setFlags(&request.KeyVals)
+
+	return api.Request(ctx, msg.TICKET_ADMIN_AN, request, &msg.EmptyResponse{})
+}
+
+// DeleteTicket deletes a ticket.
+func (api *API) DeleteTicket(ctx context.Context, ticket string) error {
+	request := msg.TicketAdminRequest{
+		Arg1: "delete",
+		Arg2: ticket,
+	}
+
+	api.setFlags(&request.KeyVals)
+
+	return api.Request(ctx, msg.TICKET_ADMIN_AN, request, &msg.EmptyResponse{})
+}
+
+// ShareOptions controls the ticket that is created by ShareLink
+type ShareOptions struct {
+	Uses    int       // Maximum number of uses of the ticket, unlimited if zero
+	Expires time.Time // Expiry time of the ticket, no expiry if zero
+}
+
+// ShareLink creates a read ticket for the given data object or collection,
+// restricted according to the given options, and returns the gateway URL
+// embedding the ticket. It requires URLTemplate to be set.
+func (api *API) ShareLink(ctx context.Context, path string, opts ShareOptions) (string, error) {
+	u, err := api.GatewayURL(path)
+	if err != nil {
+		return "", err
+	}
+
+	ticket, err := api.CreateTicket(ctx, path, ReadTicket)
+	if err != nil {
+		return "", err
+	}
+
+	// The ticket is valid without restrictions until they are set,
+	// so delete it if they can't be applied
+	if err := api.restrictTicket(ctx, ticket, opts); err != nil {
+		return "", multierr.Append(err, api.DeleteTicket(ctx, ticket))
+	}
+
+	q := u.Query()
+	q.Set("ticket", ticket)
+	u.RawQuery = q.Encode()
+
+	return u.String(), nil
+}
+
+// restrictTicket applies the restrictions of the given options to a ticket.
+func (api *API) restrictTicket(ctx context.Context, ticket string, opts ShareOptions) error {
+	if opts.Uses > 0 {
+		if err := api.SetTicketUses(ctx, ticket, opts.Uses); err != nil {
+			return err
+		}
+	}
+
+	if !opts.Expires.IsZero() {
+		return api.SetTicketExpiry(ctx, ticket, opts.Expires)
+	}
+
+	return nil
+}
+
+// GatewayURL returns the URL of the given path on the web gateway,
+// by replacing {path} in URLTemplate by the escaped path.
+func (api *API) GatewayURL(path string) (*url.URL, error) {
+	if api.URLTemplate == "" {
+		return nil, ErrNoURLTemplate
+	}
+
+	escaped := (&url.URL{Path: path}).EscapedPath()
+
+	u, err := url.Parse(strings.ReplaceAll(api.URLTemplate, "{path}", escaped))
+	if err != nil {
+		return nil, fmt.Errorf("invalid URL template: %w", err)
+	}
+
+	return u, nil
+}
+
+// generateTicket returns a random ticket string. Random bytes that are not below the largest
+// multiple of the size of the alphabet are discarded, so that all characters are equally likely.
+func generateTicket() string {
+	var (
+		ticket = make([]byte, 0, ticketLength)
+		buf    = make([]byte, ticketLength)
+		limit  = 256 - 256%len(ticketAlphabet)
+	)
+
+	for len(ticket) < ticketLength {
+		rand.Read(buf) //nolint:errcheck
+
+		for _, b := range buf {
+			if int(b) < limit && len(ticket) < ticketLength {
+				ticket = append(ticket, ticketAlphabet[int(b)%len(ticketAlphabet)])
+			}
+		}
+	}
+
+	return string(ticket)
+}
@@ -0,0 +1,111 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestCreateTicket(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponse(msg.EmptyResponse{})
+
+	ticket, err := testAPI.CreateTicket(t.Context(), "/test/path", ReadTicket)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ticket) != ticketLength || strings.Trim(ticket, ticketAlphabet) != "" {
+		t.Fatalf("unexpected ticket %q", ticket)
+	}
+}
+
+func TestShareLink(t *testing.T) {
+	testAPI := newAPI()
+
+	if _, err := testAPI.ShareLink(t.Context(), "/test/path", ShareOptions{}); !errors.Is(err, ErrNoURLTemplate) {
+		t.Fatalf("expected ErrNoURLTemplate, got %v", err)
+	}
+
+	testAPI.URLTemplate = "https://data.example.org{path}"
+
+	testAPI.AddResponses([]any{msg.EmptyResponse{}, msg.EmptyResponse{}, msg.EmptyResponse{}})
+
+	link, err := testAPI.ShareLink(t.Context(), "/test/a file", ShareOptions{
+		Uses:    10,
+		Expires: time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(link, "https://data.example.org/test/a%20file?ticket=") {
+		t.Fatalf("unexpected link %s", link)
+	}
+}
+
+// ticketConn records the ticket admin requests
+type ticketConn struct {
+	*MockConn
+	requests []msg.TicketAdminRequest
+}
+
+func (c *ticketConn) RequestWithBuffers(ctx context.Context, apiNumber msg.APINumber, request, response any, requestBuf, responseBuf []byte) error {
+	if r, ok := request.(msg.TicketAdminRequest); ok {
+		c.requests = append(c.requests, r)
+	}
+
+	return c.MockConn.RequestWithBuffers(ctx, apiNumber, request, response, requestBuf, responseBuf)
+}
+
+func TestShareLinkDeletesTicket(t *testing.T) {
+	conn := &ticketConn{MockConn: &MockConn{}}
+
+	testAPI := &API{
+		Username:    "testuser",
+		Zone:        "testzone",
+		URLTemplate: "https://data.example.org{path}",
+		Connect: func(context.Context) (Conn, error) {
+			return conn, nil
+		},
+	}
+
+	errTest := errors.New("test error")
+
+	conn.AddResponses([]any{msg.EmptyResponse{}, errTest, msg.EmptyResponse{}})
+
+	if _, err := testAPI.ShareLink(t.Context(), "/test/path", ShareOptions{Uses: 10}); !errors.Is(err, errTest) {
+		t.Fatalf("expected the test error, got %v", err)
+	}
+
+	// The unrestricted ticket must not remain valid
+	if len(conn.requests) != 3 || conn.requests[2].Arg1 != "delete" || conn.requests[2].Arg2 != conn.requests[0].Arg2 {
+		t.Fatalf("expected the ticket to be deleted, got %v", conn.requests)
+	}
+}
+
+func TestGenerateTicketUniform(t *testing.T) {
+	counts := map[rune]int{}
+
+	for range 10000 {
+		for _, c := range generateTicket() {
+			counts[c]++
+		}
+	}
+
+	// Each character is expected 10000 * ticketLength / len(ticketAlphabet) times,
+	// with a standard deviation of about 2%. A modulo bias would favour the first
+	// characters of the alphabet by 25%.
+	expected := 10000 * ticketLength / len(ticketAlphabet)
+
+	for _, c := range ticketAlphabet {
+		if counts[c] < expected*9/10 || counts[c] > expected*11/10 {
+			t.Errorf("character %c occurs %d times, expected about %d", c, counts[c], expected)
+		}
+	}
+}
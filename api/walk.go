@@ -3,10 +3,12 @@ package api
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"slices"
 	"strings"
+	"time"
 
 	"github.com/kuleuven/iron/msg"
 )
@@ -20,9 +22,10 @@ type Record interface {
 
 type record struct {
 	os.FileInfo
-	metadata       []Metadata
-	access         []Access
-	collectionSize int64
+	metadata        []Metadata
+	access          []Access
+	collectionSize  int64
+	collectionCount int64
 }
 
 func (r *record) Metadata() []Metadata {
@@ -41,6 +44,12 @@ func (r *record) Size() int64 {
 	return r.FileInfo.Size()
 }
 
+// ObjectCount returns the number of data objects in a collection, if the
+// FetchCollectionCount option was given. Data objects with several replicas are counted once.
+func (r *record) ObjectCount() int64 {
+	return r.collectionCount
+}
+
 func (r *record) Type() ObjectType {
 	if r.IsDir() {
 		return CollectionType
@@ -89,10 +98,54 @@ const (
 	// If the option FetchCollectionSize is given, Size() will be populated
 	// for collections.
 	FetchCollectionSize
+
+	// If the option FetchCollectionCount is given, the number of data objects
+	// in a collection (this does not include sub-collections) is available through
+	// the ObjectCount() method of the record, for collections.
+	FetchCollectionCount
+
+	// If the option DetectAmbiguousPath is given, GetRecord checks whether a collection
+	// exists with the same path as the data object it found, and returns ErrAmbiguousPath
+	// if so. This costs an extra query. It has no effect on Walk.
+	DetectAmbiguousPath
 )
 
 var ErrSkipNotAllowed = errors.New("skip not allowed")
 
+// WalkError is returned by Walk if the walk function returns an error,
+// to identify the path for which the walk function failed.
+type WalkError struct {
+	Path string
+	Err  error
+}
+
+func (e *WalkError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Path, e.Err)
+}
+
+func (e *WalkError) Unwrap() error {
+	return e.Err
+}
+
+// wrapWalkFunc wraps errors returned by the walk function in a WalkError.
+// SkipAll, SkipDir and SkipSubDirs are returned as is, as they are compared
+// by identity during the traversal. Errors that are already a WalkError,
+// e.g. from a nested Walk, are not wrapped again.
+func wrapWalkFunc(fn WalkFunc) WalkFunc {
+	return func(path string, record Record, err error) error {
+		err = fn(path, record, err)
+
+		var walkErr *WalkError
+
+		switch {
+		case err == nil, err == SkipAll, err == SkipDir, err == SkipSubDirs, errors.As(err, &walkErr):
+			return err
+		default:
+			return &WalkError{Path: path, Err: err}
+		}
+	}
+}
+
 // Walk traverses the iRODS hierarchy rooted at the given path, calling the
 // given function for each encountered file or directory. The function is
 // called with the path relative to the root of the traversal, the
@@ -106,8 +159,11 @@ var ErrSkipNotAllowed = errors.New("skip not allowed")
 // retrieving all children in memory.
 // The order in which the collections are visited is not specified in general.
 // The only guarantees are that parent collections are visited before their
-// children.
+// children. Errors returned by the walk function are wrapped in a *WalkError
+// that holds the path for which the function failed.
 func (api *API) Walk(ctx context.Context, path string, walkFn WalkFunc, opts ...WalkOption) error {
+	walkFn = wrapWalkFunc(walkFn)
+
 	collection, err := api.GetCollection(ctx, path)
 
 	switch {
@@ -123,6 +179,12 @@ func (api *API) Walk(ctx context.Context, path string, walkFn WalkFunc, opts ...
 		return api.walkBatches(ctx, walkFn, []Collection{*collection}, opts...)
 	}
 
+	return api.walkNonCollection(ctx, path, walkFn, err, opts...)
+}
+
+// walkNonCollection calls the walk function for a path that could not be retrieved as
+// a collection. If the path refers to a data object, its record is passed, otherwise the error.
+func (api *API) walkNonCollection(ctx context.Context, path string, walkFn WalkFunc, err error, opts ...WalkOption) error {
 	// If the collection does not exist, check if it is a data object
 	if code, ok := ErrorCode(err); ok && code == msg.CAT_NO_ROWS_FOUND {
 		if record, err := api.GetRecord(ctx, path, opts...); err == nil && !record.IsDir() {
@@ -637,9 +699,28 @@ func collectionIDPathMap(collections []Collection) map[int64]string {
 	return paths
 }
 
+// ErrAmbiguousPath is returned by GetRecord if the DetectAmbiguousPath option is given and a data object
+// and a collection exist with the same path, which can happen as the result of a race or in federated
+// zones. Use GetDataObject or GetCollection to retrieve either of them.
+var ErrAmbiguousPath = errors.New("path refers to both a data object and a collection")
+
+// checkNotCollection returns ErrAmbiguousPath if a collection exists with the given path
+func (api *API) checkNotCollection(ctx context.Context, path string) error {
+	_, err := api.GetCollection(ctx, path)
+	if errors.Is(err, ErrNoRowFound) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s", ErrAmbiguousPath, path)
+}
+
 // GetRecord retrieves a Record for the given path. The Record is a combination of
 // os.FileInfo and iRODS metadata. The metadata is only retrieved if the
-// FetchMetadata or FetchAccess WalkOptions are given.
+// FetchMetadata or FetchAccess WalkOptions are given. If a data object and a
+// collection exist with the same path, the data object is returned, unless the
+// DetectAmbiguousPath WalkOption is given.
 func (api *API) GetRecord(ctx context.Context, path string, options ...WalkOption) (Record, error) {
 	var (
 		fi  os.FileInfo
@@ -653,6 +734,8 @@ func (api *API) GetRecord(ctx context.Context, path string, options ...WalkOptio
 		fi, err = api.GetCollection(ctx, path)
 
 		objectType = CollectionType
+	} else if err == nil && slices.Contains(options, DetectAmbiguousPath) {
+		err = api.checkNotCollection(ctx, path)
 	}
 
 	if err != nil {
@@ -683,3 +766,85 @@ func (api *API) GetRecord(ctx context.Context, path string, options ...WalkOptio
 
 	return r, nil
 }
+
+// FindExpired calls walkFn for each data object in the collection tree rooted at the given path,
+// of which the expiry is set and lies before t. The records passed to walkFn only contain
+// the information of the data object itself, no metadata or access lists.
+// If walkFn returns SkipAll, the search is stopped and nil is returned.
+func (api *API) FindExpired(ctx context.Context, path string, t time.Time, walkFn WalkFunc) error {
+	return api.FindDataObjects(ctx, path, walkFn, expiredBefore(t))
+}
+
+// expiredBefore creates a Condition that selects data objects of which the expiry is set and lies before t.
+func expiredBefore(t time.Time) Condition {
+	return Condition{
+		Column: msg.ICAT_COLUMN_D_EXPIRY,
+		Op:     "BETWEEN",
+		Value:  fmt.Sprintf("'%s' '%s'", formatExpiry(time.Unix(1, 0)), formatExpiry(t)),
+	}
+}
+
+// FindDataObjects calls walkFn for each data object in the collection tree rooted at the given path
+// that matches all conditions. The conditions are evaluated by the catalog, so that the tree does not
+// need to be walked. Note that conditions on metadata attributes and values all apply to the same AVU,
+// so a data object can only be selected on a single attribute. The records passed to walkFn only
+// contain the information of the data object itself, no metadata or access lists.
+// If walkFn returns SkipAll, the search is stopped and nil is returned.
+func (api *API) FindDataObjects(ctx context.Context, path string, walkFn WalkFunc, conditions ...Condition) error {
+	for _, filter := range []Condition{
+		Equal(msg.ICAT_COLUMN_COLL_NAME, path),
+		Like(msg.ICAT_COLUMN_COLL_NAME, escapeLike(strings.TrimSuffix(path, "/"))+"/%"),
+	} {
+		objects, err := api.ListDataObjects(ctx, append([]Condition{filter}, conditions...)...)
+		if err != nil {
+			return err
+		}
+
+		for i := range objects {
+			err = walkFn(objects[i].Path, &record{FileInfo: &objects[i]}, nil)
+			if err == SkipAll {
+				return nil
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// ListModifiedSince returns the data objects and collections in the collection tree rooted at
+// the given path, including the collection itself, that were modified after the given time.
+// For data objects, only the replicas that were modified after the given time are included.
+// The records only contain the information of the objects themselves, no metadata or access lists.
+// As the catalog stores timestamps with a precision of seconds, since is truncated to seconds.
+func (api *API) ListModifiedSince(ctx context.Context, path string, since time.Time) ([]Record, error) {
+	var records []Record
+
+	for _, filter := range []Condition{
+		Equal(msg.ICAT_COLUMN_COLL_NAME, path),
+		Like(msg.ICAT_COLUMN_COLL_NAME, strings.TrimSuffix(path, "/")+"/%"),
+	} {
+		collections, err := api.ListCollections(ctx, filter, After(msg.ICAT_COLUMN_COLL_MODIFY_TIME, since))
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range collections {
+			records = append(records, &record{FileInfo: &collections[i]})
+		}
+
+		objects, err := api.ListDataObjects(ctx, filter, After(msg.ICAT_COLUMN_D_MODIFY_TIME, since))
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range objects {
+			records = append(records, &record{FileInfo: &objects[i]})
+		}
+	}
+
+	return records, nil
+}
@@ -0,0 +1,336 @@
+package api
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+// DefaultWalkConcurrency is the number of collections that are listed
+// concurrently by WalkParallel, unless ParallelWalkOptions.Concurrency is set.
+const DefaultWalkConcurrency = 4
+
+// ParallelWalkOptions controls the pool of workers of WalkParallel
+type ParallelWalkOptions struct {
+	Concurrency int // Number of collections that are listed concurrently, DefaultWalkConcurrency if zero
+}
+
+// WalkParallel behaves as Walk, but lists collections concurrently using a pool of
+// workers, see ParallelWalkOptions. The walk function is never called concurrently,
+// and the callback contract, including SkipDir, SkipSubDirs and SkipAll, is the same as
+// for Walk. Collections are visited depth first: a collection is followed by its data
+// objects, and then by its subcollections. If LexographicalOrder is given, the records
+// are visited in the same order as Walk with that option. Subcollections are listed as
+// soon as the walk function has been called for their parent, so returning SkipDir for
+// a subcollection does not avoid that it is listed. The option NoSkip has no effect.
+// If BreadthFirst is given, or if the concurrency is one or less, Walk is used instead.
+//
+// The subcollections of a collection are divided in batches over the workers, so more
+// queries are needed than by Walk, which lists a complete level of the tree in large batches.
+// WalkParallel pays off most with LexographicalOrder, for which Walk lists each collection
+// separately, or if the round trip time to the server is large compared to the query time.
+func (api *API) WalkParallel(ctx context.Context, path string, walkFn WalkFunc, parallel ParallelWalkOptions, opts ...WalkOption) error {
+	n := parallel.Concurrency
+
+	if n == 0 {
+		n = DefaultWalkConcurrency
+	}
+
+	if n <= 1 || slices.Contains(opts, BreadthFirst) {
+		return api.Walk(ctx, path, walkFn, opts...)
+	}
+
+	walkFn = wrapWalkFunc(walkFn)
+
+	collection, err := api.GetCollection(ctx, path)
+	if err != nil {
+		return api.walkNonCollection(ctx, path, walkFn, err, opts...)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+
+	w := &parallelWalker{
+		api:           api,
+		fn:            walkFn,
+		opts:          opts,
+		concurrency:   n,
+		lexographical: slices.Contains(opts, LexographicalOrder),
+		jobs:          make(chan []*listing),
+	}
+
+	var wg sync.WaitGroup
+
+	for range n {
+		wg.Go(func() {
+			w.work(ctx)
+		})
+	}
+
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	err = w.walk(ctx, w.schedule(ctx, []Collection{*collection})[0])
+	if err == SkipAll {
+		return nil
+	}
+
+	return err
+}
+
+// listing holds the children of a collection, as fetched by a worker of WalkParallel
+type listing struct {
+	collection     Collection
+	subcollections []Collection
+	objects        []DataObject
+	bulk           bulk  // Attributes, shared by the listings of a batch
+	attrErr        error // Error while fetching the attributes of the data objects
+	err            error
+	done           chan struct{}
+}
+
+type parallelWalker struct {
+	api           *API
+	fn            WalkFunc
+	opts          []WalkOption
+	concurrency   int
+	lexographical bool
+	jobs          chan []*listing
+}
+
+// schedule queues the given collections to be listed by the workers, in order.
+// The collections are listed in batches, which are divided over the workers.
+func (w *parallelWalker) schedule(ctx context.Context, collections []Collection) []*listing {
+	listings := make([]*listing, len(collections))
+
+	for i := range collections {
+		listings[i] = &listing{
+			collection: collections[i],
+			done:       make(chan struct{}),
+		}
+	}
+
+	batches := listingBatches(listings, (len(listings)+w.concurrency-1)/w.concurrency)
+
+	go func() {
+		for _, batch := range batches {
+			select {
+			case w.jobs <- batch:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return listings
+}
+
+// listingBatches splits the listings in batches of at most size listings,
+// that don't exceed the maximum IN condition length, see callBatches.
+func listingBatches(listings []*listing, size int) [][]*listing {
+	var (
+		batches [][]*listing
+		batch   []*listing
+		n       int
+	)
+
+	for _, l := range listings {
+		if strings.Contains(l.collection.Path, "'") {
+			// The irods IN condition cannot cope with single quotes,
+			// do a batch with a single item instead, so the = condition can be used
+			batches = append(batches, []*listing{l})
+
+			continue
+		}
+
+		if len(batch) == size || n+len(l.collection.Path)+4 > maxBatchLength {
+			batches = append(batches, batch)
+			batch = nil
+			n = 0
+		}
+
+		batch = append(batch, l)
+		n += len(l.collection.Path) + 4
+	}
+
+	if len(batch) > 0 {
+		batches = append(batches, batch)
+	}
+
+	return batches
+}
+
+func (w *parallelWalker) work(ctx context.Context) {
+	for {
+		select {
+		case batch := <-w.jobs:
+			w.list(ctx, batch)
+
+			for _, l := range batch {
+				close(l.done)
+			}
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// list retrieves the subcollections and data objects of a batch of collections,
+// and their attributes, and distributes them over the listings
+func (w *parallelWalker) list(ctx context.Context, batch []*listing) {
+	parents := make([]Collection, len(batch))
+	byPath := map[string]*listing{}
+	byID := map[int64]*listing{}
+
+	for i, l := range batch {
+		parents[i] = l.collection
+		byPath[l.collection.Path] = l
+		byID[l.collection.ID] = l
+	}
+
+	var (
+		ids     = collectionIDs(parents)
+		b       = bulk{}
+		attrErr error
+	)
+
+	subcollections, objects, err := w.api.listChildren(ctx, parents)
+	if err == nil {
+		err = b.PrefetchCollections(ctx, w.api, ids, w.opts...)
+	}
+
+	if err == nil {
+		attrErr = b.PrefetchDataObjectsInCollections(ctx, w.api, ids, w.opts...)
+	}
+
+	for _, l := range batch {
+		l.bulk = b
+		l.attrErr = attrErr
+		l.err = err
+	}
+
+	for _, c := range subcollections {
+		if parent, _ := Split(c.Path); byPath[parent] != nil {
+			byPath[parent].subcollections = append(byPath[parent].subcollections, c)
+		}
+	}
+
+	for _, o := range objects {
+		if l := byID[o.CollectionID]; l != nil {
+			l.objects = append(l.objects, o)
+		}
+	}
+
+	if !w.lexographical {
+		return
+	}
+
+	for _, l := range batch {
+		slices.SortFunc(l.subcollections, func(a, b Collection) int {
+			return strings.Compare(a.Path, b.Path)
+		})
+	}
+}
+
+// listChildren retrieves the subcollections and data objects of the given collections
+func (api *API) listChildren(ctx context.Context, parents []Collection) ([]Collection, []DataObject, error) {
+	subcollections, err := api.ListCollections(ctx, In(msg.ICAT_COLUMN_COLL_PARENT_NAME, collectionPaths(parents)), NotEqual(msg.ICAT_COLUMN_COLL_NAME, "/"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	objects, err := api.walkListDataObjects(ctx, collectionIDs(parents), collectionIDPathMap(parents))
+
+	return subcollections, objects, err
+}
+
+// walk calls the walk function for the collection of the listing, its data objects,
+// and recursively its subcollections, after waiting for the listing to be fetched.
+func (w *parallelWalker) walk(ctx context.Context, l *listing) error {
+	select {
+	case <-l.done:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+
+	if l.err != nil {
+		return w.visit(result{l.collection.Path, nil, l.err})
+	}
+
+	var skipped []Collection
+
+	subcollections := l.subcollections
+
+	switch err := w.fn(l.collection.Path, l.bulk.Record(&l.collection), nil); err {
+	case nil:
+	case SkipDir:
+		return nil
+	case SkipSubDirs:
+		// Subcollections are visited as if they were empty
+		skipped, subcollections = subcollections, nil
+	default:
+		return err
+	}
+
+	// Start listing the subcollections while the data objects are visited
+	children := w.schedule(ctx, subcollections)
+
+	items := make([]result, 0, len(l.objects)+len(skipped))
+
+	for i := range l.objects {
+		items = append(items, result{l.objects[i].Path, l.bulk.Record(&l.objects[i]), l.attrErr})
+	}
+
+	if len(skipped) > 0 {
+		skippedBulk := bulk{}
+
+		attrErr := skippedBulk.PrefetchCollections(ctx, w.api, collectionIDs(skipped), w.opts...)
+
+		for i := range skipped {
+			items = append(items, result{skipped[i].Path, skippedBulk.Record(&skipped[i]), attrErr})
+		}
+	}
+
+	if w.lexographical {
+		slices.SortFunc(items, func(a, b result) int {
+			return strings.Compare(a.path, b.path)
+		})
+	}
+
+	for _, item := range items {
+		// In lexographical order, subcollections that come first are walked first
+		for w.lexographical && len(children) > 0 && children[0].collection.Path < item.path {
+			if err := w.walk(ctx, children[0]); err != nil {
+				return err
+			}
+
+			children = children[1:]
+		}
+
+		if err := w.visit(item); err != nil {
+			return err
+		}
+	}
+
+	for _, child := range children {
+		if err := w.walk(ctx, child); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// visit calls the walk function for a record without children
+func (w *parallelWalker) visit(item result) error {
+	switch err := w.fn(item.path, item.record, item.err); err {
+	case SkipDir, SkipSubDirs:
+		return nil
+	default:
+		return err
+	}
+}
@@ -0,0 +1,427 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+type treeRow map[msg.ColumnNumber]string
+
+// treeConn serves a synthetic catalog of collections and data objects to general queries,
+// with an optional delay per round trip, for BenchmarkWalkParallel. It can be used concurrently.
+type treeConn struct {
+	MockConn
+	collections []treeRow
+	objects     []treeRow
+	index       map[msg.ColumnNumber]map[string][]treeRow
+	latency     time.Duration
+	roundTrips  atomic.Int64
+}
+
+// newTreeConn creates a catalog rooted at /testzone/home, in which each collection up to
+// the given depth has width subcollections, and each collection has the given number of data objects.
+func newTreeConn(width, depth, objects int) *treeConn {
+	c := &treeConn{}
+
+	var id int
+
+	var add func(path, parent string, level int)
+
+	add = func(path, parent string, level int) {
+		id++
+
+		collID := fmt.Sprintf("%d", id)
+
+		c.collections = append(c.collections, treeRow{
+			msg.ICAT_COLUMN_COLL_ID:          collID,
+			msg.ICAT_COLUMN_COLL_NAME:        path,
+			msg.ICAT_COLUMN_COLL_PARENT_NAME: parent,
+			msg.ICAT_COLUMN_COLL_OWNER_NAME:  "rods",
+			msg.ICAT_COLUMN_COLL_OWNER_ZONE:  "testzone",
+		})
+
+		for i := range objects {
+			id++
+
+			c.objects = append(c.objects, treeRow{
+				msg.ICAT_COLUMN_D_DATA_ID:    fmt.Sprintf("%d", id),
+				msg.ICAT_COLUMN_DATA_NAME:    fmt.Sprintf("file%02d", i),
+				msg.ICAT_COLUMN_D_COLL_ID:    collID,
+				msg.ICAT_COLUMN_DATA_SIZE:    "1024",
+				msg.ICAT_COLUMN_D_OWNER_NAME: "rods",
+				msg.ICAT_COLUMN_D_OWNER_ZONE: "testzone",
+				msg.ICAT_COLUMN_D_RESC_NAME:  "demoResc",
+				msg.ICAT_COLUMN_D_RESC_HIER:  "demoResc",
+			})
+		}
+
+		if level == depth {
+			return
+		}
+
+		for i := range width {
+			add(fmt.Sprintf("%s/coll%02d", path, i), path, level+1)
+		}
+	}
+
+	add("/testzone/home", "/testzone", 0)
+
+	c.index = map[msg.ColumnNumber]map[string][]treeRow{}
+
+	for _, column := range []msg.ColumnNumber{msg.ICAT_COLUMN_COLL_NAME, msg.ICAT_COLUMN_COLL_PARENT_NAME, msg.ICAT_COLUMN_D_COLL_ID} {
+		c.index[column] = map[string][]treeRow{}
+
+		for _, row := range slices.Concat(c.collections, c.objects) {
+			if value, ok := row[column]; ok {
+				c.index[column][value] = append(c.index[column][value], row)
+			}
+		}
+	}
+
+	return c
+}
+
+func (c *treeConn) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	c.roundTrips.Add(1)
+
+	time.Sleep(c.latency)
+
+	query := request.(*msg.QueryRequest)
+
+	conditions := c.parseConditions(query.Conditions)
+
+	var matches []treeRow
+
+	// Look up the candidates in the index of the first condition, and check the others
+	for value := range conditions[0].values {
+		for _, row := range conditions[0].index[value] {
+			if conditions.match(row) {
+				matches = append(matches, row)
+			}
+		}
+	}
+
+	slices.SortFunc(matches, func(a, b treeRow) int {
+		return strings.Compare(a[msg.ICAT_COLUMN_COLL_ID]+a[msg.ICAT_COLUMN_D_DATA_ID], b[msg.ICAT_COLUMN_COLL_ID]+b[msg.ICAT_COLUMN_D_DATA_ID])
+	})
+
+	offset := query.ContinueIndex
+	n := min(query.MaxRows, len(matches)-offset)
+
+	result := response.(*msg.QueryResponse)
+
+	*result = msg.QueryResponse{
+		RowCount:       n,
+		AttributeCount: len(query.Selects.Keys),
+		TotalRowCount:  len(matches),
+	}
+
+	for _, column := range query.Selects.Keys {
+		values := make([]string, n)
+
+		for i := range n {
+			values[i] = matches[offset+i][msg.ColumnNumber(column)]
+		}
+
+		result.SQLResult = append(result.SQLResult, msg.SQLResult{AttributeIndex: msg.ColumnNumber(column), ResultLen: n, Values: values})
+	}
+
+	if offset+n < len(matches) && n > 0 {
+		result.ContinueIndex = offset + n
+	}
+
+	return nil
+}
+
+type treeCondition struct {
+	column msg.ColumnNumber
+	values map[string]bool
+	negate bool
+	index  map[string][]treeRow
+}
+
+type treeConditions []treeCondition
+
+func (conditions treeConditions) match(row treeRow) bool {
+	for _, c := range conditions {
+		if c.values[row[c.column]] == c.negate {
+			return false
+		}
+	}
+
+	return true
+}
+
+// parseConditions parses the =, <> and in conditions of the walk queries. The first
+// condition is an = or in condition on an indexed column, that selects the candidates.
+func (c *treeConn) parseConditions(conditions msg.ISKeyVal) treeConditions {
+	var parsed treeConditions
+
+	for i, column := range conditions.Keys {
+		op, arg, _ := strings.Cut(conditions.Values[i], " ")
+
+		condition := treeCondition{
+			column: msg.ColumnNumber(column),
+			values: map[string]bool{},
+			negate: op == "<>",
+			index:  c.index[msg.ColumnNumber(column)],
+		}
+
+		for _, value := range strings.Split(strings.Trim(arg, "()'"), "','") {
+			condition.values[value] = true
+		}
+
+		parsed = append(parsed, condition)
+	}
+
+	slices.SortStableFunc(parsed, func(a, b treeCondition) int {
+		return cmpBool(a.negate || a.index == nil, b.negate || b.index == nil)
+	})
+
+	return parsed
+}
+
+func cmpBool(a, b bool) int {
+	switch {
+	case a == b:
+		return 0
+	case a:
+		return 1
+	default:
+		return -1
+	}
+}
+
+func newTreeAPI(conn *treeConn) *API {
+	return &API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (Conn, error) {
+			return conn, nil
+		},
+	}
+}
+
+// parallelResponses serve a walk of /test, which contains the data object file1 and
+// the collection sub, which in turn contains the data object file2. As each collection
+// has at most one subcollection, the listings are requested in a fixed order.
+var parallelResponses = []any{
+	responses[0],
+	msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 7,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 500, ResultLen: 1, Values: []string{"2"}},
+			{AttributeIndex: 501, ResultLen: 1, Values: []string{"/test/sub"}},
+			{AttributeIndex: 503, ResultLen: 1, Values: []string{"rods"}},
+			{AttributeIndex: 504, ResultLen: 1, Values: []string{"zone"}},
+			{AttributeIndex: 508, ResultLen: 1, Values: []string{"10000"}},
+			{AttributeIndex: 509, ResultLen: 1, Values: []string{"2024"}},
+			{AttributeIndex: 506, ResultLen: 1, Values: []string{"0"}},
+		},
+	},
+	dataObjectResponse("1", "file1"),
+	msg.QueryResponse{AttributeCount: 7},
+	dataObjectResponse("2", "file2"),
+}
+
+func dataObjectResponse(collID, name string) msg.QueryResponse {
+	return msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 15,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 401, ResultLen: 1, Values: []string{"4"}},
+			{AttributeIndex: 403, ResultLen: 1, Values: []string{name}},
+			{AttributeIndex: 402, ResultLen: 1, Values: []string{collID}},
+			{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+			{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+			{AttributeIndex: 407, ResultLen: 1, Values: []string{"1024"}},
+			{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+			{AttributeIndex: 412, ResultLen: 1, Values: []string{"zone"}},
+			{AttributeIndex: 415, ResultLen: 1, Values: []string{"checksum"}},
+			{AttributeIndex: 413, ResultLen: 1, Values: []string{""}},
+			{AttributeIndex: 409, ResultLen: 1, Values: []string{"demoResc"}},
+			{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path"}},
+			{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc"}},
+			{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+			{AttributeIndex: 420, ResultLen: 1, Values: []string{"10000"}},
+		},
+	}
+}
+
+// walkPaths returns the paths visited by WalkParallel, with the result of fn for each path
+func walkPaths(t *testing.T, testAPI *testAPI, fn func(path string) error, opts ...WalkOption) []string {
+	var paths []string
+
+	err := testAPI.WalkParallel(t.Context(), "/test", func(path string, record Record, err error) error {
+		if err != nil {
+			return err
+		}
+
+		paths = append(paths, path)
+
+		return fn(path)
+	}, ParallelWalkOptions{Concurrency: 2}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return paths
+}
+
+func TestWalkParallel(t *testing.T) {
+	for _, opts := range [][]WalkOption{nil, {LexographicalOrder}} {
+		testAPI := newAPI()
+
+		testAPI.AddResponses(parallelResponses)
+
+		paths := walkPaths(t, testAPI, func(string) error {
+			return nil
+		}, opts...)
+
+		if expected := []string{"/test", "/test/file1", "/test/sub", "/test/sub/file2"}; !slices.Equal(paths, expected) {
+			t.Errorf("%v: expected %v, got %v", opts, expected, paths)
+		}
+	}
+}
+
+func TestWalkParallelSkip(t *testing.T) {
+	for _, test := range []struct {
+		path     string
+		skip     error
+		expected []string
+	}{
+		{"/test/sub", SkipDir, []string{"/test", "/test/file1", "/test/sub"}},
+		{"/test", SkipSubDirs, []string{"/test", "/test/file1", "/test/sub"}},
+		{"/test/file1", SkipAll, []string{"/test", "/test/file1"}},
+	} {
+		testAPI := newAPI()
+
+		testAPI.AddResponses(parallelResponses)
+
+		paths := walkPaths(t, testAPI, func(path string) error {
+			if path == test.path {
+				return test.skip
+			}
+
+			return nil
+		})
+
+		if !slices.Equal(paths, test.expected) {
+			t.Errorf("%v: expected %v, got %v", test.skip, test.expected, paths)
+		}
+	}
+}
+
+func TestWalkParallelError(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses(parallelResponses)
+
+	errTest := errors.New("test error")
+
+	err := testAPI.WalkParallel(t.Context(), "/test", func(path string, record Record, err error) error {
+		if path == "/test/sub/file2" {
+			return errTest
+		}
+
+		return err
+	}, ParallelWalkOptions{})
+
+	var walkErr *WalkError
+
+	if !errors.As(err, &walkErr) || walkErr.Path != "/test/sub/file2" || walkErr.Err != errTest {
+		t.Fatalf("expected a walk error for /test/sub/file2, got %v", err)
+	}
+}
+
+func TestWalkParallelListError(t *testing.T) {
+	testAPI := newAPI()
+
+	errTest := errors.New("test error")
+
+	testAPI.AddResponses(parallelResponses[:3])
+	testAPI.AddResponse(errTest)
+
+	var errs []string
+
+	err := testAPI.WalkParallel(t.Context(), "/test", func(path string, record Record, err error) error {
+		if err != nil {
+			errs = append(errs, path)
+		}
+
+		return nil
+	}, ParallelWalkOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The error to list /test/sub is passed to the walk function for that collection
+	if !slices.Equal(errs, []string{"/test/sub"}) {
+		t.Errorf("expected an error for /test/sub, got %v", errs)
+	}
+}
+
+func TestWalkParallelSequential(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses(responses)
+
+	// With a concurrency of one, Walk is used, which needs the same responses as TestWalk
+	err := testAPI.WalkParallel(t.Context(), "/test", func(path string, info Record, err error) error {
+		return err
+	}, ParallelWalkOptions{Concurrency: 1}, FetchAccess, FetchMetadata, FetchCollectionSize)
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// BenchmarkWalkParallel compares Walk and WalkParallel on a tree of 1111 collections
+// and 8888 data objects, with a round trip time of 100µs.
+func BenchmarkWalkParallel(b *testing.B) {
+	for _, lexographical := range []bool{false, true} {
+		for _, n := range []int{1, 4, 16} {
+			b.Run(fmt.Sprintf("lexographical=%t/concurrency=%d", lexographical, n), func(b *testing.B) {
+				conn := newTreeConn(10, 3, 8)
+				conn.latency = 100 * time.Microsecond
+
+				testAPI := newTreeAPI(conn)
+
+				var opts []WalkOption
+
+				if lexographical {
+					opts = append(opts, LexographicalOrder)
+				}
+
+				for b.Loop() {
+					var records int
+
+					err := testAPI.WalkParallel(b.Context(), "/testzone/home", func(path string, record Record, err error) error {
+						records++
+
+						return err
+					}, ParallelWalkOptions{Concurrency: n}, opts...)
+					if err != nil {
+						b.Fatal(err)
+					}
+
+					if records != 1111+8888 {
+						b.Fatalf("expected 9999 records, got %d", records)
+					}
+				}
+
+				b.ReportMetric(float64(conn.roundTrips.Load())/float64(b.N), "roundtrips/op")
+			})
+		}
+	}
+}
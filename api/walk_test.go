@@ -1,7 +1,10 @@
 package api
 
 import (
+	"errors"
+	"slices"
 	"testing"
+	"time"
 
 	"github.com/kuleuven/iron/msg"
 )
@@ -164,6 +167,36 @@ func TestWalkBF(t *testing.T) {
 	}
 }
 
+func TestWalkError(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses(responses)
+
+	errTest := errors.New("test error")
+
+	err := testAPI.Walk(t.Context(), "/test", func(path string, info Record, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return errTest
+		}
+
+		return nil
+	})
+
+	var walkErr *WalkError
+
+	if !errors.As(err, &walkErr) || !errors.Is(err, errTest) {
+		t.Fatalf("expected WalkError wrapping the test error, got %v", err)
+	}
+
+	if walkErr.Path != "/test/file1" {
+		t.Errorf("expected path /test/file1, got %s", walkErr.Path)
+	}
+}
+
 func TestWalkSkip(t *testing.T) {
 	testAPI := newAPI()
 
@@ -243,3 +276,177 @@ func TestGetRecord(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestGetRecordAmbiguous(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses([]any{
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 14,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"2"}},
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+				{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"100"}},
+				{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+				{AttributeIndex: 412, ResultLen: 1, Values: []string{"zone"}},
+				{AttributeIndex: 415, ResultLen: 1, Values: []string{""}},
+				{AttributeIndex: 413, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 409, ResultLen: 1, Values: []string{"demoResc"}},
+				{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path"}},
+				{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc"}},
+				{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 420, ResultLen: 1, Values: []string{"10000"}},
+			},
+		},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 6,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"3"}},
+				{AttributeIndex: 503, ResultLen: 1, Values: []string{"rods"}},
+				{AttributeIndex: 504, ResultLen: 1, Values: []string{"zone"}},
+				{AttributeIndex: 508, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 509, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 506, ResultLen: 1, Values: []string{"0"}},
+			},
+		},
+	})
+
+	if _, err := testAPI.GetRecord(t.Context(), "/test/name", DetectAmbiguousPath); !errors.Is(err, ErrAmbiguousPath) {
+		t.Fatalf("expected ErrAmbiguousPath, got %v", err)
+	}
+}
+
+func TestFindExpired(t *testing.T) {
+	testAPI := newAPI()
+
+	testAPI.AddResponses([]any{
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 16,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 501, ResultLen: 1, Values: []string{"/test"}},
+				{AttributeIndex: 403, ResultLen: 1, Values: []string{"expired"}},
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+				{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"1024"}},
+				{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+				{AttributeIndex: 412, ResultLen: 1, Values: []string{"zone"}},
+				{AttributeIndex: 415, ResultLen: 1, Values: []string{""}},
+				{AttributeIndex: 413, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 409, ResultLen: 1, Values: []string{"demoResc"}},
+				{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path"}},
+				{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc"}},
+				{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 420, ResultLen: 1, Values: []string{"10000"}},
+			},
+		},
+		msg.QueryResponse{},
+	})
+
+	var paths []string
+
+	err := testAPI.FindExpired(t.Context(), "/test", time.Now(), func(path string, info Record, err error) error {
+		paths = append(paths, path)
+
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(paths) != 1 || paths[0] != "/test/expired" {
+		t.Errorf("unexpected paths: %v", paths)
+	}
+}
+
+func TestListModifiedSince(t *testing.T) {
+	testAPI := newAPI()
+
+	since := time.Unix(1700000000, 0)
+
+	query := func(columns []msg.ColumnNumber, conditions ...Condition) msg.QueryRequest {
+		request := msg.QueryRequest{
+			MaxRows: 500,
+			Options: 0x20,
+		}
+
+		for _, col := range columns {
+			request.Selects.Add(col.Int(), 1)
+		}
+
+		// Conditions are sent ordered by column number
+		slices.SortFunc(conditions, func(a, b Condition) int {
+			return a.Column.Int() - b.Column.Int()
+		})
+
+		for _, c := range conditions {
+			request.Conditions.Add(c.Column.Int(), c.Op+" "+c.Value)
+		}
+
+		return request
+	}
+
+	collectionColumns := []msg.ColumnNumber{
+		msg.ICAT_COLUMN_COLL_ID,
+		msg.ICAT_COLUMN_COLL_NAME,
+		msg.ICAT_COLUMN_COLL_OWNER_NAME,
+		msg.ICAT_COLUMN_COLL_OWNER_ZONE,
+		msg.ICAT_COLUMN_COLL_CREATE_TIME,
+		msg.ICAT_COLUMN_COLL_MODIFY_TIME,
+		msg.ICAT_COLUMN_COLL_INHERITANCE,
+	}
+
+	dataObjectColumns := []msg.ColumnNumber{
+		msg.ICAT_COLUMN_D_DATA_ID,
+		msg.ICAT_COLUMN_COLL_NAME,
+		msg.ICAT_COLUMN_DATA_NAME,
+		msg.ICAT_COLUMN_COLL_ID,
+		msg.ICAT_COLUMN_DATA_TYPE_NAME,
+		msg.ICAT_COLUMN_DATA_REPL_NUM,
+		msg.ICAT_COLUMN_DATA_SIZE,
+		msg.ICAT_COLUMN_D_OWNER_NAME,
+		msg.ICAT_COLUMN_D_OWNER_ZONE,
+		msg.ICAT_COLUMN_D_DATA_CHECKSUM,
+		msg.ICAT_COLUMN_D_REPL_STATUS,
+		msg.ICAT_COLUMN_D_RESC_NAME,
+		msg.ICAT_COLUMN_D_DATA_PATH,
+		msg.ICAT_COLUMN_D_RESC_HIER,
+		msg.ICAT_COLUMN_D_CREATE_TIME,
+		msg.ICAT_COLUMN_D_MODIFY_TIME,
+	}
+
+	for _, filter := range []Condition{
+		Equal(msg.ICAT_COLUMN_COLL_NAME, "/test"),
+		Like(msg.ICAT_COLUMN_COLL_NAME, "/test/%"),
+	} {
+		testAPI.Add(msg.GEN_QUERY_AN, query(collectionColumns, filter, Condition{
+			Column: msg.ICAT_COLUMN_COLL_MODIFY_TIME,
+			Op:     ">",
+			Value:  "'01700000000'",
+		}), msg.QueryResponse{})
+
+		testAPI.Add(msg.GEN_QUERY_AN, query(dataObjectColumns, filter, Condition{
+			Column: msg.ICAT_COLUMN_D_MODIFY_TIME,
+			Op:     ">",
+			Value:  "'01700000000'",
+		}), msg.QueryResponse{})
+	}
+
+	records, err := testAPI.ListModifiedSince(t.Context(), "/test", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(records) != 0 {
+		t.Errorf("expected no records, got %d", len(records))
+	}
+}
@@ -3,11 +3,14 @@ package iron
 
 import (
 	"context"
+	"errors"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kuleuven/iron/api"
 	"github.com/kuleuven/iron/msg"
+	"go.uber.org/multierr"
 )
 
 type Option struct {
@@ -57,10 +60,76 @@ type Option struct {
 
 	// DiscardConnectionAge is the maximum age of a connection before it is discarded.
 	DiscardConnectionAge time.Duration
+
+	// HealthCheck is an optional function that is called before an idle connection from the pool
+	// is handed to a caller of Connect(). If it returns false, the connection is closed and replaced,
+	// so that stale connections, e.g. connections that were timed out by the server, are not used.
+	// It is not called for connections that are reused concurrently or passed directly from one
+	// caller to a waiting caller. The pool is not locked while the check runs.
+	HealthCheck func(Conn) bool
+
+	// ReconnectPolicy determines how to retry establishing a new connection if the
+	// handshake fails, e.g. because the server is restarting. By default, no retries are done.
+	ReconnectPolicy ReconnectPolicy
+
+	// QueryBatchSize is the number of rows that is fetched per round trip in queries.
+	// Larger values reduce the number of round trips on high-latency links.
+	// If zero, api.DefaultQueryBatchSize is used.
+	QueryBatchSize int
+
+	// TracerProvider is an optional provider of tracers. If set, spans are recorded for
+	// API requests, for waiting on a connection from a pool and for transfers.
+	TracerProvider TracerProvider
 }
 
 type HandshakeFunc func(ctx context.Context) (Conn, error)
 
+// ReconnectPolicy configures a bounded exponential backoff for establishing new connections.
+// Errors returned by the iRODS server, such as authentication failures, are not retried.
+type ReconnectPolicy struct {
+	// MaxRetries is the number of times a failed handshake is retried. Zero disables retries.
+	MaxRetries int
+
+	// InitialBackoff is the time to wait before the first retry. Defaults to 100ms.
+	// The backoff is doubled after each failed attempt.
+	InitialBackoff time.Duration
+
+	// MaxBackoff is the maximum time to wait between two attempts. Defaults to 10s.
+	MaxBackoff time.Duration
+}
+
+// backoff returns the time to wait before the given retry, starting at zero.
+func (r ReconnectPolicy) backoff(retry int) time.Duration {
+	initial, maximum := r.InitialBackoff, r.MaxBackoff
+
+	if initial <= 0 {
+		initial = 100 * time.Millisecond
+	}
+
+	if maximum <= 0 {
+		maximum = 10 * time.Second
+	}
+
+	backoff := initial
+
+	for range retry {
+		if backoff >= maximum {
+			break
+		}
+
+		backoff *= 2
+	}
+
+	return min(backoff, maximum)
+}
+
+// retryable returns whether a failed handshake should be retried
+func (r ReconnectPolicy) retryable(ctx context.Context, err error) bool {
+	var irodsErr *msg.IRODSError
+
+	return ctx.Err() == nil && !errors.As(err, &irodsErr)
+}
+
 type Client struct {
 	env                  *Env
 	option               Option
@@ -69,7 +138,10 @@ type Client struct {
 	envCallbackExpiry    time.Time
 	nativePasswordExpiry time.Time
 	defaultPool          *Pool
+	defaultResource      atomic.Pointer[string]
+	shuttingDown         atomic.Bool
 	firstUse             sync.Once
+	tracer               Tracer
 	lock                 sync.Mutex
 	*api.API
 }
@@ -95,6 +167,10 @@ func New(ctx context.Context, env Env, option Option) (*Client, error) {
 		c.protocol = msg.Native
 	}
 
+	if option.TracerProvider != nil {
+		c.tracer = option.TracerProvider.Tracer(tracerName)
+	}
+
 	// Create default connection pool
 	c.defaultPool = newPool(c)
 
@@ -102,6 +178,9 @@ func New(ctx context.Context, env Env, option Option) (*Client, error) {
 
 	// Test first connection unless deferred
 	if !option.DeferConnectionToFirstUse {
+		c.defaultPool.lock.Lock()
+		defer c.defaultPool.lock.Unlock()
+
 		conn, err := c.defaultPool.newConn(ctx)
 		if err != nil {
 			return nil, err
@@ -118,7 +197,7 @@ func (c *Client) Option() Option {
 	return c.option
 }
 
-// Env returns the client environment.
+// Env returns a copy of the client environment.
 func (c *Client) Env() Env {
 	c.lock.Lock()
 	defer c.lock.Unlock()
@@ -142,7 +221,29 @@ func (c *Client) Env() Env {
 		}
 	}
 
-	return *c.env
+	env := *c.env
+
+	if resource := c.defaultResource.Load(); resource != nil {
+		env.DefaultResource = *resource
+	}
+
+	return env
+}
+
+// SetDefaultResource sets the resource to use when creating data objects
+// for all subsequent operations, without reconnecting. It is safe to call
+// while other operations are in progress. APIs derived using WithDefaultResource
+// keep using their own resource.
+func (c *Client) SetDefaultResource(resource string) {
+	c.defaultResource.Store(&resource)
+}
+
+func (c *Client) defaultResourceOverride() string {
+	if resource := c.defaultResource.Load(); resource != nil {
+		return *resource
+	}
+
+	return ""
 }
 
 func (c *Client) needsEnvCallback() bool {
@@ -165,6 +266,12 @@ func (c *Client) ConnectAvailable(ctx context.Context, n int) ([]Conn, error) {
 	return c.defaultPool.ConnectAvailable(ctx, n)
 }
 
+// Stats returns a snapshot of the usage of the default connection pool.
+// It can be used to determine an appropriate value for MaxConns.
+func (c *Client) Stats() PoolStats {
+	return c.defaultPool.Stats()
+}
+
 // Close closes all connections managed by the client, ensuring that any errors
 // encountered during the closing process are aggregated and returned. The method
 // is safe to call multiple times and locks the client during execution to prevent
@@ -173,7 +280,42 @@ func (c *Client) Close() error {
 	return c.defaultPool.Close()
 }
 
+// Shutdown gracefully closes the client. New calls to Connect, ConnectAvailable and Pool
+// fail with ErrShuttingDown, and Shutdown waits for connections that are in use to be
+// returned to the pool, after which all connections are closed. If the context is done
+// before all connections are returned, the remaining connections are closed forcibly
+// and the context error is returned.
+func (c *Client) Shutdown(ctx context.Context) error {
+	c.shuttingDown.Store(true)
+
+	err := c.defaultPool.drain(ctx)
+
+	return multierr.Append(err, c.Close())
+}
+
+// newConn establishes a new connection, retrying according to the ReconnectPolicy
 func (c *Client) newConn(ctx context.Context) (Conn, error) {
+	policy := c.option.ReconnectPolicy
+
+	for retry := 0; ; retry++ {
+		conn, err := c.handshake(ctx)
+		if err == nil || retry >= policy.MaxRetries || !policy.retryable(ctx, err) {
+			return conn, err
+		}
+
+		backoff := policy.backoff(retry)
+
+		logger(ctx).Debugf("failed to connect: %v, retrying in %s", err, backoff)
+
+		select {
+		case <-ctx.Done():
+			return nil, multierr.Append(err, ctx.Err())
+		case <-time.After(backoff):
+		}
+	}
+}
+
+func (c *Client) handshake(ctx context.Context) (Conn, error) {
 	c.lock.Lock()
 	defer c.lock.Unlock()
 
@@ -588,3 +588,76 @@ func TestClientVerify(t *testing.T) { //nolint:funlen
 		t.Fatal(err)
 	}
 }
+
+func TestClientSetDefaultResource(t *testing.T) {
+	client := newTestClient(2)
+	defer client.Close()
+
+	if resource := client.Env().DefaultResource; resource != "" {
+		t.Fatalf("expected no default resource, got %s", resource)
+	}
+
+	pool, err := client.Pool(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var wg errgroup.Group
+
+	for _, resource := range []string{"resc1", "resc2"} {
+		wg.Go(func() error {
+			client.SetDefaultResource(resource)
+
+			pool.WithNumThreads(1).DefaultResourceFunc()
+
+			return nil
+		})
+	}
+
+	if err := wg.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	client.SetDefaultResource("resc3")
+
+	if resource := client.Env().DefaultResource; resource != "resc3" {
+		t.Errorf("expected resc3, got %s", resource)
+	}
+
+	for _, p := range []*Pool{client.defaultPool, pool} {
+		if resource := p.DefaultResourceFunc(); resource != "resc3" {
+			t.Errorf("expected resc3, got %s", resource)
+		}
+	}
+
+	if resource := client.WithDefaultResource("other").DefaultResource; resource != "other" {
+		t.Errorf("expected other, got %s", resource)
+	}
+}
+
+func TestTransferPoolSize(t *testing.T) {
+	client, err := New(t.Context(), Env{Host: "127.0.0.1", Zone: "testZone"}, Option{
+		ClientName:                "test",
+		MaxConns:                  16,
+		DeferConnectionToFirstUse: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer client.Close()
+
+	for _, test := range []struct {
+		threads, files, expected int
+	}{
+		{5, 0, 5},
+		{5, 2, 10},
+		{5, 10, 15},
+		{1, 4, 4},
+		{0, 4, 0},
+	} {
+		if size := client.transferPoolSize(transfer.Options{MaxThreads: test.threads, MaxOutstanding: test.files}); size != test.expected {
+			t.Errorf("expected %d connections for %d threads and %d files, got %d", test.expected, test.threads, test.files, size)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+	"github.com/spf13/cobra"
+)
+
+func (a *App) admin() *cobra.Command {
+	admin := &cobra.Command{
+		Use:   "admin",
+		Short: "Run an administrative command",
+		Long:  "Run an administrative command. These commands require a rodsadmin account and the --admin flag.",
+	}
+
+	admin.AddCommand(
+		a.chown(),
+		a.modrepl(),
+	)
+
+	return admin
+}
+
+const chownDescription = `Change the owner of a data object or collection.
+
+The new owner is granted own access, after which the access of the previous
+owner is removed. Other permissions are left untouched. For users of another
+zone, use the <name>#<zone> format.
+
+This is an administrative command, it requires a rodsadmin account and the
+--admin flag.`
+
+func (a *App) chown() *cobra.Command {
+	var recursive bool
+
+	cmd := &cobra.Command{
+		Use:               "chown <user> <path>",
+		Short:             "Change the owner of a data object or collection",
+		Long:              chownDescription,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := a.Path(args[1])
+
+			if !recursive {
+				record, err := a.GetRecord(cmd.Context(), path)
+				if err != nil {
+					return err
+				}
+
+				return a.changeOwner(cmd.Context(), path, record, args[0])
+			}
+
+			return a.changeOwnerRecursive(cmd.Context(), cmd.OutOrStdout(), path, args[0])
+		},
+	}
+
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Change the owner recursively")
+
+	return cmd
+}
+
+// changeOwnerRecursive changes the owner of a collection and everything below it,
+// printing every path that is changed.
+func (a *App) changeOwnerRecursive(ctx context.Context, w io.Writer, dir, user string) error {
+	var objects, collections int
+
+	err := a.Walk(ctx, dir, func(path string, record api.Record, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if err := a.changeOwner(ctx, path, record, user); err != nil {
+			return err
+		}
+
+		if record.IsDir() {
+			collections++
+		} else {
+			objects++
+		}
+
+		fmt.Fprintln(w, path)
+
+		return nil
+	})
+
+	fmt.Fprintf(w, "changed owner of %d collections and %d data objects\n", collections, objects)
+
+	return err
+}
+
+func (a *App) changeOwner(ctx context.Context, path string, record api.Record, user string) error {
+	var owner string
+
+	switch v := record.Sys().(type) {
+	case *api.Collection:
+		owner = v.Owner + "#" + v.OwnerZone
+	case *api.DataObject:
+		if len(v.Replicas) > 0 {
+			owner = v.Replicas[0].Owner + "#" + v.Replicas[0].OwnerZone
+		}
+	}
+
+	return privilegeError(a.ChangeOwner(ctx, path, owner, user))
+}
+
+const modreplDescription = `Mark a replica of a data object as good or stale, e.g. to fix the
+replication state after a manual intervention on the storage. The replica
+is identified by its number, as listed by stat.
+
+This is an administrative command, it requires a rodsadmin account and the
+--admin flag.`
+
+var ErrInvalidReplicaStatus = errors.New("invalid replica status, expected good or stale")
+
+func (a *App) modrepl() *cobra.Command {
+	return &cobra.Command{
+		Use:               "modrepl <path> <replica number> good|stale",
+		Short:             "Change the status of a replica",
+		Long:              modreplDescription,
+		Args:              cobra.ExactArgs(3),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			replNum, err := strconv.Atoi(args[1])
+			if err != nil {
+				return fmt.Errorf("invalid replica number %s: %w", args[1], err)
+			}
+
+			var status api.ReplicaStatus
+
+			switch args[2] {
+			case api.ReplicaGood.String():
+				status = api.ReplicaGood
+			case api.ReplicaStale.String():
+				status = api.ReplicaStale
+			default:
+				return fmt.Errorf("%w: %s", ErrInvalidReplicaStatus, args[2])
+			}
+
+			return privilegeError(a.ModifyReplicaStatus(cmd.Context(), a.Path(args[0]), replNum, status))
+		},
+	}
+}
+
+// privilegeError explains errors that are caused by missing administrative privileges.
+func privilegeError(err error) error {
+	if errors.Is(err, api.ErrRequiresAdmin) {
+		return fmt.Errorf("%w: rerun with --admin", err)
+	}
+
+	if code, ok := api.ErrorCode(err); ok {
+		switch code { //nolint:exhaustive
+		case msg.CAT_INSUFFICIENT_PRIVILEGE_LEVEL, msg.SYS_NO_API_PRIV, msg.CAT_NO_ACCESS_PERMISSION:
+			return fmt.Errorf("%w: this command requires a rodsadmin account", err)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,104 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestChown(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{}, // No data object
+		collectionResponse("/testzone/home"),
+	})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"admin", "chown", "newuser", "/testzone/home"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, api.ErrRequiresAdmin) {
+		t.Fatalf("expected api.ErrRequiresAdmin, got %v", err)
+	}
+
+	app.Client.Admin = true
+
+	app.AddResponses([]any{
+		msg.QueryResponse{}, // No data object
+		collectionResponse("/testzone/home"),
+		msg.EmptyResponse{},
+		msg.EmptyResponse{},
+	})
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"admin", "chown", "newuser", "/testzone/home"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	app.AddResponses([]any{
+		collectionResponse("/testzone/home"),
+		msg.QueryResponse{},
+		msg.QueryResponse{},
+		msg.EmptyResponse{},
+		msg.EmptyResponse{},
+	})
+
+	var buf bytes.Buffer
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"admin", "chown", "-r", "newuser", "/testzone/home"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "/testzone/home\nchanged owner of 1 collections and 0 data objects\n"; buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestPrivilegeError(t *testing.T) {
+	err := privilegeError(&msg.IRODSError{Code: msg.CAT_INSUFFICIENT_PRIVILEGE_LEVEL})
+	if code, ok := api.ErrorCode(err); !ok || code != msg.CAT_INSUFFICIENT_PRIVILEGE_LEVEL {
+		t.Fatalf("expected wrapped error, got %v", err)
+	}
+
+	if err := privilegeError(nil); err != nil {
+		t.Fatalf("expected nil, got %v", err)
+	}
+}
+
+func TestModrepl(t *testing.T) {
+	app := testApp(t)
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"admin", "modrepl", "/testzone/home/file", "1", "good"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, api.ErrRequiresAdmin) {
+		t.Fatalf("expected api.ErrRequiresAdmin, got %v", err)
+	}
+
+	app.Client.Admin = true
+
+	app.AddResponse(msg.EmptyResponse{})
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"admin", "modrepl", "/testzone/home/file", "1", "stale"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"admin", "modrepl", "/testzone/home/file", "1", "locked"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrInvalidReplicaStatus) {
+		t.Fatalf("expected ErrInvalidReplicaStatus, got %v", err)
+	}
+}
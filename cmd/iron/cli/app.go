@@ -19,6 +19,9 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// logger logs the messages of this package, see iron.LogComponentField
+var logger = logrus.WithField(iron.LogComponentField, "cli")
+
 func New(_ context.Context, options ...Option) *App {
 	home := os.Getenv("HOME")
 
@@ -29,6 +32,7 @@ func New(_ context.Context, options ...Option) *App {
 	app := &App{
 		name:    "iron",
 		loadEnv: FileLoader(home + "/.irods/irods_environment.json"),
+		sinks:   defaultSinks(),
 	}
 
 	for _, option := range options {
@@ -47,19 +51,35 @@ type App struct {
 	configStoreArgs []string
 	passwordStore   PasswordStore
 	workdirStore    WorkdirStore
+	profiles        *profiles
+	sinks           map[string]SinkFactory
 
 	releaseVersion string
 	updater        *selfupdate.Updater
 	repo           selfupdate.RepositorySlug
 
-	Admin          bool
-	Debug          int
-	Native         bool
-	Workdir        string
-	PamTTL         time.Duration
-	NonInteractive bool
+	Admin bool
+	Debug int
+	// DebugComponents restricts the debug output to the given components, see iron.LogComponentField
+	DebugComponents []string
+	Native          bool
+	Workdir         string
+	Profile         string
+	PamTTL          time.Duration
+	NonInteractive  bool
+
+	// NoCachePassword disables caching of entered passwords in the shell
+	NoCachePassword bool
+
+	// NoColors disables ANSI colors in the output
+	NoColors bool
 
-	inShell bool
+	// ProgressJSON is the file descriptor to emit JSON progress events of transfers to, zero if disabled
+	ProgressJSON int
+
+	inShell      bool
+	colors       bool // Whether ANSI colors are used, as decided by Init
+	cachedPrompt *cachedPrompt
 }
 
 func (a *App) Command() *cobra.Command {
@@ -77,6 +97,7 @@ func (a *App) Command() *cobra.Command {
 	shellCmd.Use = "shell [zone]"
 	shellCmd.Args = cobra.MaximumNArgs(1)
 	shellCmd.PersistentPreRunE = a.ShellInit
+	shellCmd.Flags().BoolVar(&a.NoCachePassword, "no-cache-password", false, "Do not remember entered passwords for reconnects during the shell session")
 
 	// Open subcommand
 	openURLCmd := a.xopen()
@@ -103,9 +124,11 @@ func (a *App) root(shellCommand bool) *cobra.Command {
 		a.create(),
 		a.touch(),
 		a.upload(),
+		a.importLocalLinks(),
 		a.download(),
 		a.cat(),
 		a.head(),
+		a.grep(),
 		a.save(),
 		a.chmod(),
 		a.inherit(),
@@ -113,9 +136,21 @@ func (a *App) root(shellCommand bool) *cobra.Command {
 		a.find(),
 		a.tree(),
 		a.stat(),
+		a.du(),
+		a.open(),
+		a.share(),
+		a.admin(),
+		a.report(),
+		a.repl(),
 		a.meta(),
 		a.checksum(),
 		a.checksums(),
+		a.manifest(),
+		a.verify(),
+		a.watch(),
+		a.backup(),
+		a.repair(),
+		a.env(),
 		a.version(),
 		a.sleep(),
 		a.ps(),
@@ -134,15 +169,28 @@ func (a *App) root(shellCommand bool) *cobra.Command {
 		rootCmd.AddCommand(a.local())
 	}
 
+	if a.profiles != nil {
+		rootCmd.AddCommand(a.profile())
+	}
+
 	if !shellCommand && a.updater != nil {
 		rootCmd.AddCommand(a.update())
 	}
 
 	if !shellCommand {
 		rootCmd.PersistentFlags().CountVarP(&a.Debug, "debug", "v", "Enable debug output")
+		rootCmd.PersistentFlags().StringSliceVar(&a.DebugComponents, "debug-components", nil, "Comma separated list of components to enable debug output for, implies --debug. Components: conn (connections, TLS and authentication), pool (connection pools), msg (protocol messages, with -vv), api (API calls), transfer (transfers), cli (this command line client)")
 		rootCmd.PersistentFlags().BoolVar(&a.Admin, "admin", false, "Enable admin access")
 		rootCmd.PersistentFlags().BoolVar(&a.Native, "native", false, "Use native protocol")
-		rootCmd.PersistentFlags().StringVar(&a.Workdir, "workdir", a.Workdir, "Working directory")
+		rootCmd.PersistentFlags().StringVar(&a.Workdir, "workdir", a.Workdir, "Working directory for this invocation, used as base for relative paths. Overrides the stored working directory without changing it. A relative value is resolved against the root collection of the zone.")
+		rootCmd.PersistentFlags().BoolVar(&a.NoColors, "no-color", false, "Disable colored output, also disabled if NO_COLOR is set or the output is not a terminal")
+		rootCmd.PersistentFlags().IntVar(&a.ProgressJSON, "progress-json", 0, "Emit the progress of transfers as JSON events, one per line, instead of showing a progress bar. The events are written to stdout, or to the file descriptor passed as --progress-json=<fd>. Commands that write data to stdout, such as cat and tee, require another file descriptor.")
+		rootCmd.PersistentFlags().Lookup("progress-json").NoOptDefVal = "1"
+
+		if a.profiles != nil {
+			rootCmd.PersistentFlags().StringVar(&a.Profile, "profile", "", "Profile to use")
+		}
+
 		rootCmd.PersistentFlags().DurationVar(&a.PamTTL, "ttl", 168*time.Hour, "In case pam authentication is used, request a session that is valid for the given duration. This value is rounded down to the nearest hour.")
 	}
 
@@ -271,16 +319,26 @@ func (a *App) ResetClient() error {
 // It is used under the PersistentPreRunE hook.
 // To override, either adjust SkipInit or implement your own PersistentPreRunE hook.
 func (a *App) Init(cmd *cobra.Command, args []string) error {
-	if a.Debug > 0 {
-		logrus.SetLevel(logrus.DebugLevel + logrus.Level(a.Debug-1))
+	if err := a.setupLogging(); err != nil {
+		return err
 	}
 
+	a.colors = useColors(cmd.OutOrStdout(), a.NoColors)
+
+	setColors(a.colors)
+
 	if a.Client != nil || SkipInit(cmd) {
 		return nil
 	}
 
 	a.CheckUpdate(cmd.Context())
 
+	if a.profiles != nil {
+		if err := a.selectProfile(cmd); err != nil {
+			return err
+		}
+	}
+
 	var zone string
 
 	// Get zone from arguments
@@ -327,8 +385,8 @@ func (a *App) ResetInitConfigStore(cmd *cobra.Command, args []string) error {
 		return err
 	}
 
-	if a.Debug > 0 {
-		logrus.SetLevel(logrus.DebugLevel + logrus.Level(a.Debug-1))
+	if err := a.setupLogging(); err != nil {
+		return err
 	}
 
 	if a.Client != nil {
@@ -362,6 +420,8 @@ func (a *App) init(cmd *cobra.Command, zone string) error {
 
 	if strings.HasPrefix(cmd.Use, "auth ") {
 		ctx = context.WithValue(ctx, ForceReauthentication, true)
+
+		a.clearPasswordCache()
 	}
 
 	env, dialer, err := a.loadEnv(ctx, zone)
@@ -374,6 +434,17 @@ func (a *App) init(cmd *cobra.Command, zone string) error {
 
 	env.GeneratedPasswordTimeout = a.PamTTL
 
+	// Report all configuration problems at once, before attempting to connect
+	validated := env
+
+	validated.ApplyDefaults()
+
+	if err = validated.Validate(); err != nil {
+		cmd.SilenceUsage = true
+
+		return InitError{a, env, err}
+	}
+
 	clientName := a.name
 
 	// Telemetry: send version, except for prereleases
@@ -385,6 +456,12 @@ func (a *App) init(cmd *cobra.Command, zone string) error {
 
 	if a.NonInteractive {
 		authPrompt = iron.Bot{}
+	} else if a.inShell && !a.NoCachePassword {
+		if a.cachedPrompt == nil {
+			a.cachedPrompt = &cachedPrompt{Prompt: iron.StdPrompt}
+		}
+
+		authPrompt = a.cachedPrompt
 	}
 
 	a.Client, err = iron.New(cmd.Context(), env, iron.Option{
@@ -396,17 +473,36 @@ func (a *App) init(cmd *cobra.Command, zone string) error {
 		AuthenticationPrompt: authPrompt,
 	})
 	if err != nil {
+		// Don't reuse a password that might be wrong
+		a.clearPasswordCache()
+
 		// Doesn't make sense to print usage here
 		cmd.SilenceUsage = true
 
 		return InitError{a, env, err}
 	}
 
+	a.resolveWorkdir(cmd, env.Zone)
+
+	return nil
+}
+
+// resolveWorkdir makes sure the working directory, which is the base for relative paths, is absolute.
+// If no working directory is stored or given, the root collection of the zone is used. A relative
+// --workdir is resolved against the root collection of the zone. The --workdir flag only applies to
+// the current invocation, the stored working directory is left untouched.
+func (a *App) resolveWorkdir(cmd *cobra.Command, zone string) {
+	root := fmt.Sprintf("/%s", zone)
+
 	if a.Workdir == "" {
-		a.Workdir = fmt.Sprintf("/%s", env.Zone)
+		a.Workdir = root
+
+		return
 	}
 
-	return nil
+	if flag := cmd.Flag("workdir"); flag != nil && flag.Changed {
+		a.Workdir = a.PathIn(a.Workdir, root)
+	}
 }
 
 type InitError struct {
@@ -444,7 +540,7 @@ func (e InitError) Error() string {
 }
 
 func SkipInit(cmd *cobra.Command) bool {
-	if cmd.Use == "__complete [command-line]" || cmd.Use == "help [command]" || cmd.Use == "completion" || cmd.Use == "version" || cmd.Use == "update" || cmd.Use == "local" || cmd.Use == "exit" {
+	if cmd.Use == "__complete [command-line]" || cmd.Use == "help [command]" || cmd.Use == "completion" || cmd.Use == "version" || cmd.Use == "env [zone]" || cmd.Use == "update" || cmd.Use == "local" || cmd.Use == "exit" || cmd.Use == "profile" {
 		return true
 	}
 
@@ -455,7 +551,16 @@ func SkipInit(cmd *cobra.Command) bool {
 	return false
 }
 
+// clearPasswordCache forgets the passwords that were entered in the shell
+func (a *App) clearPasswordCache() {
+	if a.cachedPrompt != nil {
+		a.cachedPrompt.Clear()
+	}
+}
+
 func (a *App) Close() error {
+	a.clearPasswordCache()
+
 	if a.Client == nil {
 		return nil
 	}
@@ -1,6 +1,7 @@
 package cli
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
 	"encoding/json"
@@ -99,6 +100,7 @@ func TestNew(t *testing.T) { //nolint:funlen
 	envfile, err := writeConfig(t, iron.Env{
 		Host:                    "127.0.0.1",
 		Port:                    tcpAddr.Port,
+		Zone:                    "testZone",
 		ClientServerNegotiation: "no_negotiation",
 	})
 	if err != nil {
@@ -325,6 +327,66 @@ func TestAutocomplete(t *testing.T) {
 	}
 }
 
+func TestAutocompleteProfile(t *testing.T) {
+	dir := t.TempDir()
+
+	var defaultLoaded bool
+
+	app := New(t.Context(),
+		WithName("test"),
+		WithLoader(func(context.Context, string) (iron.Env, iron.DialFunc, error) {
+			defaultLoaded = true
+
+			return iron.Env{}, nil, os.ErrNotExist
+		}),
+		WithProfiles(filepath.Join(dir, "profiles"), iron.Env{
+			Port:                    1,
+			ClientServerNegotiation: "no_negotiation",
+		}),
+	)
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"profile", "add", "p1", "user1", "zone1", "127.0.0.1"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	app.Profile = "p1"
+
+	ls := app.list()
+	ls.SetContext(t.Context())
+
+	if opts, _ := app.CompleteArgs(ls, []string{}, "/zone1/"); len(opts) != 0 {
+		t.Fatalf("expected no options, got %v", opts)
+	}
+
+	if defaultLoaded {
+		t.Fatal("expected the environment of the profile to be used")
+	}
+}
+
+func TestCompletionScripts(t *testing.T) {
+	for _, shell := range []string{"bash", "zsh", "fish"} {
+		app := testApp(t)
+
+		var buf bytes.Buffer
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{"completion", shell})
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		// The script calls back into the binary to complete remote paths
+		if !strings.Contains(buf.String(), "__complete") {
+			t.Fatalf("expected %s completion script to use dynamic completion, got %s", shell, buf.String())
+		}
+	}
+}
+
 func TestAutocompleteLocal(t *testing.T) {
 	app := testApp(t)
 	app.inShell = true
@@ -443,3 +505,105 @@ func TestXOpen(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestPathTilde(t *testing.T) {
+	app := testApp(t)
+
+	app.Client.API.Username = "testuser"
+	app.Workdir = "/testzone/home/testuser/sub"
+
+	for path, expected := range map[string]string{
+		"~":                    "/testzone/home/testuser",
+		"~/":                   "/testzone/home/testuser",
+		"~/data":               "/testzone/home/testuser/data",
+		"~otheruser":           "/testzone/home/otheruser",
+		"~otheruser/data/../x": "/testzone/home/otheruser/x",
+		"~user#otherzone/data": "/otherzone/home/user/data",
+		"data/~":               "/testzone/home/testuser/sub/data/~",
+		"a~b":                  "/testzone/home/testuser/sub/a~b",
+	} {
+		if result := app.Path(path); result != expected {
+			t.Errorf("%s: expected %s, got %s", path, expected, result)
+		}
+	}
+}
+
+func TestIsProtected(t *testing.T) {
+	app := testApp(t)
+
+	app.Client.API.Username = "testuser"
+
+	for path, expected := range map[string]bool{
+		"/":                                  true,
+		"/testzone":                          true,
+		"/testzone/home":                     true,
+		"/testzone/trash":                    true,
+		"/testzone/trash/home":               true,
+		"/testzone/home/testuser":            true,
+		"/testzone/trash/home/testuser":      true,
+		"/otherzone/home":                    true,
+		"/testzone/home/otheruser":           false,
+		"/testzone/home/testuser/data":       false,
+		"/testzone/trash/home/testuser/data": false,
+		"/testzone/other":                    false,
+	} {
+		if result := app.isProtected(path); result != expected {
+			t.Errorf("%s: expected %v, got %v", path, expected, result)
+		}
+	}
+}
+
+func TestResolveWorkdir(t *testing.T) {
+	envfile := filepath.Join(t.TempDir(), "irods_environment.json")
+
+	if err := os.WriteFile(envfile, []byte(`{"irods_zone_name": "testzone"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := StoreWorkdirInFile(envfile, "/testzone/home/stored"); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, testCase := range []struct {
+		args     []string
+		expected string
+	}{
+		{nil, "/testzone/home/stored"},
+		{[]string{"--workdir", "project"}, "/testzone/project"},
+		{[]string{"--workdir", "/testzone/other/"}, "/testzone/other"},
+		{[]string{"--workdir", "/otherzone/coll"}, "/otherzone/coll"},
+	} {
+		app := testApp(t)
+
+		WithDefaultWorkdirFromFile(envfile)(app.App)
+
+		cmd := app.Command()
+
+		if err := cmd.ParseFlags(testCase.args); err != nil {
+			t.Fatal(err)
+		}
+
+		app.resolveWorkdir(cmd, "testzone")
+
+		if app.Workdir != testCase.expected {
+			t.Errorf("%v: expected workdir %s, got %s", testCase.args, testCase.expected, app.Workdir)
+		}
+
+		if path := app.Path("data"); path != testCase.expected+"/data" {
+			t.Errorf("%v: expected relative path to be resolved against %s, got %s", testCase.args, testCase.expected, path)
+		}
+
+		if stored, err := GetWorkdirFromFile(envfile); err != nil || stored != "/testzone/home/stored" {
+			t.Errorf("%v: expected stored workdir to be unchanged, got %s (%v)", testCase.args, stored, err)
+		}
+	}
+
+	// Without stored or given working directory, the root collection of the zone is used
+	app := testApp(t)
+
+	app.resolveWorkdir(app.Command(), "testzone")
+
+	if app.Workdir != "/testzone" {
+		t.Errorf("expected workdir /testzone, got %s", app.Workdir)
+	}
+}
@@ -26,3 +26,13 @@ func findParentOf(pid int) (int, error) {
 
 	return int(stat.PPid), nil
 }
+
+func freeSpace(path string) (int64, error) {
+	var stat syscall.Statfs_t
+
+	if err := syscall.Statfs(path, &stat); err != nil {
+		return 0, err
+	}
+
+	return int64(stat.Bavail) * int64(stat.Bsize), nil //nolint:unconvert
+}
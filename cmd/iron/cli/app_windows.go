@@ -6,6 +6,8 @@ import (
 	"os"
 	"os/user"
 	"strings"
+
+	"golang.org/x/sys/windows"
 )
 
 func uidOfFile(_ os.FileInfo) int {
@@ -33,3 +35,18 @@ func strToOrd(s string) int {
 func findParentOf(_ int) (int, error) {
 	return 0, os.ErrInvalid
 }
+
+func freeSpace(path string) (int64, error) {
+	ptr, err := windows.UTF16PtrFromString(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var free uint64
+
+	if err := windows.GetDiskFreeSpaceEx(ptr, &free, nil, nil); err != nil {
+		return 0, err
+	}
+
+	return int64(free), nil
+}
@@ -0,0 +1,165 @@
+package cli
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/kuleuven/iron/transfer"
+	"github.com/spf13/cobra"
+)
+
+// snapshotFile is the name of the file in the local backup directory
+// that records the state of the last successful backup.
+const snapshotFile = ".iron-snapshot"
+
+// snapshot records the collection that was backed up, and the time
+// at which the last successful backup was started.
+type snapshot struct {
+	Collection string    `json:"collection"`
+	Time       time.Time `json:"time"`
+}
+
+var ErrSnapshotMismatch = errors.New("snapshot belongs to another collection, use --full to start over")
+
+// readSnapshot reads the snapshot in the given directory. If there is no snapshot, nil is returned.
+func readSnapshot(dir string) (*snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil //nolint:nilnil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var s snapshot
+
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("invalid snapshot %s: %w", filepath.Join(dir, snapshotFile), err)
+	}
+
+	return &s, nil
+}
+
+// writeSnapshot atomically replaces the snapshot in the given directory.
+func writeSnapshot(dir string, s snapshot) error {
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+
+	tmp := filepath.Join(dir, snapshotFile+".tmp")
+
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp, filepath.Join(dir, snapshotFile))
+}
+
+// removeSnapshot removes the snapshot in the given directory, if there is one.
+func removeSnapshot(dir string) error {
+	if err := os.Remove(filepath.Join(dir, snapshotFile)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+func (a *App) backup() *cobra.Command {
+	opts := transfer.Options{
+		SyncModTime: true,
+		MaxQueued:   10000,
+	}
+
+	var sinceLast, full bool
+
+	examples := []string{
+		a.name + " backup /path/to/collection /local/backup",
+		a.name + " backup /path/to/collection /local/backup --since-last",
+		a.name + " backup /path/to/collection /local/backup --full",
+	}
+
+	cmd := &cobra.Command{
+		Use:               "backup <collection path> <local directory>",
+		Short:             "Back up a collection to a local directory",
+		Long:              "Back up a collection and its subcollections to a local directory. After each successful backup, the start time of the backup is recorded in a " + snapshotFile + " file in the local directory. If --since-last is passed, only data objects and collections that were modified since the last recorded backup are transferred, without listing the complete collection tree. Modified collections that were not backed up yet, such as collections that were moved into the collection tree, are transferred completely. If no backup was recorded yet, or if --full is passed, the local directory is synchronized with the complete collection. Passing --full discards the recorded backup first, also if it was recorded for another collection. Files are never removed from the local directory.",
+		Example:           strings.Join(examples, "\n"),
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			remote := a.Path(args[0])
+			local := filepath.Clean(args[1])
+
+			record, err := a.GetRecord(cmd.Context(), remote)
+			if err != nil {
+				return err
+			}
+
+			if !record.IsDir() {
+				return fmt.Errorf("%w: %s", ErrNotACollection, remote)
+			}
+
+			if err = os.MkdirAll(local, 0o755); err != nil {
+				return err
+			}
+
+			var last *snapshot
+
+			if sinceLast {
+				if last, err = readSnapshot(local); err != nil {
+					return err
+				}
+			}
+
+			if last != nil && last.Collection != remote {
+				return fmt.Errorf("%w: %s", ErrSnapshotMismatch, last.Collection)
+			}
+
+			// Forget the previous backup, so that an interrupted full pass is not followed by an
+			// incremental one, and a snapshot of another collection is replaced
+			if full && !opts.DryRun {
+				if err := removeSnapshot(local); err != nil {
+					return err
+				}
+			}
+
+			if err := a.progressOutput(cmd, &opts, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			// Take the snapshot time before listing, so that modifications during the backup are
+			// picked up by the next run. It is truncated to seconds, the precision of the catalog.
+			started := time.Now().Truncate(time.Second)
+
+			if last == nil {
+				err = a.DownloadDir(cmd.Context(), local, remote, opts)
+			} else {
+				// Include the second of the last snapshot, as other changes might have happened within the same second
+				err = a.DownloadModifiedSince(cmd.Context(), local, remote, last.Time.Add(-time.Second), opts)
+			}
+
+			if err != nil || opts.DryRun {
+				return err
+			}
+
+			return writeSnapshot(local, snapshot{
+				Collection: remote,
+				Time:       started,
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&sinceLast, "since-last", false, "Only transfer data objects modified since the last backup")
+	cmd.Flags().BoolVar(&full, "full", false, "Discard the recorded backup and synchronize the complete collection")
+	cmd.Flags().IntVar(&opts.MaxThreads, "threads", 5, "Number of download threads to use")
+	cmd.Flags().BoolVar(&opts.IntegrityChecksums, "verify-checksum", false, "Compute checksums before and after downloading files, and verify equality to ensure transfer integrity")
+	cmd.Flags().BoolVar(&opts.DryRun, dryrunOption, false, "Only print the actions that would be taken, without performing any changes")
+	cmd.Flags().StringSliceVar(&opts.IgnorePatterns, "ignore", nil, "Comma separated list of patterns to ignore. The pattern is applied to the names of files and directories, not the complete path.")
+	cmd.MarkFlagsMutuallyExclusive("since-last", "full")
+
+	return cmd
+}
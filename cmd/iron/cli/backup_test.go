@@ -0,0 +1,82 @@
+package cli
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	s, err := readSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s != nil {
+		t.Fatalf("expected no snapshot, got %v", s)
+	}
+
+	expected := snapshot{
+		Collection: "/testzone/coll",
+		Time:       time.Unix(1700000000, 0),
+	}
+
+	if err = writeSnapshot(dir, expected); err != nil {
+		t.Fatal(err)
+	}
+
+	s, err = readSnapshot(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Collection != expected.Collection || !s.Time.Equal(expected.Time) {
+		t.Errorf("expected %v, got %v", expected, s)
+	}
+}
+
+func TestBackupSnapshotMismatch(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := writeSnapshot(dir, snapshot{Collection: "/testzone/other", Time: time.Unix(1700000000, 0)}); err != nil {
+		t.Fatal(err)
+	}
+
+	app := testApp(t)
+
+	app.AddResponses(statResponses[:2])
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"backup", "--since-last", "--threads", "1", "/testzone/coll", dir})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrSnapshotMismatch) {
+		t.Fatalf("expected %v, got %v", ErrSnapshotMismatch, err)
+	}
+}
+
+func TestBackupFullRemovesSnapshot(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := writeSnapshot(dir, snapshot{Collection: "/testzone/other", Time: time.Unix(1700000000, 0)}); err != nil {
+		t.Fatal(err)
+	}
+
+	app := testApp(t)
+
+	app.AddResponses(statResponses[:2])
+
+	// Let the backup fail before the transfer starts
+	cmd := app.Command()
+	cmd.SetArgs([]string{"backup", "--full", "--progress-json=99", "/testzone/coll", dir})
+
+	if err := cmd.ExecuteContext(t.Context()); err == nil {
+		t.Fatal("expected an error")
+	}
+
+	// The snapshot of the other collection is gone, although the backup did not succeed
+	if s, err := readSnapshot(dir); err != nil || s != nil {
+		t.Fatalf("expected no snapshot, got %v: %v", s, err)
+	}
+}
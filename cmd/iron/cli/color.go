@@ -0,0 +1,51 @@
+package cli
+
+import (
+	"io"
+	"os"
+
+	"golang.org/x/term"
+)
+
+// colors lists all ANSI escape sequences that are cleared when colors are disabled
+var colors = []*string{
+	&Reset, &Red, &Green, &Yellow, &Blue, &Magenta, &Cyan, &Gray, &LightGray, &White, &NoColor, &Bold, &NoBold,
+	&HeaderBackground, &RowBackground, &AltRowBackground, &NoBackground,
+	&Underline, &NoUnderline,
+}
+
+var defaultColors = func() []string {
+	values := make([]string, len(colors))
+
+	for i, c := range colors {
+		values[i] = *c
+	}
+
+	return values
+}()
+
+// useColors decides whether output written to w should contain ANSI colors.
+// Colors are disabled if the --no-color flag is passed, if the NO_COLOR
+// environment variable is set to a non-empty value (see https://no-color.org),
+// or if w is not a terminal.
+func useColors(w io.Writer, noColor bool) bool {
+	if noColor || os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+
+	f, ok := w.(*os.File)
+
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+// setColors enables or disables ANSI colors in tables. Colors in transfer output
+// are controlled through transfer.Options, see App.progressOutput.
+func setColors(enabled bool) {
+	for i, c := range colors {
+		if enabled {
+			*c = defaultColors[i]
+		} else {
+			*c = ""
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"os"
+	"testing"
+)
+
+func TestUseColors(t *testing.T) {
+	t.Setenv("NO_COLOR", "")
+
+	if useColors(&bytes.Buffer{}, false) {
+		t.Fatal("expected no colors for a non-terminal writer")
+	}
+
+	if useColors(os.Stdout, true) {
+		t.Fatal("expected no colors if --no-color is passed")
+	}
+
+	t.Setenv("NO_COLOR", "1")
+
+	if useColors(os.Stdout, false) {
+		t.Fatal("expected no colors if NO_COLOR is set")
+	}
+}
+
+func TestSetColors(t *testing.T) {
+	defer setColors(true)
+
+	setColors(false)
+
+	if Bold != "" || Reset != "" {
+		t.Fatal("expected colors to be disabled")
+	}
+
+	setColors(true)
+
+	if Bold != "\033[01m" || Reset != "\033[00m" {
+		t.Fatal("expected colors to be enabled")
+	}
+}
@@ -8,19 +8,23 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"os"
 	"path/filepath"
 	"runtime"
 	"slices"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
+	"github.com/dustin/go-humanize"
 	"github.com/kuleuven/iron/api"
 	"github.com/kuleuven/iron/cmd/iron/tabwriter"
 	"github.com/kuleuven/iron/msg"
 	"github.com/kuleuven/iron/transfer"
 	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
 	"golang.org/x/term"
 )
 
@@ -100,7 +104,7 @@ func (a *App) auth() *cobra.Command {
 }
 
 func (a *App) mkdir() *cobra.Command {
-	var recursive bool
+	var recursive, verbose bool
 
 	cmd := &cobra.Command{
 		Use:               "mkdir <target path>",
@@ -108,15 +112,31 @@ func (a *App) mkdir() *cobra.Command {
 		Args:              cobra.ExactArgs(1),
 		ValidArgsFunction: a.CompleteArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if recursive && verbose {
+				created, err := a.CreateCollectionParents(cmd.Context(), a.Path(args[0]))
+
+				for _, path := range created {
+					fmt.Fprintf(cmd.OutOrStdout(), "created collection %s\n", path)
+				}
+
+				return err
+			}
+
 			if recursive {
 				return a.CreateCollectionAll(cmd.Context(), a.Path(args[0]))
 			}
 
-			return a.CreateCollection(cmd.Context(), a.Path(args[0]))
+			err := a.CreateCollection(cmd.Context(), a.Path(args[0]))
+			if err == nil && verbose {
+				fmt.Fprintf(cmd.OutOrStdout(), "created collection %s\n", a.Path(args[0]))
+			}
+
+			return err
 		},
 	}
 
 	cmd.Flags().BoolVarP(&recursive, "parents", "p", false, "Create parents if necessary")
+	cmd.Flags().BoolVar(&verbose, "verbose", false, "Print the collections that were created. Existing parents are not printed.")
 
 	return cmd
 }
@@ -130,7 +150,11 @@ func (a *App) rmdir() *cobra.Command {
 		Args:              cobra.ExactArgs(1),
 		ValidArgsFunction: a.CompleteArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return a.DeleteCollection(cmd.Context(), a.Path(args[0]), skip)
+			path := a.Path(args[0])
+
+			return a.removeReported(cmd, transfer.RemoveDirectory, path, skip, false, func() error {
+				return a.DeleteCollection(cmd.Context(), path, skip)
+			})
 		},
 	}
 
@@ -140,15 +164,19 @@ func (a *App) rmdir() *cobra.Command {
 }
 
 func (a *App) stat() *cobra.Command {
-	var jsonFormat bool
+	var jsonFormat, resource, user, replicas, lock, physical bool
 
 	cmd := &cobra.Command{
 		Use:               "stat <path>",
 		Short:             "Get information about an object or collection",
-		Long:              "Get information about an object or collection. For collections, the total size of all contained data objects is shown, but this count does not include any sub-collections.",
+		Long:              "Get information about an object or collection. For collections, the total size of all contained data objects is shown, but this count does not include any sub-collections. For data objects, the comment and expiry are shown if set. If --replicas is passed, the checksum of each replica of a data object is shown, computing missing checksums, and a warning is shown if the replicas have divergent checksums. If --physical is passed, the host of the storage resource and the physical path in its vault are shown for each replica. Use --resource or --user to get information about a resource or user instead. The output of --json for collections and data objects has a schema_version field, which is incremented on incompatible changes. The --json output of ls and find is a different, unversioned format that only includes the fields of the listing.",
 		Args:              cobra.ExactArgs(1),
 		ValidArgsFunction: a.CompleteArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if resource || user {
+				return a.statItem(cmd, args[0], resource, jsonFormat)
+			}
+
 			path := a.Path(args[0])
 
 			record, err := a.GetRecord(cmd.Context(), path, api.FetchMetadata, api.FetchAccess, api.FetchCollectionSize)
@@ -156,17 +184,50 @@ func (a *App) stat() *cobra.Command {
 				return err
 			}
 
-			var printer Printer = &TablePrinter{
-				Writer: &tabwriter.TabWriter{
-					Writer: cmd.OutOrStdout(),
-				},
-				Zone: a.Zone,
+			if !record.IsDir() {
+				d := &dataObjectRecord{Record: record}
+
+				if d.Comment, err = a.GetComment(cmd.Context(), path); err != nil {
+					return err
+				}
+
+				if d.Expiry, err = a.GetExpiry(cmd.Context(), path); err != nil {
+					return err
+				}
+
+				if replicas {
+					if d.Replicas, err = a.ChecksumAll(cmd.Context(), path); err != nil {
+						return err
+					}
+				}
+
+				if lock {
+					state, err := a.GetLockState(cmd.Context(), path)
+					if err != nil {
+						return err
+					}
+
+					d.Lock = &state
+				}
+
+				if physical {
+					if d.Physical, err = a.physicalReplicas(cmd.Context(), record); err != nil {
+						return err
+					}
+				}
+
+				record = d
 			}
 
 			if jsonFormat {
-				printer = &JSONPrinter{
+				return writeStatJSON(cmd.OutOrStdout(), path, record)
+			}
+
+			printer := &TablePrinter{
+				Writer: &tabwriter.TabWriter{
 					Writer: cmd.OutOrStdout(),
-				}
+				},
+				Zone: a.Zone,
 			}
 
 			printer.Setup(true, true, true)
@@ -180,12 +241,178 @@ func (a *App) stat() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVarP(&jsonFormat, "json", "j", false, "Output in JSON format")
+	cmd.Flags().BoolVarP(&resource, "resource", "r", false, "Interpret the argument as a resource name")
+	cmd.Flags().BoolVarP(&user, "user", "u", false, "Interpret the argument as a user or group name, optionally followed by #zone")
+	cmd.Flags().BoolVar(&replicas, "replicas", false, "Show the checksum of each replica of a data object")
+	cmd.Flags().BoolVar(&lock, "lock", false, "Show the advisory lock that is held on a data object, if any")
+	cmd.Flags().BoolVar(&physical, "physical", false, "Show the resource host and the physical path of each replica of a data object")
+	cmd.MarkFlagsMutuallyExclusive("resource", "user")
 
 	return cmd
 }
 
+// physicalReplicas locates the replicas of a data object on the storage resources.
+func (a *App) physicalReplicas(ctx context.Context, record api.Record) ([]physicalReplica, error) {
+	obj, ok := record.Sys().(*api.DataObject)
+	if !ok {
+		return nil, nil
+	}
+
+	var (
+		replicas []physicalReplica
+		hosts    = map[string]string{}
+	)
+
+	for _, r := range obj.Replicas {
+		// The replica is stored on the leaf resource of the hierarchy
+		name := r.ResourceName
+
+		if r.ResourceHierarchy != "" {
+			hierarchy := strings.Split(r.ResourceHierarchy, ";")
+			name = hierarchy[len(hierarchy)-1]
+		}
+
+		host, ok := hosts[name]
+		if !ok {
+			resc, err := a.GetResource(ctx, name)
+			if err != nil {
+				return nil, err
+			}
+
+			host = resc.Location
+			hosts[name] = host
+		}
+
+		replicas = append(replicas, physicalReplica{
+			Number:   r.Number,
+			Resource: name,
+			Host:     host,
+			Path:     r.PhysicalPath,
+		})
+	}
+
+	return replicas, nil
+}
+
+// statItem prints information about a resource or a user, including its metadata
+func (a *App) statItem(cmd *cobra.Command, name string, isResource, jsonFormat bool) error {
+	var (
+		properties []Property
+		itemType   api.ObjectType
+	)
+
+	if isResource {
+		resc, err := a.GetResource(cmd.Context(), name)
+		if err != nil {
+			return err
+		}
+
+		itemType = api.ResourceType
+		properties = []Property{
+			{"ID", resc.ID},
+			{"Name", resc.Name},
+			{"Zone", resc.Zone},
+			{"Type", resc.Type},
+			{"Class", resc.Class},
+			{"Location", resc.Location},
+			{"Vault path", resc.Path},
+			{"Context", resc.Context},
+			{"Parent ID", resc.ParentID},
+			{"Created", resc.CreatedAt},
+			{"Modified", resc.ModifiedAt},
+		}
+	} else {
+		u, err := a.GetUser(cmd.Context(), name)
+		if err != nil {
+			return err
+		}
+
+		itemType = api.UserType
+		properties = []Property{
+			{"ID", u.ID},
+			{"Name", u.Name},
+			{"Zone", u.Zone},
+			{"Type", u.Type},
+			{"Created", u.CreatedAt},
+			{"Modified", u.ModifiedAt},
+		}
+	}
+
+	metadata, err := a.ListMetadata(cmd.Context(), name, itemType)
+	if err != nil {
+		return err
+	}
+
+	var printer Printer = &TablePrinter{
+		Writer: &tabwriter.TabWriter{
+			Writer: cmd.OutOrStdout(),
+		},
+		Zone: a.Zone,
+	}
+
+	if jsonFormat {
+		printer = &JSONPrinter{
+			Writer: cmd.OutOrStdout(),
+		}
+	}
+
+	defer printer.Flush()
+
+	printer.PrintProperties(properties, metadata)
+
+	return nil
+}
+
+func (a *App) du() *cobra.Command {
+	var inBytes, jsonFormat bool
+
+	cmd := &cobra.Command{
+		Use:               "du <collection path>",
+		Short:             "Show the total size of a collection",
+		Long:              "Show the total size and the number of data objects of a collection, including all its sub-collections. All replicas are taken into account.",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+
+			path := a.Path(args[0])
+
+			size, count, err := a.CollectionSize(cmd.Context(), path)
+			if err != nil {
+				return err
+			}
+
+			result := map[string]any{
+				"path":    path,
+				"size":    size,
+				"objects": count,
+			}
+
+			return outputResult(cmd.OutOrStdout(), result, jsonFormat, func(w io.Writer) {
+				sizeStr := humanize.Bytes(uint64(size))
+
+				if inBytes {
+					sizeStr = strconv.FormatInt(size, 10)
+				}
+
+				fmt.Fprintf(w, "%s\t%d objects\t%s\n", sizeStr, count, path)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVarP(&inBytes, "bytes", "b", false, "Print the size in bytes")
+	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+// ErrProtectedCollection is returned when a protected collection would be removed recursively.
+var ErrProtectedCollection = errors.New("refusing to recursively remove a zone, home or trash collection, pass --i-really-mean-it to proceed")
+
 func (a *App) rm() *cobra.Command {
-	var recursive, skip bool
+	var recursive, skip, force, dryRun bool
 
 	cmd := &cobra.Command{
 		Use:               "rm <path>",
@@ -202,29 +429,86 @@ func (a *App) rm() *cobra.Command {
 
 			if obj.IsDir() {
 				if !recursive {
-					return a.DeleteCollection(cmd.Context(), path, skip)
+					return a.removeReported(cmd, transfer.RemoveDirectory, path, skip, dryRun, func() error {
+						return a.DeleteCollection(cmd.Context(), path, skip)
+					})
+				}
+
+				if a.isProtected(path) && !force {
+					return fmt.Errorf("%w: %s", ErrProtectedCollection, path)
 				}
 
 				opts := transfer.Options{
 					MaxQueued:  10000,
 					MaxThreads: 1,
-					Output:     cmd.OutOrStdout(),
 					SkipTrash:  skip,
+					DryRun:     dryRun,
+				}
+
+				if err := a.progressOutput(cmd, &opts, cmd.OutOrStdout()); err != nil {
+					return err
 				}
 
 				return a.RemoveDir(cmd.Context(), path, opts)
 			}
 
-			return a.DeleteDataObject(cmd.Context(), path, skip)
+			return a.removeReported(cmd, transfer.RemoveFile, path, skip, dryRun, func() error {
+				return a.DeleteDataObject(cmd.Context(), path, skip)
+			})
 		},
 	}
 
 	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Remove files in collection recursively")
 	cmd.Flags().BoolVarP(&skip, "skip-trash", "S", false, "Do not move to trash")
+	cmd.Flags().BoolVar(&force, "i-really-mean-it", false, "Allow to recursively remove the root collection, a zone, the home or trash collection of a zone, or your own home or trash collection")
+	cmd.Flags().BoolVar(&dryRun, dryrunOption, false, "Only print what would be removed, and whether it would be moved to the trash or deleted permanently, without removing anything")
 
 	return cmd
 }
 
+// removeReported runs the given removal, unless dryRun is set, and reports whether the
+// data object or collection was, or would be, moved to the trash or deleted permanently.
+// If --progress-json is passed, the outcome is emitted as JSON progress events instead.
+func (a *App) removeReported(cmd *cobra.Command, action transfer.Action, path string, skipTrash, dryRun bool, remove func() error) error {
+	removal := transfer.RemovalOf(path, skipTrash)
+
+	if dryRun {
+		fmt.Fprintf(cmd.OutOrStdout(), "would %s (%s)\n", action.FormatColors(path, a.colors), removal)
+
+		return nil
+	}
+
+	var opts transfer.Options
+
+	if err := a.progressOutput(cmd, &opts, cmd.OutOrStdout()); err != nil {
+		return err
+	}
+
+	startTime := time.Now()
+
+	if err := remove(); err != nil {
+		return err
+	}
+
+	if opts.JSONOutput == nil {
+		fmt.Fprintf(cmd.OutOrStdout(), "%s (%s)\n", action.FormatColors(path, a.colors), removal)
+
+		return nil
+	}
+
+	progress := transfer.JSONProgress(opts.JSONOutput)
+
+	progress.Handler(transfer.Progress{
+		Action:     action,
+		Label:      path,
+		StartedAt:  startTime,
+		FinishedAt: time.Now(),
+		Removal:    removal,
+	})
+
+	return progress.Close()
+}
+
 const (
 	dataSizeKW   = "dataSize"
 	replStatusKW = "replStatus"
@@ -321,17 +605,33 @@ func (a *App) mv() *cobra.Command {
 		"  " + a.name + " mv /path/to/collection1/file.txt /path/to/collection2/",
 		"  " + a.name + " mv /path/to/collection1 /path/to/collection2/          (move collection1 to /path/to/collection2/collection1)",
 		"  " + a.name + " mv /path/to/collection1/ /path/to/collection2/         (move contents of collection1 into collection2)",
+		"  " + a.name + " mv --from-file renames.txt                             (move each tab-separated source and target listed in renames.txt)",
 	}
 
-	var preserveMtime bool
+	var (
+		preserveMtime bool
+		fromFile      string
+	)
 
 	cmd := &cobra.Command{
 		Use:               "mv <path> <target path>",
 		Short:             "Move a data object or a collection, or move all contents of a collection to the target collection",
 		Example:           strings.Join(examples, "\n"),
-		Args:              cobra.ExactArgs(2),
+		Args:              cobra.RangeArgs(0, 2),
 		ValidArgsFunction: a.CompleteArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if fromFile != "" {
+				if len(args) > 0 {
+					return ErrFromFileArgs
+				}
+
+				return a.moveFromFile(cmd, fromFile)
+			}
+
+			if len(args) != 2 {
+				return fmt.Errorf("accepts 2 arg(s), received %d", len(args))
+			}
+
 			if strings.HasSuffix(args[0], "/") {
 				if !strings.HasSuffix(args[1], "/") {
 					return ErrAmbiguousTarget
@@ -355,6 +655,10 @@ func (a *App) mv() *cobra.Command {
 				return err
 			}
 
+			if obj.IsDir() && api.IsSubPath(dest, src) {
+				return fmt.Errorf("%w: %s is inside %s", ErrRecursiveMove, dest, src)
+			}
+
 			if obj.IsDir() {
 				err = a.RenameCollection(cmd.Context(), src, dest)
 			} else {
@@ -370,11 +674,21 @@ func (a *App) mv() *cobra.Command {
 	}
 
 	cmd.Flags().BoolVar(&preserveMtime, "preserve-mtime", false, "Preserve modification time of files when moving. By default, the modification time is updated to the current time.")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Read the paths to move from a file, or from standard input if the file is \"-\". Each line holds a source and a target path, separated by a tab. The moves are executed concurrently.")
+
+	cmd.MarkFlagsMutuallyExclusive("from-file", "preserve-mtime")
 
 	return cmd
 }
 
+// ErrRecursiveMove is returned when a collection would be moved into itself.
+var ErrRecursiveMove = errors.New("cannot move a collection into itself")
+
 func (a *App) moveDir(ctx context.Context, src, dest string) error {
+	if api.IsSubPath(dest, src) {
+		return fmt.Errorf("%w: %s is inside %s", ErrRecursiveMove, dest, src)
+	}
+
 	return a.Walk(ctx, src, func(path string, record api.Record, err error) error {
 		if err != nil {
 			return err
@@ -408,8 +722,9 @@ in the target collection. Otherwise, a subcollection with the same name will be
 
 func (a *App) cp() *cobra.Command {
 	var (
-		skip, newer, dryRun bool
-		maxThreads          int
+		skip, newer, dryRun, preserveACLs bool
+		ignoreExisting, update            bool
+		maxThreads, parallelFiles         int
 	)
 
 	examples := []string{
@@ -441,29 +756,60 @@ func (a *App) cp() *cobra.Command {
 
 			if obj.IsDir() {
 				opts := transfer.Options{
-					MaxQueued:   10000,
-					MaxThreads:  maxThreads,
-					Output:      cmd.OutOrStdout(),
-					SkipTrash:   skip,
-					OnlyIfNewer: newer,
-					DryRun:      dryRun,
+					MaxQueued:      10000,
+					MaxThreads:     maxThreads,
+					MaxOutstanding: parallelFiles,
+					SkipTrash:      skip,
+					Exclusive:      ignoreExisting,
+					OnlyIfNewer:    newer || update,
+					DryRun:         dryRun,
+					PreserveACLs:   preserveACLs,
+				}
+
+				if err := a.progressOutput(cmd, &opts, cmd.OutOrStdout()); err != nil {
+					return err
 				}
 
 				if !strings.HasSuffix(args[1], "/") {
 					return ErrAmbiguousTarget
 				}
 
+				if api.IsSubPath(dest, src) {
+					return fmt.Errorf("%w: %s is inside %s", transfer.ErrRecursiveCopy, dest, src)
+				}
+
 				return a.CopyDir(cmd.Context(), src, dest, opts)
 			}
 
-			return a.CopyDataObject(cmd.Context(), src, dest)
+			if ignoreExisting || update {
+				existing, err := a.statRemote(cmd.Context(), dest)
+				if err != nil || skipExisting(obj, existing, ignoreExisting, update) {
+					return err
+				}
+			}
+
+			opts := transfer.Options{
+				MaxThreads:   1,
+				DryRun:       dryRun,
+				PreserveACLs: preserveACLs,
+			}
+
+			if err := a.progressOutput(cmd, &opts, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			return a.Copy(cmd.Context(), src, dest, opts)
 		},
 	}
 
 	cmd.Flags().BoolVar(&newer, "newer", false, "Only copy files that are newer than the existing files in the destination")
+	cmd.Flags().BoolVar(&ignoreExisting, "ignore-existing", false, "Skip data objects that already exist in the destination")
+	cmd.Flags().BoolVarP(&update, "update", "u", false, "Skip data objects that are newer in the destination")
 	cmd.Flags().BoolVarP(&skip, "delete-skip-trash", "S", false, "Do not move to trash (applies only when copying a collection)")
 	cmd.Flags().IntVar(&maxThreads, "threads", 5, "Number of upload threads to use (applies only when copying a collection)")
+	cmd.Flags().IntVar(&parallelFiles, "parallel-files", 0, "Maximum number of data objects to copy at the same time, each using --threads threads. Zero means no limit, in which case all copies share --threads connections (applies only when copying a collection)")
 	cmd.Flags().BoolVar(&dryRun, dryrunOption, false, "Only print the actions that would be taken, without performing any changes. Checksums are still computed and stored.")
+	cmd.Flags().BoolVar(&preserveACLs, "preserve-acls", false, "Apply the access permissions and inheritance of the source to the copied collections and data objects")
 
 	return cmd
 }
@@ -539,22 +885,125 @@ func (a *App) touch() *cobra.Command {
 }
 
 func (a *App) checksum() *cobra.Command {
-	return &cobra.Command{
+	var (
+		jsonFormat, verify, compute, recursive, force bool
+		threads                                       int
+	)
+
+	cmd := &cobra.Command{
 		Use:               "checksum <object path>",
 		Short:             "Compute or get the checksum of a file",
+		Long:              "Get the checksum of a data object. If no checksum is stored in the catalog yet, it is computed. If --verify is passed, the checksum is recomputed by the server and compared to the checksum stored in the catalog, and the command fails on a mismatch. Data objects without a stored checksum are only checksummed during verification if --compute is passed. If --recursive is passed, the checksums of all data objects in a collection and its subcollections are computed if missing, or recomputed if --force is passed, and the number of computed and already present checksums is reported.",
 		Args:              cobra.ExactArgs(1),
 		ValidArgsFunction: a.CompleteArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			checksum, err := a.Checksum(cmd.Context(), a.Path(args[0]), false)
+			path := a.Path(args[0])
+
+			if recursive {
+				return a.checksumRecursive(cmd, path, threads, verify, force, jsonFormat)
+			}
+
+			if verify {
+				return a.verifyChecksum(cmd, path, compute, jsonFormat)
+			}
+
+			checksum, err := a.Checksum(cmd.Context(), path, false)
 			if err != nil {
 				return err
 			}
 
-			fmt.Printf("%s\n", hex.EncodeToString(checksum))
+			result := map[string]any{
+				"path":     path,
+				"checksum": hex.EncodeToString(checksum),
+			}
 
-			return nil
+			return outputResult(cmd.OutOrStdout(), result, jsonFormat, func(w io.Writer) {
+				fmt.Fprintf(w, "%s\n", hex.EncodeToString(checksum))
+			})
 		},
 	}
+
+	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+	cmd.Flags().BoolVar(&verify, "verify", false, "Verify the stored checksum against a freshly computed checksum")
+	cmd.Flags().BoolVar(&compute, "compute", false, "Compute the checksum if none is stored, in combination with --verify")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Compute the checksums of all data objects in a collection and its subcollections")
+	cmd.Flags().BoolVar(&force, "force", false, "Recompute checksums that are already present, in combination with --recursive")
+	cmd.Flags().IntVar(&threads, "threads", 4, "Number of data objects to checksum at the same time, in combination with --recursive")
+
+	return cmd
+}
+
+// checksumRecursive computes the missing checksums of all data objects in a collection,
+// and reports how many checksums were computed and how many were already present.
+func (a *App) checksumRecursive(cmd *cobra.Command, path string, threads int, verify, force, jsonFormat bool) error {
+	var computed, present atomic.Int64
+
+	opts := transfer.Options{
+		MaxQueued:          10000,
+		MaxThreads:         threads,
+		IntegrityChecksums: true,
+		CompareChecksums:   verify,
+		ForceChecksums:     force,
+		ChecksumHandler: func(_ string, isComputed bool) {
+			if isComputed {
+				computed.Add(1)
+			} else {
+				present.Add(1)
+			}
+		},
+	}
+
+	if err := a.progressOutput(cmd, &opts, cmd.OutOrStdout()); err != nil {
+		return err
+	}
+
+	if err := a.ComputeChecksums(cmd.Context(), path, opts); err != nil {
+		return err
+	}
+
+	result := map[string]any{
+		"path":     path,
+		"computed": computed.Load(),
+		"present":  present.Load(),
+	}
+
+	return outputResult(cmd.OutOrStdout(), result, jsonFormat, func(w io.Writer) {
+		fmt.Fprintf(w, "%d checksums computed, %d already present\n", computed.Load(), present.Load())
+	})
+}
+
+// verifyChecksum lets the server recompute the checksum of a data object and compare it to the
+// checksum stored in the catalog. If no checksum is stored and compute is set, it is computed instead.
+func (a *App) verifyChecksum(cmd *cobra.Command, path string, compute, jsonFormat bool) error {
+	status := "verified"
+
+	err := a.VerifyChecksum(cmd.Context(), path)
+
+	switch {
+	case api.Is(err, msg.CAT_NO_CHECKSUM_FOR_REPLICA) && compute:
+		status = "computed"
+	case api.Is(err, msg.CAT_NO_CHECKSUM_FOR_REPLICA):
+		return fmt.Errorf("%w: no checksum stored for %s, use --compute to compute it", ErrVerificationFailed, path)
+	case api.Is(err, msg.USER_CHKSUM_MISMATCH):
+		return fmt.Errorf("%w: checksum mismatch for %s", ErrVerificationFailed, path)
+	case err != nil:
+		return err
+	}
+
+	checksum, err := a.Checksum(cmd.Context(), path, false)
+	if err != nil {
+		return err
+	}
+
+	result := map[string]any{
+		"path":     path,
+		"checksum": hex.EncodeToString(checksum),
+		"status":   status,
+	}
+
+	return outputResult(cmd.OutOrStdout(), result, jsonFormat, func(w io.Writer) {
+		Fprintcolorln(w, Green, hex.EncodeToString(checksum)+" ("+status+")")
+	})
 }
 
 func (a *App) checksums() *cobra.Command {
@@ -576,11 +1025,14 @@ func (a *App) checksums() *cobra.Command {
 			opts := transfer.Options{
 				MaxQueued:          10000,
 				MaxThreads:         1,
-				Output:             cmd.OutOrStdout(),
 				IntegrityChecksums: compute,
 				CompareChecksums:   verify,
 			}
 
+			if err := a.progressOutput(cmd, &opts, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
 			return a.ComputeChecksums(cmd.Context(), path, opts)
 		},
 	}
@@ -593,6 +1045,88 @@ func (a *App) checksums() *cobra.Command {
 
 var ErrAmbiguousTarget = errors.New("ambiguous command, please specify a target collection or directory with a trailing slash")
 
+var ErrInsufficientSpace = errors.New("insufficient disk space")
+
+// skipExisting reports whether the transfer of a single file should be skipped, because the
+// target exists and --ignore-existing is passed, or because the target is newer than the
+// source and --update is passed, like the rsync options with the same name.
+func skipExisting(source, target os.FileInfo, ignoreExisting, update bool) bool {
+	if target == nil {
+		return false
+	}
+
+	if ignoreExisting {
+		return true
+	}
+
+	return update && target.ModTime().Truncate(time.Second).After(source.ModTime().Truncate(time.Second))
+}
+
+// statRemote returns the record at the given path, or nil if it does not exist
+func (a *App) statRemote(ctx context.Context, path string) (os.FileInfo, error) {
+	record, err := a.GetRecord(ctx, path)
+	if api.Is(err, msg.CAT_NO_ROWS_FOUND) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return record, nil
+}
+
+// statLocal returns the file info of the given local path, or nil if it does not exist
+func statLocal(path string) (os.FileInfo, error) {
+	fi, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	return fi, nil
+}
+
+var ErrProgressOnStdout = errors.New("standard output carries data, pass another file descriptor, e.g. --progress-json=2")
+
+// progressOutput sets the output for the progress bar of a transfer. If --progress-json
+// is passed, JSON progress events are written to the chosen file descriptor instead,
+// which must be open.
+func (a *App) progressOutput(cmd *cobra.Command, opts *transfer.Options, output io.Writer) error {
+	opts.NoColors = !a.colors
+
+	switch a.ProgressJSON {
+	case 0:
+		opts.Output = output
+	case 1:
+		opts.JSONOutput = cmd.OutOrStdout()
+	case 2:
+		opts.JSONOutput = cmd.ErrOrStderr()
+	default:
+		f := os.NewFile(uintptr(a.ProgressJSON), "progress")
+		if f == nil {
+			return fmt.Errorf("invalid file descriptor for --progress-json: %d", a.ProgressJSON)
+		}
+
+		if _, err := f.Stat(); err != nil {
+			return fmt.Errorf("invalid file descriptor for --progress-json: %w", err)
+		}
+
+		opts.JSONOutput = f
+	}
+
+	return nil
+}
+
+// dataProgressOutput is progressOutput for commands that write data to stdout,
+// on which JSON progress events can't be written.
+func (a *App) dataProgressOutput(cmd *cobra.Command, opts *transfer.Options, output io.Writer) error {
+	if a.ProgressJSON == 1 {
+		return ErrProgressOnStdout
+	}
+
+	return a.progressOutput(cmd, opts, output)
+}
+
 const uploadDescription = `Upload a file or directory to the target path.
 This command will compare the source and target, and only upload the missing parts.
 It can be repeated to keep the target up to date.
@@ -602,6 +1136,8 @@ If the source directory ends in a slash, files underneath will be placed directl
 in the target collection. Otherwise, a subcollection with the same name will be created.`
 
 func (a *App) upload() *cobra.Command { //nolint:funlen
+	var ignoreExisting, update, estimate, yes, verify bool
+
 	opts := transfer.Options{
 		SyncModTime: true,
 		MaxQueued:   10000,
@@ -641,9 +1177,21 @@ func (a *App) upload() *cobra.Command { //nolint:funlen
 				return err
 			}
 
-			opts.Output = cmd.OutOrStdout()
+			if err := a.progressOutput(cmd, &opts, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			opts.Exclusive = opts.Exclusive || ignoreExisting
+			opts.OnlyIfNewer = opts.OnlyIfNewer || update
 
 			if !fi.IsDir() {
+				if ignoreExisting || update {
+					existing, err := a.statRemote(cmd.Context(), target)
+					if err != nil || skipExisting(fi, existing, ignoreExisting, update) {
+						return err
+					}
+				}
+
 				return a.Upload(cmd.Context(), source, target, opts)
 			}
 
@@ -651,18 +1199,41 @@ func (a *App) upload() *cobra.Command { //nolint:funlen
 				return ErrAmbiguousTarget
 			}
 
-			return a.UploadDir(cmd.Context(), source, target, opts)
+			uploadDir := func(opts transfer.Options) error {
+				if err := a.UploadDir(cmd.Context(), source, target, opts); err != nil || !verify || opts.DryRun {
+					return err
+				}
+
+				return a.VerifyDir(cmd.Context(), source, target, opts)
+			}
+
+			if estimate && !opts.DryRun {
+				return a.withEstimate(cmd, opts, yes, uploadDir)
+			}
+
+			return uploadDir(opts)
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.Exclusive, "exclusive", false, "Do not overwrite existing files")
 	cmd.Flags().BoolVar(&opts.OnlyIfNewer, "newer", false, "Only upload files that are newer than the existing files in the destination")
+	cmd.Flags().BoolVar(&ignoreExisting, "ignore-existing", false, "Skip files that already exist in the destination")
+	cmd.Flags().BoolVarP(&update, "update", "u", false, "Skip files that are newer in the destination")
+	cmd.Flags().BoolVar(&estimate, "estimate", false, "Before uploading a directory, print the number of files and bytes to upload and an estimate of the duration, and ask for confirmation")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation after printing the estimate")
 	cmd.Flags().BoolVar(&opts.Delete, "delete", false, "Delete files in the destination that no longer exist in the source")
 	cmd.Flags().BoolVarP(&opts.SkipTrash, "delete-skip-trash", "S", false, "Do not move to trash when deleting")
 	cmd.Flags().BoolVar(&opts.DisableUpdateInPlace, "no-update-in-place", false, "Do not update objects in place, delete old versions first")
+	cmd.Flags().BoolVar(&opts.CheckResource, "check-resource", false, "Check that the target resource is not marked down before uploading")
+	cmd.Flags().BoolVar(&verify, "verify", false, "After uploading a directory, verify that the destination matches the source exactly, comparing checksums and reporting missing and extra files")
+	cmd.Flags().BoolVar(&opts.DetectContentType, "detect-content-type", false, "Record the detected MIME type of uploaded files as metadata")
+	cmd.Flags().BoolVarP(&opts.FollowSymlinks, "follow-symlinks", "L", false, "Follow symbolic links when uploading a directory, and upload the linked files and directories. Without it, symbolic links are skipped")
 	cmd.Flags().IntVar(&opts.MaxThreads, "threads", 5, "Number of upload threads to use")
+	cmd.Flags().IntVar(&opts.MaxOutstanding, "parallel-files", 0, "Maximum number of files to upload at the same time, each using --threads threads. Zero means no limit, in which case all uploads share --threads connections")
 	cmd.Flags().BoolVar(&opts.CompareChecksums, "checksum", false, "Compare checksums instead of size and modtime to select files to upload")
+	cmd.Flags().BoolVar(&opts.ChecksumOnModTimeChange, "checksum-modtime", false, "Compare checksums of files that only differ in modtime, and only sync the modtime if the checksums match")
 	cmd.Flags().BoolVar(&opts.IntegrityChecksums, "verify-checksum", false, "Compute checksums before and after uploading files, and verify equality to ensure transfer integrity")
+	cmd.Flags().BoolVar(&opts.ReportVerification, "report-verification", false, "Report the outcome of the checksum verification for each file")
 	cmd.Flags().BoolVar(&opts.DryRun, dryrunOption, false, "Only print the actions that would be taken, without performing any changes. Server side checksums are still computed and stored, even if this flag is used.")
 	cmd.Flags().StringSliceVar(&opts.IgnorePatterns, "ignore", nil, "Comma separated list of patterns to ignore when uploading a directory. The pattern is applied to filenames only, not the complete path.")
 	cmd.Flags().BoolVar(&opts.SyncModTime, "sync-modtime", true, "Use the modification time of the destination file to match the source file. Disable with --sync-modtime=false.")
@@ -670,6 +1241,33 @@ func (a *App) upload() *cobra.Command { //nolint:funlen
 	return cmd
 }
 
+const importLocalLinksDescription = `Upload a local directory to the target collection, following symbolic links.
+Linked files and directories are uploaded as if they were regular files and directories,
+so that a local tree that is assembled from symbolic links is registered completely.
+Links that point to one of their parent directories would loop forever, and are skipped
+with a warning. This is the same as upload --follow-symlinks.`
+
+func (a *App) importLocalLinks() *cobra.Command {
+	cmd := a.upload()
+
+	cmd.Use = "import-local-links <local directory> [target collection]"
+	cmd.Aliases = nil
+	cmd.Short = "Upload a local directory, following symbolic links"
+	cmd.Long = importLocalLinksDescription
+	cmd.Example = strings.Join([]string{
+		"  " + a.name + " import-local-links /local/folder /path/to/collection/    (upload local folder to target collection as a subcollection)",
+		"  " + a.name + " import-local-links /local/folder/ /path/to/collection/   (upload local folder contents to target collection)",
+	}, "\n")
+
+	// Symbolic links are always followed
+	flag := cmd.Flags().Lookup("follow-symlinks")
+	flag.Value.Set("true") //nolint:errcheck
+	flag.DefValue = "true"
+	flag.Hidden = true
+
+	return cmd
+}
+
 const downloadDescription = `Download a data object or a collection to the local path.
 This command will compare the source and target, and only download the missing parts.
 It can be repeated to keep the target up to date.
@@ -679,6 +1277,8 @@ If the source collection ends in a slash, files underneath will be placed direct
 in the target folder. Otherwise, a subfolder with the same name will be created.`
 
 func (a *App) download() *cobra.Command { //nolint:funlen
+	var force, ignoreExisting, update, estimate, yes bool
+
 	opts := transfer.Options{
 		SyncModTime: true,
 		MaxQueued:   10000,
@@ -723,9 +1323,21 @@ func (a *App) download() *cobra.Command { //nolint:funlen
 				return err
 			}
 
-			opts.Output = cmd.OutOrStdout()
+			if err := a.progressOutput(cmd, &opts, cmd.OutOrStdout()); err != nil {
+				return err
+			}
+
+			opts.Exclusive = opts.Exclusive || ignoreExisting
+			opts.OnlyIfNewer = opts.OnlyIfNewer || update
 
 			if !record.IsDir() {
+				if ignoreExisting || update {
+					existing, err := statLocal(target)
+					if err != nil || skipExisting(record, existing, ignoreExisting, update) {
+						return err
+					}
+				}
+
 				return a.Download(cmd.Context(), target, source, opts)
 			}
 
@@ -733,16 +1345,37 @@ func (a *App) download() *cobra.Command { //nolint:funlen
 				return ErrAmbiguousTarget
 			}
 
+			if !force && !opts.DryRun {
+				if err := a.checkFreeSpace(cmd.Context(), source, target); err != nil {
+					return err
+				}
+			}
+
+			if estimate && !opts.DryRun {
+				return a.withEstimate(cmd, opts, yes, func(opts transfer.Options) error {
+					return a.DownloadDir(cmd.Context(), target, source, opts)
+				})
+			}
+
 			return a.DownloadDir(cmd.Context(), target, source, opts)
 		},
 	}
 
 	cmd.Flags().BoolVar(&opts.Exclusive, "exclusive", false, "Do not overwrite existing files")
 	cmd.Flags().BoolVar(&opts.OnlyIfNewer, "newer", false, "Only download files that are newer than the existing files in the destination")
+	cmd.Flags().BoolVar(&ignoreExisting, "ignore-existing", false, "Skip files that already exist in the destination")
+	cmd.Flags().BoolVarP(&update, "update", "u", false, "Skip files that are newer in the destination")
+	cmd.Flags().BoolVar(&estimate, "estimate", false, "Before downloading a collection, print the number of files and bytes to download and an estimate of the duration, and ask for confirmation")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Do not ask for confirmation after printing the estimate")
 	cmd.Flags().BoolVar(&opts.Delete, "delete", false, "Delete files in the destination that no longer exist in the source")
 	cmd.Flags().IntVar(&opts.MaxThreads, "threads", 5, "Number of download threads to use")
+	cmd.Flags().IntVar(&opts.MaxOutstanding, "parallel-files", 0, "Maximum number of files to download at the same time, each using --threads threads. Zero means no limit, in which case all downloads share --threads connections")
+	cmd.Flags().BoolVar(&opts.PreAllocate, "preallocate", false, "Reserve disk space for each file before downloading it, to fail early if the disk is full")
+	cmd.Flags().BoolVar(&force, "force", false, "Download a collection even if its total size exceeds the available disk space")
 	cmd.Flags().BoolVar(&opts.CompareChecksums, "checksum", false, "Compare checksums instead of size and modtime to select files to download")
+	cmd.Flags().BoolVar(&opts.ChecksumOnModTimeChange, "checksum-modtime", false, "Compare checksums of files that only differ in modtime, and only sync the modtime if the checksums match")
 	cmd.Flags().BoolVar(&opts.IntegrityChecksums, "verify-checksum", false, "Compute checksums before and after downloading files, and verify equality to ensure transfer integrity")
+	cmd.Flags().BoolVar(&opts.ReportVerification, "report-verification", false, "Report the outcome of the checksum verification for each file")
 	cmd.Flags().BoolVar(&opts.DryRun, dryrunOption, false, "Only print the actions that would be taken, without performing any changes. Server side checksums are still computed and stored, even if this flag is used.")
 	cmd.Flags().StringSliceVar(&opts.IgnorePatterns, "ignore", nil, "Comma separated list of patterns to ignore when downloading a directory. The pattern is applied to filenames only, not the complete path.")
 	cmd.Flags().BoolVar(&opts.SyncModTime, "sync-modtime", true, "Use the modification time of the destination file to match the source file. Disable with --sync-modtime=false.")
@@ -750,6 +1383,92 @@ func (a *App) download() *cobra.Command { //nolint:funlen
 	return cmd
 }
 
+// checkFreeSpace compares the size of the data objects in the source collection that
+// are missing at the target with the free disk space at the target, and returns
+// ErrInsufficientSpace if they don't fit. Files that exist at the target are only
+// counted for the part that they are smaller than the data object, and each data
+// object is counted once, regardless of its number of replicas.
+// If the free space cannot be determined, the check is skipped.
+func (a *App) checkFreeSpace(ctx context.Context, source, target string) error {
+	// The target might not exist yet, use the nearest existing parent
+	dir := target
+
+	for parent := filepath.Dir(dir); parent != dir; parent = filepath.Dir(dir) {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+
+		dir = parent
+	}
+
+	free, err := freeSpace(dir)
+	if err != nil {
+		return nil
+	}
+
+	size, err := a.missingSize(ctx, source, target)
+	if err != nil || size <= free {
+		return err
+	}
+
+	return fmt.Errorf("%w: %s needs %s, but only %s is available in %s, use --force to download anyway", ErrInsufficientSpace, source, humanize.Bytes(uint64(size)), humanize.Bytes(uint64(free)), dir)
+}
+
+// missingSize returns the number of bytes that need to be added at the target
+// to download the source collection, see checkFreeSpace.
+func (a *App) missingSize(ctx context.Context, source, target string) (int64, error) {
+	conditions := []api.Condition{
+		api.Equal(msg.ICAT_COLUMN_COLL_NAME, source),
+		api.Like(msg.ICAT_COLUMN_COLL_NAME, source+"/%"),
+	}
+
+	if source == "/" {
+		conditions = conditions[1:]
+	}
+
+	// Replicas of the same size are returned once, keep the largest size otherwise
+	sizes := map[string]int64{}
+
+	for _, condition := range conditions {
+		results := a.Query(msg.ICAT_COLUMN_COLL_NAME, msg.ICAT_COLUMN_DATA_NAME, msg.ICAT_COLUMN_DATA_SIZE).With(condition).Execute(ctx)
+
+		for results.Next() {
+			var (
+				coll, name string
+				size       int64
+			)
+
+			if err := results.Scan(&coll, &name, &size); err != nil {
+				results.Close()
+
+				return 0, err
+			}
+
+			path := coll + "/" + name
+
+			sizes[path] = max(sizes[path], size)
+		}
+
+		if err := results.Err(); err != nil {
+			return 0, err
+		}
+	}
+
+	var total int64
+
+	for path, size := range sizes {
+		rel := strings.TrimPrefix(strings.TrimPrefix(path, source), "/")
+
+		if fi, err := os.Stat(filepath.Join(target, filepath.FromSlash(rel))); err == nil {
+			size -= fi.Size()
+		}
+
+		total += max(size, 0)
+	}
+
+	return total, nil
+}
+
 func localPathEndsWithSeparator(path string) bool {
 	return strings.HasSuffix(path, "/") || strings.HasSuffix(path, string(os.PathSeparator))
 }
@@ -771,10 +1490,15 @@ func (a *App) cat() *cobra.Command {
 				output = cmd.ErrOrStderr()
 			}
 
-			return a.Client.ToWriter(cmd.Context(), cmd.OutOrStdout(), source, transfer.Options{
+			opts := transfer.Options{
 				MaxThreads: maxThreads,
-				Output:     output,
-			})
+			}
+
+			if err := a.dataProgressOutput(cmd, &opts, output); err != nil {
+				return err
+			}
+
+			return a.Client.ToWriter(cmd.Context(), cmd.OutOrStdout(), source, opts)
 		},
 	}
 
@@ -872,15 +1596,22 @@ func (a *App) save() *cobra.Command {
 			}
 
 			input := cmd.InOrStdin()
+			progressOutput := a.progressOutput
 
 			if cmd.CalledAs() == "tee" {
 				input = io.TeeReader(input, cmd.OutOrStdout())
+				progressOutput = a.dataProgressOutput
 			}
 
-			return a.Client.FromReader(cmd.Context(), input, target, appendFlag, transfer.Options{
+			opts := transfer.Options{
 				MaxThreads: maxThreads,
-				Output:     output,
-			})
+			}
+
+			if err := progressOutput(cmd, &opts, output); err != nil {
+				return err
+			}
+
+			return a.Client.FromReader(cmd.Context(), input, target, appendFlag, opts)
 		},
 	}
 
@@ -987,62 +1718,194 @@ For data objects it indicates the status of the replicas, as follows:
 
 var columnsDisplayDescription = "Columns to display. Available options: creator, size, date, status, name, checksum, all."
 
-func (a *App) list() *cobra.Command {
-	var (
-		jsonFormat, listACL, listMeta, collectionSizes bool
-		columns                                        []string
-	)
+func (a *App) list() *cobra.Command {
+	var (
+		jsonFormat, listACL, listMeta, collectionSizes, recursive, counts, treeSize bool
+		onlyFiles, onlyDirs, noPager                                                bool
+		columns                                                                     []string
+	)
+
+	defaultColumns := []string{"creator", "size", "date", "status", "name"}
+
+	cmd := &cobra.Command{
+		Use:               "ls <collection path>",
+		Aliases:           []string{"list"},
+		Short:             "List the contents of a collection or information about a data object",
+		Long:              listDescription,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			defer pageOutput(cmd, noPager)()
+
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+
+			dir := a.Path(args[0])
+
+			if treeSize {
+				return a.listTreeSize(cmd.Context(), cmd.OutOrStdout(), dir)
+			}
+
+			available := []string{"creator", "size", "date", "status", "checksum", "name"}
+			opts := walkOptions(listACL, listMeta, collectionSizes)
+
+			if counts {
+				available = slices.Insert(available, 2, "objects")
+				opts = append(opts, api.FetchCollectionCount)
+
+				if !slices.Contains(columns, "all") {
+					columns = append(columns, "objects")
+				}
+			}
+
+			hideColumns, err := hiddenColumns(columns, defaultColumns, available...)
+			if err != nil {
+				return err
+			}
+
+			newPrinter := func() Printer {
+				var printer Printer = &TablePrinter{
+					Writer: &tabwriter.TabWriter{
+						Writer:      cmd.OutOrStdout(),
+						HideColumns: hideColumns,
+					},
+					Zone:         a.Zone,
+					ObjectCounts: counts,
+				}
+
+				if jsonFormat {
+					printer = &JSONPrinter{
+						Writer:       cmd.OutOrStdout(),
+						ObjectCounts: counts,
+					}
+				}
+
+				printer.Setup(listACL, listMeta, collectionSizes)
+
+				return filterPrinter(printer, onlyFiles, onlyDirs)
+			}
+
+			if recursive {
+				return a.listRecursive(cmd.Context(), cmd.OutOrStdout(), dir, dir, newPrinter, !jsonFormat, opts)
+			}
+
+			printer := newPrinter()
+
+			defer printer.Flush()
+
+			return a.Walk(cmd.Context(), dir, listFunc(dir, printer), opts...)
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+	cmd.Flags().BoolVarP(&listACL, "acl", "a", false, "List ACLs")
+	cmd.Flags().BoolVarP(&listMeta, "meta", "m", false, "List metadata")
+	cmd.Flags().BoolVarP(&collectionSizes, "sizes", "s", false, "Show the total size of objects in a collection (this does not include sub-collections).")
+	cmd.Flags().BoolVarP(&recursive, "recursive", "R", false, "List subcollections recursively, grouped per collection")
+	cmd.Flags().BoolVar(&counts, "counts", false, "Show the number of data objects in a collection (this does not include sub-collections). Data objects with several replicas are counted once.")
+	cmd.Flags().BoolVar(&treeSize, "tree-size", false, "Print the full tree structure with the size of each data object and the total size of each collection including sub-collections. This requires additional queries per collection.")
+	cmd.Flags().StringSliceVar(&columns, "columns", defaultColumns, columnsDisplayDescription)
+
+	addTypeFilterFlags(cmd, &onlyFiles, &onlyDirs)
+	addPagerFlag(cmd, &noPager)
+
+	cmd.MarkFlagsMutuallyExclusive("tree-size", "json")
+	cmd.MarkFlagsMutuallyExclusive("tree-size", "recursive")
+	cmd.MarkFlagsMutuallyExclusive("tree-size", "only-files")
+	cmd.MarkFlagsMutuallyExclusive("tree-size", "only-dirs")
+
+	return cmd
+}
+
+// listRecursive lists the given collection and all of its subcollections, one
+// collection at a time, similar to `ls -R`. If headers is set, the contents of
+// each collection are preceded by its path. Otherwise, records are printed
+// with their path relative to root.
+func (a *App) listRecursive(ctx context.Context, w io.Writer, root, dir string, newPrinter func() Printer, headers bool, opts []api.WalkOption) error {
+	var subcollections []string
+
+	printer := newPrinter()
+
+	err := a.Walk(ctx, dir, func(path string, record api.Record, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if path == dir && record.IsDir() {
+			if headers {
+				fmt.Fprintf(w, "%s:\n", dir)
+			}
+
+			return api.SkipSubDirs
+		}
+
+		name := record.Name()
+
+		if !headers && path != root {
+			name = strings.TrimPrefix(strings.TrimPrefix(path, root), "/")
+		}
+
+		printer.Print(name, record)
+
+		if record.IsDir() {
+			subcollections = append(subcollections, path)
+
+			return api.SkipDir
+		}
+
+		return nil
+	}, opts...)
+
+	printer.Flush()
+
+	if err != nil {
+		return err
+	}
+
+	for _, subcollection := range subcollections {
+		if headers {
+			fmt.Fprintln(w)
+		}
+
+		if err := a.listRecursive(ctx, w, root, subcollection, newPrinter, headers, opts); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// listTreeSize prints the tree structure beneath dir, with the size of each
+// data object and the total size of each collection in parentheses. The total
+// size of a collection includes its subcollections and all replicas.
+func (a *App) listTreeSize(ctx context.Context, w io.Writer, dir string) error {
+	return a.Walk(ctx, dir, func(path string, record api.Record, err error) error {
+		if err != nil {
+			return err
+		}
 
-	defaultColumns := []string{"creator", "size", "date", "status", "name"}
+		name := record.Name()
 
-	cmd := &cobra.Command{
-		Use:               "ls <collection path>",
-		Aliases:           []string{"list"},
-		Short:             "List the contents of a collection or information about a data object",
-		Long:              listDescription,
-		Args:              cobra.MaximumNArgs(1),
-		ValidArgsFunction: a.CompleteArgs,
-		RunE: func(cmd *cobra.Command, args []string) error {
-			if len(args) == 0 {
-				args = []string{"."}
-			}
+		if path == dir {
+			name = dir
+		}
 
-			dir := a.Path(args[0])
+		size := record.Size()
 
-			hideColumns, err := hiddenColumns(columns, defaultColumns, "creator", "size", "date", "status", "checksum", "name")
+		if record.IsDir() {
+			size, _, err = a.CollectionSize(ctx, path)
 			if err != nil {
 				return err
 			}
+		}
 
-			var printer Printer = &TablePrinter{
-				Writer: &tabwriter.TabWriter{
-					Writer:      cmd.OutOrStdout(),
-					HideColumns: hideColumns,
-				},
-				Zone: a.Zone,
-			}
-
-			if jsonFormat {
-				printer = &JSONPrinter{
-					Writer: cmd.OutOrStdout(),
-				}
-			}
-
-			printer.Setup(listACL, listMeta, collectionSizes)
-
-			defer printer.Flush()
-
-			return a.Walk(cmd.Context(), dir, listFunc(dir, printer), walkOptions(listACL, listMeta, collectionSizes)...)
-		},
-	}
+		depth := strings.Count(strings.TrimPrefix(path, dir), "/")
 
-	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
-	cmd.Flags().BoolVarP(&listACL, "acl", "a", false, "List ACLs")
-	cmd.Flags().BoolVarP(&listMeta, "meta", "m", false, "List metadata")
-	cmd.Flags().BoolVarP(&collectionSizes, "sizes", "s", false, "Show the total size of objects in a collection (this does not include sub-collections).")
-	cmd.Flags().StringSliceVar(&columns, "columns", defaultColumns, columnsDisplayDescription)
+		fmt.Fprintf(w, "%s (%s)\n", indentString(name, depth, false), humanize.Bytes(uint64(size)))
 
-	return cmd
+		return nil
+	}, api.LexographicalOrder, api.NoSkip)
 }
 
 func listFunc(dir string, printer Printer) func(path string, record api.Record, err error) error {
@@ -1133,10 +1996,12 @@ func hiddenColumnsSelection(columns, defaultColumns []string) []string {
 
 func (a *App) tree() *cobra.Command { //nolint:funlen
 	var (
-		jsonFormat      bool
-		maxDepth        int
-		columns         []string
-		collectionSizes bool
+		jsonFormat          bool
+		maxDepth            int
+		columns             []string
+		collectionSizes     bool
+		onlyFiles, onlyDirs bool
+		noPager             bool
 	)
 
 	defaultColumns := []string{"name"}
@@ -1147,6 +2012,8 @@ func (a *App) tree() *cobra.Command { //nolint:funlen
 		Args:              cobra.MaximumNArgs(1),
 		ValidArgsFunction: a.CompleteArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			defer pageOutput(cmd, noPager)()
+
 			if len(args) == 0 {
 				args = []string{"."}
 			}
@@ -1175,6 +2042,8 @@ func (a *App) tree() *cobra.Command { //nolint:funlen
 
 			printer.Setup(false, false, collectionSizes)
 
+			printer = filterPrinter(printer, onlyFiles, onlyDirs)
+
 			defer printer.Flush()
 
 			opts := []api.WalkOption{api.LexographicalOrder}
@@ -1196,6 +2065,9 @@ func (a *App) tree() *cobra.Command { //nolint:funlen
 	cmd.Flags().StringSliceVar(&columns, "columns", defaultColumns, columnsDisplayDescription)
 	cmd.Flags().BoolVarP(&collectionSizes, "sizes", "s", false, "Show the total size of objects in a collection (this does not include sub-collections).")
 
+	addTypeFilterFlags(cmd, &onlyFiles, &onlyDirs)
+	addPagerFlag(cmd, &noPager)
+
 	return cmd
 }
 
@@ -1239,8 +2111,12 @@ func indentString(s string, depth int, jsonFormat bool) string {
 
 func (a *App) find() *cobra.Command {
 	var (
-		jsonFormat, listACL, listMeta, collectionSizes bool
-		columns                                        []string
+		jsonFormat, listACL, listMeta, collectionSizes, expired bool
+		onlyFiles, onlyDirs                                     bool
+		columns                                                 []string
+		maxResults                                              int
+		sinkName                                                string
+		predicates                                              findPredicates
 	)
 
 	defaultColumns := []string{"creator", "size", "date", "status", "name"}
@@ -1249,6 +2125,7 @@ func (a *App) find() *cobra.Command {
 		Use:               "find <collection path>",
 		Aliases:           []string{"search"},
 		Short:             "Find collections or data objects based on globs",
+		Long:              "Find collections or data objects based on globs. If --expired is passed, the argument is interpreted as a collection instead, and all data objects in the collection and its subcollections of which the expiry has passed are listed. Likewise, if --name, --meta, --newer-than or --larger-than is passed, all data objects in the collection and its subcollections that match all of the given predicates are listed.",
 		Args:              cobra.MaximumNArgs(1),
 		ValidArgsFunction: a.CompleteArgs,
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -1258,6 +2135,16 @@ func (a *App) find() *cobra.Command {
 
 			pattern := a.Path(args[0])
 
+			var filter *findFilter
+
+			if predicates.IsSet() {
+				var err error
+
+				if filter, err = predicates.Filter(time.Now()); err != nil {
+					return err
+				}
+			}
+
 			hideColumns, err := hiddenColumns(columns, defaultColumns, "creator", "size", "date", "status", "checksum", "name")
 			if err != nil {
 				return err
@@ -1277,26 +2164,91 @@ func (a *App) find() *cobra.Command {
 				}
 			}
 
+			var sinkPrinter *SinkPrinter
+
+			if sinkName != "" {
+				sink, err := a.openSink(sinkName, cmd.OutOrStdout(), recordColumns)
+				if err != nil {
+					return err
+				}
+
+				sinkPrinter = &SinkPrinter{Sink: sink}
+				printer = sinkPrinter
+			}
+
 			printer.Setup(listACL, listMeta, collectionSizes)
 
-			defer printer.Flush()
+			printer = filterPrinter(printer, onlyFiles, onlyDirs)
+
+			limiter := &resultLimiter{
+				Max:    maxResults,
+				Writer: cmd.ErrOrStderr(),
+			}
+
+			switch {
+			case expired:
+				err = a.FindExpired(cmd.Context(), pattern, time.Now(), findFunc(printer, limiter))
+			case filter != nil:
+				err = a.findMatching(cmd.Context(), pattern, filter, findFunc(printer, limiter))
+			default:
+				err = a.Glob(cmd.Context(), a.Workdir, pattern, findFunc(printer, limiter))
+			}
+
+			printer.Flush()
+
+			if sinkPrinter != nil {
+				return multierr.Append(err, sinkPrinter.Err())
+			}
 
-			return a.Glob(cmd.Context(), a.Workdir, pattern, findFunc(printer))
+			return err
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+	cmd.Flags().BoolVar(&expired, "expired", false, "Find data objects of which the expiry has passed")
+	cmd.Flags().IntVar(&maxResults, maxResultsOption, 0, "Stop after the given number of results, 0 means unlimited")
 	cmd.Flags().StringSliceVar(&columns, "columns", defaultColumns, columnsDisplayDescription)
+	cmd.Flags().StringVar(&predicates.Name, "name", "", "Find data objects of which the name matches the given glob")
+	cmd.Flags().StringArrayVar(&predicates.Meta, "meta", nil, "Find data objects that have the given key=value metadata, can be repeated to require multiple attributes")
+	cmd.Flags().StringVar(&predicates.NewerThan, "newer-than", "", "Find data objects that were modified within the given duration, e.g. 12h or 7d")
+	cmd.Flags().StringVar(&predicates.LargerThan, "larger-than", "", "Find data objects that are larger than the given size, e.g. 100MB")
+
+	addTypeFilterFlags(cmd, &onlyFiles, &onlyDirs)
+
+	a.addSinkFlag(cmd, &sinkName)
+
+	cmd.MarkFlagsMutuallyExclusive("json", sinkOption)
+
+	for _, name := range []string{"name", "meta", "newer-than", "larger-than"} {
+		cmd.MarkFlagsMutuallyExclusive("expired", name)
+	}
 
 	return cmd
 }
 
-func findFunc(printer Printer) func(path string, record api.Record, err error) error {
+// addTypeFilterFlags adds the --only-files and --only-dirs flags to a listing command.
+func addTypeFilterFlags(cmd *cobra.Command, onlyFiles, onlyDirs *bool) {
+	cmd.Flags().BoolVar(onlyFiles, "only-files", false, "Only list data objects")
+	cmd.Flags().BoolVar(onlyDirs, "only-dirs", false, "Only list collections")
+
+	cmd.MarkFlagsMutuallyExclusive("only-files", "only-dirs")
+}
+
+func findFunc(printer Printer, limiter *resultLimiter) func(path string, record api.Record, err error) error {
 	return func(path string, record api.Record, err error) error {
 		if err != nil {
 			return err
 		}
 
+		// Filtered records should not count towards the limit
+		if filter, ok := printer.(*FilterPrinter); ok && !filter.Match(record) {
+			return nil
+		}
+
+		if !limiter.Allow() {
+			return api.SkipAll
+		}
+
 		printer.Print(path, record)
 
 		return nil
@@ -1321,13 +2273,16 @@ func (a *App) meta() *cobra.Command {
 			return client.SetMetadata
 		}),
 		a.metaunset(),
+		a.metaimport(),
 	)
 
 	return meta
 }
 
 func (a *App) metals() *cobra.Command {
-	return &cobra.Command{
+	var jsonFormat bool
+
+	cmd := &cobra.Command{
 		Use:               "ls <path>",
 		Short:             "List metadata",
 		Args:              cobra.ExactArgs(1),
@@ -1340,21 +2295,31 @@ func (a *App) metals() *cobra.Command {
 				return err
 			}
 
-			out := &tabwriter.TabWriter{
-				Writer: cmd.OutOrStdout(),
+			metadata := stat.Metadata()
+
+			if metadata == nil {
+				metadata = []api.Metadata{}
 			}
 
-			defer out.Flush()
+			return outputResult(cmd.OutOrStdout(), metadata, jsonFormat, func(w io.Writer) {
+				out := &tabwriter.TabWriter{
+					Writer: w,
+				}
 
-			fmt.Fprintf(out, "%sKEY\tVALUE\tUNITS%s\n", Bold, Reset)
+				defer out.Flush()
 
-			for _, m := range stat.Metadata() {
-				fmt.Fprintf(out, "%s\t%s\t%s\n", m.Name, m.Value, m.Units)
-			}
+				fmt.Fprintf(out, "%sKEY\tVALUE\tUNITS%s\n", Bold, Reset)
 
-			return nil
+				for _, m := range metadata {
+					fmt.Fprintf(out, "%s\t%s\t%s\n", m.Name, m.Value, m.Units)
+				}
+			})
 		},
 	}
+
+	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+
+	return cmd
 }
 
 func (a *App) metaop(op, description string, fn func(*api.API) func(context.Context, string, api.ObjectType, api.Metadata) error) *cobra.Command {
@@ -1409,6 +2374,94 @@ func (a *App) metaunset() *cobra.Command {
 	}
 }
 
+const metaImportDescription = `Import metadata from a JSON file, or from standard input if the file is "-".
+
+The file should contain an object that maps paths to a list of metadata triplets:
+
+  {"/zone/home/user/file.txt": [{"name": "key", "value": "value", "units": ""}]}
+
+For every listed path, the current metadata is compared to the desired metadata,
+and only the missing triplets are added. Existing triplets with an attribute name that
+occurs in the file, but with a different value or units, are removed. Triplets with other
+attribute names are left untouched. Importing the same file twice is a no-op.`
+
+func (a *App) metaimport() *cobra.Command {
+	var dryRun bool
+
+	cmd := &cobra.Command{
+		Use:               "import <local file>",
+		Short:             "Import metadata from a JSON file",
+		Long:              metaImportDescription,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var r io.Reader = cmd.InOrStdin()
+
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+
+				defer f.Close()
+
+				r = f
+			}
+
+			var desired map[string][]api.Metadata
+
+			if err := json.NewDecoder(r).Decode(&desired); err != nil {
+				return err
+			}
+
+			paths := slices.Sorted(maps.Keys(desired))
+
+			for _, path := range paths {
+				if err := a.importMetadata(cmd, a.Path(path), desired[path], dryRun); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, dryrunOption, false, "Only print the metadata triplets that would be added or removed, without performing any changes")
+
+	return cmd
+}
+
+func (a *App) importMetadata(cmd *cobra.Command, path string, desired []api.Metadata, dryRun bool) error {
+	stat, err := a.GetRecord(cmd.Context(), path, api.FetchMetadata)
+	if err != nil {
+		return err
+	}
+
+	add, remove := api.DiffMetadata(stat.Metadata(), desired)
+
+	if dryRun {
+		for _, m := range remove {
+			fmt.Fprintf(cmd.OutOrStdout(), "would remove %s from %s\n", formatTriplet(m), path)
+		}
+
+		for _, m := range add {
+			fmt.Fprintf(cmd.OutOrStdout(), "would add %s to %s\n", formatTriplet(m), path)
+		}
+
+		return nil
+	}
+
+	return a.Client.ModifyMetadata(cmd.Context(), path, stat.Type(), add, remove)
+}
+
+func formatTriplet(m api.Metadata) string {
+	if m.Units == "" {
+		return fmt.Sprintf("%q=%q", m.Name, m.Value)
+	}
+
+	return fmt.Sprintf("%q=%q [%s]", m.Name, m.Value, m.Units)
+}
+
 func (a *App) pwd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "pwd",
@@ -1433,8 +2486,7 @@ func (a *App) cd() *cobra.Command {
 
 			target := a.Path(args[0])
 
-			// Check if the target is a collection
-			if _, err := a.GetCollection(cmd.Context(), target); err != nil {
+			if err := a.checkCollection(cmd.Context(), target); err != nil {
 				return err
 			}
 
@@ -1449,6 +2501,25 @@ func (a *App) cd() *cobra.Command {
 	}
 }
 
+var (
+	ErrNotACollection   = errors.New("not a collection")
+	ErrNoSuchCollection = errors.New("no such collection")
+)
+
+// checkCollection checks whether the target is an existing collection,
+// and distinguishes between a missing path and a data object.
+func (a *App) checkCollection(ctx context.Context, target string) error {
+	if _, err := a.GetCollection(ctx, target); !api.Is(err, msg.CAT_NO_ROWS_FOUND) {
+		return err
+	}
+
+	if _, err := a.GetDataObject(ctx, target); err == nil {
+		return fmt.Errorf("%w: %s", ErrNotACollection, target)
+	}
+
+	return fmt.Errorf("%w: %s", ErrNoSuchCollection, target)
+}
+
 func (a *App) local() *cobra.Command { //nolint:funlen
 	local := &cobra.Command{
 		Use:   "local",
@@ -1484,25 +2555,44 @@ func (a *App) local() *cobra.Command { //nolint:funlen
 				return os.Chdir(args[0])
 			},
 		},
-		&cobra.Command{
-			Use:               "ls [local directory]",
-			Short:             "List the local working directory",
-			Args:              cobra.MaximumNArgs(1),
-			ValidArgsFunction: a.CompleteArgs,
-			RunE: func(cmd *cobra.Command, args []string) error {
-				if len(args) == 0 {
-					args = []string{"."}
-				}
+		a.localList(),
+	)
 
-				entries, err := os.ReadDir(args[0])
-				if err != nil {
-					return err
-				}
+	return local
+}
 
-				slices.SortFunc(entries, func(a, b os.DirEntry) int {
-					return strings.Compare(a.Name(), b.Name())
-				})
+func (a *App) localList() *cobra.Command {
+	var jsonFormat bool
+
+	cmd := &cobra.Command{
+		Use:               "ls [local directory]",
+		Short:             "List the local working directory",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+
+			entries, err := os.ReadDir(args[0])
+			if err != nil {
+				return err
+			}
+
+			slices.SortFunc(entries, func(a, b os.DirEntry) int {
+				return strings.Compare(a.Name(), b.Name())
+			})
+
+			result := make([]map[string]any, len(entries))
+
+			for i, entry := range entries {
+				result[i] = map[string]any{
+					"name": entry.Name(),
+					"dir":  entry.IsDir(),
+				}
+			}
 
+			return outputResult(cmd.OutOrStdout(), result, jsonFormat, func(w io.Writer) {
 				for _, entry := range entries {
 					name := entry.Name()
 					color := NoColor
@@ -1512,15 +2602,15 @@ func (a *App) local() *cobra.Command { //nolint:funlen
 						color = Blue
 					}
 
-					Fprintcolorln(cmd.OutOrStdout(), color, name)
+					Fprintcolorln(w, color, name)
 				}
-
-				return nil
-			},
+			})
 		},
-	)
+	}
 
-	return local
+	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+
+	return cmd
 }
 
 // The main purpose of this command is to test context cancellation
@@ -1547,16 +2637,25 @@ func (a *App) sleep() *cobra.Command {
 }
 
 func (a *App) query() *cobra.Command {
-	var jsonFormat bool
+	var (
+		jsonFormat, noPager bool
+		maxResults          int
+		sinkName            string
+	)
 
 	examples := "  Print available column names:\n\t" + a.name + " query\n  Run a query:\n\t" + a.name + " query \"select DATA_NAME, DATA_SIZE\""
 
 	cmd := &cobra.Command{
 		Use:     "query [sql]",
 		Short:   "Run a generic query",
+		Long:    "Run a generic query using the GenQuery2 syntax. If the server does not support GenQuery2, the query is translated to a regular GenQuery, which supports selecting columns and aggregates, conditions joined by AND and a LIMIT clause. If --sink is passed, the query is always translated to a regular GenQuery, so that the rows are keyed by the canonical names of the columns.",
 		Example: examples,
 		Args:    cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if sinkName == "" {
+				defer pageOutput(cmd, noPager)()
+			}
+
 			if len(args) == 0 {
 				columns, err := a.GenericQueryColumns(cmd.Context())
 				if err != nil {
@@ -1572,7 +2671,16 @@ func (a *App) query() *cobra.Command {
 				return nil
 			}
 
-			results := a.GenericQuery(args[0]).Execute(cmd.Context())
+			limiter := &resultLimiter{
+				Max:    maxResults,
+				Writer: cmd.ErrOrStderr(),
+			}
+
+			if sinkName != "" {
+				return a.writeQueryResults(cmd, sinkName, limiter.Query(args[0]), limiter)
+			}
+
+			results := a.Query2(cmd.Context(), limiter.Query(args[0]))
 
 			defer results.Close()
 
@@ -1581,19 +2689,25 @@ func (a *App) query() *cobra.Command {
 			}
 
 			if jsonFormat {
-				return json.NewEncoder(cmd.OutOrStdout()).Encode(results.Rows())
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(limiter.Rows(results.Rows()))
 			}
 
-			return printQueryResults(cmd, args, results)
+			return printQueryResults(cmd, args, results, limiter)
 		},
 	}
 
 	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON ([][]string)")
+	cmd.Flags().IntVar(&maxResults, maxResultsOption, 0, "Stop after the given number of results, 0 means unlimited")
+
+	a.addSinkFlag(cmd, &sinkName)
+	addPagerFlag(cmd, &noPager)
+
+	cmd.MarkFlagsMutuallyExclusive("json", sinkOption)
 
 	return cmd
 }
 
-func printQueryResults(cmd *cobra.Command, args []string, results *api.GenericResult) error {
+func printQueryResults(cmd *cobra.Command, args []string, results *api.GenericResult, limiter *resultLimiter) error {
 	columns := guessColumns(args[0])
 
 	out := &tabwriter.TabWriter{
@@ -1612,7 +2726,7 @@ func printQueryResults(cmd *cobra.Command, args []string, results *api.GenericRe
 
 	formatting := strings.Repeat("%s\t", len(columns)-1) + "%s\n"
 
-	for results.Next() {
+	for results.Next() && limiter.Allow() {
 		if err := results.Scan(ptrs...); err != nil {
 			return err
 		}
@@ -1623,6 +2737,38 @@ func printQueryResults(cmd *cobra.Command, args []string, results *api.GenericRe
 	return results.Err()
 }
 
+// writeQueryResults writes the results of a query to a sink, keyed by the canonical
+// names of the selected columns. The query is translated to a regular GenQuery,
+// as the results of GenQuery2 do not include the columns.
+func (a *App) writeQueryResults(cmd *cobra.Command, sinkName, query string, limiter *resultLimiter) error {
+	q, err := a.PrepareQuery2(query)
+	if err != nil {
+		return err
+	}
+
+	results := q.Execute(cmd.Context())
+
+	defer results.Close()
+
+	sink, err := a.openSink(sinkName, cmd.OutOrStdout(), results.ColumnNames())
+	if err != nil {
+		return err
+	}
+
+	for results.Next() && limiter.Allow() {
+		row, err := results.ScanMap()
+		if err != nil {
+			return multierr.Append(err, sink.Close())
+		}
+
+		if err := sink.Write(row); err != nil {
+			return multierr.Append(err, sink.Close())
+		}
+	}
+
+	return multierr.Append(results.Err(), sink.Close())
+}
+
 func guessColumns(query string) []string {
 	var columns []string
 
@@ -1687,7 +2833,9 @@ func resolveValues(ptrs []any) []any {
 }
 
 func (a *App) ps() *cobra.Command {
-	return &cobra.Command{
+	var jsonFormat bool
+
+	cmd := &cobra.Command{
 		Use:    "ps",
 		Short:  "List processes",
 		Args:   cobra.NoArgs,
@@ -1709,32 +2857,56 @@ func (a *App) ps() *cobra.Command {
 				"PROGRAM",
 			}
 
-			out := &tabwriter.TabWriter{
-				Writer: cmd.OutOrStdout(),
+			var rows [][]string
+
+			for result.Next() {
+				values := make([]string, len(columns))
+				ptrs := make([]any, len(values))
+
+				for i := range values {
+					ptrs[i] = &values[i]
+				}
+
+				if err := result.Scan(ptrs...); err != nil {
+					return err
+				}
+
+				rows = append(rows, values)
 			}
 
-			defer out.Flush()
+			if err := result.Err(); err != nil {
+				return err
+			}
 
-			Fprintcolorln(out, Bold, strings.Join(columns, "\t"))
+			procs := make([]map[string]string, len(rows))
 
-			values := make([]string, len(columns))
-			ptrs := make([]any, len(values))
+			for i, values := range rows {
+				procs[i] = map[string]string{}
 
-			for i := range values {
-				ptrs[i] = &values[i]
+				for j, column := range columns {
+					procs[i][strings.ReplaceAll(strings.ToLower(column), " ", "_")] = values[j]
+				}
 			}
 
-			for result.Next() {
-				if err := result.Scan(ptrs...); err != nil {
-					return err
+			return outputResult(cmd.OutOrStdout(), procs, jsonFormat, func(w io.Writer) {
+				out := &tabwriter.TabWriter{
+					Writer: w,
 				}
 
-				fmt.Fprintf(out, "%s\n", strings.Join(values, "\t"))
-			}
+				defer out.Flush()
+
+				Fprintcolorln(out, Bold, strings.Join(columns, "\t"))
 
-			return result.Err()
+				for _, values := range rows {
+					fmt.Fprintf(out, "%s\n", strings.Join(values, "\t"))
+				}
+			})
 		},
 	}
+
+	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+
+	return cmd
 }
 
 func Fprintcolorln(w io.Writer, color string, args ...any) {
@@ -3,9 +3,17 @@ package cli
 import (
 	"bytes"
 	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/kuleuven/iron/api"
 	"github.com/kuleuven/iron/msg"
 	"github.com/kuleuven/iron/transfer"
 )
@@ -34,6 +42,30 @@ func TestMkdir(t *testing.T) {
 	}
 }
 
+func TestMkdirVerbose(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{},
+		collectionResponse("/testzone"),
+		msg.EmptyResponse{},
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"mkdir", "-p", "--verbose", "/testzone/testdir"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "created collection /testzone/testdir\n" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
 func TestRmdir(t *testing.T) {
 	app := testApp(t)
 
@@ -160,6 +192,52 @@ func TestList(t *testing.T) {
 	}
 }
 
+func TestListOnlyFilesOrDirs(t *testing.T) {
+	for flag, expected := range map[string][]string{
+		"--only-files": {"file1", "file2", "file3"},
+		"--only-dirs":  {"a", "home"},
+	} {
+		app := testApp(t)
+
+		app.AddResponses(responses)
+
+		var buf bytes.Buffer
+
+		cmd := app.Command()
+		cmd.SetArgs([]string{"ls", "--json", flag, "/testzone"})
+		cmd.SetOut(&buf)
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		var names []string
+
+		for line := range strings.Lines(buf.String()) {
+			var m map[string]any
+
+			if err := json.Unmarshal([]byte(line), &m); err != nil {
+				t.Fatal(err)
+			}
+
+			names = append(names, m["name"].(string))
+		}
+
+		if !slices.Equal(names, expected) {
+			t.Errorf("%s: expected %v, got %v", flag, expected, names)
+		}
+	}
+
+	app := testApp(t)
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"find", "--only-files", "--only-dirs", "/testzone"})
+
+	if err := cmd.ExecuteContext(t.Context()); err == nil {
+		t.Fatal("expected error for mutually exclusive flags")
+	}
+}
+
 func TestListExtra(t *testing.T) {
 	app := testApp(t)
 
@@ -206,6 +284,112 @@ func TestListExtra(t *testing.T) {
 	}
 }
 
+func TestListCounts(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses(responses)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 2,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"100"}},
+				{AttributeIndex: 402, ResultLen: 1, Values: []string{"1"}},
+			},
+		},
+		// Each data object is listed once, regardless of the number of replicas
+		msg.QueryResponse{
+			RowCount:       17,
+			AttributeCount: 2,
+			TotalRowCount:  17,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: 17, Values: []string{"200", "201", "202", "203", "204", "205", "206", "207", "208", "209", "210", "211", "212", "213", "214", "215", "216"}},
+				{AttributeIndex: 402, ResultLen: 17, Values: slices.Repeat([]string{"2"}, 17)},
+			},
+		},
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"ls", "--counts", "/testzone"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "OBJECTS") || !strings.Contains(buf.String(), "17") {
+		t.Fatalf("expected object counts in output, got %s", buf.String())
+	}
+}
+
+func collectionResponse(path string) msg.QueryResponse {
+	return msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 6,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+			{AttributeIndex: 503, ResultLen: 1, Values: []string{path}},
+			{AttributeIndex: 504, ResultLen: 1, Values: []string{"zone"}},
+			{AttributeIndex: 508, ResultLen: 1, Values: []string{"10000"}},
+			{AttributeIndex: 509, ResultLen: 1, Values: []string{"2024"}},
+			{AttributeIndex: 506, ResultLen: 1, Values: []string{"1"}},
+		},
+	}
+}
+
+func TestListRecursive(t *testing.T) {
+	for _, jsonFormat := range []bool{false, true} {
+		app := testApp(t)
+
+		app.AddResponses(responses)
+
+		for _, coll := range []string{"/testzone/a", "/testzone/home"} {
+			app.AddResponses([]any{
+				collectionResponse(coll),
+				msg.QueryResponse{},
+				msg.QueryResponse{},
+			})
+		}
+
+		var buf bytes.Buffer
+
+		args := []string{"ls", "-R", "--columns", "name", "/testzone"}
+
+		if jsonFormat {
+			args = append(args, "--json")
+		}
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		output := buf.String()
+
+		if jsonFormat {
+			if !strings.Contains(output, `"name":"a"`) || strings.Contains(output, "/testzone:") {
+				t.Errorf("unexpected output: %s", output)
+			}
+
+			continue
+		}
+
+		for _, header := range []string{"/testzone:\n", "\n\n/testzone/a:\n", "\n/testzone/home:\n"} {
+			if !strings.Contains(output, header) {
+				t.Errorf("expected header %q in output: %s", header, output)
+			}
+		}
+	}
+}
+
 func TestListJSON(t *testing.T) {
 	app := testApp(t)
 
@@ -275,66 +459,881 @@ func TestStatJSON(t *testing.T) {
 	}
 }
 
-func TestMv(t *testing.T) {
-	app := testApp(t)
+// dataObjectStatResponses returns the responses for a stat of /testzone/file
+// without any of the optional flags.
+func dataObjectStatResponses() []any {
+	return []any{
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 14,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+				{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"1024"}},
+				{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+				{AttributeIndex: 412, ResultLen: 1, Values: []string{"testzone"}},
+				{AttributeIndex: 415, ResultLen: 1, Values: []string{""}},
+				{AttributeIndex: 413, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 409, ResultLen: 1, Values: []string{"demoResc"}},
+				{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path"}},
+				{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc"}},
+				{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 420, ResultLen: 1, Values: []string{"10000"}},
+			},
+		},
+		msg.QueryResponse{},
+		msg.QueryResponse{},
+		msg.QueryResponse{},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 1,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"1024"}},
+			},
+		},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 1,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 418, ResultLen: 1, Values: []string{"reviewed"}},
+			},
+		},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 1,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 416, ResultLen: 1, Values: []string{"01700000000"}},
+			},
+		},
+	}
+}
 
-	app.AddResponses(statResponses[:2])
-	app.AddResponse(msg.EmptyResponse{})
+func TestStatDataObject(t *testing.T) {
+	for _, jsonFormat := range []bool{false, true} {
+		app := testApp(t)
 
-	cmd := app.Command()
-	cmd.SetArgs([]string{"mv", "/testzone/coll", "/testzone/coll2"})
+		app.AddResponses(dataObjectStatResponses())
 
-	if err := cmd.ExecuteContext(t.Context()); err != nil {
-		t.Fatal(err)
+		var buf bytes.Buffer
+
+		args := []string{"stat", "/testzone/file"}
+
+		if jsonFormat {
+			args = append(args, "--json")
+		}
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"COMMENT", "reviewed", "EXPIRY", time.Unix(1700000000, 0).Format(time.DateTime)}
+
+		if jsonFormat {
+			expected = []string{`"comment":"reviewed"`, `"expiry":"` + time.Unix(1700000000, 0).Format(time.RFC3339) + `"`}
+		}
+
+		for _, e := range expected {
+			if !strings.Contains(buf.String(), e) {
+				t.Errorf("expected %q in output, got %q", e, buf.String())
+			}
+		}
 	}
 }
 
-func TestRm(t *testing.T) {
+func TestStatLock(t *testing.T) {
+	for _, jsonFormat := range []bool{false, true} {
+		app := testApp(t)
+
+		app.AddResponses(dataObjectStatResponses())
+		app.AddResponse(msg.FileDescriptor(1)) // Write lock
+
+		var buf bytes.Buffer
+
+		args := []string{"stat", "--lock", "/testzone/file"}
+
+		if jsonFormat {
+			args = append(args, "--json")
+		}
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"COMMENT", "reviewed", "LOCK", "write locked"}
+
+		if jsonFormat {
+			expected = []string{`"comment":"reviewed"`, `"lock":"write locked"`}
+		}
+
+		for _, e := range expected {
+			if !strings.Contains(buf.String(), e) {
+				t.Errorf("expected %q in output, got %q", e, buf.String())
+			}
+		}
+	}
+}
+
+func TestStatPhysical(t *testing.T) {
+	for _, jsonFormat := range []bool{false, true} {
+		app := testApp(t)
+
+		app.AddResponses(dataObjectStatResponses())
+		app.AddResponse(msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 11,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 301, ResultLen: 1, Values: []string{"10001"}},
+				{AttributeIndex: 317, ResultLen: 1, Values: []string{"0"}},
+				{AttributeIndex: 302, ResultLen: 1, Values: []string{"demoResc"}},
+				{AttributeIndex: 303, ResultLen: 1, Values: []string{"testzone"}},
+				{AttributeIndex: 304, ResultLen: 1, Values: []string{"unixfilesystem"}},
+				{AttributeIndex: 305, ResultLen: 1, Values: []string{"cache"}},
+				{AttributeIndex: 306, ResultLen: 1, Values: []string{"storage1.example.org"}},
+				{AttributeIndex: 307, ResultLen: 1, Values: []string{"/var/lib/irods/Vault"}},
+				{AttributeIndex: 316, ResultLen: 1, Values: []string{""}},
+				{AttributeIndex: 311, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 312, ResultLen: 1, Values: []string{"10000"}},
+			},
+		})
+
+		var buf bytes.Buffer
+
+		args := []string{"stat", "--physical", "/testzone/file"}
+
+		if jsonFormat {
+			args = append(args, "--json")
+		}
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"PHYSICAL 0", "storage1.example.org:/path"}
+
+		if jsonFormat {
+			expected = []string{`"host":"storage1.example.org","number":0,"path":"/path","resource":"demoResc"`}
+		}
+
+		for _, e := range expected {
+			if !strings.Contains(buf.String(), e) {
+				t.Errorf("expected %q in output, got %q", e, buf.String())
+			}
+		}
+	}
+}
+
+func TestStatDataObjectJSONSchema(t *testing.T) {
 	app := testApp(t)
 
-	app.AddResponses(statResponses[:2])
-	app.AddResponse(msg.CollectionOperationStat{})
+	app.AddResponses(dataObjectStatResponses())
+
+	var buf bytes.Buffer
 
 	cmd := app.Command()
-	cmd.SetArgs([]string{"rm", "/testzone/coll"})
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"stat", "--json", "/testzone/file"})
 
 	if err := cmd.ExecuteContext(t.Context()); err != nil {
 		t.Fatal(err)
 	}
-}
 
-func TestCopy(t *testing.T) {
-	app := testApp(t)
+	var result map[string]any
 
-	app.AddResponse(msg.QueryResponse{
+	if err := json.Unmarshal(buf.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+
+	if result["schema_version"] != float64(StatSchemaVersion) || result["type"] != "data_object" || result["name"] != "/testzone/file" {
+		t.Errorf("unexpected output %s", buf.String())
+	}
+
+	if result["comment"] != "reviewed" || result["expiry"] != time.Unix(1700000000, 0).Format(time.RFC3339) {
+		t.Errorf("unexpected output %s", buf.String())
+	}
+
+	// The optional fields are omitted if the flags are not passed
+	for _, key := range []string{"lock", "replicas", "divergent", "physical"} {
+		if _, ok := result[key]; ok {
+			t.Errorf("unexpected field %s in output %s", key, buf.String())
+		}
+	}
+}
+
+func singleValueResponse(column msg.ColumnNumber, value string) msg.QueryResponse {
+	return msg.QueryResponse{
 		RowCount:       1,
-		AttributeCount: 14,
+		AttributeCount: 1,
 		TotalRowCount:  1,
-		ContinueIndex:  0,
 		SQLResult: []msg.SQLResult{
-			{AttributeIndex: 401, ResultLen: 2, Values: []string{"1"}},
-			{AttributeIndex: 500, ResultLen: 2, Values: []string{"1"}},
-			{AttributeIndex: 406, ResultLen: 2, Values: []string{"generic"}},
-			{AttributeIndex: 404, ResultLen: 2, Values: []string{"0"}},
-			{AttributeIndex: 407, ResultLen: 2, Values: []string{"1024000"}},
-			{AttributeIndex: 411, ResultLen: 2, Values: []string{"rods"}},
-			{AttributeIndex: 412, ResultLen: 1, Values: []string{"testzone"}},
-			{AttributeIndex: 415, ResultLen: 2, Values: []string{"checksum"}},
-			{AttributeIndex: 413, ResultLen: 2, Values: []string{""}},
-			{AttributeIndex: 409, ResultLen: 2, Values: []string{"resc"}},
-			{AttributeIndex: 410, ResultLen: 2, Values: []string{"/path1"}},
-			{AttributeIndex: 422, ResultLen: 2, Values: []string{"demoResc;resc"}},
-			{AttributeIndex: 419, ResultLen: 2, Values: []string{"10000"}},
-			{AttributeIndex: 420, ResultLen: 2, Values: []string{"10000"}},
+			{AttributeIndex: column, ResultLen: 1, Values: []string{value}},
 		},
+	}
+}
+
+func TestStatReplicas(t *testing.T) {
+	replicasResponse := func(checksums ...string) msg.QueryResponse {
+		n := len(checksums)
+
+		repeat := func(value string) []string {
+			values := make([]string, n)
+
+			for i := range values {
+				values[i] = value
+			}
+
+			return values
+		}
+
+		numbers := make([]string, n)
+
+		for i := range numbers {
+			numbers[i] = strconv.Itoa(i)
+		}
+
+		return msg.QueryResponse{
+			RowCount:       n,
+			AttributeCount: 14,
+			TotalRowCount:  n,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: n, Values: repeat("1")},
+				{AttributeIndex: 500, ResultLen: n, Values: repeat("1")},
+				{AttributeIndex: 406, ResultLen: n, Values: repeat("generic")},
+				{AttributeIndex: 404, ResultLen: n, Values: numbers},
+				{AttributeIndex: 407, ResultLen: n, Values: repeat("1024")},
+				{AttributeIndex: 411, ResultLen: n, Values: repeat("rods")},
+				{AttributeIndex: 412, ResultLen: n, Values: repeat("testzone")},
+				{AttributeIndex: 415, ResultLen: n, Values: checksums},
+				{AttributeIndex: 413, ResultLen: n, Values: repeat("1")},
+				{AttributeIndex: 409, ResultLen: n, Values: repeat("demoResc")},
+				{AttributeIndex: 410, ResultLen: n, Values: repeat("/path")},
+				{AttributeIndex: 422, ResultLen: n, Values: repeat("demoResc")},
+				{AttributeIndex: 419, ResultLen: n, Values: repeat("10000")},
+				{AttributeIndex: 420, ResultLen: n, Values: repeat("10000")},
+			},
+		}
+	}
+
+	for _, jsonFormat := range []bool{false, true} {
+		app := testApp(t)
+
+		app.AddResponses([]any{
+			replicasResponse("sha2:qqqq", "sha2:qqqr"),
+			msg.QueryResponse{},
+			msg.QueryResponse{},
+			msg.QueryResponse{},
+			singleValueResponse(407, "1024"),
+			singleValueResponse(418, ""),
+			singleValueResponse(416, ""),
+			replicasResponse("sha2:qqqq", "sha2:qqqr"),
+		})
+
+		var buf bytes.Buffer
+
+		args := []string{"stat", "--replicas", "/testzone/file"}
+
+		if jsonFormat {
+			args = append(args, "--json")
+		}
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"REPLICA 0", "REPLICA 1", "aaaaaa", "aaaaab", "divergent checksums"}
+
+		if jsonFormat {
+			expected = []string{`"checksum":"aaaaaa"`, `"number":1`, `"divergent":true`}
+		}
+
+		for _, e := range expected {
+			if !strings.Contains(buf.String(), e) {
+				t.Errorf("expected %q in output, got %q", e, buf.String())
+			}
+		}
+	}
+}
+
+// expiredResponse lists an expired data object in /testzone/coll
+var expiredResponse = msg.QueryResponse{
+	RowCount:       1,
+	AttributeCount: 16,
+	TotalRowCount:  1,
+	SQLResult: []msg.SQLResult{
+		{AttributeIndex: 401, ResultLen: 1, Values: []string{"1"}},
+		{AttributeIndex: 501, ResultLen: 1, Values: []string{"/testzone/coll"}},
+		{AttributeIndex: 403, ResultLen: 1, Values: []string{"expired"}},
+		{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+		{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+		{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+		{AttributeIndex: 407, ResultLen: 1, Values: []string{"1024"}},
+		{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+		{AttributeIndex: 412, ResultLen: 1, Values: []string{"testzone"}},
+		{AttributeIndex: 415, ResultLen: 1, Values: []string{""}},
+		{AttributeIndex: 413, ResultLen: 1, Values: []string{"1"}},
+		{AttributeIndex: 409, ResultLen: 1, Values: []string{"demoResc"}},
+		{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path"}},
+		{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc"}},
+		{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+		{AttributeIndex: 420, ResultLen: 1, Values: []string{"10000"}},
+	},
+}
+
+func TestFindExpired(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		expiredResponse,
+		msg.QueryResponse{},
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"find", "--expired", "--json", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `"name":"/testzone/coll/expired"`) {
+		t.Errorf("expected expired data object in output, got %q", buf.String())
+	}
+}
+
+func TestStatResource(t *testing.T) {
+	for _, jsonFormat := range []bool{false, true} {
+		app := testApp(t)
+
+		app.AddResponses([]any{
+			msg.QueryResponse{
+				RowCount:       1,
+				AttributeCount: 11,
+				TotalRowCount:  1,
+				SQLResult: []msg.SQLResult{
+					{AttributeIndex: 301, ResultLen: 1, Values: []string{"10001"}},
+					{AttributeIndex: 317, ResultLen: 1, Values: []string{"0"}},
+					{AttributeIndex: 302, ResultLen: 1, Values: []string{"demoResc"}},
+					{AttributeIndex: 303, ResultLen: 1, Values: []string{"testzone"}},
+					{AttributeIndex: 304, ResultLen: 1, Values: []string{"unixfilesystem"}},
+					{AttributeIndex: 305, ResultLen: 1, Values: []string{"cache"}},
+					{AttributeIndex: 306, ResultLen: 1, Values: []string{"localhost"}},
+					{AttributeIndex: 307, ResultLen: 1, Values: []string{"/var/lib/irods/Vault"}},
+					{AttributeIndex: 316, ResultLen: 1, Values: []string{""}},
+					{AttributeIndex: 311, ResultLen: 1, Values: []string{"10000"}},
+					{AttributeIndex: 312, ResultLen: 1, Values: []string{"10000"}},
+				},
+			},
+			msg.QueryResponse{
+				RowCount:       1,
+				AttributeCount: 3,
+				TotalRowCount:  1,
+				SQLResult: []msg.SQLResult{
+					{AttributeIndex: 630, ResultLen: 1, Values: []string{"tier"}},
+					{AttributeIndex: 631, ResultLen: 1, Values: []string{"fast"}},
+					{AttributeIndex: 632, ResultLen: 1, Values: []string{""}},
+				},
+			},
+		})
+
+		var buf bytes.Buffer
+
+		args := []string{"stat", "--resource", "demoResc"}
+
+		if jsonFormat {
+			args = append(args, "--json")
+		}
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := []string{"/var/lib/irods/Vault", "tier", "fast"}
+
+		if jsonFormat {
+			expected = append(expected, `"vault_path":`)
+		}
+
+		for _, e := range expected {
+			if !strings.Contains(buf.String(), e) {
+				t.Errorf("expected %q in output: %s", e, buf.String())
+			}
+		}
+	}
+}
+
+func TestStatUser(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 6,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 201, ResultLen: 1, Values: []string{"10002"}},
+				{AttributeIndex: 202, ResultLen: 1, Values: []string{"alice"}},
+				{AttributeIndex: 204, ResultLen: 1, Values: []string{"testzone"}},
+				{AttributeIndex: 203, ResultLen: 1, Values: []string{"rodsuser"}},
+				{AttributeIndex: 208, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 209, ResultLen: 1, Values: []string{"10000"}},
+			},
+		},
+		msg.QueryResponse{},
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"stat", "--user", "alice"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "rodsuser") || strings.Contains(buf.String(), "METADATA") {
+		t.Errorf("unexpected output: %s", buf.String())
+	}
+}
+
+func TestDu(t *testing.T) {
+	app := testApp(t)
+
+	for range 2 {
+		app.AddResponse(msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 2,
+			TotalRowCount:  1,
+			ContinueIndex:  0,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"100"}},
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"1"}},
+			},
+		})
+	}
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"du", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCheckFreeSpace(t *testing.T) {
+	app := testApp(t)
+
+	// Returns the replicas of a single data object
+	objects := func(sizes ...string) msg.QueryResponse {
+		response := msg.QueryResponse{
+			RowCount:       len(sizes),
+			AttributeCount: 3,
+			TotalRowCount:  len(sizes),
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 501, ResultLen: len(sizes)},
+				{AttributeIndex: 403, ResultLen: len(sizes)},
+				{AttributeIndex: 407, ResultLen: len(sizes), Values: sizes},
+			},
+		}
+
+		for range sizes {
+			response.SQLResult[0].Values = append(response.SQLResult[0].Values, "/testzone/coll")
+			response.SQLResult[1].Values = append(response.SQLResult[1].Values, "file")
+		}
+
+		return response
+	}
+
+	// Two replicas of the same object are counted once
+	app.AddResponses([]any{
+		objects("100", "100"),
+		msg.QueryResponse{},
+		objects("4611686018427387904", "0"),
+		msg.QueryResponse{},
+	})
+
+	target := filepath.Join(t.TempDir(), "does", "not", "exist")
+
+	if err := app.checkFreeSpace(t.Context(), "/testzone/coll", target); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := app.checkFreeSpace(t.Context(), "/testzone/coll", target); !errors.Is(err, ErrInsufficientSpace) {
+		t.Fatalf("expected ErrInsufficientSpace, got %v", err)
+	}
+}
+
+func TestMissingSize(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{
+			RowCount:       3,
+			AttributeCount: 3,
+			TotalRowCount:  3,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 501, ResultLen: 3, Values: []string{"/testzone/coll", "/testzone/coll", "/testzone/coll"}},
+				{AttributeIndex: 403, ResultLen: 3, Values: []string{"file1", "file1", "file2"}},
+				{AttributeIndex: 407, ResultLen: 3, Values: []string{"100", "200", "300"}},
+			},
+		},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 3,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 501, ResultLen: 1, Values: []string{"/testzone/coll/sub"}},
+				{AttributeIndex: 403, ResultLen: 1, Values: []string{"file3"}},
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"400"}},
+			},
+		},
+	})
+
+	target := t.TempDir()
+
+	// file2 exists locally, and has 250 of its 300 bytes
+	if err := os.WriteFile(filepath.Join(target, "file2"), make([]byte, 250), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	size, err := app.missingSize(t.Context(), "/testzone/coll", target)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The largest replica of file1, the rest of file2, and file3
+	if size != 200+50+400 {
+		t.Fatalf("expected 650 bytes, got %d", size)
+	}
+}
+
+func TestDuJSON(t *testing.T) {
+	app := testApp(t)
+
+	for range 2 {
+		app.AddResponse(msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 2,
+			TotalRowCount:  1,
+			ContinueIndex:  0,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"100"}},
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"1"}},
+			},
+		})
+	}
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"du", "--json", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"objects":2,"path":"/testzone/coll","size":200}` + "\n"; buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestMv(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses(statResponses[:2])
+	app.AddResponse(msg.EmptyResponse{})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"mv", "/testzone/coll", "/testzone/coll2"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImportLocalLinks(t *testing.T) {
+	app := testApp(t)
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"import-local-links", filepath.Join(t.TempDir(), "missing"), "/testzone/coll/"})
+
+	if err := cmd.ExecuteContext(t.Context()); !os.IsNotExist(err) {
+		t.Fatalf("expected a not exist error, got %v", err)
+	}
+
+	importCmd, _, err := cmd.Find([]string{"import-local-links"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if flag := importCmd.Flags().Lookup("follow-symlinks"); flag.Value.String() != "true" || !flag.Hidden {
+		t.Fatal("expected symbolic links to be followed")
+	}
+}
+
+func TestRm(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses(statResponses[:2])
+	app.AddResponse(msg.CollectionOperationStat{})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"rm", "/testzone/coll"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "/testzone/coll/ (moved to trash)") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestRmDryRun(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses(statResponses[:2])
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"rm", "--dry-run", "-S", "/testzone/coll"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "would ") || !strings.Contains(buf.String(), "/testzone/coll/ (permanently deleted)") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestRmTrash(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{},
+		collectionResponse("/testzone/trash/home/user/coll"),
+		msg.CollectionOperationStat{},
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"rm", "/testzone/trash/home/user/coll"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Removing from the trash is permanent, even without --skip-trash
+	if !strings.Contains(buf.String(), "/testzone/trash/home/user/coll/ (permanently deleted)") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestRmProgressJSON(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses(statResponses[:2])
+	app.AddResponse(msg.CollectionOperationStat{})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"rm", "--progress-json", "/testzone/coll"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	dec := json.NewDecoder(&buf)
+
+	var events []transfer.ProgressEvent
+
+	for dec.More() {
+		var event transfer.ProgressEvent
+
+		if err := dec.Decode(&event); err != nil {
+			t.Fatal(err)
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) != 2 || events[0].Type != "progress" || events[0].Label != "/testzone/coll" || !events[0].Finished || events[0].Removal != "moved to trash" || events[1].Type != "done" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestRmProtected(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{}, // No data object
+		collectionResponse("/testzone/home"),
+	})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"rm", "-r", "/testzone/home"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrProtectedCollection) {
+		t.Fatalf("expected ErrProtectedCollection, got %v", err)
+	}
+}
+
+func TestCopy(t *testing.T) {
+	app := testApp(t)
+
+	object := msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 14,
+		TotalRowCount:  1,
+		ContinueIndex:  0,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 401, ResultLen: 2, Values: []string{"1"}},
+			{AttributeIndex: 500, ResultLen: 2, Values: []string{"1"}},
+			{AttributeIndex: 406, ResultLen: 2, Values: []string{"generic"}},
+			{AttributeIndex: 404, ResultLen: 2, Values: []string{"0"}},
+			{AttributeIndex: 407, ResultLen: 2, Values: []string{"1024000"}},
+			{AttributeIndex: 411, ResultLen: 2, Values: []string{"rods"}},
+			{AttributeIndex: 412, ResultLen: 1, Values: []string{"testzone"}},
+			{AttributeIndex: 415, ResultLen: 2, Values: []string{"checksum"}},
+			{AttributeIndex: 413, ResultLen: 2, Values: []string{""}},
+			{AttributeIndex: 409, ResultLen: 2, Values: []string{"resc"}},
+			{AttributeIndex: 410, ResultLen: 2, Values: []string{"/path1"}},
+			{AttributeIndex: 422, ResultLen: 2, Values: []string{"demoResc;resc"}},
+			{AttributeIndex: 419, ResultLen: 2, Values: []string{"10000"}},
+			{AttributeIndex: 420, ResultLen: 2, Values: []string{"10000"}},
+		},
+	}
+
+	app.AddResponse(object)
+	app.AddResponse(object)
+	app.AddResponse(msg.EmptyResponse{})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"cp", "/testzone/coll/file", "/testzone/coll2/"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "+ /testzone/coll/file") {
+		t.Fatalf("expected the copy to be reported, got %q", buf.String())
+	}
+}
+
+func TestCopyDryRun(t *testing.T) {
+	app := testApp(t)
+
+	object := msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 14,
+		TotalRowCount:  1,
+		ContinueIndex:  0,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 401, ResultLen: 2, Values: []string{"1"}},
+			{AttributeIndex: 500, ResultLen: 2, Values: []string{"1"}},
+			{AttributeIndex: 406, ResultLen: 2, Values: []string{"generic"}},
+			{AttributeIndex: 404, ResultLen: 2, Values: []string{"0"}},
+			{AttributeIndex: 407, ResultLen: 2, Values: []string{"1024000"}},
+			{AttributeIndex: 411, ResultLen: 2, Values: []string{"rods"}},
+			{AttributeIndex: 412, ResultLen: 1, Values: []string{"testzone"}},
+			{AttributeIndex: 415, ResultLen: 2, Values: []string{"checksum"}},
+			{AttributeIndex: 413, ResultLen: 2, Values: []string{""}},
+			{AttributeIndex: 409, ResultLen: 2, Values: []string{"resc"}},
+			{AttributeIndex: 410, ResultLen: 2, Values: []string{"/path1"}},
+			{AttributeIndex: 422, ResultLen: 2, Values: []string{"demoResc;resc"}},
+			{AttributeIndex: 419, ResultLen: 2, Values: []string{"10000"}},
+			{AttributeIndex: 420, ResultLen: 2, Values: []string{"10000"}},
+		},
+	}
+
+	// No response for the copy request, which would fail the test if it was sent
+	app.AddResponse(object)
+	app.AddResponse(object)
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"cp", "--dry-run", "/testzone/coll/file", "/testzone/coll2/"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCopyIntoSelf(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{}, // No data object
+		collectionResponse("/testzone/coll"),
 	})
-	app.AddResponse(msg.EmptyResponse{})
 
 	cmd := app.Command()
-	cmd.SetArgs([]string{"cp", "/testzone/coll/file", "/testzone/coll2/"})
+	cmd.SetArgs([]string{"cp", "/testzone/coll", "/testzone/coll/"})
 
-	if err := cmd.ExecuteContext(t.Context()); err != nil {
-		t.Fatal(err)
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, transfer.ErrRecursiveCopy) {
+		t.Fatalf("expected transfer.ErrRecursiveCopy, got %v", err)
+	}
+}
+
+func TestMoveIntoSelf(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{}, // No data object
+		collectionResponse("/testzone/coll"),
+	})
+
+	for _, args := range [][]string{
+		{"mv", "/testzone/coll", "/testzone/coll/sub/deeper"},
+		{"mv", "/testzone/coll/", "/testzone/coll/sub/"},
+	} {
+		cmd := app.Command()
+		cmd.SetArgs(args)
+
+		if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrRecursiveMove) {
+			t.Fatalf("%v: expected ErrRecursiveMove, got %v", args, err)
+		}
 	}
 }
 
@@ -377,6 +1376,63 @@ func TestChecksum(t *testing.T) {
 	}
 }
 
+func TestChecksumVerify(t *testing.T) {
+	for _, test := range []struct {
+		args      []string
+		responses []any
+		expected  string
+		err       bool
+	}{
+		{
+			args:      []string{"checksum", "--verify", "/testzone/obj1"},
+			responses: []any{msg.EmptyResponse{}, msg.String{String: "sha2:qqqq"}},
+			expected:  "aaaaaa (verified)",
+		},
+		{
+			args:      []string{"checksum", "--verify", "/testzone/obj1"},
+			responses: []any{&msg.IRODSError{Code: msg.USER_CHKSUM_MISMATCH}},
+			err:       true,
+		},
+		{
+			args:      []string{"checksum", "--verify", "/testzone/obj1"},
+			responses: []any{&msg.IRODSError{Code: msg.CAT_NO_CHECKSUM_FOR_REPLICA}},
+			err:       true,
+		},
+		{
+			args:      []string{"checksum", "--verify", "--compute", "/testzone/obj1"},
+			responses: []any{&msg.IRODSError{Code: msg.CAT_NO_CHECKSUM_FOR_REPLICA}, msg.String{String: "sha2:qqqq"}},
+			expected:  "aaaaaa (computed)",
+		},
+	} {
+		app := testApp(t)
+
+		app.AddResponses(test.responses)
+
+		var buf bytes.Buffer
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(test.args)
+
+		err := cmd.ExecuteContext(t.Context())
+		if test.err {
+			if !errors.Is(err, ErrVerificationFailed) {
+				t.Errorf("%v: expected verification error, got %v", test.args, err)
+			}
+
+			continue
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(buf.String(), test.expected) {
+			t.Errorf("%v: expected %q in output, got %q", test.args, test.expected, buf.String())
+		}
+	}
+}
+
 func TestPWD(t *testing.T) {
 	app := testApp(t)
 
@@ -426,6 +1482,26 @@ func TestMetaList(t *testing.T) {
 	}
 }
 
+func TestMetaListJSON(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses(statResponses[:len(statResponses)-2])
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"meta", "ls", "--json", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "[]\n" {
+		t.Fatalf("expected empty list, got %q", buf.String())
+	}
+}
+
 func TestMetaBasicOps(t *testing.T) {
 	for _, op := range []string{"add", "rm", "set"} {
 		app := testApp(t)
@@ -457,6 +1533,32 @@ func TestMetaUnset(t *testing.T) {
 	}
 }
 
+func TestMetaImport(t *testing.T) {
+	for _, dryRun := range []bool{false, true} {
+		app := testApp(t)
+
+		app.AddResponses(statResponses[:3])
+
+		if !dryRun {
+			app.AddResponse(msg.EmptyResponse{})
+		}
+
+		args := []string{"meta", "import", "-"}
+
+		if dryRun {
+			args = append(args, "--dry-run")
+		}
+
+		cmd := app.Command()
+		cmd.SetArgs(args)
+		cmd.SetIn(strings.NewReader(`{"/testzone/coll": [{"name": "a", "value": "b"}]}`))
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
 func TestCat(t *testing.T) {
 	app := testApp(t)
 
@@ -547,6 +1649,63 @@ func TestCD(t *testing.T) {
 	}
 }
 
+func TestCDNotACollection(t *testing.T) {
+	app := testApp(t)
+
+	app.workdirStore = func(_ context.Context, _ string) error {
+		return nil
+	}
+
+	app.AddResponse(msg.QueryResponse{})
+	app.AddResponse(msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 14,
+		TotalRowCount:  1,
+		ContinueIndex:  0,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 401, ResultLen: 2, Values: []string{"1"}},
+			{AttributeIndex: 500, ResultLen: 2, Values: []string{"1"}},
+			{AttributeIndex: 406, ResultLen: 2, Values: []string{"generic"}},
+			{AttributeIndex: 404, ResultLen: 2, Values: []string{"0"}},
+			{AttributeIndex: 407, ResultLen: 2, Values: []string{"1024000"}},
+			{AttributeIndex: 411, ResultLen: 2, Values: []string{"rods"}},
+			{AttributeIndex: 412, ResultLen: 1, Values: []string{"testzone"}},
+			{AttributeIndex: 415, ResultLen: 2, Values: []string{"checksum"}},
+			{AttributeIndex: 413, ResultLen: 2, Values: []string{""}},
+			{AttributeIndex: 409, ResultLen: 2, Values: []string{"resc"}},
+			{AttributeIndex: 410, ResultLen: 2, Values: []string{"/path1"}},
+			{AttributeIndex: 422, ResultLen: 2, Values: []string{"demoResc;resc"}},
+			{AttributeIndex: 419, ResultLen: 2, Values: []string{"10000"}},
+			{AttributeIndex: 420, ResultLen: 2, Values: []string{"10000"}},
+		},
+	})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"cd", "testfile"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrNotACollection) {
+		t.Fatalf("expected %v, got %v", ErrNotACollection, err)
+	}
+}
+
+func TestCDMissing(t *testing.T) {
+	app := testApp(t)
+
+	app.workdirStore = func(_ context.Context, _ string) error {
+		return nil
+	}
+
+	app.AddResponse(msg.QueryResponse{})
+	app.AddResponse(msg.QueryResponse{})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"cd", "missing"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrNoSuchCollection) {
+		t.Fatalf("expected %v, got %v", ErrNoSuchCollection, err)
+	}
+}
+
 func TestSleep(t *testing.T) {
 	app := testApp(t)
 
@@ -587,6 +1746,71 @@ func TestPS(t *testing.T) {
 	}
 }
 
+func TestPSJSON(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponse(msg.QueryResponse{
+		AttributeCount: 9,
+		RowCount:       1,
+		ContinueIndex:  -1,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 1000001, ResultLen: 1, Values: []string{"10"}},
+			{AttributeIndex: 1000002, ResultLen: 1, Values: []string{"1764600000"}},
+			{AttributeIndex: 1000003, ResultLen: 1, Values: []string{"user"}},
+			{AttributeIndex: 1000004, ResultLen: 1, Values: []string{"zone"}},
+			{AttributeIndex: 1000005, ResultLen: 1, Values: []string{"user"}},
+			{AttributeIndex: 1000006, ResultLen: 1, Values: []string{"zone"}},
+			{AttributeIndex: 1000007, ResultLen: 1, Values: []string{"1.2.3.4"}},
+			{AttributeIndex: 1000008, ResultLen: 1, Values: []string{"example.org"}},
+			{AttributeIndex: 1000009, ResultLen: 1, Values: []string{"iron"}},
+		},
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"ps", "--json"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `"process_id":"10"`) || !strings.Contains(buf.String(), `"program":"iron"`) {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestLocalListJSON(t *testing.T) {
+	app := testApp(t)
+
+	dir := t.TempDir()
+
+	if err := os.Mkdir(filepath.Join(dir, "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "file"), []byte("test"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+
+	// The local commands are only available in the shell
+	cmd := app.localList()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"--json", dir})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != `[{"dir":false,"name":"file"},{"dir":true,"name":"sub"}]`+"\n" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
 func TestQuery(t *testing.T) {
 	app := testApp(t)
 
@@ -784,3 +2008,177 @@ func TestGuessColumns(t *testing.T) {
 		})
 	}
 }
+
+func TestListTreeSize(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		responses[0],
+		msg.QueryResponse{},
+		msg.QueryResponse{},
+	})
+
+	for range 2 {
+		app.AddResponse(msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 2,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"1000"}},
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"1"}},
+			},
+		})
+	}
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"ls", "--tree-size", "/testzone"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "/testzone (2.0 kB)\n"; buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestProgressOutput(t *testing.T) {
+	app := testApp(t)
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+
+	var opts transfer.Options
+
+	if err := app.progressOutput(cmd, &opts, &stderr); err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.Output != &stderr || opts.JSONOutput != nil {
+		t.Fatal("expected progress bar output")
+	}
+
+	app.ProgressJSON = 1
+	opts = transfer.Options{}
+
+	if err := app.progressOutput(cmd, &opts, &stderr); err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.Output != nil || opts.JSONOutput != &stdout {
+		t.Fatal("expected JSON output to stdout")
+	}
+
+	app.ProgressJSON = 2
+	opts = transfer.Options{}
+
+	if err := app.progressOutput(cmd, &opts, &stderr); err != nil {
+		t.Fatal(err)
+	}
+
+	if opts.JSONOutput != &stderr {
+		t.Fatal("expected JSON output to stderr")
+	}
+}
+
+func TestProgressOutputInvalid(t *testing.T) {
+	app := testApp(t)
+
+	cmd := app.Command()
+
+	var opts transfer.Options
+
+	// Commands that write data to stdout refuse JSON events on stdout
+	app.ProgressJSON = 1
+
+	if err := app.dataProgressOutput(cmd, &opts, nil); !errors.Is(err, ErrProgressOnStdout) {
+		t.Fatalf("expected %v, got %v", ErrProgressOnStdout, err)
+	}
+
+	app.ProgressJSON = 2
+
+	if err := app.dataProgressOutput(cmd, &opts, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// A file descriptor that is not open is refused
+	app.ProgressJSON = 1000
+
+	if err := app.progressOutput(cmd, &opts, nil); err == nil {
+		t.Fatal("expected an error for a file descriptor that is not open")
+	}
+}
+
+func TestCopyIgnoreExisting(t *testing.T) {
+	app := testApp(t)
+
+	object := msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 14,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 401, ResultLen: 1, Values: []string{"1"}},
+			{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+			{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+			{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+			{AttributeIndex: 407, ResultLen: 1, Values: []string{"1024000"}},
+			{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+			{AttributeIndex: 412, ResultLen: 1, Values: []string{"testzone"}},
+			{AttributeIndex: 415, ResultLen: 1, Values: []string{"checksum"}},
+			{AttributeIndex: 413, ResultLen: 1, Values: []string{""}},
+			{AttributeIndex: 409, ResultLen: 1, Values: []string{"resc"}},
+			{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path1"}},
+			{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc;resc"}},
+			{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+			{AttributeIndex: 420, ResultLen: 1, Values: []string{"10000"}},
+		},
+	}
+
+	// The target exists, so no copy is requested
+	app.AddResponses([]any{object, object})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"cp", "--ignore-existing", "/testzone/coll/file", "/testzone/coll2/"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	// The target is as old as the source, so it is copied
+	app.AddResponses([]any{object, object, object, msg.EmptyResponse{}})
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"cp", "--update", "/testzone/coll/file", "/testzone/coll2/"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSkipExisting(t *testing.T) {
+	older := &api.DataObject{Replicas: []api.Replica{{ModifiedAt: time.Unix(1000, 0)}}}
+	newer := &api.DataObject{Replicas: []api.Replica{{ModifiedAt: time.Unix(2000, 0)}}}
+
+	for _, test := range []struct {
+		source, target         os.FileInfo
+		ignoreExisting, update bool
+		expected               bool
+	}{
+		{older, nil, true, true, false},
+		{older, newer, true, false, true},
+		{older, newer, false, true, true},
+		{newer, older, false, true, false},
+		{newer, newer, false, true, false},
+		{older, newer, false, false, false},
+	} {
+		if skip := skipExisting(test.source, test.target, test.ignoreExisting, test.update); skip != test.expected {
+			t.Errorf("expected %v for %+v, got %v", test.expected, test, skip)
+		}
+	}
+}
@@ -28,6 +28,11 @@ func (a *App) CompleteArgs(cmd *cobra.Command, args []string, toComplete string)
 	// Use zone of the client if we have one
 	if a.Client != nil {
 		zone = a.Zone
+	} else if a.profiles != nil {
+		// Completion from a regular shell skips the initialization, so select the profile here
+		if err := a.selectProfile(cmd); err != nil {
+			return nil, cobra.ShellCompDirectiveNoFileComp
+		}
 	}
 
 	// Try to find the zone of the previous arguments
@@ -100,11 +105,13 @@ func (a *App) completeArgument(ctx context.Context, zone, toComplete string, arg
 		return nil, cobra.ShellCompDirectiveNoFileComp
 	}
 
+	// Never prompt for credentials, as completion runs in the background of a shell
 	client, err := iron.New(ctx, env, iron.Option{
-		ClientName:        a.name,
-		Admin:             a.Admin,
-		UseNativeProtocol: a.Native,
-		DialFunc:          dialer,
+		ClientName:           a.name,
+		Admin:                a.Admin,
+		UseNativeProtocol:    a.Native,
+		DialFunc:             dialer,
+		AuthenticationPrompt: iron.Bot{},
 	})
 	if err != nil {
 		return nil, cobra.ShellCompDirectiveNoFileComp
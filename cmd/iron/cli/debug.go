@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kuleuven/iron"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrUnknownComponent = errors.New("unknown debug component")
+
+// setupLogging sets the log level according to --debug, and restricts the debug
+// and trace output to the components passed with --debug-components, which
+// imply --debug. See iron.LogComponentField for the list of components.
+func (a *App) setupLogging() error {
+	for _, component := range a.DebugComponents {
+		if !slices.Contains(iron.LogComponents, component) {
+			return fmt.Errorf("%w: %s, expected one of %s", ErrUnknownComponent, component, strings.Join(iron.LogComponents, ", "))
+		}
+	}
+
+	level := a.Debug
+
+	if len(a.DebugComponents) > 0 {
+		level = max(level, 1)
+	}
+
+	if level > 0 {
+		logrus.SetLevel(logrus.DebugLevel + logrus.Level(level-1))
+	}
+
+	formatter := logrus.StandardLogger().Formatter
+
+	if f, ok := formatter.(*componentFormatter); ok {
+		formatter = f.Formatter
+	}
+
+	logrus.SetFormatter(&componentFormatter{
+		Formatter:  formatter,
+		Components: a.DebugComponents,
+	})
+
+	return nil
+}
+
+// componentFormatter drops debug and trace entries of components that are not
+// listed in Components, unless Components is empty. The component is only
+// included in debug and trace entries, other entries are meant for the user.
+type componentFormatter struct {
+	logrus.Formatter
+	Components []string
+}
+
+func (f *componentFormatter) Format(entry *logrus.Entry) ([]byte, error) {
+	if entry.Level < logrus.DebugLevel {
+		delete(entry.Data, iron.LogComponentField)
+
+		return f.Formatter.Format(entry)
+	}
+
+	if component, _ := entry.Data[iron.LogComponentField].(string); len(f.Components) > 0 && !slices.Contains(f.Components, component) {
+		return nil, nil
+	}
+
+	return f.Formatter.Format(entry)
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron"
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetupLogging(t *testing.T) {
+	std := logrus.StandardLogger()
+	level, formatter, out := std.GetLevel(), std.Formatter, std.Out
+
+	t.Cleanup(func() {
+		std.SetLevel(level)
+		std.SetFormatter(formatter)
+		std.SetOutput(out)
+	})
+
+	app := testApp(t)
+
+	app.DebugComponents = []string{"tls"}
+
+	if err := app.setupLogging(); !errors.Is(err, ErrUnknownComponent) {
+		t.Fatalf("expected ErrUnknownComponent, got %v", err)
+	}
+
+	app.DebugComponents = []string{"transfer"}
+
+	if err := app.setupLogging(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Setting up twice must not wrap the formatter twice
+	if err := app.setupLogging(); err != nil {
+		t.Fatal(err)
+	}
+
+	if std.GetLevel() != logrus.DebugLevel {
+		t.Fatalf("expected debug level, got %s", std.GetLevel())
+	}
+
+	if _, ok := std.Formatter.(*componentFormatter).Formatter.(*componentFormatter); ok {
+		t.Fatal("formatter is wrapped twice")
+	}
+
+	var buf bytes.Buffer
+
+	std.SetOutput(&buf)
+	std.SetFormatter(&componentFormatter{
+		Formatter:  &logrus.TextFormatter{DisableTimestamp: true},
+		Components: []string{"transfer"},
+	})
+
+	logrus.WithField(iron.LogComponentField, "transfer").Debug("shown")
+	logrus.WithField(iron.LogComponentField, "msg").Debug("hidden")
+	logrus.WithField(iron.LogComponentField, "msg").Warn("warning")
+
+	output := buf.String()
+
+	if !strings.Contains(output, "shown") || strings.Contains(output, "hidden") || !strings.Contains(output, "warning") {
+		t.Fatalf("unexpected output: %q", output)
+	}
+
+	if strings.Count(output, "component=") != 1 {
+		t.Fatalf("expected the component only for debug output: %q", output)
+	}
+}
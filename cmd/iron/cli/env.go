@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// redacted replaces secrets in the printed environment
+const redacted = "********"
+
+func (a *App) env() *cobra.Command {
+	return &cobra.Command{
+		Use:   "env [zone]",
+		Short: "Print and validate the effective configuration",
+		Long:  "Print the configuration that would be used to connect, after loading the configuration file and applying the command line flags and the default values, with secrets redacted. The configuration is validated, and invalid settings or combinations of settings, such as PAM authentication without TLS, are reported. The server is not contacted.",
+		Args:  cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if a.profiles != nil {
+				if err := a.selectProfile(cmd); err != nil {
+					return err
+				}
+			}
+
+			var zone string
+
+			if len(args) > 0 {
+				zone = args[0]
+			}
+
+			env, _, err := a.loadEnv(cmd.Context(), zone)
+			if err != nil {
+				return err
+			}
+
+			env.GeneratedPasswordTimeout = a.PamTTL
+
+			env.ApplyDefaults()
+
+			if env.Password != "" {
+				env.Password = redacted
+			}
+
+			payload, err := json.MarshalIndent(env, "", "  ")
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), string(payload))
+
+			if err := env.Validate(); err != nil {
+				cmd.SilenceUsage = true
+
+				return fmt.Errorf("invalid configuration: %w", err)
+			}
+
+			Fprintcolorln(cmd.OutOrStdout(), Green, "✔ configuration is valid")
+
+			return nil
+		},
+	}
+}
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron"
+)
+
+func TestEnv(t *testing.T) {
+	env := iron.Env{
+		Host:     "localhost",
+		Zone:     "testzone",
+		Username: "testuser",
+		Password: "secret",
+	}
+
+	app := New(t.Context(), WithLoader(func(context.Context, string) (iron.Env, iron.DialFunc, error) {
+		return env, nil, nil
+	}))
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"env"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "secret") || !strings.Contains(buf.String(), redacted) {
+		t.Fatalf("expected password to be redacted, got %s", buf.String())
+	}
+
+	if !strings.Contains(buf.String(), `"irods_port": 1247`) || !strings.Contains(buf.String(), "configuration is valid") {
+		t.Fatalf("unexpected output: %s", buf.String())
+	}
+
+	env.AuthScheme = "pam_password"
+	env.ClientServerNegotiation = "off"
+
+	buf.Reset()
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"env"})
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, iron.ErrTLSRequired) {
+		t.Fatalf("expected ErrTLSRequired, got %v", err)
+	}
+}
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/kuleuven/iron/transfer"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+var ErrAborted = errors.New("aborted")
+
+// withEstimate performs a dry run of a directory transfer to count the files and bytes that
+// would be transferred, prints an estimate of the duration based on the rate of the previous
+// estimated transfer, and asks for confirmation before running the actual transfer. The prompt
+// is skipped if yes is set or if the session is not interactive.
+func (a *App) withEstimate(cmd *cobra.Command, opts transfer.Options, yes bool, run func(opts transfer.Options) error) error {
+	var (
+		files int
+		size  int64
+		mu    sync.Mutex
+	)
+
+	dryRun := opts
+	dryRun.DryRun = true
+	dryRun.Output = nil
+	dryRun.JSONOutput = nil
+	dryRun.DryRunHandler = func(task transfer.Task) {
+		if task.Action != transfer.TransferFile {
+			return
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+
+		files++
+		size += task.Size
+	}
+
+	if err := run(dryRun); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%d files to transfer, %s in total", files, humanize.Bytes(uint64(size)))
+
+	if rate := a.loadTransferRate(); rate > 0 {
+		duration := time.Duration(float64(size) / rate * float64(time.Second))
+
+		fmt.Fprintf(cmd.OutOrStdout(), ", estimated duration %s at %s/s", duration.Round(time.Second), humanize.Bytes(uint64(rate)))
+	}
+
+	fmt.Fprintln(cmd.OutOrStdout())
+
+	if files > 0 && !yes && !a.NonInteractive && term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprint(cmd.OutOrStdout(), "Continue? [y/N] ")
+
+		answer, err := bufio.NewReader(cmd.InOrStdin()).ReadString('\n')
+		if err != nil {
+			return err
+		}
+
+		if answer = strings.ToLower(strings.TrimSpace(answer)); answer != "y" && answer != "yes" {
+			return ErrAborted
+		}
+	}
+
+	started := time.Now()
+
+	if err := run(opts); err != nil {
+		return err
+	}
+
+	if elapsed := time.Since(started); size > 0 && elapsed > 0 {
+		a.saveTransferRate(float64(size) / elapsed.Seconds())
+	}
+
+	return nil
+}
+
+// transferRateFile returns the file that caches the rate of the last estimated transfer
+func (a *App) transferRateFile() (string, error) {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(cacheDir, a.name, "transfer-rate"), nil
+}
+
+// loadTransferRate returns the rate in bytes per second of the last estimated transfer,
+// or zero if unknown
+func (a *App) loadTransferRate() float64 {
+	rateFile, err := a.transferRateFile()
+	if err != nil {
+		return 0
+	}
+
+	payload, err := os.ReadFile(rateFile)
+	if err != nil {
+		return 0
+	}
+
+	rate, err := strconv.ParseFloat(strings.TrimSpace(string(payload)), 64)
+	if err != nil {
+		return 0
+	}
+
+	return rate
+}
+
+func (a *App) saveTransferRate(rate float64) {
+	rateFile, err := a.transferRateFile()
+	if err != nil {
+		logger.Debugf("failed to get user cache dir: %s", err)
+
+		return
+	}
+
+	if err := os.MkdirAll(filepath.Dir(rateFile), 0o755); err != nil {
+		logger.Debugf("failed to create dir %s: %s", filepath.Dir(rateFile), err)
+	} else if err := os.WriteFile(rateFile, []byte(strconv.FormatFloat(rate, 'f', 0, 64)), 0o600); err != nil {
+		logger.Debugf("failed to write %s: %s", rateFile, err)
+	}
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron/transfer"
+)
+
+func TestWithEstimate(t *testing.T) {
+	t.Setenv("XDG_CACHE_HOME", t.TempDir())
+	t.Setenv("HOME", t.TempDir())
+
+	app := testApp(t)
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+
+	var runs int
+
+	run := func(opts transfer.Options) error {
+		runs++
+
+		if !opts.DryRun {
+			time.Sleep(10 * time.Millisecond)
+
+			return nil
+		}
+
+		opts.DryRunHandler(transfer.Task{Action: transfer.CreateDirectory, IrodsPath: "/testzone/dir"})
+		opts.DryRunHandler(transfer.Task{Action: transfer.TransferFile, IrodsPath: "/testzone/dir/file1", Size: 1000})
+		opts.DryRunHandler(transfer.Task{Action: transfer.TransferFile, IrodsPath: "/testzone/dir/file2", Size: 2000})
+
+		return nil
+	}
+
+	if err := app.withEstimate(cmd, transfer.Options{}, true, run); err != nil {
+		t.Fatal(err)
+	}
+
+	if runs != 2 {
+		t.Fatalf("expected a dry run and a transfer, got %d runs", runs)
+	}
+
+	if buf.String() != "2 files to transfer, 3.0 kB in total\n" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	if app.loadTransferRate() <= 0 {
+		t.Fatal("expected transfer rate to be saved")
+	}
+
+	buf.Reset()
+
+	if err := app.withEstimate(cmd, transfer.Options{}, true, run); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), "estimated duration") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
@@ -0,0 +1,173 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+)
+
+var ErrInvalidPredicate = errors.New("invalid predicate")
+
+// findPredicates are the flags of find that select data objects in a collection tree
+type findPredicates struct {
+	Name       string
+	Meta       []string
+	NewerThan  string
+	LargerThan string
+}
+
+// IsSet returns whether any predicate is given
+func (p findPredicates) IsSet() bool {
+	return p.Name != "" || len(p.Meta) > 0 || p.NewerThan != "" || p.LargerThan != ""
+}
+
+// Filter parses the predicates into a findFilter, relative to the given time
+func (p findPredicates) Filter(now time.Time) (*findFilter, error) {
+	f := &findFilter{
+		Name:    p.Name,
+		MinSize: -1,
+	}
+
+	if p.Name != "" {
+		if _, err := filepath.Match(p.Name, ""); err != nil {
+			return nil, fmt.Errorf("%w: --name %q: %w", ErrInvalidPredicate, p.Name, err)
+		}
+	}
+
+	for _, m := range p.Meta {
+		key, value, ok := strings.Cut(m, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("%w: --meta %q, expected key=value", ErrInvalidPredicate, m)
+		}
+
+		f.Meta = append(f.Meta, api.Metadata{Name: key, Value: value})
+	}
+
+	if p.NewerThan != "" {
+		d, err := parseExpiry(p.NewerThan)
+		if err != nil {
+			return nil, fmt.Errorf("%w: --newer-than %q, expected a duration such as 12h or 7d", ErrInvalidPredicate, p.NewerThan)
+		}
+
+		f.Since = now.Add(-d)
+	}
+
+	if p.LargerThan != "" {
+		size, err := humanize.ParseBytes(p.LargerThan)
+		if err != nil {
+			return nil, fmt.Errorf("%w: --larger-than %q, expected a size such as 100MB", ErrInvalidPredicate, p.LargerThan)
+		}
+
+		f.MinSize = int64(size)
+	}
+
+	return f, nil
+}
+
+// findFilter selects data objects of which the name matches a glob, that have all
+// given metadata, that were modified after Since and that are larger than MinSize.
+type findFilter struct {
+	Name    string
+	Meta    []api.Metadata
+	Since   time.Time
+	MinSize int64
+}
+
+// Match returns whether the record at the given path matches the filter
+func (f *findFilter) Match(path string, record api.Record) bool {
+	if record.IsDir() {
+		return false
+	}
+
+	if f.Name != "" {
+		if ok, _ := filepath.Match(f.Name, Name(path)); !ok {
+			return false
+		}
+	}
+
+	if !f.Since.IsZero() && !record.ModTime().After(f.Since) {
+		return false
+	}
+
+	if f.MinSize >= 0 && record.Size() <= f.MinSize {
+		return false
+	}
+
+	for _, m := range f.Meta {
+		if !slices.ContainsFunc(record.Metadata(), func(avu api.Metadata) bool {
+			return avu.Name == m.Name && avu.Value == m.Value
+		}) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Conditions returns the catalog conditions that correspond to the filter. As all metadata
+// conditions of a query apply to the same AVU, the filter must have at most one metadata predicate.
+func (f *findFilter) Conditions() []api.Condition {
+	var conditions []api.Condition
+
+	if f.Name != "" {
+		conditions = append(conditions, api.LikeGlob(msg.ICAT_COLUMN_DATA_NAME, f.Name))
+	}
+
+	if !f.Since.IsZero() {
+		conditions = append(conditions, api.After(msg.ICAT_COLUMN_D_MODIFY_TIME, f.Since))
+	}
+
+	if f.MinSize >= 0 {
+		conditions = append(conditions, api.Condition{
+			Column: msg.ICAT_COLUMN_DATA_SIZE,
+			Op:     ">",
+			Value:  fmt.Sprintf("'%d'", f.MinSize),
+		})
+	}
+
+	for _, m := range f.Meta {
+		conditions = append(conditions,
+			api.Equal(msg.ICAT_COLUMN_META_DATA_ATTR_NAME, m.Name),
+			api.Equal(msg.ICAT_COLUMN_META_DATA_ATTR_VALUE, m.Value),
+		)
+	}
+
+	return conditions
+}
+
+// findMatching calls walkFn for each data object in the collection tree rooted at path
+// that matches the filter. If at most one metadata predicate is given, the search is done by
+// the catalog, otherwise the tree is walked and the records are filtered client side.
+func (a *App) findMatching(ctx context.Context, path string, f *findFilter, walkFn api.WalkFunc) error {
+	if len(f.Meta) <= 1 {
+		// The records of FindDataObjects have no metadata, the catalog has checked it
+		refine := *f
+		refine.Meta = nil
+
+		return a.FindDataObjects(ctx, path, filterWalkFunc(&refine, walkFn), f.Conditions()...)
+	}
+
+	return a.Walk(ctx, path, filterWalkFunc(f, walkFn), api.FetchMetadata)
+}
+
+func filterWalkFunc(f *findFilter, walkFn api.WalkFunc) api.WalkFunc {
+	return func(path string, record api.Record, err error) error {
+		if err != nil {
+			return walkFn(path, record, err)
+		}
+
+		if !f.Match(path, record) {
+			return nil
+		}
+
+		return walkFn(path, record, nil)
+	}
+}
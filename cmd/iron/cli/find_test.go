@@ -0,0 +1,124 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestFindPredicates(t *testing.T) {
+	for _, test := range []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"--meta", "project=x", "--name", "exp*", "--larger-than", "1KB"}, true},
+		{[]string{"--meta", "project=x", "--name", "other*"}, false},
+		{[]string{"--meta", "project=x", "--larger-than", "1MB"}, false},
+	} {
+		app := testApp(t)
+
+		app.AddResponses([]any{
+			expiredResponse,
+			msg.QueryResponse{},
+		})
+
+		var buf bytes.Buffer
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(append(append([]string{"find", "--json"}, test.args...), "/testzone/coll"))
+
+		if err := cmd.ExecuteContext(t.Context()); err != nil {
+			t.Fatal(err)
+		}
+
+		if found := strings.Contains(buf.String(), `"name":"/testzone/coll/expired"`); found != test.expected {
+			t.Errorf("%v: expected match %v, got %q", test.args, test.expected, buf.String())
+		}
+	}
+}
+
+func TestFindPredicatesInvalid(t *testing.T) {
+	for _, p := range []findPredicates{
+		{Meta: []string{"novalue"}},
+		{Meta: []string{"=value"}},
+		{NewerThan: "yesterday"},
+		{LargerThan: "big"},
+		{Name: "[a"},
+	} {
+		if _, err := p.Filter(time.Now()); !errors.Is(err, ErrInvalidPredicate) {
+			t.Errorf("%+v: expected ErrInvalidPredicate, got %v", p, err)
+		}
+	}
+}
+
+func TestFindFilterConditions(t *testing.T) {
+	p := findPredicates{
+		Name:       "*.txt",
+		Meta:       []string{"project=a=b"},
+		NewerThan:  "1d",
+		LargerThan: "1KiB",
+	}
+
+	f, err := p.Filter(time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if f.Meta[0].Name != "project" || f.Meta[0].Value != "a=b" || f.MinSize != 1024 {
+		t.Fatalf("unexpected filter: %+v", f)
+	}
+
+	conditions := f.Conditions()
+
+	if len(conditions) != 5 {
+		t.Fatalf("expected 5 conditions, got %v", conditions)
+	}
+
+	if conditions[0].Column != msg.ICAT_COLUMN_DATA_NAME || conditions[0].Value != "'%.txt'" {
+		t.Errorf("unexpected name condition: %+v", conditions[0])
+	}
+}
+
+func TestFindMatching(t *testing.T) {
+	app := testApp(t)
+
+	// The predicates are evaluated by the catalog, without walking the tree
+	app.AddResponses([]any{
+		expiredResponse,
+		msg.QueryResponse{},
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"find", "--name", "exp*", "--json", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(buf.String(), `"name":"/testzone/coll/expired"`) {
+		t.Errorf("expected matching data object in output, got %q", buf.String())
+	}
+}
+
+func TestFindExpiredWithPredicates(t *testing.T) {
+	for _, flag := range []string{"--name=exp*", "--meta=key=value", "--newer-than=1h", "--larger-than=1MB"} {
+		app := testApp(t)
+
+		cmd := app.Command()
+		cmd.SetOut(&bytes.Buffer{})
+		cmd.SetErr(&bytes.Buffer{})
+		cmd.SetArgs([]string{"find", "--expired", flag, "/testzone/coll"})
+
+		if err := cmd.ExecuteContext(t.Context()); err == nil || !strings.Contains(err.Error(), "expired") {
+			t.Errorf("%s: expected --expired to be rejected, got %v", flag, err)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sync"
+	"sync/atomic"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/spf13/cobra"
+	"golang.org/x/sync/errgroup"
+)
+
+const grepDescription = `Search the contents of a data object for lines that match a regular
+expression, or of all data objects in a collection and its subcollections if
+-r is passed. The data objects are streamed and matched client-side, without
+storing them locally. Matches are printed per data object, preceded by its
+path when searching a collection. Like grep, the command fails if no
+line matched.
+
+The syntax of the regular expression is described at
+https://golang.org/s/re2syntax.`
+
+var (
+	ErrGrepCollection = errors.New("is a collection, pass -r to search it")
+	ErrGrepFailed     = errors.New("failed to search data objects")
+	ErrNoMatch        = errors.New("no lines matched")
+)
+
+// maxGrepLineLength is the maximum length of a line that can be matched
+const maxGrepLineLength = 1024 * 1024
+
+func (a *App) grep() *cobra.Command {
+	var (
+		recursive, ignoreCase, namesOnly bool
+		jobs                             int
+	)
+
+	cmd := &cobra.Command{
+		Use:               "grep <pattern> <path>",
+		Short:             "Search the contents of data objects",
+		Long:              grepDescription,
+		Args:              cobra.ExactArgs(2),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			pattern := args[0]
+
+			if ignoreCase {
+				pattern = "(?i)" + pattern
+			}
+
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return err
+			}
+
+			path := a.Path(args[1])
+
+			record, err := a.GetRecord(cmd.Context(), path)
+			if err != nil {
+				return err
+			}
+
+			g := &grepper{
+				Regexp:    re,
+				NamesOnly: namesOnly,
+				Writer:    cmd.OutOrStdout(),
+			}
+
+			switch {
+			case !record.IsDir():
+				err = a.grepObject(cmd.Context(), g, path, false)
+			case !recursive:
+				return fmt.Errorf("%s: %w", path, ErrGrepCollection)
+			default:
+				err = a.grepCollection(cmd.Context(), cmd.ErrOrStderr(), g, path, jobs)
+			}
+
+			// Like grep, fail if nothing matched
+			if err == nil && !g.matched.Load() {
+				cmd.SilenceUsage = true
+
+				return ErrNoMatch
+			}
+
+			return err
+		},
+	}
+
+	cmd.Flags().BoolVarP(&recursive, "recursive", "r", false, "Search all data objects in a collection and its subcollections")
+	cmd.Flags().BoolVarP(&ignoreCase, "ignore-case", "i", false, "Ignore case distinctions")
+	cmd.Flags().BoolVarP(&namesOnly, "files-with-matches", "l", false, "Only print the paths of the data objects that match")
+	cmd.Flags().IntVarP(&jobs, "jobs", "j", 4, "Number of data objects to search at the same time")
+
+	return cmd
+}
+
+// grepper holds the options of a search, and serializes the output
+// of data objects that are searched at the same time.
+type grepper struct {
+	Regexp    *regexp.Regexp
+	NamesOnly bool
+	Writer    io.Writer
+	matched   atomic.Bool
+	sync.Mutex
+}
+
+// grepCollection searches all data objects in a collection and its subcollections,
+// searching at most jobs data objects at the same time. Data objects that can't be
+// searched are reported to errWriter, and an error wrapping ErrGrepFailed is returned
+// after the search has completed.
+func (a *App) grepCollection(ctx context.Context, errWriter io.Writer, g *grepper, dir string, jobs int) error {
+	wg, ctx := errgroup.WithContext(ctx)
+
+	wg.SetLimit(max(jobs, 1))
+
+	var failed atomic.Int64
+
+	err := a.Walk(ctx, dir, func(path string, record api.Record, err error) error {
+		if err != nil || record.IsDir() {
+			return err
+		}
+
+		wg.Go(func() error {
+			err := a.grepObject(ctx, g, path, true)
+			if err != nil && ctx.Err() == nil {
+				fmt.Fprintf(errWriter, "%s: %s\n", path, err)
+
+				failed.Add(1)
+			}
+
+			return ctx.Err()
+		})
+
+		return ctx.Err()
+	})
+
+	if err := wg.Wait(); err != nil {
+		return err
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if n := failed.Load(); n > 0 {
+		return fmt.Errorf("%w: %d data objects could not be searched", ErrGrepFailed, n)
+	}
+
+	return nil
+}
+
+// grepObject streams a data object and prints the lines that match. The output is locked
+// at the first match until the data object has been searched, so that the output of
+// different data objects is not mixed up, without keeping the matches in memory.
+func (a *App) grepObject(ctx context.Context, g *grepper, path string, withPath bool) error {
+	f, err := a.OpenDataObject(ctx, path, api.O_RDONLY)
+	if err != nil {
+		return err
+	}
+
+	defer f.Close()
+
+	var locked bool
+
+	defer func() {
+		if locked {
+			g.Unlock()
+		}
+	}()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, maxGrepLineLength)
+
+	for scanner.Scan() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		if !g.Regexp.Match(scanner.Bytes()) {
+			continue
+		}
+
+		if !locked {
+			g.Lock()
+
+			locked = true
+
+			g.matched.Store(true)
+		}
+
+		if g.NamesOnly {
+			_, err = fmt.Fprintln(g.Writer, path)
+
+			return err
+		}
+
+		if withPath {
+			if _, err = fmt.Fprintf(g.Writer, "%s:", path); err != nil {
+				return err
+			}
+		}
+
+		if _, err = g.Writer.Write(scanner.Bytes()); err != nil {
+			return err
+		}
+
+		if _, err = io.WriteString(g.Writer, "\n"); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestGrepObject(t *testing.T) {
+	app := testApp(t)
+
+	content := []byte("first line\nSecond match\nthird\nfourth MATCH\n")
+
+	for _, namesOnly := range []bool{false, true} {
+		app.AddResponse(msg.FileDescriptor(1))
+		app.AddBuffer(msg.DATA_OBJ_READ_AN, msg.OpenedDataObjectRequest{
+			FileDescriptor: 1,
+			Size:           4096,
+		}, msg.ReadResponse(len(content)), nil, content)
+		app.AddResponse(msg.EmptyResponse{})
+
+		var buf bytes.Buffer
+
+		g := &grepper{
+			Regexp:    regexp.MustCompile("(?i)match"),
+			NamesOnly: namesOnly,
+			Writer:    &buf,
+		}
+
+		if err := app.grepObject(t.Context(), g, "/testzone/obj1", true); err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "/testzone/obj1:Second match\n/testzone/obj1:fourth MATCH\n"
+
+		if namesOnly {
+			expected = "/testzone/obj1\n"
+		}
+
+		if buf.String() != expected {
+			t.Fatalf("expected %q, got %q", expected, buf.String())
+		}
+	}
+}
+
+func TestGrepCollection(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{}, // No data object
+		collectionResponse("/testzone/coll"),
+	})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"grep", "pattern", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrGrepCollection) {
+		t.Fatalf("expected ErrGrepCollection, got %v", err)
+	}
+}
+
+func TestGrepNoMatch(t *testing.T) {
+	app := testApp(t)
+
+	content := []byte("first line\nsecond line\n")
+
+	app.AddResponse(msg.FileDescriptor(1))
+	app.AddBuffer(msg.DATA_OBJ_READ_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+		Size:           4096,
+	}, msg.ReadResponse(len(content)), nil, content)
+	app.AddResponse(msg.EmptyResponse{})
+
+	var buf bytes.Buffer
+
+	g := &grepper{
+		Regexp: regexp.MustCompile("match"),
+		Writer: &buf,
+	}
+
+	if err := app.grepObject(t.Context(), g, "/testzone/obj1", true); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() > 0 || g.matched.Load() {
+		t.Fatalf("expected no matches, got %q", buf.String())
+	}
+
+	// A recursive search of an empty collection matches nothing
+	app.AddResponses([]any{
+		msg.QueryResponse{}, // No data object
+		collectionResponse("/testzone/coll"),
+		collectionResponse("/testzone/coll"),
+		msg.QueryResponse{}, // No subcollections
+		msg.QueryResponse{}, // No data objects
+	})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"grep", "-r", "pattern", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrNoMatch) {
+		t.Fatalf("expected ErrNoMatch, got %v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+)
+
+// softMaxResults is the number of results after which a warning is printed
+// if no maximum number of results was requested.
+const softMaxResults = 100000
+
+const maxResultsOption = "max-results"
+
+// resultLimiter counts the results that are output, and stops after Max results.
+// Warnings are written to Writer, so that they don't end up in the regular output.
+type resultLimiter struct {
+	Max    int
+	Writer io.Writer
+	count  int
+}
+
+// Allow registers a result and reports whether it may be output.
+func (l *resultLimiter) Allow() bool {
+	l.count++
+
+	switch {
+	case l.Max > 0 && l.count > l.Max:
+		if l.count == l.Max+1 {
+			Fprintcolorln(l.Writer, Yellow, fmt.Sprintf("Warning: results truncated after %d results", l.Max))
+		}
+
+		return false
+	case l.Max <= 0 && l.count == softMaxResults+1:
+		Fprintcolorln(l.Writer, Yellow, fmt.Sprintf("Warning: more than %d results, use --%s to limit the output", softMaxResults, maxResultsOption))
+	}
+
+	return true
+}
+
+// Rows returns the rows that may be output.
+func (l *resultLimiter) Rows(rows [][]string) [][]string {
+	for i := range rows {
+		if !l.Allow() {
+			return rows[:i]
+		}
+	}
+
+	return rows
+}
+
+var limitClause = regexp.MustCompile(`(?i)\sLIMIT\s+\d+\s*$`)
+
+// Query adds a LIMIT clause to the query if it has none, so that the server stops
+// after one result more than the maximum, which is enough to detect truncation.
+func (l *resultLimiter) Query(query string) string {
+	if l.Max <= 0 || limitClause.MatchString(query) {
+		return query
+	}
+
+	return query + " LIMIT " + strconv.Itoa(l.Max+1)
+}
@@ -0,0 +1,94 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestResultLimiter(t *testing.T) {
+	var buf bytes.Buffer
+
+	limiter := &resultLimiter{
+		Max:    2,
+		Writer: &buf,
+	}
+
+	rows := limiter.Rows([][]string{{"a"}, {"b"}, {"c"}})
+	if len(rows) != 2 {
+		t.Fatalf("expected 2 rows, got %d", len(rows))
+	}
+
+	if limiter.Allow() {
+		t.Fatal("expected no more results to be allowed")
+	}
+
+	if strings.Count(buf.String(), "truncated") != 1 {
+		t.Errorf("expected a single warning, got %q", buf.String())
+	}
+}
+
+func TestResultLimiterSoftMax(t *testing.T) {
+	var buf bytes.Buffer
+
+	limiter := &resultLimiter{
+		Writer: &buf,
+	}
+
+	for range softMaxResults + 10 {
+		if !limiter.Allow() {
+			t.Fatal("expected all results to be allowed")
+		}
+	}
+
+	if strings.Count(buf.String(), "--max-results") != 1 {
+		t.Errorf("expected a single warning, got %q", buf.String())
+	}
+}
+
+func TestResultLimiterQuery(t *testing.T) {
+	limiter := &resultLimiter{Max: 10}
+
+	for query, expected := range map[string]string{
+		"SELECT DATA_NAME":                   "SELECT DATA_NAME LIMIT 11",
+		"SELECT DATA_NAME limit 5":           "SELECT DATA_NAME limit 5",
+		"SELECT DATA_NAME WHERE X = 'limit'": "SELECT DATA_NAME WHERE X = 'limit' LIMIT 11",
+	} {
+		if actual := limiter.Query(query); actual != expected {
+			t.Errorf("expected %q, got %q", expected, actual)
+		}
+	}
+
+	if query := (&resultLimiter{}).Query("SELECT DATA_NAME"); query != "SELECT DATA_NAME" {
+		t.Errorf("expected unmodified query, got %q", query)
+	}
+}
+
+func TestQueryMaxResults(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponse(msg.String{
+		String: `[["a"], ["b"], ["c"]]`,
+	})
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&stdout)
+	cmd.SetErr(&stderr)
+	cmd.SetArgs([]string{"query", "--max-results", "2", "--json", "SELECT DATA_NAME"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.TrimSpace(stdout.String()) != `[["a"],["b"]]` {
+		t.Errorf("unexpected output: %q", stdout.String())
+	}
+
+	if !strings.Contains(stderr.String(), "truncated after 2 results") {
+		t.Errorf("expected warning, got %q", stderr.String())
+	}
+}
@@ -0,0 +1,253 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/spf13/cobra"
+)
+
+// A manifest lists the data objects in a collection tree, one per line, as tab-separated
+// sha256 checksum in hex, size in bytes, modification time in RFC3339 format and the path
+// relative to the collection. Data objects without a checksum are listed with noChecksum.
+type manifestEntry struct {
+	Checksum string
+	Size     int64
+	ModTime  time.Time
+	Path     string
+}
+
+const noChecksum = "-"
+
+func (e manifestEntry) String() string {
+	return fmt.Sprintf("%s\t%d\t%s\t%s", e.Checksum, e.Size, e.ModTime.UTC().Format(time.RFC3339), e.Path)
+}
+
+var (
+	ErrInvalidManifest    = errors.New("invalid manifest")
+	ErrVerificationFailed = errors.New("verification failed")
+)
+
+// parseManifest reads a manifest. Empty lines and lines starting with # are ignored.
+func parseManifest(r io.Reader) ([]manifestEntry, error) {
+	var entries []manifestEntry
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		fields := strings.SplitN(text, "\t", 4)
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("%w: line %d: expected 4 tab-separated fields", ErrInvalidManifest, line)
+		}
+
+		size, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidManifest, line, err)
+		}
+
+		modTime, err := time.Parse(time.RFC3339, fields[2])
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidManifest, line, err)
+		}
+
+		entries = append(entries, manifestEntry{
+			Checksum: fields[0],
+			Size:     size,
+			ModTime:  modTime,
+			Path:     fields[3],
+		})
+	}
+
+	return entries, scanner.Err()
+}
+
+// walkManifest calls fn with a manifest entry for each data object in the collection tree,
+// in lexographical order. If compute is set, missing checksums are computed.
+func (a *App) walkManifest(ctx context.Context, root string, compute bool, fn func(manifestEntry) error) error {
+	return a.Walk(ctx, root, func(path string, record api.Record, err error) error {
+		if err != nil {
+			return err
+		}
+
+		obj, ok := record.Sys().(*api.DataObject)
+		if !ok {
+			return nil
+		}
+
+		entry := manifestEntry{
+			Checksum: noChecksum,
+			Size:     record.Size(),
+			ModTime:  record.ModTime(),
+			Path:     strings.TrimPrefix(strings.TrimPrefix(path, root), "/"),
+		}
+
+		for _, replica := range obj.Replicas {
+			if checksum := parseIrodsChecksum(replica.Checksum); checksum != "" {
+				entry.Checksum = checksum
+
+				break
+			}
+		}
+
+		if entry.Checksum == noChecksum && compute {
+			checksum, err := a.Checksum(ctx, path, false)
+			if err != nil {
+				return err
+			}
+
+			entry.Checksum = hex.EncodeToString(checksum)
+		}
+
+		return fn(entry)
+	}, api.LexographicalOrder, api.NoSkip)
+}
+
+func (a *App) manifest() *cobra.Command {
+	var compute bool
+
+	cmd := &cobra.Command{
+		Use:               "manifest <collection path>",
+		Short:             "Generate a checksummed list of all data objects in a collection",
+		Long:              "Generate a manifest of all data objects in a collection and its subcollections. Each line contains the sha256 checksum in hex, the size in bytes, the modification time and the relative path, separated by tabs. Data objects without a checksum are listed with " + noChecksum + ", unless --compute is passed. The manifest can be checked later using `verify --manifest`.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return a.walkManifest(cmd.Context(), a.Path(args[0]), compute, func(entry manifestEntry) error {
+				_, err := fmt.Fprintln(cmd.OutOrStdout(), entry)
+
+				return err
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&compute, "compute", false, "Compute missing checksums")
+
+	return cmd
+}
+
+func (a *App) verify() *cobra.Command {
+	var (
+		manifest string
+		compute  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:               "verify [collection path]",
+		Short:             "Verify a collection against a manifest",
+		Long:              "Verify the data objects in a collection and its subcollections against a manifest generated by `manifest`, to detect drift in archived datasets. Data objects that were added or removed since the manifest was generated are reported, as well as data objects of which the size, modification time or checksum changed. The command fails if any discrepancy is found. If no collection is given, the current working directory is verified.",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+
+			var r io.Reader = cmd.InOrStdin()
+
+			if manifest != "-" {
+				f, err := os.Open(manifest)
+				if err != nil {
+					return err
+				}
+
+				defer f.Close()
+
+				r = f
+			}
+
+			entries, err := parseManifest(r)
+			if err != nil {
+				return err
+			}
+
+			// Sort the manifest in the same order as the walk, so that both can be merged
+			slices.SortFunc(entries, func(a, b manifestEntry) int {
+				return api.ComparePaths(a.Path, b.Path)
+			})
+
+			var discrepancies int
+
+			report := func(color, prefix, path, reason string) {
+				discrepancies++
+
+				Fprintcolorln(cmd.OutOrStdout(), color, prefix+" "+path+" ("+reason+")")
+			}
+
+			removed := func(until string) {
+				for len(entries) > 0 && (until == "" || api.ComparePaths(entries[0].Path, until) < 0) {
+					report(Red, "-", entries[0].Path, "removed")
+
+					entries = entries[1:]
+				}
+			}
+
+			var verified int
+
+			err = a.walkManifest(cmd.Context(), a.Path(args[0]), compute, func(actual manifestEntry) error {
+				removed(actual.Path)
+
+				if len(entries) == 0 || entries[0].Path != actual.Path {
+					report(Green, "+", actual.Path, "added")
+
+					return nil
+				}
+
+				expected := entries[0]
+				entries = entries[1:]
+
+				switch {
+				case actual.Size != expected.Size:
+					report(Yellow, "~", actual.Path, fmt.Sprintf("size changed from %d to %d", expected.Size, actual.Size))
+				case !actual.ModTime.Equal(expected.ModTime):
+					report(Yellow, "~", actual.Path, "modification time changed from "+expected.ModTime.UTC().Format(time.RFC3339)+" to "+actual.ModTime.UTC().Format(time.RFC3339))
+				case expected.Checksum == noChecksum:
+					verified++
+				case actual.Checksum == noChecksum:
+					report(Yellow, "?", actual.Path, "no checksum stored, use --compute to compute it")
+				case actual.Checksum != expected.Checksum:
+					report(Red, "!", actual.Path, "checksum mismatch")
+				default:
+					verified++
+				}
+
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+
+			removed("")
+
+			if discrepancies > 0 {
+				return fmt.Errorf("%w: %d discrepancies found", ErrVerificationFailed, discrepancies)
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "%d data objects verified\n", verified)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&manifest, "manifest", "", "Manifest file generated by `manifest`, or - to read from stdin")
+	cmd.Flags().BoolVar(&compute, "compute", false, "Compute missing checksums")
+
+	cmd.MarkFlagRequired("manifest") //nolint:errcheck
+
+	return cmd
+}
@@ -0,0 +1,116 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+const testManifest = "-\t1024000\t1970-01-01T02:46:40Z\tfile1\n-\t100\t1970-01-01T02:46:40Z\tfile2\n-\t1024000\t1970-01-01T02:46:40Z\tfile3\n"
+
+func TestManifest(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses(responses)
+	app.AddResponse(msg.QueryResponse{})
+	app.AddResponse(msg.QueryResponse{})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"manifest", "/testzone"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != testManifest {
+		t.Fatalf("unexpected manifest: %q", buf.String())
+	}
+}
+
+func TestParseManifest(t *testing.T) {
+	entries, err := parseManifest(strings.NewReader("# comment\n\nabcd\t5\t2024-01-02T03:04:05Z\tdir/file with spaces\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := manifestEntry{
+		Checksum: "abcd",
+		Size:     5,
+		ModTime:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Path:     "dir/file with spaces",
+	}
+
+	if len(entries) != 1 || entries[0] != expected {
+		t.Fatalf("unexpected entries: %v", entries)
+	}
+
+	if entries[0].String() != "abcd\t5\t2024-01-02T03:04:05Z\tdir/file with spaces" {
+		t.Fatalf("unexpected string: %q", entries[0].String())
+	}
+
+	for _, invalid := range []string{"abcd\t5\tfile", "abcd\tfive\t2024-01-02T03:04:05Z\tfile", "abcd\t5\tyesterday\tfile"} {
+		if _, err := parseManifest(strings.NewReader(invalid)); !errors.Is(err, ErrInvalidManifest) {
+			t.Errorf("%q: expected ErrInvalidManifest, got %v", invalid, err)
+		}
+	}
+}
+
+func TestVerifyManifest(t *testing.T) {
+	for _, tc := range []struct {
+		manifest      string
+		discrepancies []string
+	}{
+		{testManifest, nil},
+		{
+			"-\t1\t1970-01-01T00:00:00Z\tfile0\n" + strings.Replace(testManifest, "\t100\t", "\t99\t", 1) + "-\t1\t1970-01-01T00:00:00Z\tfile4\n",
+			[]string{"- file0 (removed)", "~ file2 (size changed from 99 to 100)", "- file4 (removed)"},
+		},
+		{
+			strings.Replace(testManifest, "\tfile3\n", "\tfile0\n", 1),
+			[]string{"- file0 (removed)", "+ file3 (added)"},
+		},
+	} {
+		app := testApp(t)
+
+		app.AddResponses(responses)
+		app.AddResponse(msg.QueryResponse{})
+		app.AddResponse(msg.QueryResponse{})
+
+		file := filepath.Join(t.TempDir(), "manifest.tsv")
+
+		if err := os.WriteFile(file, []byte(tc.manifest), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		var buf bytes.Buffer
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs([]string{"verify", "--manifest", file, "/testzone"})
+
+		err := cmd.ExecuteContext(t.Context())
+
+		if tc.discrepancies == nil && err != nil {
+			t.Fatal(err)
+		}
+
+		if tc.discrepancies != nil && !errors.Is(err, ErrVerificationFailed) {
+			t.Fatalf("expected ErrVerificationFailed, got %v", err)
+		}
+
+		for _, discrepancy := range tc.discrepancies {
+			if !strings.Contains(buf.String(), discrepancy) {
+				t.Errorf("expected %q to be reported, got %q", discrepancy, buf.String())
+			}
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/spf13/cobra"
+)
+
+func (a *App) open() *cobra.Command {
+	var ticket, browser bool
+
+	cmd := &cobra.Command{
+		Use:               "open <path>",
+		Short:             "Print the web URL of a data object or collection",
+		Long:              "Print the URL of a data object or collection on a web gateway, such as a WebDAV, S3 or HTTP gateway. The URL is constructed from the iron_url_template setting in the irods environment file, in which {path} is replaced by the path, e.g. https://data.example.org{path}. If requested, a read ticket is created and added to the URL, so that it can be shared.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := a.Path(args[0])
+
+			u, err := a.GatewayURL(path)
+			if err != nil {
+				return err
+			}
+
+			if ticket {
+				t, err := a.CreateTicket(cmd.Context(), path, api.ReadTicket)
+				if err != nil {
+					return err
+				}
+
+				q := u.Query()
+				q.Set("ticket", t)
+				u.RawQuery = q.Encode()
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), u.String())
+
+			if browser {
+				return openBrowser(u.String())
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&ticket, "ticket", false, "Create a read ticket and add it to the URL")
+	cmd.Flags().BoolVar(&browser, "browser", false, "Open the URL in the default browser")
+
+	return cmd
+}
+
+func openBrowser(u string) error {
+	var cmd *exec.Cmd
+
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", u)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", u)
+	default:
+		cmd = exec.Command("xdg-open", u)
+	}
+
+	return cmd.Start()
+}
@@ -0,0 +1,70 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron"
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestOpen(t *testing.T) {
+	app := testApp(t)
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"open", "/testzone/home/a file"})
+	cmd.SetOut(&buf)
+
+	err := cmd.ExecuteContext(t.Context())
+	if !errors.Is(err, api.ErrNoURLTemplate) {
+		t.Fatalf("expected api.ErrNoURLTemplate, got %v", err)
+	}
+
+	env := app.Client.Env()
+	env.URLTemplate = "https://data.example.org/webdav{path}"
+
+	app.Client, err = iron.New(t.Context(), env, iron.Option{
+		HandshakeFunc: func(ctx context.Context) (iron.Conn, error) {
+			return app.mockConn, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf.Reset()
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"open", "/testzone/home/a file"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "https://data.example.org/webdav/testzone/home/a%20file\n" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	app.AddResponse(msg.EmptyResponse{})
+
+	buf.Reset()
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"open", "--ticket", "/testzone/home/a file"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "https://data.example.org/webdav/testzone/home/a%20file?ticket=") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
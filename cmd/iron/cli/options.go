@@ -63,8 +63,28 @@ var ForceReauthentication ContextKey = "force_reauthentication"
 // FileLoader loads an irods environment from a file and returns a Loader.
 // The environment is loaded from the file, and the password is read from the
 // .irodsA file in the same directory, or the file specified by the
-// IRODS_AUTHENTICATION_FILE environment variable if set.
+// IRODS_AUTHENTICATION_FILE environment variable or the irods_authentication_file
+// setting, if set.
 func FileLoader(file string) Loader {
+	return fileLoader(file, filepath.Join(filepath.Dir(file), ".irodsA"))
+}
+
+// authenticationFile returns the location of the cached password file. As for the
+// icommands, the IRODS_AUTHENTICATION_FILE environment variable takes precedence
+// over the irods_authentication_file setting of the environment.
+func authenticationFile(env iron.Env, defaultAuthFile string) string {
+	if f, ok := os.LookupEnv("IRODS_AUTHENTICATION_FILE"); ok && f != "" {
+		return f
+	}
+
+	if env.AuthenticationFile != "" {
+		return env.AuthenticationFile
+	}
+
+	return defaultAuthFile
+}
+
+func fileLoader(file, defaultAuthFile string) Loader {
 	return func(ctx context.Context, _ string) (iron.Env, iron.DialFunc, error) {
 		var env iron.Env
 
@@ -72,17 +92,13 @@ func FileLoader(file string) Loader {
 			return env, nil, err
 		}
 
+		authFile := authenticationFile(env, defaultAuthFile)
+
 		if forceReauthentication, ok := ctx.Value(ForceReauthentication).(bool); ok && forceReauthentication {
 			// Force reauthentication
 			env.Password = ""
 		} else if env.AuthScheme != "native" || env.Password == "" {
 			// Try to read the password from the .irodsA file
-			authFile := filepath.Join(filepath.Dir(file), ".irodsA")
-
-			if f, ok := os.LookupEnv("IRODS_AUTHENTICATION_FILE"); ok {
-				authFile = f
-			}
-
 			if password, err := ReadAuthFile(authFile, env.IrodsAuthenticationUID); err == nil {
 				env.Password = password
 				env.AuthScheme = "native"
@@ -91,7 +107,7 @@ func FileLoader(file string) Loader {
 
 		if env.AuthScheme == "pam_interactive" {
 			env.PersistentState = &persistentState{
-				file: filepath.Join(filepath.Dir(file), ".irodsA.json"),
+				file: authFile + ".json",
 			}
 		}
 
@@ -251,11 +267,16 @@ func WithPasswordStore(store PasswordStore) Option {
 	}
 }
 
+// FilePasswordStore stores the password in the .irodsA file in the same directory
+// as the given environment file, or in the file specified by the IRODS_AUTHENTICATION_FILE
+// environment variable or the irods_authentication_file setting, if set.
 func FilePasswordStore(file string) PasswordStore {
-	return func(_ context.Context, env iron.Env, password string) error {
-		authFile := filepath.Join(filepath.Dir(file), ".irodsA")
+	return authFilePasswordStore(filepath.Join(filepath.Dir(file), ".irodsA"))
+}
 
-		return WriteAuthFile(authFile, password, env.IrodsAuthenticationUID)
+func authFilePasswordStore(defaultAuthFile string) PasswordStore {
+	return func(_ context.Context, env iron.Env, password string) error {
+		return WriteAuthFile(authenticationFile(env, defaultAuthFile), password, env.IrodsAuthenticationUID)
 	}
 }
 
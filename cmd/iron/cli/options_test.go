@@ -326,6 +326,57 @@ func TestWriteAuthFile(t *testing.T) { //nolint:gocognit,funlen
 	}
 }
 
+func TestAuthenticationFile(t *testing.T) {
+	dir := t.TempDir()
+	authFile := filepath.Join(dir, "shared", ".irodsA")
+
+	if err := os.Mkdir(filepath.Dir(authFile), 0o700); err != nil {
+		t.Fatal(err)
+	}
+
+	envfile := filepath.Join(dir, "irods_environment.json")
+
+	if err := os.WriteFile(envfile, []byte(`{"irods_zone_name": "testZone", "irods_authentication_scheme": "native", "irods_authentication_file": "`+authFile+`"}`), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("IRODS_AUTHENTICATION_FILE", "")
+
+	env, _, err := FileLoader(envfile)(t.Context(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = FilePasswordStore(envfile)(t.Context(), env, "testPassword"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = os.Stat(filepath.Join(dir, ".irodsA")); !os.IsNotExist(err) {
+		t.Fatalf("expected password not to be stored next to the environment file, got %v", err)
+	}
+
+	if env, _, err = FileLoader(envfile)(t.Context(), ""); err != nil {
+		t.Fatal(err)
+	}
+
+	if env.Password != "testPassword" {
+		t.Fatalf("expected password to be read from %s, got %q", authFile, env.Password)
+	}
+
+	// The environment variable takes precedence
+	override := filepath.Join(dir, "override")
+
+	t.Setenv("IRODS_AUTHENTICATION_FILE", override)
+
+	if err = FilePasswordStore(envfile)(t.Context(), env, "otherPassword"); err != nil {
+		t.Fatal(err)
+	}
+
+	if password, err := ReadAuthFile(override, nil); err != nil || password != "otherPassword" {
+		t.Fatalf("expected password to be stored in %s, got %q, %v", override, password, err)
+	}
+}
+
 func TestPersistentState(t *testing.T) {
 	testFile := filepath.Join(t.TempDir(), ".irodsA.json")
 
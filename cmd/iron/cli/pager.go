@@ -0,0 +1,155 @@
+package cli
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"os/exec"
+
+	"github.com/google/shlex"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+const noPagerOption = "no-pager"
+
+// defaultPager is used if $PAGER is not set
+const defaultPager = "less"
+
+// addPagerFlag adds the --no-pager flag to a command that supports paging, see pageOutput.
+func addPagerFlag(cmd *cobra.Command, noPager *bool) {
+	cmd.Flags().BoolVar(noPager, noPagerOption, false, "Do not pipe the output through $PAGER if it does not fit on the terminal")
+}
+
+// pageOutput redirects the output of cmd through $PAGER if the output is a terminal
+// and the output doesn't fit on it. The output is buffered until it exceeds the height
+// of the terminal, afterwards it is streamed to the pager. The returned function must
+// be called after all output has been written, it waits for the pager to exit.
+func pageOutput(cmd *cobra.Command, noPager bool) func() {
+	out := cmd.OutOrStdout()
+
+	f, ok := out.(*os.File)
+	if noPager || !ok || !term.IsTerminal(int(f.Fd())) {
+		return func() {}
+	}
+
+	_, height, err := term.GetSize(int(f.Fd()))
+	if err != nil || height <= 0 {
+		return func() {}
+	}
+
+	command := os.Getenv("PAGER")
+	if command == "" {
+		command = defaultPager
+	}
+
+	pw := &pagerWriter{
+		Writer:  out,
+		Height:  height - 1, // Keep a line for the prompt
+		Command: command,
+	}
+
+	cmd.SetOut(pw)
+
+	return func() {
+		pw.Close() //nolint:errcheck
+
+		cmd.SetOut(out)
+	}
+}
+
+// pagerWriter buffers the output until it exceeds Height lines,
+// and then starts Command to page the remaining output.
+type pagerWriter struct {
+	Writer  io.Writer
+	Height  int
+	Command string
+
+	buf   bytes.Buffer
+	lines int
+	pager *exec.Cmd
+	stdin io.WriteCloser
+}
+
+func (pw *pagerWriter) Write(b []byte) (int, error) {
+	if pw.stdin != nil {
+		// If the user quits the pager, the remaining output is discarded
+		pw.stdin.Write(b) //nolint:errcheck
+
+		return len(b), nil
+	}
+
+	pw.buf.Write(b)
+	pw.lines += bytes.Count(b, []byte("\n"))
+
+	if pw.lines <= pw.Height {
+		return len(b), nil
+	}
+
+	if err := pw.start(); err != nil {
+		// Fall back to writing to the terminal directly
+		pw.stdin = nopWriteCloser{pw.Writer}
+	}
+
+	pw.stdin.Write(pw.buf.Bytes()) //nolint:errcheck
+
+	pw.buf.Reset()
+
+	return len(b), nil
+}
+
+func (pw *pagerWriter) start() error {
+	args, err := shlex.Split(pw.Command)
+	if err != nil || len(args) == 0 {
+		return exec.ErrNotFound
+	}
+
+	pager := exec.Command(args[0], args[1:]...) //nolint:gosec
+	pager.Stdout = pw.Writer
+	pager.Stderr = os.Stderr
+
+	// Let less pass colors, and don't clear the screen on exit
+	if os.Getenv("LESS") == "" {
+		pager.Env = append(os.Environ(), "LESS=FRX")
+	}
+
+	stdin, err := pager.StdinPipe()
+	if err != nil {
+		return err
+	}
+
+	if err := pager.Start(); err != nil {
+		return err
+	}
+
+	pw.pager = pager
+	pw.stdin = stdin
+
+	return nil
+}
+
+// Close writes the buffered output if the pager was not started,
+// otherwise it closes the input of the pager and waits for it to exit.
+func (pw *pagerWriter) Close() error {
+	if pw.stdin == nil {
+		_, err := pw.Writer.Write(pw.buf.Bytes())
+
+		return err
+	}
+
+	err := pw.stdin.Close()
+
+	if pw.pager != nil {
+		err = pw.pager.Wait()
+	}
+
+	return err
+}
+
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestPagerWriter(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	for _, command := range []string{"cat", "nonexistent-pager"} {
+		for _, n := range []int{3, 10} {
+			var buf bytes.Buffer
+
+			pw := &pagerWriter{
+				Writer:  &buf,
+				Height:  5,
+				Command: command,
+			}
+
+			var expected strings.Builder
+
+			for i := range n {
+				fmt.Fprintf(pw, "line %d\n", i)
+				fmt.Fprintf(&expected, "line %d\n", i)
+			}
+
+			if err := pw.Close(); err != nil {
+				t.Fatal(err)
+			}
+
+			if started := pw.pager != nil; started != (n > 5 && command == "cat") {
+				t.Errorf("%s with %d lines: unexpected pager state %v", command, n, started)
+			}
+
+			if buf.String() != expected.String() {
+				t.Errorf("%s with %d lines: unexpected output %q", command, n, buf.String())
+			}
+		}
+	}
+}
+
+func TestPageOutputNoTerminal(t *testing.T) {
+	var buf bytes.Buffer
+
+	cmd := &cobra.Command{}
+	cmd.SetOut(&buf)
+
+	done := pageOutput(cmd, false)
+
+	if cmd.OutOrStdout() != &buf {
+		t.Fatal("expected no pager if the output is not a terminal")
+	}
+
+	done()
+}
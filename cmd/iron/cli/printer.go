@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/dustin/go-humanize"
@@ -13,9 +16,48 @@ import (
 type Printer interface {
 	Setup(hasACL, hasMeta, hasCollectionSize bool)
 	Print(name string, i api.Record)
+	PrintProperties(properties []Property, metadata []api.Metadata)
 	Flush()
 }
 
+// Property is a named value of an item that is not part of the
+// iRODS hierarchy, such as a resource or a user.
+type Property struct {
+	Name  string
+	Value any
+}
+
+// dataObjectRecord is a record of a data object, together with its comment and expiry,
+// and optionally the checksums of its replicas, its advisory lock state and the
+// physical location of its replicas.
+type dataObjectRecord struct {
+	api.Record
+	Comment  string
+	Expiry   time.Time
+	Replicas []api.ReplicaChecksum
+	Lock     *api.LockState
+	Physical []physicalReplica
+}
+
+// physicalReplica is the location of a replica on the storage of its resource
+type physicalReplica struct {
+	Number   int
+	Resource string
+	Host     string
+	Path     string
+}
+
+// Divergent returns true if the replicas have different checksums.
+func (d *dataObjectRecord) Divergent() bool {
+	for _, r := range d.Replicas {
+		if !bytes.Equal(r.Checksum, d.Replicas[0].Checksum) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type TablePrinter struct {
 	Writer interface {
 		io.Writer
@@ -23,12 +65,25 @@ type TablePrinter struct {
 	}
 	Zone string
 
+	// ObjectCounts adds an OBJECTS column after the SIZE column, with the number
+	// of data objects in each collection. Requires the FetchCollectionCount option.
+	ObjectCounts bool
+
 	hasCollectionSizes bool
 }
 
+// objectCounter is implemented by records that know the number of data objects in a collection
+type objectCounter interface {
+	ObjectCount() int64
+}
+
 func (tp *TablePrinter) Setup(hasACL, hasMeta, hasCollectionSizes bool) {
 	header1 := "CREATOR\tSIZE\tDATE\tSTATUS\tCHECKSUM\tNAME"
 
+	if tp.ObjectCounts {
+		header1 = "CREATOR\tSIZE\tOBJECTS\tDATE\tSTATUS\tCHECKSUM\tNAME"
+	}
+
 	if hasMeta {
 		header1 += "\t─── METADATA KEY\tVALUE\tUNITS\n"
 	} else {
@@ -99,9 +154,15 @@ func (tp *TablePrinter) Print(name string, i api.Record) { //nolint:funlen
 		))
 	}
 
+	size := tp.formatSize(i)
+
+	if tp.ObjectCounts {
+		size += "\t" + tp.formatCount(i)
+	}
+
 	header := fmt.Sprintf("%s\t%s\t%s\t%s\t%s\t%s%s%s",
 		owner,
-		tp.formatSize(i),
+		size,
 		t,
 		status,
 		checksum,
@@ -132,8 +193,77 @@ func (tp *TablePrinter) Print(name string, i api.Record) { //nolint:funlen
 			metaLine = meta[i]
 		}
 
+		if tp.ObjectCounts {
+			aclLine += "\t"
+		}
+
 		fmt.Fprintf(tp.Writer, "%s\t\t\t\t\t%s\n", aclLine, metaLine)
 	}
+
+	d, ok := i.(*dataObjectRecord)
+	if !ok {
+		return
+	}
+
+	if d.Comment != "" {
+		fmt.Fprintf(tp.Writer, "%s─── COMMENT%s\t%s\n", Bold, Reset, d.Comment)
+	}
+
+	if !d.Expiry.IsZero() {
+		fmt.Fprintf(tp.Writer, "%s─── EXPIRY%s\t%s\n", Bold, Reset, d.Expiry.Format(time.DateTime))
+	}
+
+	if d.Lock != nil {
+		fmt.Fprintf(tp.Writer, "%s─── LOCK%s\t%s\n", Bold, Reset, d.Lock)
+	}
+
+	color = NoColor
+
+	if d.Divergent() {
+		color = Red
+	}
+
+	for _, r := range d.Replicas {
+		fmt.Fprintf(tp.Writer, "%s─── REPLICA %d%s\t%s%x%s\t%s\n", Bold, r.Number, Reset, color, r.Checksum, NoColor, r.ResourceHierarchy)
+	}
+
+	if d.Divergent() {
+		fmt.Fprintf(tp.Writer, "%s─── WARNING%s\t%sreplicas have divergent checksums%s\n", Bold, Reset, Red, NoColor)
+	}
+
+	for _, r := range d.Physical {
+		fmt.Fprintf(tp.Writer, "%s─── PHYSICAL %d%s\t%s:%s\t%s\n", Bold, r.Number, Reset, r.Host, r.Path, r.Resource)
+	}
+}
+
+// PrintProperties prints a list of properties, followed by the metadata. Setup must not be called.
+func (tp *TablePrinter) PrintProperties(properties []Property, metadata []api.Metadata) {
+	for _, p := range properties {
+		value := p.Value
+
+		if t, ok := value.(time.Time); ok {
+			value = t.Format(time.DateTime)
+		}
+
+		fmt.Fprintf(tp.Writer, "%s%s%s\t%v\t\n", Bold, p.Name, Reset, value)
+	}
+
+	if len(metadata) == 0 {
+		return
+	}
+
+	fmt.Fprintf(tp.Writer, "%s─── METADATA KEY\tVALUE\tUNITS%s\n", Bold, Reset)
+
+	for p, m := range metadata {
+		fmt.Fprintf(tp.Writer, "%s %s%s\t%s\t%s%s\n",
+			bracket(p, len(metadata)),
+			Yellow,
+			m.Name,
+			m.Value,
+			m.Units,
+			NoColor,
+		)
+	}
 }
 
 func (tp *TablePrinter) formatSize(i api.Record) string {
@@ -144,6 +274,15 @@ func (tp *TablePrinter) formatSize(i api.Record) string {
 	return humanize.Bytes(uint64(i.Size()))
 }
 
+func (tp *TablePrinter) formatCount(i api.Record) string {
+	c, ok := i.(objectCounter)
+	if !ok || !i.IsDir() {
+		return ""
+	}
+
+	return strconv.FormatInt(c.ObjectCount(), 10)
+}
+
 func appendStatus(list, status string) string {
 	switch status {
 	case "1":
@@ -227,8 +366,16 @@ func (tp *TablePrinter) Flush() {
 	tp.Writer.Flush()
 }
 
+// JSONPrinter prints each record as a line of JSON, for ls and find --json.
+// Its output is not versioned and does not include the data object fields
+// that only stat shows, stat --json uses the versioned statJSON instead.
 type JSONPrinter struct {
-	Writer          io.Writer
+	Writer io.Writer
+
+	// ObjectCounts adds the number of data objects in each collection to the output.
+	// Requires the FetchCollectionCount option.
+	ObjectCounts bool
+
 	hasACL, hasMeta bool
 }
 
@@ -247,6 +394,28 @@ func (jp *JSONPrinter) Print(name string, i api.Record) {
 		delete(m, "metadata")
 	}
 
+	if c, ok := i.(objectCounter); ok && jp.ObjectCounts && i.IsDir() {
+		m["objects"] = c.ObjectCount()
+	}
+
+	json.NewEncoder(jp.Writer).Encode(m) //nolint:errcheck,errchkjson
+}
+
+func (jp *JSONPrinter) PrintProperties(properties []Property, metadata []api.Metadata) {
+	m := map[string]any{
+		"metadata": metadata,
+	}
+
+	for _, p := range properties {
+		key := strings.ReplaceAll(strings.ToLower(p.Name), " ", "_")
+
+		if t, ok := p.Value.(time.Time); ok {
+			m[key] = t.Format(time.RFC3339)
+		} else {
+			m[key] = p.Value
+		}
+	}
+
 	json.NewEncoder(jp.Writer).Encode(m) //nolint:errcheck,errchkjson
 }
 
@@ -254,6 +423,40 @@ func (jp *JSONPrinter) Flush() {
 	// empty
 }
 
+// FilterPrinter is a Printer that only passes data objects, or only
+// collections, to the underlying Printer.
+type FilterPrinter struct {
+	Printer
+
+	OnlyFiles, OnlyDirs bool
+}
+
+func (fp *FilterPrinter) Print(name string, i api.Record) {
+	if !fp.Match(i) {
+		return
+	}
+
+	fp.Printer.Print(name, i)
+}
+
+// Match reports whether the record passes the filter.
+func (fp *FilterPrinter) Match(i api.Record) bool {
+	return !(fp.OnlyFiles && i.IsDir() || fp.OnlyDirs && !i.IsDir())
+}
+
+// filterPrinter wraps the printer in a FilterPrinter if one of the filters is set.
+func filterPrinter(printer Printer, onlyFiles, onlyDirs bool) Printer {
+	if !onlyFiles && !onlyDirs {
+		return printer
+	}
+
+	return &FilterPrinter{
+		Printer:   printer,
+		OnlyFiles: onlyFiles,
+		OnlyDirs:  onlyDirs,
+	}
+}
+
 func toMap(name string, i api.Record) map[string]any {
 	var (
 		creator  string
@@ -302,3 +505,16 @@ func parseIrodsChecksum(s string) string {
 
 	return ""
 }
+
+// outputResult writes result to w as a single line of JSON if jsonFormat is set,
+// so that the output of all read commands can be consumed by scripts in the same way.
+// Otherwise, printText is called to print the result in a human readable form.
+func outputResult(w io.Writer, result any, jsonFormat bool, printText func(w io.Writer)) error {
+	if jsonFormat {
+		return json.NewEncoder(w).Encode(result)
+	}
+
+	printText(w)
+
+	return nil
+}
@@ -0,0 +1,280 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/kuleuven/iron"
+	"github.com/spf13/cobra"
+)
+
+// WithProfiles enables named profiles, each stored as <dir>/<name>.json together with
+// its own <name>.irodsA password file and working directory. A profile can be selected
+// for a single command using the --profile flag, or made the default using
+// `profile use <name>`. New profiles are created using `profile add`, based on
+// the given template. This option should be passed after the options that
+// configure the default loader and stores, which are used if no profile is selected.
+func WithProfiles(dir string, template iron.Env) Option {
+	return func(a *App) {
+		a.profiles = &profiles{
+			dir:      dir,
+			template: template,
+		}
+	}
+}
+
+type profiles struct {
+	dir      string
+	template iron.Env
+
+	// Original loader and stores, to restore when switching back to the default profile
+	defaults *profileStores
+}
+
+type profileStores struct {
+	loadEnv       Loader
+	configStore   ConfigStore
+	passwordStore PasswordStore
+	workdirStore  WorkdirStore
+	workdir       string
+}
+
+// defaultProfile is the name used to refer to the configuration without profile
+const defaultProfile = "default"
+
+var (
+	ErrInvalidProfile = errors.New("invalid profile name")
+	ErrProfileExists  = errors.New("profile already exists")
+)
+
+func (p *profiles) file(name string) (string, error) {
+	if name == "" || name == defaultProfile || strings.ContainsAny(name, `/\`) || strings.HasPrefix(name, ".") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidProfile, name)
+	}
+
+	return filepath.Join(p.dir, name+".json"), nil
+}
+
+func (p *profiles) currentFile() string {
+	return filepath.Join(p.dir, ".current")
+}
+
+// current returns the name of the profile that was selected using `profile use`.
+func (p *profiles) current() string {
+	data, err := os.ReadFile(p.currentFile())
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(string(data))
+}
+
+func (p *profiles) list() ([]string, error) {
+	entries, err := os.ReadDir(p.dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var names []string
+
+	for _, entry := range entries {
+		if name, ok := strings.CutSuffix(entry.Name(), ".json"); ok && !entry.IsDir() && !strings.HasPrefix(name, ".") {
+			names = append(names, name)
+		}
+	}
+
+	slices.Sort(names)
+
+	return names, nil
+}
+
+// selectProfile applies the profile given by the --profile flag,
+// or otherwise the profile selected using `profile use`.
+func (a *App) selectProfile(cmd *cobra.Command) error {
+	name := a.Profile
+
+	if name == "" {
+		name = a.profiles.current()
+	}
+
+	return a.applyProfile(cmd, name)
+}
+
+// applyProfile configures the loader and stores of the app for the given profile.
+// An empty name or "default" restores the configuration without profile.
+func (a *App) applyProfile(cmd *cobra.Command, name string) error {
+	if a.profiles.defaults == nil {
+		a.profiles.defaults = &profileStores{
+			loadEnv:       a.loadEnv,
+			configStore:   a.configStore,
+			passwordStore: a.passwordStore,
+			workdirStore:  a.workdirStore,
+			workdir:       a.Workdir,
+		}
+	}
+
+	workdirChanged := cmd.Flag("workdir") != nil && cmd.Flag("workdir").Changed
+
+	if name == "" || name == defaultProfile {
+		a.loadEnv = a.profiles.defaults.loadEnv
+		a.configStore = a.profiles.defaults.configStore
+		a.passwordStore = a.profiles.defaults.passwordStore
+		a.workdirStore = a.profiles.defaults.workdirStore
+
+		if !workdirChanged {
+			a.Workdir = a.profiles.defaults.workdir
+		}
+
+		return nil
+	}
+
+	file, err := a.profiles.file(name)
+	if err != nil {
+		return err
+	}
+
+	authFile := strings.TrimSuffix(file, ".json") + ".irodsA"
+
+	a.loadEnv = fileLoader(file, authFile)
+	a.configStore = FileStore(file, a.profiles.template)
+	a.passwordStore = authFilePasswordStore(authFile)
+	a.workdirStore = func(_ context.Context, workdir string) error {
+		return StoreWorkdirInFile(file, workdir)
+	}
+
+	if !workdirChanged {
+		a.Workdir, _ = GetWorkdirFromFile(file) //nolint:errcheck
+	}
+
+	return nil
+}
+
+func (a *App) profile() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage profiles for multiple zones or accounts",
+	}
+
+	cmd.AddCommand(a.profileList(), a.profileUse(), a.profileAdd())
+
+	return cmd
+}
+
+func (a *App) profileList() *cobra.Command {
+	var jsonFormat bool
+
+	cmd := &cobra.Command{
+		Use:     "ls",
+		Aliases: []string{"list"},
+		Short:   "List profiles",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			names, err := a.profiles.list()
+			if err != nil {
+				return err
+			}
+
+			current := a.Profile
+
+			if current == "" {
+				current = a.profiles.current()
+			}
+
+			if current == "" {
+				current = defaultProfile
+			}
+
+			names = append([]string{defaultProfile}, names...)
+
+			result := map[string]any{
+				"profiles": names,
+				"current":  current,
+			}
+
+			return outputResult(cmd.OutOrStdout(), result, jsonFormat, func(w io.Writer) {
+				for _, name := range names {
+					if name == current {
+						Fprintcolorln(w, Bold, "* "+name)
+					} else {
+						fmt.Fprintln(w, "  "+name)
+					}
+				}
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+
+	return cmd
+}
+
+func (a *App) profileUse() *cobra.Command {
+	return &cobra.Command{
+		Use:   "use <name>",
+		Short: "Select the profile to use by default",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+
+			if name == defaultProfile {
+				if err := os.Remove(a.profiles.currentFile()); err != nil && !errors.Is(err, os.ErrNotExist) {
+					return err
+				}
+			} else {
+				file, err := a.profiles.file(name)
+				if err != nil {
+					return err
+				}
+
+				if _, err := os.Stat(file); err != nil {
+					return err
+				}
+
+				if err := os.WriteFile(a.profiles.currentFile(), []byte(name+"\n"), 0o600); err != nil {
+					return err
+				}
+			}
+
+			if err := a.ResetClient(); err != nil {
+				return err
+			}
+
+			a.Profile = name
+
+			return a.applyProfile(cmd, name)
+		},
+	}
+}
+
+func (a *App) profileAdd() *cobra.Command {
+	labels := []string{"user name", "zone name", "host"}
+
+	return &cobra.Command{
+		Use:   "add <name> <" + strings.Join(labels, "> <") + ">",
+		Short: "Create a new profile",
+		Long:  "Create a new profile. Select it using `" + a.name + " profile use <name>` or the --profile flag, and run `auth` to authenticate.",
+		Args:  cobra.ExactArgs(len(labels) + 1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			file, err := a.profiles.file(args[0])
+			if err != nil {
+				return err
+			}
+
+			if _, err := os.Stat(file); err == nil {
+				return fmt.Errorf("%w: %s", ErrProfileExists, args[0])
+			}
+
+			_, err = FileStore(file, a.profiles.template)(cmd.Context(), args[1:])
+
+			return err
+		},
+	}
+}
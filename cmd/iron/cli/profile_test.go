@@ -0,0 +1,148 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron"
+	"github.com/spf13/cobra"
+)
+
+func TestProfiles(t *testing.T) { //nolint:funlen
+	dir := t.TempDir()
+
+	app := New(t.Context(),
+		WithName("test"),
+		WithLoader(FileLoader(filepath.Join(dir, "irods_environment.json"))),
+		WithDefaultWorkdir("/defaultzone/home/user"),
+		WithProfiles(filepath.Join(dir, "profiles"), iron.Env{
+			Port: 1247,
+		}),
+	)
+
+	run := func(args ...string) (string, error) {
+		var buf bytes.Buffer
+
+		cmd := app.Command()
+		cmd.SetOut(&buf)
+		cmd.SetArgs(args)
+
+		err := cmd.ExecuteContext(t.Context())
+
+		return buf.String(), err
+	}
+
+	if _, err := run("profile", "add", "p1", "user1", "zone1", "host1"); err != nil {
+		t.Fatal(err)
+	}
+
+	env, _, err := FileLoader(filepath.Join(dir, "profiles", "p1.json"))(t.Context(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if env.Username != "user1" || env.Zone != "zone1" || env.Host != "host1" || env.Port != 1247 {
+		t.Fatalf("unexpected profile env: %+v", env)
+	}
+
+	if _, err := run("profile", "add", "p1", "user1", "zone1", "host1"); !errors.Is(err, ErrProfileExists) {
+		t.Fatalf("expected ErrProfileExists, got %v", err)
+	}
+
+	if _, err := run("profile", "add", "../p2", "user1", "zone1", "host1"); !errors.Is(err, ErrInvalidProfile) {
+		t.Fatalf("expected ErrInvalidProfile, got %v", err)
+	}
+
+	if _, err := run("profile", "use", "p2"); err == nil {
+		t.Fatal("expected error for unknown profile")
+	}
+
+	if _, err := run("profile", "use", "p1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(dir, "profiles", ".current")); err != nil || strings.TrimSpace(string(data)) != "p1" {
+		t.Fatalf("expected p1 to be the current profile, got %q (%v)", data, err)
+	}
+
+	out, err := run("profile", "ls")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(out, "  default\n") || !strings.Contains(out, "* p1") {
+		t.Fatalf("unexpected output: %q", out)
+	}
+
+	out, err = run("profile", "ls", "--json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := `{"current":"p1","profiles":["default","p1"]}` + "\n"; out != expected {
+		t.Fatalf("expected %q, got %q", expected, out)
+	}
+
+	if _, err := run("profile", "use", "default"); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "profiles", ".current")); !os.IsNotExist(err) {
+		t.Fatalf("expected .current to be removed, got %v", err)
+	}
+}
+
+func TestApplyProfile(t *testing.T) {
+	dir := t.TempDir()
+
+	app := New(t.Context(),
+		WithName("test"),
+		WithDefaultWorkdir("/defaultzone/home/user"),
+		WithProfiles(dir, iron.Env{}),
+	)
+
+	file := filepath.Join(dir, "p1.json")
+
+	if _, err := FileStore(file, iron.Env{})(t.Context(), []string{"user1", "zone1", "host1"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := StoreWorkdirInFile(file, "/zone1/home/user1/sub"); err != nil {
+		t.Fatal(err)
+	}
+
+	cmd := &cobra.Command{}
+
+	if err := app.applyProfile(cmd, "p1"); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Workdir != "/zone1/home/user1/sub" {
+		t.Fatalf("expected workdir of profile, got %s", app.Workdir)
+	}
+
+	env, _, err := app.loadEnv(t.Context(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if env.Username != "user1" || env.Zone != "zone1" {
+		t.Fatalf("unexpected env: %+v", env)
+	}
+
+	if err := app.applyProfile(cmd, defaultProfile); err != nil {
+		t.Fatal(err)
+	}
+
+	if app.Workdir != "/defaultzone/home/user" {
+		t.Fatalf("expected default workdir, got %s", app.Workdir)
+	}
+
+	if err := app.applyProfile(cmd, ".hidden"); !errors.Is(err, ErrInvalidProfile) {
+		t.Fatalf("expected ErrInvalidProfile, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package cli
+
+import (
+	"sync"
+
+	"github.com/kuleuven/iron"
+)
+
+// cachedPrompt is a prompt that remembers the answers to password prompts,
+// so that a reconnect within the same shell session doesn't ask for
+// the password again. The passwords are only kept in memory.
+type cachedPrompt struct {
+	iron.Prompt
+
+	passwords map[string]string
+	sync.Mutex
+}
+
+func (p *cachedPrompt) Password(message string) (string, error) {
+	p.Lock()
+	defer p.Unlock()
+
+	if password, ok := p.passwords[message]; ok {
+		return password, nil
+	}
+
+	password, err := p.Prompt.Password(message)
+	if err != nil {
+		return "", err
+	}
+
+	if p.passwords == nil {
+		p.passwords = map[string]string{}
+	}
+
+	p.passwords[message] = password
+
+	return password, nil
+}
+
+// Clear forgets all cached passwords
+func (p *cachedPrompt) Clear() {
+	p.Lock()
+	defer p.Unlock()
+
+	clear(p.passwords)
+}
@@ -0,0 +1,52 @@
+package cli
+
+import (
+	"testing"
+
+	"github.com/kuleuven/iron"
+)
+
+type countingPrompt struct {
+	iron.Bot
+	calls int
+}
+
+func (p *countingPrompt) Password(message string) (string, error) {
+	p.calls++
+
+	return p.Bot.Password(message)
+}
+
+func TestCachedPrompt(t *testing.T) {
+	bot := &countingPrompt{Bot: iron.Bot{"Password": "secret"}}
+	p := &cachedPrompt{Prompt: bot}
+
+	for range 2 {
+		password, err := p.Password("Password")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if password != "secret" {
+			t.Fatalf("expected secret, got %s", password)
+		}
+	}
+
+	if bot.calls != 1 {
+		t.Fatalf("expected 1 prompt, got %d", bot.calls)
+	}
+
+	if _, err := p.Password("Unknown"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	p.Clear()
+
+	if _, err := p.Password("Password"); err != nil {
+		t.Fatal(err)
+	}
+
+	if bot.calls != 3 {
+		t.Fatalf("expected 3 prompts, got %d", bot.calls)
+	}
+}
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrInvalidRenameFile = errors.New("invalid rename file")
+	ErrRenamesFailed     = errors.New("renames failed")
+	ErrFromFileArgs      = errors.New("no paths can be passed as arguments if --from-file is used")
+)
+
+// parseRenames reads a file with one tab-separated source and target path per line.
+// Relative paths are resolved against the working directory, and a target ending in
+// a slash is completed with the name of the source, as for mv. Empty lines and lines
+// starting with # are ignored.
+func (a *App) parseRenames(r io.Reader) ([]api.Rename, error) {
+	var renames []api.Rename
+
+	scanner := bufio.NewScanner(r)
+
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		source, target, ok := strings.Cut(text, "\t")
+		if !ok || source == "" || target == "" {
+			return nil, fmt.Errorf("%w: line %d: expected 2 tab-separated fields", ErrInvalidRenameFile, line)
+		}
+
+		source = a.Path(source)
+
+		if strings.HasSuffix(target, "/") {
+			target += Name(source)
+		}
+
+		target = a.Path(target)
+
+		if api.IsSubPath(target, source) {
+			return nil, fmt.Errorf("%w: line %d: %w: %s is inside %s", ErrInvalidRenameFile, line, ErrRecursiveMove, target, source)
+		}
+
+		renames = append(renames, api.Rename{Source: source, Target: target})
+	}
+
+	return renames, scanner.Err()
+}
+
+// moveFromFile renames all pairs listed in the given file, or standard input if the
+// file is "-". Failing pairs are reported, without stopping the other renames.
+func (a *App) moveFromFile(cmd *cobra.Command, file string) error {
+	var r io.Reader = cmd.InOrStdin()
+
+	if file != "-" {
+		f, err := os.Open(file)
+		if err != nil {
+			return err
+		}
+
+		defer f.Close()
+
+		r = f
+	}
+
+	renames, err := a.parseRenames(r)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+
+	for i, err := range a.RenameMany(cmd.Context(), renames) {
+		if err == nil {
+			continue
+		}
+
+		failed++
+
+		Fprintcolorln(cmd.ErrOrStderr(), Red, fmt.Sprintf("%s -> %s: %s", renames[i].Source, renames[i].Target, err))
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%w: %d of %d", ErrRenamesFailed, failed, len(renames))
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%d renamed\n", len(renames))
+
+	return nil
+}
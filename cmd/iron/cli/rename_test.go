@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestParseRenames(t *testing.T) {
+	app := testApp(t)
+
+	app.Workdir = "/testzone/home/user"
+
+	renames, err := app.parseRenames(strings.NewReader("# comment\n\na\tb\n/testzone/c\t/testzone/d/\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []api.Rename{
+		{Source: "/testzone/home/user/a", Target: "/testzone/home/user/b"},
+		{Source: "/testzone/c", Target: "/testzone/d/c"},
+	}
+
+	if !slices.Equal(renames, expected) {
+		t.Fatalf("expected %v, got %v", expected, renames)
+	}
+
+	for _, input := range []string{"a b\n", "a\t\n", "a\ta/b\n"} {
+		if _, err := app.parseRenames(strings.NewReader(input)); !errors.Is(err, ErrInvalidRenameFile) {
+			t.Errorf("%q: expected ErrInvalidRenameFile, got %v", input, err)
+		}
+	}
+}
+
+func TestMoveFromFile(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{}, // Source is no data object
+		collectionResponse("/testzone/a"),
+		msg.QueryResponse{}, // Target does not exist
+		msg.QueryResponse{},
+		msg.EmptyResponse{},
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"mv", "--from-file", "-"})
+	cmd.SetIn(strings.NewReader("/testzone/a\t/testzone/b\n"))
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.String() != "1 renamed\n" {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	app.AddResponses([]any{
+		msg.QueryResponse{}, // Source is no data object
+		collectionResponse("/testzone/a"),
+		msg.QueryResponse{}, // Target is a collection
+		collectionResponse("/testzone/b"),
+	})
+
+	buf.Reset()
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"mv", "--from-file", "-"})
+	cmd.SetIn(strings.NewReader("/testzone/a\t/testzone/b\n"))
+	cmd.SetOut(&buf)
+	cmd.SetErr(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrRenamesFailed) {
+		t.Fatalf("expected ErrRenamesFailed, got %v", err)
+	}
+
+	if !strings.Contains(buf.String(), api.ErrTargetExists.Error()) {
+		t.Fatalf("expected conflict to be reported, got %q", buf.String())
+	}
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"mv", "--from-file", "-", "/testzone/a"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrFromFileArgs) {
+		t.Fatalf("expected ErrFromFileArgs, got %v", err)
+	}
+}
@@ -0,0 +1,179 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+	"github.com/spf13/cobra"
+)
+
+var (
+	ErrNoGoodReplica        = errors.New("no good replica to repair from")
+	ErrNoMajorityOfReplicas = errors.New("good replicas have divergent checksums without a majority")
+)
+
+// repairTarget is a replica that needs to be replaced by a copy of a good replica.
+// Stale replicas are updated in place, divergent replicas need to be trimmed first.
+type repairTarget struct {
+	api.Replica
+	Divergent bool
+}
+
+// rootResource returns the root resource of the hierarchy of a replica.
+func rootResource(replica api.Replica) string {
+	if replica.ResourceHierarchy == "" {
+		return replica.ResourceName
+	}
+
+	root, _, _ := strings.Cut(replica.ResourceHierarchy, ";")
+
+	return root
+}
+
+// planRepair determines the good replica to repair from, and the replicas that need repair. Stale replicas
+// always need repair. If divergent is set, the checksums of the good replicas are compared as well, and the
+// good replicas that don't match the checksum of the majority of good replicas need repair too.
+func (a *App) planRepair(ctx context.Context, obj *api.DataObject, divergent bool) (api.Replica, []repairTarget, error) {
+	var good, stale []api.Replica
+
+	for _, replica := range obj.Replicas {
+		switch replica.Status {
+		case "1":
+			good = append(good, replica)
+		case "0":
+			stale = append(stale, replica)
+		}
+	}
+
+	if len(good) == 0 {
+		return api.Replica{}, nil, fmt.Errorf("%w: %s", ErrNoGoodReplica, obj.Path)
+	}
+
+	source := good[0]
+
+	var targets []repairTarget
+
+	for _, replica := range stale {
+		targets = append(targets, repairTarget{Replica: replica})
+	}
+
+	if !divergent || len(good) < 2 {
+		return source, targets, nil
+	}
+
+	checksums, err := a.ChecksumAll(ctx, obj.Path)
+	if err != nil {
+		return api.Replica{}, nil, err
+	}
+
+	byNumber := map[int][]byte{}
+
+	for _, c := range checksums {
+		byNumber[c.Number] = c.Checksum
+	}
+
+	var majority []byte
+
+	for _, replica := range good {
+		var count int
+
+		for _, other := range good {
+			if bytes.Equal(byNumber[replica.Number], byNumber[other.Number]) {
+				count++
+			}
+		}
+
+		if 2*count > len(good) {
+			majority, source = byNumber[replica.Number], replica
+
+			break
+		}
+	}
+
+	if majority == nil {
+		return api.Replica{}, nil, fmt.Errorf("%w: %s", ErrNoMajorityOfReplicas, obj.Path)
+	}
+
+	for _, replica := range good {
+		if !bytes.Equal(byNumber[replica.Number], majority) {
+			targets = append(targets, repairTarget{Replica: replica, Divergent: true})
+		}
+	}
+
+	return source, targets, nil
+}
+
+func (a *App) repair() *cobra.Command {
+	var dryRun, divergent bool
+
+	cmd := &cobra.Command{
+		Use:               "repair <object path>",
+		Short:             "Update stale replicas of a data object from a good replica",
+		Long:              "Update the stale replicas of a data object by replicating a good replica to their resources on the server. If --divergent is passed, the checksums of the good replicas are compared as well, computing missing checksums, and good replicas of which the checksum does not match the checksum of the majority of good replicas are trimmed and replaced by a copy of a matching replica. The command fails if there is no good replica to repair from.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := a.Path(args[0])
+
+			obj, err := a.GetDataObject(cmd.Context(), path)
+			if err != nil {
+				return err
+			}
+
+			source, targets, err := a.planRepair(cmd.Context(), obj, divergent)
+			if err != nil {
+				return err
+			}
+
+			if len(targets) == 0 {
+				fmt.Fprintf(cmd.OutOrStdout(), "%s: all replicas are good\n", path)
+
+				return nil
+			}
+
+			for _, target := range targets {
+				label := fmt.Sprintf("replica %d on %s", target.Number, rootResource(target.Replica))
+				reason := fmt.Sprintf("from replica %d", source.Number)
+
+				if target.Divergent {
+					reason = "divergent checksum, replaced " + reason
+				} else {
+					reason = "stale, updated " + reason
+				}
+
+				if dryRun {
+					Fprintcolorln(cmd.OutOrStdout(), Yellow, "~ "+label+" ("+reason+")")
+
+					continue
+				}
+
+				if target.Divergent {
+					if err := a.TrimDataObjectReplica(cmd.Context(), path, target.Number); err != nil {
+						return err
+					}
+				}
+
+				err := a.ReplicateReplica(cmd.Context(), path, source.Number, rootResource(target.Replica))
+				if api.Is(err, msg.SYS_NO_GOOD_REPLICA) {
+					return fmt.Errorf("%w: %s", ErrNoGoodReplica, path)
+				} else if err != nil {
+					return fmt.Errorf("%s: %w", label, err)
+				}
+
+				Fprintcolorln(cmd.OutOrStdout(), Green, "✔ "+label+" ("+reason+")")
+			}
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&dryRun, dryrunOption, false, "Only print the actions that would be taken, without performing any changes")
+	cmd.Flags().BoolVar(&divergent, "divergent", false, "Also replace good replicas of which the checksum differs from the majority of good replicas")
+
+	return cmd
+}
@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func replicaStatusResponse(statuses, resources, checksums []string) msg.QueryResponse {
+	n := len(statuses)
+
+	repeat := func(value string) []string {
+		values := make([]string, n)
+
+		for i := range values {
+			values[i] = value
+		}
+
+		return values
+	}
+
+	numbers := make([]string, n)
+
+	for i := range numbers {
+		numbers[i] = strconv.Itoa(i)
+	}
+
+	return msg.QueryResponse{
+		RowCount:       n,
+		AttributeCount: 14,
+		TotalRowCount:  n,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 401, ResultLen: n, Values: repeat("1")},
+			{AttributeIndex: 500, ResultLen: n, Values: repeat("1")},
+			{AttributeIndex: 406, ResultLen: n, Values: repeat("generic")},
+			{AttributeIndex: 404, ResultLen: n, Values: numbers},
+			{AttributeIndex: 407, ResultLen: n, Values: repeat("1024")},
+			{AttributeIndex: 411, ResultLen: n, Values: repeat("rods")},
+			{AttributeIndex: 412, ResultLen: n, Values: repeat("testzone")},
+			{AttributeIndex: 415, ResultLen: n, Values: checksums},
+			{AttributeIndex: 413, ResultLen: n, Values: statuses},
+			{AttributeIndex: 409, ResultLen: n, Values: resources},
+			{AttributeIndex: 410, ResultLen: n, Values: repeat("/path")},
+			{AttributeIndex: 422, ResultLen: n, Values: resources},
+			{AttributeIndex: 419, ResultLen: n, Values: repeat("10000")},
+			{AttributeIndex: 420, ResultLen: n, Values: repeat("10000")},
+		},
+	}
+}
+
+func replicateRequest(source int, resource string) msg.DataObjectRequest {
+	request := msg.DataObjectRequest{
+		Path:          "/testzone/file",
+		OperationType: msg.OPER_TYPE_REPLICATE_DATA_OBJ,
+	}
+
+	request.KeyVals.Add(msg.REPL_NUM_KW, strconv.Itoa(source))
+	request.KeyVals.Add(msg.DEST_RESC_NAME_KW, resource)
+
+	return request
+}
+
+func TestRepairStale(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponse(replicaStatusResponse([]string{"1", "0"}, []string{"demoResc", "otherResc;leaf"}, []string{"", ""}))
+	app.Add(msg.DATA_OBJ_REPL_AN, replicateRequest(0, "otherResc"), msg.EmptyResponse{})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"repair", "/testzone/file"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "replica 1 on otherResc (stale, updated from replica 0)"; !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected %q in output, got %q", expected, buf.String())
+	}
+}
+
+func TestRepairDryRun(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponse(replicaStatusResponse([]string{"0", "1"}, []string{"demoResc", "otherResc"}, []string{"", ""}))
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"repair", "--dry-run", "/testzone/file"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "~ replica 0 on demoResc (stale, updated from replica 1)"; !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected %q in output, got %q", expected, buf.String())
+	}
+}
+
+func TestRepairNoGoodReplica(t *testing.T) {
+	for _, responses := range [][]any{
+		{
+			replicaStatusResponse([]string{"0", "0"}, []string{"demoResc", "otherResc"}, []string{"", ""}),
+		},
+		{
+			replicaStatusResponse([]string{"1", "0"}, []string{"demoResc", "otherResc"}, []string{"", ""}),
+			&msg.IRODSError{Code: msg.SYS_NO_GOOD_REPLICA},
+		},
+	} {
+		app := testApp(t)
+
+		app.AddResponses(responses)
+
+		cmd := app.Command()
+		cmd.SetArgs([]string{"repair", "/testzone/file"})
+
+		if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrNoGoodReplica) {
+			t.Errorf("expected no good replica error, got %v", err)
+		}
+	}
+}
+
+func TestRepairDivergent(t *testing.T) {
+	app := testApp(t)
+
+	response := replicaStatusResponse([]string{"1", "1", "1"}, []string{"resc0", "resc1", "resc2"}, []string{"sha2:qqqr", "sha2:qqqq", "sha2:qqqq"})
+
+	app.AddResponses([]any{response, response, msg.EmptyResponse{}})
+	app.Add(msg.DATA_OBJ_REPL_AN, replicateRequest(1, "resc0"), msg.EmptyResponse{})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"repair", "--divergent", "/testzone/file"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if expected := "replica 0 on resc0 (divergent checksum, replaced from replica 1)"; !strings.Contains(buf.String(), expected) {
+		t.Errorf("expected %q in output, got %q", expected, buf.String())
+	}
+}
+
+func TestRepairNoMajority(t *testing.T) {
+	app := testApp(t)
+
+	response := replicaStatusResponse([]string{"1", "1"}, []string{"resc0", "resc1"}, []string{"sha2:qqqr", "sha2:qqqq"})
+
+	app.AddResponses([]any{response, response})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"repair", "--divergent", "/testzone/file"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrNoMajorityOfReplicas) {
+		t.Errorf("expected no majority error, got %v", err)
+	}
+}
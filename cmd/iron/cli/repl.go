@@ -0,0 +1,107 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/dustin/go-humanize"
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/cmd/iron/tabwriter"
+	"github.com/spf13/cobra"
+)
+
+func (a *App) repl() *cobra.Command {
+	repl := &cobra.Command{
+		Use:   "repl",
+		Short: "Run a replica command",
+	}
+
+	repl.AddCommand(
+		a.replls(),
+	)
+
+	return repl
+}
+
+const replLsDescription = `List the replicas in a collection and its subcollections that are stored on a
+resource, e.g. to find out which data objects need to be migrated before the
+resource is decommissioned. The resource can be the root, an intermediate or a
+leaf resource of a resource hierarchy.`
+
+func (a *App) replls() *cobra.Command {
+	var (
+		jsonFormat, inBytes bool
+		resource            string
+	)
+
+	cmd := &cobra.Command{
+		Use:               "ls <collection path>",
+		Short:             "List the replicas on a resource",
+		Long:              replLsDescription,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+
+			objects, err := a.ListReplicasOnResource(cmd.Context(), a.Path(args[0]), resource)
+			if err != nil {
+				return err
+			}
+
+			replicas := []map[string]any{}
+
+			for _, obj := range objects {
+				for _, r := range obj.Replicas {
+					replicas = append(replicas, map[string]any{
+						"path":      obj.Path,
+						"number":    r.Number,
+						"size":      r.Size,
+						"status":    r.Status,
+						"hierarchy": r.ResourceHierarchy,
+					})
+				}
+			}
+
+			return outputResult(cmd.OutOrStdout(), replicas, jsonFormat, func(w io.Writer) {
+				printReplicas(w, objects, inBytes)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&resource, "resource", "", "Resource to list the replicas of")
+	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+	cmd.Flags().BoolVarP(&inBytes, "bytes", "b", false, "Print sizes in bytes")
+
+	cmd.MarkFlagRequired("resource") //nolint:errcheck
+
+	return cmd
+}
+
+func printReplicas(w io.Writer, objects []api.DataObject, inBytes bool) {
+	out := &tabwriter.TabWriter{
+		Writer: w,
+	}
+
+	defer out.Flush()
+
+	var total, count int64
+
+	for _, obj := range objects {
+		for _, r := range obj.Replicas {
+			size := humanize.Bytes(uint64(r.Size))
+
+			if inBytes {
+				size = fmt.Sprint(r.Size)
+			}
+
+			fmt.Fprintf(out, "%d\t%s\t%s\t%s\n", r.Number, size, r.ResourceHierarchy, obj.Path)
+
+			total += r.Size
+			count++
+		}
+	}
+
+	fmt.Fprintf(out, "%d replicas, %s\n", count, humanize.Bytes(uint64(total)))
+}
@@ -0,0 +1,41 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestReplLs(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{},
+		expiredResponse,
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"repl", "ls", "--resource", "demoResc", "/testzone"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, e := range []string{"demoResc", "/testzone/coll/expired", "1 replicas, 1.0 kB"} {
+		if !strings.Contains(buf.String(), e) {
+			t.Errorf("expected %q in output, got %q", e, buf.String())
+		}
+	}
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"repl", "ls", "/testzone"})
+
+	if err := cmd.ExecuteContext(t.Context()); err == nil {
+		t.Fatal("expected error for missing --resource flag")
+	}
+}
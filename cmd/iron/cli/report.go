@@ -0,0 +1,421 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/cmd/iron/tabwriter"
+	"github.com/kuleuven/iron/msg"
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+)
+
+// reportColumn is a column of a report. Plain columns are grouped,
+// aggregated columns are computed per group. Only Count and Sum can be
+// used as aggregates, so that partial results can be added up.
+type reportColumn struct {
+	Header string
+	Column api.Column
+	Size   bool // The column holds a size in bytes
+}
+
+// reportBucket is a bucket of the age distribution of data objects,
+// containing the data objects that were modified less than MaxAge ago,
+// but not in a previous bucket. A zero MaxAge matches all remaining objects.
+type reportBucket struct {
+	Label  string
+	MaxAge time.Duration
+}
+
+// reportDefinition describes a canned report. To add a report,
+// add a definition to the reports list.
+type reportDefinition struct {
+	Name    string
+	Short   string
+	Long    string
+	Columns []reportColumn
+	SortBy  string         // Header of the column to sort by, in descending order
+	Top     int            // Default number of rows to output, 0 means all
+	Buckets []reportBucket // If set, the columns are computed per bucket
+}
+
+const (
+	day  = 24 * time.Hour
+	year = 365 * day
+)
+
+var (
+	objectsColumn = reportColumn{Header: "OBJECTS", Column: api.Count(msg.ICAT_COLUMN_D_DATA_ID)}
+	sizeColumn    = reportColumn{Header: "SIZE", Column: api.Sum(msg.ICAT_COLUMN_DATA_SIZE), Size: true}
+)
+
+var reports = []reportDefinition{
+	{
+		Name:  "owners",
+		Short: "Number and total size of data objects per owner",
+		Columns: []reportColumn{
+			{Header: "OWNER", Column: msg.ICAT_COLUMN_D_OWNER_NAME},
+			{Header: "ZONE", Column: msg.ICAT_COLUMN_D_OWNER_ZONE},
+			objectsColumn,
+			sizeColumn,
+		},
+		SortBy: "SIZE",
+	},
+	{
+		Name:  "resources",
+		Short: "Number and total size of replicas per resource",
+		Columns: []reportColumn{
+			{Header: "RESOURCE", Column: msg.ICAT_COLUMN_D_RESC_NAME},
+			objectsColumn,
+			sizeColumn,
+		},
+		SortBy: "SIZE",
+	},
+	{
+		Name:  "collections",
+		Short: "Largest collections by the total size of the data objects they contain",
+		Long:  "Only the data objects directly in a collection are taken into account, not those in its subcollections.",
+		Columns: []reportColumn{
+			{Header: "COLLECTION", Column: msg.ICAT_COLUMN_COLL_NAME},
+			objectsColumn,
+			sizeColumn,
+		},
+		SortBy: "SIZE",
+		Top:    10,
+	},
+	{
+		Name:  "age",
+		Short: "Number and total size of data objects by the time since their last modification",
+		Columns: []reportColumn{
+			objectsColumn,
+			sizeColumn,
+		},
+		Buckets: []reportBucket{
+			{Label: "< 1 month", MaxAge: 30 * day},
+			{Label: "1-6 months", MaxAge: 182 * day},
+			{Label: "6-12 months", MaxAge: year},
+			{Label: "1-3 years", MaxAge: 3 * year},
+			{Label: "> 3 years"},
+		},
+	},
+}
+
+const reportDescription = `Print a report about the data objects in a collection, including all its
+subcollections. If no collection is given, the whole zone is reported.
+All replicas are taken into account. The aggregation is done by the server.`
+
+func (a *App) report() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Print aggregate reports",
+		Long:  reportDescription,
+	}
+
+	for _, def := range reports {
+		cmd.AddCommand(a.reportCommand(def))
+	}
+
+	return cmd
+}
+
+func (a *App) reportCommand(def reportDefinition) *cobra.Command {
+	var (
+		jsonFormat, csvFormat, inBytes bool
+		top                            int
+		sinkName                       string
+	)
+
+	long := def.Short + ".\n\n" + reportDescription
+
+	if def.Long != "" {
+		long += "\n\n" + def.Long
+	}
+
+	cmd := &cobra.Command{
+		Use:               def.Name + " [collection path]",
+		Short:             def.Short,
+		Long:              long,
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			scope := "/" + a.Zone
+
+			if len(args) > 0 {
+				scope = a.Path(args[0])
+			}
+
+			headers, rows, err := a.runReport(cmd.Context(), def, scope, time.Now())
+			if err != nil {
+				return err
+			}
+
+			if top > 0 && len(rows) > top {
+				rows = rows[:top]
+			}
+
+			switch {
+			case sinkName != "":
+				return a.writeReport(cmd.OutOrStdout(), sinkName, headers, rows)
+			case jsonFormat:
+				return json.NewEncoder(cmd.OutOrStdout()).Encode(reportObjects(def, headers, rows))
+			case csvFormat:
+				w := csv.NewWriter(cmd.OutOrStdout())
+
+				w.Write(headers) //nolint:errcheck
+				w.WriteAll(rows) //nolint:errcheck
+
+				return w.Error()
+			}
+
+			printReport(cmd.OutOrStdout(), def, headers, rows, inBytes)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&jsonFormat, "json", false, "Output as JSON")
+	cmd.Flags().BoolVar(&csvFormat, "csv", false, "Output as CSV")
+	cmd.Flags().BoolVarP(&inBytes, "bytes", "b", false, "Print sizes in bytes")
+	cmd.Flags().IntVar(&top, "top", def.Top, "Only output the given number of rows, 0 means all")
+
+	a.addSinkFlag(cmd, &sinkName)
+
+	cmd.MarkFlagsMutuallyExclusive("json", "csv", sinkOption)
+
+	return cmd
+}
+
+// runReport runs the queries of a report for the data objects below scope,
+// and returns the headers and the sorted rows. The values of aggregated
+// columns are returned as decimal integers.
+func (a *App) runReport(ctx context.Context, def reportDefinition, scope string, now time.Time) ([]string, [][]string, error) {
+	headers := make([]string, len(def.Columns))
+
+	for i, col := range def.Columns {
+		headers[i] = col.Header
+	}
+
+	if len(def.Buckets) == 0 {
+		rows, err := a.reportRows(ctx, def.Columns, scope)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if i := slices.Index(headers, def.SortBy); i >= 0 {
+			slices.SortStableFunc(rows, func(x, y []string) int {
+				return cmp.Compare(parseInt(y[i]), parseInt(x[i]))
+			})
+		}
+
+		return headers, rows, nil
+	}
+
+	// The buckets are computed from the totals of the objects modified
+	// after the start of each bucket, by subtracting the previous totals.
+	rows := make([][]string, len(def.Buckets))
+	previous := make([]int64, len(def.Columns))
+
+	for i, bucket := range def.Buckets {
+		var conditions []api.Condition
+
+		if bucket.MaxAge > 0 {
+			conditions = append(conditions, api.After(msg.ICAT_COLUMN_D_MODIFY_TIME, now.Add(-bucket.MaxAge)))
+		}
+
+		totals, err := a.reportRows(ctx, def.Columns, scope, conditions...)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		rows[i] = []string{bucket.Label}
+
+		for j := range def.Columns {
+			var total int64
+
+			if len(totals) > 0 {
+				total = parseInt(totals[0][j])
+			}
+
+			rows[i] = append(rows[i], strconv.FormatInt(total-previous[j], 10))
+			previous[j] = total
+		}
+	}
+
+	return append([]string{"AGE"}, headers...), rows, nil
+}
+
+// reportRows runs a grouped query for the data objects below scope, including
+// those directly in scope, and adds up the aggregates of both queries.
+func (a *App) reportRows(ctx context.Context, columns []reportColumn, scope string, conditions ...api.Condition) ([][]string, error) {
+	var (
+		selects []api.Column
+		groupBy []msg.ColumnNumber
+	)
+
+	for _, col := range columns {
+		selects = append(selects, col.Column)
+
+		if column, ok := col.Column.(msg.ColumnNumber); ok {
+			groupBy = append(groupBy, column)
+		}
+	}
+
+	scopes := []api.Condition{
+		api.Equal(msg.ICAT_COLUMN_COLL_NAME, scope),
+		api.Like(msg.ICAT_COLUMN_COLL_NAME, strings.TrimSuffix(scope, "/")+"/%"),
+	}
+
+	if scope == "/" {
+		scopes = scopes[1:]
+	}
+
+	var (
+		rows  [][]string
+		index = map[string]int{}
+	)
+
+	for _, condition := range scopes {
+		result := a.Query(selects...).GroupBy(groupBy...).With(condition).With(conditions...).Execute(ctx)
+
+		for result.Next() {
+			values := make([]string, len(columns))
+			ptrs := make([]any, len(values))
+
+			for i := range values {
+				ptrs[i] = &values[i]
+			}
+
+			if err := result.Scan(ptrs...); err != nil {
+				result.Close()
+
+				return nil, err
+			}
+
+			var key []string
+
+			for i, col := range columns {
+				if _, ok := col.Column.(msg.ColumnNumber); ok {
+					key = append(key, values[i])
+				}
+			}
+
+			j, ok := index[strings.Join(key, "\x00")]
+			if !ok {
+				index[strings.Join(key, "\x00")] = len(rows)
+				rows = append(rows, values)
+
+				continue
+			}
+
+			for i, col := range columns {
+				if _, ok := col.Column.(msg.ColumnNumber); !ok {
+					rows[j][i] = strconv.FormatInt(parseInt(rows[j][i])+parseInt(values[i]), 10)
+				}
+			}
+		}
+
+		if err := result.Close(); err != nil {
+			return nil, err
+		}
+
+		if err := result.Err(); err != nil {
+			return nil, err
+		}
+	}
+
+	return rows, nil
+}
+
+// writeReport writes the rows of a report to a sink, keyed by the lowercase headers.
+func (a *App) writeReport(w io.Writer, sinkName string, headers []string, rows [][]string) error {
+	columns := make([]string, len(headers))
+
+	for i, header := range headers {
+		columns[i] = strings.ToLower(header)
+	}
+
+	sink, err := a.openSink(sinkName, w, columns)
+	if err != nil {
+		return err
+	}
+
+	for _, values := range rows {
+		row := make(map[string]string, len(columns))
+
+		for i, column := range columns {
+			row[column] = values[i]
+		}
+
+		if err := sink.Write(row); err != nil {
+			return multierr.Append(err, sink.Close())
+		}
+	}
+
+	return sink.Close()
+}
+
+// reportObjects converts the rows of a report to objects for JSON output,
+// keyed by the lowercase headers. Aggregated values are output as numbers.
+func reportObjects(def reportDefinition, headers []string, rows [][]string) []map[string]any {
+	objects := make([]map[string]any, len(rows))
+
+	offset := len(headers) - len(def.Columns)
+
+	for i, row := range rows {
+		objects[i] = map[string]any{}
+
+		for j, header := range headers {
+			var value any = row[j]
+
+			if j >= offset {
+				if _, ok := def.Columns[j-offset].Column.(msg.ColumnNumber); !ok {
+					value = parseInt(row[j])
+				}
+			}
+
+			objects[i][strings.ToLower(header)] = value
+		}
+	}
+
+	return objects
+}
+
+func printReport(w io.Writer, def reportDefinition, headers []string, rows [][]string, inBytes bool) {
+	out := &tabwriter.TabWriter{
+		Writer: w,
+	}
+
+	defer out.Flush()
+
+	Fprintcolorln(out, Bold, strings.Join(headers, "\t"))
+
+	offset := len(headers) - len(def.Columns)
+
+	for _, row := range rows {
+		values := slices.Clone(row)
+
+		for j, col := range def.Columns {
+			if col.Size && !inBytes {
+				values[offset+j] = humanize.Bytes(uint64(parseInt(values[offset+j])))
+			}
+		}
+
+		fmt.Fprintln(out, strings.Join(values, "\t"))
+	}
+}
+
+func parseInt(s string) int64 {
+	i, _ := strconv.ParseInt(s, 10, 64) //nolint:errcheck
+
+	return i
+}
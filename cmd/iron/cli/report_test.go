@@ -0,0 +1,111 @@
+package cli
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func reportResponse(def reportDefinition, values ...[]string) msg.QueryResponse {
+	response := msg.QueryResponse{
+		RowCount:       len(values[0]),
+		AttributeCount: len(values),
+		TotalRowCount:  len(values[0]),
+	}
+
+	for i, column := range values {
+		response.SQLResult = append(response.SQLResult, msg.SQLResult{
+			AttributeIndex: msg.ColumnNumber(def.Columns[i].Column.Int()),
+			ResultLen:      len(column),
+			Values:         column,
+		})
+	}
+
+	return response
+}
+
+func TestReportOwners(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		reportResponse(reports[0], []string{"alice"}, []string{"testzone"}, []string{"2"}, []string{"100"}),
+		reportResponse(reports[0], []string{"alice", "bob"}, []string{"testzone", "testzone"}, []string{"3", "1"}, []string{"50", "500"}),
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"report", "owners", "/testzone/home", "--csv"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "OWNER,ZONE,OBJECTS,SIZE\nbob,testzone,1,500\nalice,testzone,5,150\n"
+
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+
+	app.AddResponses([]any{
+		msg.QueryResponse{},
+		reportResponse(reports[0], []string{"alice", "bob"}, []string{"testzone", "testzone"}, []string{"3", "1"}, []string{"50", "500"}),
+	})
+
+	buf.Reset()
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"report", "owners", "--json", "--top", "1"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	expected = `[{"objects":1,"owner":"bob","size":500,"zone":"testzone"}]` + "\n"
+
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestReportAge(t *testing.T) {
+	app := testApp(t)
+
+	// Cumulative totals of the objects modified after the start of each bucket
+	for _, totals := range [][2]string{{"1", "10"}, {"3", "30"}, {"3", "30"}, {"6", "1000"}, {"10", "2000"}} {
+		app.AddResponse(reportResponse(reports[3], []string{totals[0]}, []string{totals[1]}))
+	}
+
+	def := reports[3]
+
+	headers, rows, err := app.runReport(t.Context(), def, "/", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := [][]string{
+		{"< 1 month", "1", "10"},
+		{"1-6 months", "2", "20"},
+		{"6-12 months", "0", "0"},
+		{"1-3 years", "3", "970"},
+		{"> 3 years", "4", "1000"},
+	}
+
+	if !slices.Equal(headers, []string{"AGE", "OBJECTS", "SIZE"}) || !slices.EqualFunc(rows, expected, slices.Equal) {
+		t.Fatalf("unexpected report: %v %v", headers, rows)
+	}
+
+	var buf bytes.Buffer
+
+	printReport(&buf, def, headers, rows, false)
+
+	if !strings.Contains(buf.String(), "1.0 kB") {
+		t.Fatalf("expected human readable sizes, got %s", buf.String())
+	}
+}
@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/spf13/cobra"
+)
+
+func (a *App) share() *cobra.Command {
+	var (
+		expires string
+		uses    int
+	)
+
+	cmd := &cobra.Command{
+		Use:               "share <path>",
+		Short:             "Create a link to share a data object or collection",
+		Long:              "Create a read ticket for a data object or collection and print the URL on the web gateway that embeds the ticket, so that it can be sent to someone without an account. The URL is constructed from the iron_url_template setting in the irods environment file. The ticket can be restricted in time and in number of uses.",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			opts := api.ShareOptions{
+				Uses: uses,
+			}
+
+			if expires != "" {
+				d, err := parseExpiry(expires)
+				if err != nil {
+					return err
+				}
+
+				opts.Expires = time.Now().Add(d)
+			}
+
+			link, err := a.ShareLink(cmd.Context(), a.Path(args[0]), opts)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), link)
+
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&expires, "expires", "", "Expire the link after the given duration, e.g. 12h or 7d")
+	cmd.Flags().IntVar(&uses, "uses", 0, "Maximum number of times the link can be used, unlimited if zero")
+
+	return cmd
+}
+
+// parseExpiry parses a duration, that in addition to time.ParseDuration
+// accepts a number of days with a d suffix.
+func parseExpiry(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid expiry %q", s)
+		}
+
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+
+	d, err := time.ParseDuration(s)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("invalid expiry %q", s)
+	}
+
+	return d, nil
+}
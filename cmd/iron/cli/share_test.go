@@ -0,0 +1,69 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron"
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestShare(t *testing.T) {
+	app := testApp(t)
+
+	env := app.Client.Env()
+	env.URLTemplate = "https://data.example.org{path}"
+
+	var err error
+
+	app.Client, err = iron.New(t.Context(), env, iron.Option{
+		HandshakeFunc: func(ctx context.Context) (iron.Conn, error) {
+			return app.mockConn, nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	app.AddResponses([]any{msg.EmptyResponse{}, msg.EmptyResponse{}, msg.EmptyResponse{}})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"share", "/testzone/home/file", "--expires", "7d", "--uses", "10"})
+	cmd.SetOut(&buf)
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.HasPrefix(buf.String(), "https://data.example.org/testzone/home/file?ticket=") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+}
+
+func TestParseExpiry(t *testing.T) {
+	for s, expected := range map[string]time.Duration{
+		"7d":  7 * 24 * time.Hour,
+		"12h": 12 * time.Hour,
+		"30m": 30 * time.Minute,
+	} {
+		d, err := parseExpiry(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if d != expected {
+			t.Errorf("expected %s for %q, got %s", expected, s, d)
+		}
+	}
+
+	for _, s := range []string{"", "d", "-1d", "abc", "-1h"} {
+		if _, err := parseExpiry(s); err == nil {
+			t.Errorf("expected error for %q", s)
+		}
+	}
+}
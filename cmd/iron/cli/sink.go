@@ -0,0 +1,167 @@
+package cli
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/spf13/cobra"
+	"go.uber.org/multierr"
+)
+
+// Sink receives the rows that are output by the query, find and report commands,
+// e.g. to feed catalog data into an analytics pipeline. Each row maps the names
+// of the columns to their values.
+type Sink interface {
+	Write(row map[string]string) error
+	Close() error
+}
+
+// SinkFactory creates a Sink that writes to w. The names of the columns
+// are passed in the order in which they are output.
+type SinkFactory func(w io.Writer, columns []string) (Sink, error)
+
+// WithSink registers a Sink under the given name, so that it can be selected
+// using the --sink flag. The ndjson and csv sinks are built in. Sinks for formats
+// that pull in heavier dependencies, such as parquet, are best registered from
+// a file behind a build tag in the main package.
+func WithSink(name string, factory SinkFactory) Option {
+	return func(a *App) {
+		a.sinks[name] = factory
+	}
+}
+
+// defaultSinks returns the built-in sinks
+func defaultSinks() map[string]SinkFactory {
+	return map[string]SinkFactory{
+		"ndjson": newNDJSONSink,
+		"csv":    newCSVSink,
+	}
+}
+
+const sinkOption = "sink"
+
+var ErrUnknownSink = errors.New("unknown sink")
+
+// addSinkFlag adds the --sink flag to a command that outputs rows.
+func (a *App) addSinkFlag(cmd *cobra.Command, name *string) {
+	names := slices.Sorted(maps.Keys(a.sinks))
+
+	cmd.Flags().StringVar(name, sinkOption, "", "Write the rows to the given sink, one of: "+strings.Join(names, ", "))
+}
+
+// openSink creates the sink with the given name.
+func (a *App) openSink(name string, w io.Writer, columns []string) (Sink, error) {
+	factory, ok := a.sinks[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownSink, name)
+	}
+
+	return factory(w, columns)
+}
+
+type ndjsonSink struct {
+	encoder *json.Encoder
+}
+
+func newNDJSONSink(w io.Writer, _ []string) (Sink, error) {
+	return &ndjsonSink{
+		encoder: json.NewEncoder(w),
+	}, nil
+}
+
+func (s *ndjsonSink) Write(row map[string]string) error {
+	return s.encoder.Encode(row)
+}
+
+func (s *ndjsonSink) Close() error {
+	return nil
+}
+
+type csvSink struct {
+	writer  *csv.Writer
+	columns []string
+}
+
+func newCSVSink(w io.Writer, columns []string) (Sink, error) {
+	s := &csvSink{
+		writer:  csv.NewWriter(w),
+		columns: columns,
+	}
+
+	return s, s.writer.Write(columns)
+}
+
+func (s *csvSink) Write(row map[string]string) error {
+	values := make([]string, len(s.columns))
+
+	for i, column := range s.columns {
+		values[i] = row[column]
+	}
+
+	return s.writer.Write(values)
+}
+
+func (s *csvSink) Close() error {
+	s.writer.Flush()
+
+	return s.writer.Error()
+}
+
+// recordColumns are the columns of the rows that SinkPrinter writes
+var recordColumns = []string{"path", "type", "size", "modified", "owner", "checksum"}
+
+// SinkPrinter is a Printer that writes the printed records to a Sink.
+// As a Printer can't return errors, the first error is kept and
+// returned by Err after Flush has been called.
+type SinkPrinter struct {
+	Sink Sink
+	err  error
+}
+
+func (sp *SinkPrinter) Setup(_, _, _ bool) {}
+
+func (sp *SinkPrinter) Print(name string, i api.Record) {
+	if sp.err != nil {
+		return
+	}
+
+	row := map[string]string{
+		"path":     name,
+		"type":     "data_object",
+		"size":     strconv.FormatInt(i.Size(), 10),
+		"modified": i.ModTime().Format(time.RFC3339),
+	}
+
+	switch v := i.Sys().(type) {
+	case *api.DataObject:
+		if len(v.Replicas) > 0 {
+			row["owner"] = v.Replicas[0].Owner
+			row["checksum"] = parseIrodsChecksum(v.Replicas[0].Checksum)
+		}
+	case *api.Collection:
+		row["type"] = "collection"
+		row["owner"] = v.Owner
+	}
+
+	sp.err = sp.Sink.Write(row)
+}
+
+func (sp *SinkPrinter) PrintProperties(_ []Property, _ []api.Metadata) {}
+
+func (sp *SinkPrinter) Flush() {
+	sp.err = multierr.Append(sp.err, sp.Sink.Close())
+}
+
+// Err returns the first error that occurred while writing to the sink.
+func (sp *SinkPrinter) Err() error {
+	return sp.err
+}
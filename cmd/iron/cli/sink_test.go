@@ -0,0 +1,176 @@
+package cli
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+)
+
+type recordingSink struct {
+	columns []string
+	rows    []map[string]string
+	closed  bool
+}
+
+func (s *recordingSink) Write(row map[string]string) error {
+	s.rows = append(s.rows, row)
+
+	return nil
+}
+
+func (s *recordingSink) Close() error {
+	s.closed = true
+
+	return nil
+}
+
+func TestFindSink(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		expiredResponse,
+		msg.QueryResponse{},
+	})
+
+	var buf bytes.Buffer
+
+	cmd := app.Command()
+	cmd.SetOut(&buf)
+	cmd.SetArgs([]string{"find", "--expired", "--sink", "csv", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 2 || lines[0] != "path,type,size,modified,owner,checksum" || !strings.HasPrefix(lines[1], "/testzone/coll/expired,data_object,1024,") {
+		t.Fatalf("unexpected output: %q", buf.String())
+	}
+
+	cmd = app.Command()
+	cmd.SetArgs([]string{"find", "--sink", "parquet", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); !errors.Is(err, ErrUnknownSink) {
+		t.Fatalf("expected ErrUnknownSink, got %v", err)
+	}
+}
+
+func TestReportSink(t *testing.T) {
+	app := testApp(t)
+
+	sink := &recordingSink{}
+
+	WithSink("test", func(_ io.Writer, columns []string) (Sink, error) {
+		sink.columns = columns
+
+		return sink, nil
+	})(app.App)
+
+	app.AddResponses([]any{
+		reportResponse(reports[1], []string{"demoResc", "archive"}, []string{"3", "1"}, []string{"50", "500"}),
+	})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"report", "resources", "/", "--sink", "test"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !sink.closed || strings.Join(sink.columns, ",") != "resource,objects,size" {
+		t.Fatalf("unexpected sink state: %v", sink)
+	}
+
+	if len(sink.rows) != 2 || sink.rows[0]["resource"] != "archive" || sink.rows[1]["objects"] != "3" {
+		t.Fatalf("unexpected rows: %v", sink.rows)
+	}
+}
+
+func TestNDJSONSink(t *testing.T) {
+	var buf bytes.Buffer
+
+	sink, err := newNDJSONSink(&buf, []string{"a", "b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, row := range []map[string]string{{"a": "1", "b": "x"}, {"a": "2", "b": "y"}} {
+		if err := sink.Write(row); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{"a":"1","b":"x"}` + "\n" + `{"a":"2","b":"y"}` + "\n"
+
+	if buf.String() != expected {
+		t.Fatalf("expected %q, got %q", expected, buf.String())
+	}
+}
+
+func TestQuerySink(t *testing.T) {
+	app := testApp(t)
+
+	sink := &recordingSink{}
+
+	WithSink("test", func(_ io.Writer, columns []string) (Sink, error) {
+		sink.columns = columns
+
+		return sink, nil
+	})(app.App)
+
+	app.AddResponses([]any{
+		msg.QueryResponse{
+			RowCount:       2,
+			AttributeCount: 2,
+			TotalRowCount:  2,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 403, ResultLen: 2, Values: []string{"a", "b"}},
+				{AttributeIndex: 407, ResultLen: 2, Values: []string{"1", "2"}},
+			},
+		},
+	})
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"query", "select DATA_NAME, DATA_SIZE where COLL_NAME = '/testzone/coll'", "--sink", "test"})
+
+	if err := cmd.ExecuteContext(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if !sink.closed || strings.Join(sink.columns, ",") != "DATA_NAME,DATA_SIZE" {
+		t.Fatalf("unexpected sink state: %v", sink)
+	}
+
+	if len(sink.rows) != 2 || sink.rows[0]["DATA_NAME"] != "a" || sink.rows[1]["DATA_SIZE"] != "2" {
+		t.Fatalf("unexpected rows: %v", sink.rows)
+	}
+}
+
+func TestSinkPrinterNoReplicas(t *testing.T) {
+	sink := &recordingSink{}
+	printer := &SinkPrinter{Sink: sink}
+
+	printer.Print("/testzone/coll/file", &testRecord{
+		FileInfo: &api.DataObject{Path: "/testzone/coll/file"},
+	})
+
+	printer.Flush()
+
+	if err := printer.Err(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(sink.rows) != 1 || sink.rows[0]["owner"] != "" || sink.rows[0]["type"] != "data_object" {
+		t.Fatalf("unexpected rows: %v", sink.rows)
+	}
+}
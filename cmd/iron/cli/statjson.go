@@ -0,0 +1,169 @@
+package cli
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/kuleuven/iron/api"
+)
+
+// StatSchemaVersion is the version of the JSON output of stat, see statJSON.
+// It is incremented whenever a field is removed, renamed or changes type;
+// fields may be added without changing the version.
+const StatSchemaVersion = 1
+
+// statJSON is the JSON output of stat --json for a collection or data object.
+// All timestamps are formatted as RFC 3339 in the local time zone.
+// The ls and find commands do not use this schema, their --json output
+// has one unversioned object per line, see JSONPrinter.
+type statJSON struct {
+	// SchemaVersion is StatSchemaVersion
+	SchemaVersion int `json:"schema_version"`
+	// Type is either "collection" or "data_object"
+	Type string `json:"type"`
+	// Name is the absolute path of the collection or data object
+	Name string `json:"name"`
+	// ID is the identifier in the catalog
+	ID int64 `json:"id"`
+	// Size is the size of a data object, or the total size of the
+	// data objects directly in a collection
+	Size int64 `json:"size"`
+	// Modified is the modification time
+	Modified string `json:"modified"`
+	// Creator is the owner of the collection or of the first replica
+	Creator string `json:"creator"`
+	// Metadata lists the AVUs, it is an empty array if there are none
+	Metadata []statMetadata `json:"metadata"`
+	// ACL lists the access permissions, it is an empty array if there are none
+	ACL []statAccess `json:"acl"`
+
+	// Fields that are only present for data objects
+	*statDataObjectJSON
+}
+
+type statDataObjectJSON struct {
+	// Checksum is the hex encoded sha256 checksum of the first replica,
+	// or an empty string if it has no (sha256) checksum
+	Checksum string `json:"checksum"`
+	// Comment is the comment of the data object, or an empty string
+	Comment string `json:"comment"`
+	// Expiry is the expiry of the data object, or null if it is not set
+	Expiry *string `json:"expiry"`
+	// Lock is the lock state, only present if --lock is passed
+	Lock *string `json:"lock,omitempty"`
+	// Replicas lists the checksums of all replicas, only present if --replicas is passed
+	Replicas []statReplica `json:"replicas,omitempty"`
+	// Divergent tells whether the replicas have different checksums, only present if --replicas is passed
+	Divergent *bool `json:"divergent,omitempty"`
+	// Physical lists the physical location of all replicas, only present if --physical is passed
+	Physical []statPhysicalReplica `json:"physical,omitempty"`
+}
+
+type statMetadata struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	Units string `json:"units"`
+}
+
+type statAccess struct {
+	// User is the name of the user or group
+	User string `json:"user"`
+	Zone string `json:"zone"`
+	// Type is the user type, e.g. "rodsuser" or "rodsgroup"
+	Type       string `json:"type"`
+	Permission string `json:"permission"`
+}
+
+type statReplica struct {
+	// Checksum is the hex encoded sha256 checksum
+	Checksum string `json:"checksum"`
+	Number   int    `json:"number"`
+	Resource string `json:"resource"`
+}
+
+type statPhysicalReplica struct {
+	Host     string `json:"host"`
+	Number   int    `json:"number"`
+	Path     string `json:"path"`
+	Resource string `json:"resource"`
+}
+
+// toStatJSON converts the record at the given path to its stat --json representation
+func toStatJSON(name string, record api.Record) *statJSON {
+	s := &statJSON{
+		SchemaVersion: StatSchemaVersion,
+		Type:          "collection",
+		Name:          name,
+		Size:          record.Size(),
+		Modified:      formatStatTime(record.ModTime()),
+		Metadata:      []statMetadata{},
+		ACL:           []statAccess{},
+	}
+
+	for _, m := range record.Metadata() {
+		s.Metadata = append(s.Metadata, statMetadata{m.Name, m.Value, m.Units})
+	}
+
+	for _, a := range record.Access() {
+		s.ACL = append(s.ACL, statAccess{a.User.Name, a.User.Zone, a.User.Type, a.Permission})
+	}
+
+	switch v := record.Sys().(type) {
+	case *api.Collection:
+		s.ID = v.ID
+		s.Creator = v.Owner
+	case *api.DataObject:
+		s.Type = "data_object"
+		s.ID = v.ID
+		s.statDataObjectJSON = &statDataObjectJSON{}
+
+		if len(v.Replicas) > 0 {
+			s.Creator = v.Replicas[0].Owner
+			s.Checksum = parseIrodsChecksum(v.Replicas[0].Checksum)
+		}
+	}
+
+	d, ok := record.(*dataObjectRecord)
+	if !ok || s.statDataObjectJSON == nil {
+		return s
+	}
+
+	s.Comment = d.Comment
+
+	if !d.Expiry.IsZero() {
+		expiry := formatStatTime(d.Expiry)
+		s.Expiry = &expiry
+	}
+
+	if d.Lock != nil {
+		lock := d.Lock.String()
+		s.Lock = &lock
+	}
+
+	if d.Replicas != nil {
+		divergent := d.Divergent()
+		s.Divergent = &divergent
+		s.Replicas = []statReplica{}
+
+		for _, r := range d.Replicas {
+			s.Replicas = append(s.Replicas, statReplica{hex.EncodeToString(r.Checksum), r.Number, r.ResourceHierarchy})
+		}
+	}
+
+	for _, r := range d.Physical {
+		s.Physical = append(s.Physical, statPhysicalReplica{r.Host, r.Number, r.Path, r.Resource})
+	}
+
+	return s
+}
+
+func formatStatTime(t time.Time) string {
+	return t.Format(time.RFC3339)
+}
+
+// writeStatJSON writes the stat --json output of the record at the given path
+func writeStatJSON(w io.Writer, name string, record api.Record) error {
+	return json.NewEncoder(w).Encode(toStatJSON(name, record))
+}
@@ -0,0 +1,118 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron/api"
+)
+
+var updateGolden = flag.Bool("update", false, "update the golden files in testdata")
+
+// testRecord is an api.Record with fixed metadata and access lists
+type testRecord struct {
+	os.FileInfo
+	metadata []api.Metadata
+	access   []api.Access
+}
+
+func (r *testRecord) Metadata() []api.Metadata {
+	return r.metadata
+}
+
+func (r *testRecord) Access() []api.Access {
+	return r.access
+}
+
+func (r *testRecord) Type() api.ObjectType {
+	if r.IsDir() {
+		return api.CollectionType
+	}
+
+	return api.DataObjectType
+}
+
+func TestStatJSONGolden(t *testing.T) {
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.FixedZone("CEST", 2*3600))
+
+	obj := &api.DataObject{
+		ID:   10001,
+		Path: "/testzone/home/rods/file.txt",
+		Replicas: []api.Replica{
+			{
+				Number:            0,
+				Owner:             "rods",
+				OwnerZone:         "testzone",
+				Checksum:          "sha2:n4bQgYhMfWWaL+qgxVrQFaO/TxsrC4Is0V1sFbDwCgg=",
+				Status:            "1",
+				Size:              4,
+				ResourceHierarchy: "demoResc",
+				ModifiedAt:        modified,
+			},
+		},
+	}
+
+	lock := api.Unlocked
+
+	for _, test := range []struct {
+		golden string
+		name   string
+		record api.Record
+	}{
+		{"stat_collection.json", "/testzone/home/rods", &testRecord{
+			FileInfo: &api.Collection{
+				ID:         10000,
+				Path:       "/testzone/home/rods",
+				Owner:      "rods",
+				ModifiedAt: modified,
+			},
+			access: []api.Access{
+				{User: api.User{Name: "rods", Zone: "testzone", Type: "rodsadmin"}, Permission: "own"},
+			},
+		}},
+		{"stat_data_object.json", obj.Path, &dataObjectRecord{
+			Record: &testRecord{
+				FileInfo: obj,
+				metadata: []api.Metadata{
+					{Name: "project", Value: "iron", Units: ""},
+				},
+			},
+			Comment: "reviewed",
+			Expiry:  time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+			Lock:    &lock,
+			Replicas: []api.ReplicaChecksum{
+				{Number: 0, ResourceHierarchy: "demoResc", Checksum: []byte{0x9f, 0x86}},
+			},
+			Physical: []physicalReplica{
+				{Number: 0, Resource: "demoResc", Host: "storage1.example.org", Path: "/var/lib/irods/Vault/home/rods/file.txt"},
+			},
+		}},
+	} {
+		var buf bytes.Buffer
+
+		if err := writeStatJSON(&buf, test.name, test.record); err != nil {
+			t.Fatal(err)
+		}
+
+		path := filepath.Join("testdata", test.golden)
+
+		if *updateGolden {
+			if err := os.WriteFile(path, buf.Bytes(), 0o600); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		expected, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(buf.Bytes(), expected) {
+			t.Errorf("the output of stat --json changed, update StatSchemaVersion if the change is incompatible, and run go test -update to update %s.\nexpected: %s\ngot:      %s", path, expected, buf.Bytes())
+		}
+	}
+}
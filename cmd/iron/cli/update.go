@@ -11,7 +11,6 @@ import (
 
 	"github.com/Masterminds/semver/v3"
 	"github.com/creativeprojects/go-selfupdate"
-	"github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 )
 
@@ -44,7 +43,7 @@ func (a *App) CheckUpdate(ctx context.Context) {
 
 	latest, err := a.LatestVersion(ctx)
 	if err != nil {
-		logrus.Debugf("failed to check for updates: %s", err)
+		logger.Debugf("failed to check for updates: %s", err)
 
 		return
 	}
@@ -55,7 +54,7 @@ func (a *App) CheckUpdate(ctx context.Context) {
 		return
 	}
 
-	logrus.Infof("Currently running version %s of %s. Version %s has been released and is available for installation. Please update with `%s update`.", current, a.name, latest, a.name)
+	logger.Infof("Currently running version %s of %s. Version %s has been released and is available for installation. Please update with `%s update`.", current, a.name, latest, a.name)
 }
 
 func (a *App) LatestVersion(ctx context.Context) (*semver.Version, error) {
@@ -90,9 +89,9 @@ func (a *App) LatestVersion(ctx context.Context) (*semver.Version, error) {
 
 	// Write to cache file
 	if err := os.MkdirAll(cacheDir, 0o755); err != nil {
-		logrus.Debugf("failed to create dir %s: %s", cacheDir, err)
+		logger.Debugf("failed to create dir %s: %s", cacheDir, err)
 	} else if err := os.WriteFile(releaseFile, []byte(latest.Version()), 0o600); err != nil {
-		logrus.Debugf("failed to write %s: %s", releaseFile, err)
+		logger.Debugf("failed to write %s: %s", releaseFile, err)
 	}
 
 	return semver.NewVersion(latest.Version())
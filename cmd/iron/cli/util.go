@@ -15,6 +15,10 @@ func (a *App) PathIn(path, workdir string) string {
 		return workdir
 	}
 
+	if strings.HasPrefix(path, "~") {
+		path = a.expandHome(path)
+	}
+
 	if !strings.HasPrefix(path, "/") {
 		path = "/" + path
 
@@ -51,6 +55,47 @@ func (a *App) PathIn(path, workdir string) string {
 	return "/" + strings.Join(kept, "/")
 }
 
+// expandHome replaces a leading ~ by the home collection of the current user,
+// and a leading ~user or ~user#zone by the home collection of the given user.
+func (a *App) expandHome(path string) string {
+	user, rest, _ := strings.Cut(path[1:], "/")
+
+	zone := a.Zone
+
+	if user == "" {
+		user = a.Username
+	} else if u, z, ok := strings.Cut(user, "#"); ok {
+		user, zone = u, z
+	}
+
+	home := "/" + zone + "/home/" + user
+
+	if rest == "" {
+		return home
+	}
+
+	return home + "/" + rest
+}
+
+// isProtected reports whether the path is the root collection, a zone, the home or
+// trash collection of a zone, or the home or trash collection of the current user.
+func (a *App) isProtected(path string) bool {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+
+	switch len(parts) {
+	case 1:
+		return true
+	case 2:
+		return parts[1] == "home" || parts[1] == "trash"
+	case 3:
+		return parts[1] == "trash" && parts[2] == "home" || parts[0] == a.Zone && parts[1] == "home" && parts[2] == a.Username
+	case 4:
+		return parts[0] == a.Zone && parts[1] == "trash" && parts[2] == "home" && parts[3] == a.Username
+	default:
+		return false
+	}
+}
+
 func Name(path string) string {
 	_, name := api.Split(path)
 
@@ -0,0 +1,295 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"io"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+	"github.com/spf13/cobra"
+)
+
+// watcher keeps track of the data objects and collections in a collection tree,
+// so that changes in the catalog can be reported incrementally.
+type watcher struct {
+	*api.API
+	Root   string
+	Writer io.Writer
+
+	known       map[int64]watchedObject
+	collections map[string]bool
+	since       time.Time
+}
+
+type watchedObject struct {
+	Path    string
+	ModTime time.Time
+}
+
+// filters returns the conditions that select the collection tree rooted at the given path.
+// Each condition needs to be queried separately, as only one condition per column is supported.
+func filters(root string) []api.Condition {
+	return []api.Condition{
+		api.Equal(msg.ICAT_COLUMN_COLL_NAME, root),
+		api.Like(msg.ICAT_COLUMN_COLL_NAME, strings.TrimSuffix(root, "/")+"/%"),
+	}
+}
+
+// scan adds the data objects in the collection tree rooted at the given path to found,
+// unless they are already there, and adds its collections to the known collections.
+func (w *watcher) scan(ctx context.Context, root string, found map[int64]*api.DataObject) error {
+	for _, filter := range filters(root) {
+		objs, err := w.ListDataObjects(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		for i := range objs {
+			if _, ok := found[objs[i].ID]; !ok {
+				found[objs[i].ID] = &objs[i]
+			}
+		}
+	}
+
+	for _, filter := range filters(root) {
+		colls, err := w.ListCollections(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		for i := range colls {
+			w.collections[colls[i].Path] = true
+
+			w.advance(colls[i].ModTime())
+		}
+	}
+
+	return nil
+}
+
+// rescan lists the data objects and subcollections directly in a collection that was modified.
+// Data objects that are found are added to found. Known data objects that are no longer in the
+// collection, or in one of its former subcollections, are added to vanished.
+func (w *watcher) rescan(ctx context.Context, coll string, found map[int64]*api.DataObject, vanished map[int64]bool) error {
+	objs, err := w.ListDataObjectsInCollection(ctx, coll)
+	if err != nil {
+		return err
+	}
+
+	present := map[int64]bool{}
+
+	for i := range objs {
+		present[objs[i].ID] = true
+
+		if _, ok := found[objs[i].ID]; !ok {
+			found[objs[i].ID] = &objs[i]
+		}
+	}
+
+	for id, obj := range w.known {
+		if path.Dir(obj.Path) == coll && !present[id] {
+			vanished[id] = true
+		}
+	}
+
+	subs, err := w.ListSubCollections(ctx, coll)
+	if err != nil {
+		return err
+	}
+
+	current := map[string]bool{}
+
+	for _, sub := range subs {
+		current[sub.Path] = true
+
+		// A collection that was created or moved here, its contents are new at this path
+		if !w.collections[sub.Path] {
+			if err := w.scan(ctx, sub.Path, found); err != nil {
+				return err
+			}
+		}
+	}
+
+	for sub := range w.collections {
+		if path.Dir(sub) != coll || current[sub] {
+			continue
+		}
+
+		// A collection that was removed or moved away, with all of its contents
+		for id, obj := range w.known {
+			if api.IsSubPath(obj.Path, sub) {
+				vanished[id] = true
+			}
+		}
+
+		for c := range w.collections {
+			if c == sub || api.IsSubPath(c, sub) {
+				delete(w.collections, c)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (w *watcher) track(obj *api.DataObject) {
+	w.known[obj.ID] = watchedObject{
+		Path:    obj.Path,
+		ModTime: obj.ModTime(),
+	}
+
+	w.advance(obj.ModTime())
+}
+
+func (w *watcher) advance(t time.Time) {
+	if w.since.Before(t) {
+		w.since = t
+	}
+}
+
+// Init lists the current contents of the collection tree, without reporting them.
+func (w *watcher) Init(ctx context.Context) error {
+	w.known = map[int64]watchedObject{}
+	w.collections = map[string]bool{}
+
+	found := map[int64]*api.DataObject{}
+
+	if err := w.scan(ctx, w.Root, found); err != nil {
+		return err
+	}
+
+	for _, obj := range found {
+		w.track(obj)
+	}
+
+	return nil
+}
+
+// Poll reports the data objects that were added, modified, renamed or removed since the last poll.
+// Only the data objects and collections that were modified since the most recent modification time
+// seen so far are retrieved, to avoid listing the complete collection tree on each poll. The contents
+// of the modified collections are compared to the known contents to find data objects that were
+// removed or moved. A data object that disappears from one path and shows up at another one, with
+// the same identifier, is reported as renamed. The catalog times are used rather than the local
+// clock, so that clock skew cannot cause changes to be missed.
+func (w *watcher) Poll(ctx context.Context) error {
+	// Include the last second seen, as other changes might have happened within the same second
+	records, err := w.ListModifiedSince(ctx, w.Root, w.since.Add(-time.Second))
+	if err != nil {
+		return err
+	}
+
+	found := map[int64]*api.DataObject{}
+	vanished := map[int64]bool{}
+
+	for _, record := range records {
+		switch v := record.Sys().(type) {
+		case *api.DataObject:
+			found[v.ID] = v
+		case *api.Collection:
+			w.advance(v.ModTime())
+
+			if !w.collections[v.Path] {
+				err = w.scan(ctx, v.Path, found)
+			} else {
+				err = w.rescan(ctx, v.Path, found, vanished)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	objs := slices.SortedFunc(maps.Values(found), func(a, b *api.DataObject) int {
+		return strings.Compare(a.Path, b.Path)
+	})
+
+	for _, obj := range objs {
+		delete(vanished, obj.ID)
+
+		prev, ok := w.known[obj.ID]
+
+		switch {
+		case !ok:
+			w.report(Green, "+", obj.Path, "added")
+		case prev.Path != obj.Path:
+			w.report(Yellow, "~", obj.Path, "renamed from "+prev.Path)
+		case obj.ModTime().After(prev.ModTime):
+			w.report(Yellow, "~", obj.Path, "modified")
+		default:
+			continue
+		}
+
+		w.track(obj)
+	}
+
+	for _, id := range slices.Sorted(maps.Keys(vanished)) {
+		w.report(Red, "-", w.known[id].Path, "removed")
+
+		delete(w.known, id)
+	}
+
+	return nil
+}
+
+func (w *watcher) report(color, prefix, path, reason string) {
+	Fprintcolorln(w.Writer, color, time.Now().Format(time.DateTime)+" "+prefix+" "+path+" ("+reason+")")
+}
+
+func (a *App) watch() *cobra.Command {
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:               "watch [collection path]",
+		Short:             "Report changes to the data objects in a collection",
+		Long:              "Watch a collection and its subcollections, and report data objects that are added, modified, renamed or removed, until interrupted. The catalog is polled at the given interval, only retrieving the data objects and collections that were modified since the previous poll. If no collection is given, the current working directory is watched.",
+		Args:              cobra.MaximumNArgs(1),
+		ValidArgsFunction: a.CompleteArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if interval <= 0 {
+				return errors.New("interval must be positive")
+			}
+
+			if len(args) == 0 {
+				args = []string{"."}
+			}
+
+			w := &watcher{
+				API:    a.API,
+				Root:   a.Path(args[0]),
+				Writer: cmd.OutOrStdout(),
+			}
+
+			if err := w.Init(cmd.Context()); err != nil {
+				return err
+			}
+
+			ticker := time.NewTicker(interval)
+
+			defer ticker.Stop()
+
+			for {
+				select {
+				case <-cmd.Context().Done():
+					return nil
+				case <-ticker.C:
+				}
+
+				if err := w.Poll(cmd.Context()); err != nil {
+					return err
+				}
+			}
+		},
+	}
+
+	cmd.Flags().DurationVar(&interval, "interval", 10*time.Second, "Interval between polls")
+
+	return cmd
+}
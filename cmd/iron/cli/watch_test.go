@@ -0,0 +1,142 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func repeat(value string, n int) []string {
+	values := make([]string, n)
+
+	for i := range values {
+		values[i] = value
+	}
+
+	return values
+}
+
+func watchResponse(coll string, ids, names, modTimes []string) msg.QueryResponse {
+	n := len(ids)
+
+	return msg.QueryResponse{
+		RowCount:       n,
+		AttributeCount: 16,
+		TotalRowCount:  n,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 401, ResultLen: n, Values: ids},
+			{AttributeIndex: 501, ResultLen: n, Values: repeat(coll, n)},
+			{AttributeIndex: 403, ResultLen: n, Values: names},
+			{AttributeIndex: 500, ResultLen: n, Values: repeat("1", n)},
+			{AttributeIndex: 406, ResultLen: n, Values: repeat("generic", n)},
+			{AttributeIndex: 404, ResultLen: n, Values: repeat("0", n)},
+			{AttributeIndex: 407, ResultLen: n, Values: repeat("1024", n)},
+			{AttributeIndex: 411, ResultLen: n, Values: repeat("rods", n)},
+			{AttributeIndex: 412, ResultLen: n, Values: repeat("testzone", n)},
+			{AttributeIndex: 415, ResultLen: n, Values: repeat("", n)},
+			{AttributeIndex: 413, ResultLen: n, Values: repeat("1", n)},
+			{AttributeIndex: 409, ResultLen: n, Values: repeat("demoResc", n)},
+			{AttributeIndex: 410, ResultLen: n, Values: repeat("/path", n)},
+			{AttributeIndex: 422, ResultLen: n, Values: repeat("demoResc", n)},
+			{AttributeIndex: 419, ResultLen: n, Values: repeat("10000", n)},
+			{AttributeIndex: 420, ResultLen: n, Values: modTimes},
+		},
+	}
+}
+
+func watchCollections(paths []string, modTime string) msg.QueryResponse {
+	n := len(paths)
+
+	return msg.QueryResponse{
+		RowCount:       n,
+		AttributeCount: 7,
+		TotalRowCount:  n,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 500, ResultLen: n, Values: repeat("1", n)},
+			{AttributeIndex: 501, ResultLen: n, Values: paths},
+			{AttributeIndex: 503, ResultLen: n, Values: repeat("rods", n)},
+			{AttributeIndex: 504, ResultLen: n, Values: repeat("testzone", n)},
+			{AttributeIndex: 508, ResultLen: n, Values: repeat("10000", n)},
+			{AttributeIndex: 509, ResultLen: n, Values: repeat(modTime, n)},
+			{AttributeIndex: 506, ResultLen: n, Values: repeat("0", n)},
+		},
+	}
+}
+
+func TestWatcher(t *testing.T) {
+	app := testApp(t)
+
+	app.AddResponses([]any{
+		// Init: data objects and collections in the tree
+		watchResponse("/testzone/coll", []string{"1", "2", "3"}, []string{"a", "b", "c"}, repeat("10000", 3)),
+		watchResponse("/testzone/coll/sub", []string{"5"}, []string{"f"}, []string{"10000"}),
+		watchCollections([]string{"/testzone/coll"}, "10000"),
+		watchCollections([]string{"/testzone/coll/sub"}, "10000"),
+		// Poll: modified collections and replicas
+		watchCollections([]string{"/testzone/coll"}, "20000"),
+		watchResponse("/testzone/coll", []string{"2", "4"}, []string{"b", "e"}, repeat("20000", 2)),
+		msg.QueryResponse{},
+		msg.QueryResponse{},
+		// Poll: contents of the modified collection, c was renamed to d and sub to sub2
+		watchResponse("/testzone/coll", []string{"2", "3", "4"}, []string{"b", "d", "e"}, []string{"20000", "10000", "20000"}),
+		watchCollections([]string{"/testzone/coll/sub2"}, "10000"),
+		// Poll: contents of the new collection
+		watchResponse("/testzone/coll/sub2", []string{"5"}, []string{"f"}, []string{"10000"}),
+		msg.QueryResponse{},
+		watchCollections([]string{"/testzone/coll/sub2"}, "10000"),
+		msg.QueryResponse{},
+	})
+
+	var buf bytes.Buffer
+
+	w := &watcher{
+		API:    app.App.API,
+		Root:   "/testzone/coll",
+		Writer: &buf,
+	}
+
+	if err := w.Init(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	if buf.Len() > 0 {
+		t.Fatalf("expected no output after init, got %q", buf.String())
+	}
+
+	if err := w.Poll(t.Context()); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		"~ /testzone/coll/b (modified)",
+		"~ /testzone/coll/d (renamed from /testzone/coll/c)",
+		"+ /testzone/coll/e (added)",
+		"~ /testzone/coll/sub2/f (renamed from /testzone/coll/sub/f)",
+		"- /testzone/coll/a (removed)",
+	} {
+		if !strings.Contains(buf.String(), expected) {
+			t.Errorf("expected %q in output, got %q", expected, buf.String())
+		}
+	}
+
+	if strings.Count(buf.String(), "\n") != 5 {
+		t.Errorf("expected 5 changes, got %q", buf.String())
+	}
+
+	if len(w.known) != 4 || w.collections["/testzone/coll/sub"] || !w.collections["/testzone/coll/sub2"] {
+		t.Errorf("unexpected state: %v %v", w.known, w.collections)
+	}
+}
+
+func TestWatchInvalidInterval(t *testing.T) {
+	app := testApp(t)
+
+	cmd := app.Command()
+	cmd.SetArgs([]string{"watch", "--interval", "0s", "/testzone/coll"})
+
+	if err := cmd.ExecuteContext(t.Context()); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -41,16 +41,19 @@ func main() {
 
 	defer stop()
 
+	template := iron.Env{
+		AuthScheme:      "pam_interactive",
+		DefaultResource: "default",
+	}
+
 	app := cli.New(
 		ctx,
 		cli.WithVersion(version),
-		cli.WithConfigStore(cli.FileStore(config, iron.Env{
-			AuthScheme:      "pam_interactive",
-			DefaultResource: "default",
-		}), []string{"user name", "zone name", "host"}),
+		cli.WithConfigStore(cli.FileStore(config, template), []string{"user name", "zone name", "host"}),
 		cli.WithLoader(cli.FileLoader(config)),
 		cli.WithPasswordStore(cli.FilePasswordStore(config)),
 		cli.WithDefaultWorkdirFromFile(config),
+		cli.WithProfiles(home+"/.irods/profiles", template),
 	)
 
 	if updateSlug != "" {
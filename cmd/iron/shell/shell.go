@@ -42,10 +42,26 @@ func New(root *cobra.Command, opts ...prompt.Option) *cobra.Command {
 		opts...,
 	)
 
-	return &cobra.Command{
+	var commands string
+
+	cmd := &cobra.Command{
 		Use:   "shell",
 		Short: "Start an interactive shell.",
-		Run: func(cmd *cobra.Command, _ []string) {
+		RunE: func(cmd *cobra.Command, _ []string) error {
+			if cmd.Flags().Changed("command") {
+				shell.editCommandTree(cmd)
+
+				if err := shell.run(commands); err != nil {
+					// The error has been reported by the failing command
+					cmd.SilenceErrors = true
+					cmd.SilenceUsage = true
+
+					return err
+				}
+
+				return nil
+			}
+
 			shell.saveStdin()
 
 			shell.editCommandTree(cmd)
@@ -53,8 +69,82 @@ func New(root *cobra.Command, opts ...prompt.Option) *cobra.Command {
 			prompt.New(shell.executor, opts...).Run()
 
 			shell.restoreStdin()
+
+			return nil
 		},
 	}
+
+	cmd.Flags().StringVarP(&commands, "command", "c", "", "Run the given commands in a single session and exit, instead of starting an interactive shell. Commands are separated by semicolons or newlines, and are split into arguments as in the interactive shell, so quotes are needed to pass arguments containing spaces or semicolons. Execution stops at the first command that fails.")
+
+	return cmd
+}
+
+// run executes the given commands non-interactively, stopping at the first command that fails.
+func (s *cobraShell) run(commands string) error {
+	lines, err := splitCommands(commands)
+	if err != nil {
+		return err
+	}
+
+	for _, args := range lines {
+		if err := execute(s.root, args); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// splitCommands splits a script into commands, separated by semicolons or newlines that are
+// not quoted or escaped, and splits each command into arguments. Empty commands are skipped.
+func splitCommands(script string) ([][]string, error) {
+	var (
+		commands                  [][]string
+		current                   strings.Builder
+		single, double, backslash bool
+	)
+
+	flush := func() error {
+		args, err := shlex.Split(current.String())
+		if err != nil {
+			return err
+		}
+
+		if len(args) > 0 {
+			commands = append(commands, args)
+		}
+
+		current.Reset()
+
+		return nil
+	}
+
+	for _, r := range script {
+		switch {
+		case backslash:
+			backslash = false
+		case r == '\\' && !single:
+			backslash = true
+		case r == '\'' && !double:
+			single = !single
+		case r == '"' && !single:
+			double = !double
+		case (r == ';' || r == '\n') && !single && !double:
+			if err := flush(); err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		current.WriteRune(r)
+	}
+
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return commands, nil
 }
 
 func (s *cobraShell) editCommandTree(shell *cobra.Command) {
@@ -2,7 +2,9 @@ package shell
 
 import (
 	"bytes"
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/elk-language/go-prompt"
@@ -24,8 +26,97 @@ func TestNew(t *testing.T) {
 		t.Errorf("Expected shell command Short to be 'Start an interactive shell.', got '%s'", shellCmd.Short)
 	}
 
-	if shellCmd.Run == nil {
-		t.Error("Expected shell command to have a Run function")
+	if shellCmd.RunE == nil {
+		t.Error("Expected shell command to have a RunE function")
+	}
+}
+
+func TestSplitCommands(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected [][]string
+	}{
+		{"ls /coll; du /coll", [][]string{{"ls", "/coll"}, {"du", "/coll"}}},
+		{"ls /coll\ndu /coll;;", [][]string{{"ls", "/coll"}, {"du", "/coll"}}},
+		{`mkdir "a;b"; ls 'c;d'`, [][]string{{"mkdir", "a;b"}, {"ls", "c;d"}}},
+		{`ls a\;b`, [][]string{{"ls", "a;b"}}},
+		{`ls "it's; here"`, [][]string{{"ls", "it's; here"}}},
+		{"  ; ", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.input, func(t *testing.T) {
+			result, err := splitCommands(tt.input)
+			if err != nil {
+				t.Fatalf("Unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(result, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, result)
+			}
+		})
+	}
+
+	if _, err := splitCommands(`ls "unterminated; du`); err == nil {
+		t.Error("Expected error for unterminated quote")
+	}
+}
+
+func TestRunCommands(t *testing.T) {
+	var executed []string
+
+	root := &cobra.Command{Use: "root"}
+
+	for _, name := range []string{"first", "second"} {
+		root.AddCommand(&cobra.Command{
+			Use: name,
+			RunE: func(cmd *cobra.Command, args []string) error {
+				executed = append(executed, name+":"+strings.Join(args, ","))
+
+				return nil
+			},
+		})
+	}
+
+	root.AddCommand(&cobra.Command{
+		Use: "fail",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return errors.New("failed")
+		},
+	})
+
+	shellCmd := New(root)
+	root.AddCommand(shellCmd)
+
+	var buf bytes.Buffer
+
+	root.SetOut(&buf)
+	root.SetErr(&buf)
+	root.SetArgs([]string{"shell", "-c", "first a b; second 'c d'"})
+
+	if err := root.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if expected := []string{"first:a,b", "second:c d"}; !reflect.DeepEqual(executed, expected) {
+		t.Errorf("Expected %v, got %v", expected, executed)
+	}
+
+	// Execution stops at the first failing command
+	executed = nil
+
+	shellCmd = New(root)
+
+	if err := shellCmd.Flags().Set("command", "first; fail; second"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := shellCmd.RunE(shellCmd, nil); err == nil || err.Error() != "failed" {
+		t.Errorf("Expected failed error, got %v", err)
+	}
+
+	if expected := []string{"first:"}; !reflect.DeepEqual(executed, expected) {
+		t.Errorf("Expected %v, got %v", expected, executed)
 	}
 }
 
@@ -25,7 +25,6 @@ import (
 	"github.com/kuleuven/iron/api"
 	"github.com/kuleuven/iron/msg"
 	"github.com/kuleuven/iron/scramble"
-	"github.com/sirupsen/logrus"
 	"go.uber.org/multierr"
 )
 
@@ -109,6 +108,10 @@ type conn struct {
 type DialFunc func(ctx context.Context, env Env, clientName string) (net.Conn, error)
 
 func DefaultDialFunc(ctx context.Context, env Env, clientName string) (net.Conn, error) {
+	if env.Host == "" {
+		return nil, fmt.Errorf("%w: irods_host", ErrMissingSetting)
+	}
+
 	dialer := net.Dialer{
 		Timeout: env.DialTimeout,
 	}
@@ -169,16 +172,14 @@ func newConn(ctx context.Context, transport net.Conn, env Env, clientName string
 		connectedAt: time.Now(),
 	}
 
+	if errs := c.env.validateSettings(); len(errs) == 1 {
+		return nil, errs[0]
+	} else if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
 	// Make sure TLS is required when not using native authentication
 	if c.env.AuthScheme != native {
-		if c.env.ClientServerNegotiation != requestServerNegotiationToken {
-			return nil, ErrTLSRequired
-		}
-
-		if c.env.ClientServerNegotiationPolicy == ClientServerRefuseTLS {
-			return nil, ErrTLSRequired
-		}
-
 		c.env.ClientServerNegotiationPolicy = ClientServerRequireTLS
 	}
 
@@ -253,6 +254,10 @@ func (c *conn) startup(ctx context.Context) error {
 		Option:         c.option,
 	}
 
+	if id := RequestID(ctx); id != "" {
+		pack.Option = fmt.Sprintf("%s[%s]", pack.Option, id)
+	}
+
 	if c.env.ClientServerNegotiation == requestServerNegotiationToken {
 		pack.Option = fmt.Sprintf("%s%s", pack.Option, c.env.ClientServerNegotiation)
 	}
@@ -359,6 +364,54 @@ func (c *conn) handshakeNegotiation() error {
 
 var ErrUnknownSSLVerifyPolicy = fmt.Errorf("unknown SSL verification policy")
 
+var (
+	ErrUnknownTLSVersion          = fmt.Errorf("unknown or unsupported TLS version")
+	ErrUnknownTLSCipherSuite      = fmt.Errorf("unknown or insecure TLS cipher suite")
+	ErrIncompleteTLSClientKeyPair = fmt.Errorf("both a client certificate and key file must be configured")
+)
+
+// parseTLSVersion parses a minimum TLS version such as "1.3", "TLS1.3" or "TLSv1.3".
+// Versions below TLS 1.2 are not supported. An empty string defaults to TLS 1.2.
+func parseTLSVersion(version string) (uint16, error) {
+	v := strings.TrimPrefix(strings.TrimPrefix(strings.ToUpper(strings.TrimSpace(version)), "TLS"), "V")
+
+	switch v {
+	case "", "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	default:
+		return 0, fmt.Errorf("%w: %q, expected 1.2 or 1.3", ErrUnknownTLSVersion, version)
+	}
+}
+
+// parseTLSCipherSuites looks up the given cipher suites by name.
+// Only the cipher suites that are considered secure by crypto/tls are accepted.
+func parseTLSCipherSuites(names []string) ([]uint16, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	known := map[string]uint16{}
+
+	for _, suite := range tls.CipherSuites() {
+		known[suite.Name] = suite.ID
+	}
+
+	ids := make([]uint16, len(names))
+
+	for i, name := range names {
+		id, ok := known[strings.TrimSpace(name)]
+		if !ok {
+			return nil, fmt.Errorf("%w: %q", ErrUnknownTLSCipherSuite, name)
+		}
+
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
 // Make configurable for testing
 var tlsTime = time.Now
 
@@ -392,9 +445,20 @@ func verifyPeerCertificateNoHostname(tlsConfig *tls.Config, certificates [][]byt
 }
 
 func (c *conn) handshakeTLS() error {
+	minVersion, err := parseTLSVersion(c.env.SSLMinVersion)
+	if err != nil {
+		return err
+	}
+
+	cipherSuites, err := parseTLSCipherSuites(c.env.SSLCipherSuites)
+	if err != nil {
+		return err
+	}
+
 	tlsConfig := &tls.Config{
-		MinVersion: tls.VersionTLS12,
-		Time:       tlsTime,
+		MinVersion:   minVersion,
+		CipherSuites: cipherSuites,
+		Time:         tlsTime,
 	}
 
 	switch c.env.SSLVerifyServer {
@@ -416,8 +480,6 @@ func (c *conn) handshakeTLS() error {
 	}
 
 	if c.env.SSLCACertificateFile != "" {
-		var err error
-
 		tlsConfig.RootCAs, err = rootcerts.LoadCACerts(&rootcerts.Config{
 			CAFile: c.env.SSLCACertificateFile,
 		})
@@ -426,6 +488,19 @@ func (c *conn) handshakeTLS() error {
 		}
 	}
 
+	if c.env.SSLClientCertFile != "" || c.env.SSLClientKeyFile != "" {
+		if c.env.SSLClientCertFile == "" || c.env.SSLClientKeyFile == "" {
+			return ErrIncompleteTLSClientKeyPair
+		}
+
+		cert, err := tls.LoadX509KeyPair(c.env.SSLClientCertFile, c.env.SSLClientKeyFile)
+		if err != nil {
+			return err
+		}
+
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+
 	tlsConn := tls.Client(c.transport, tlsConfig)
 
 	if err := tlsConn.Handshake(); err != nil {
@@ -555,7 +630,7 @@ func (c *conn) authenticateNativeDeprecated(ctx context.Context) error {
 		Username: c.env.ProxyUsername,
 	}
 
-	logrus.Debugf("Responding %s %s ", response.Response, response.Username)
+	logger(ctx).Debugf("Responding %s %s ", response.Response, response.Username)
 
 	return c.Request(ctx, msg.AUTH_RESPONSE_AN, response, &msg.AuthResponse{})
 }
@@ -803,6 +878,10 @@ func (c *conn) RequestWithBuffers(ctx context.Context, apiNumber msg.APINumber,
 		return fmt.Errorf("%w: %d previous transport errors", ErrTransport, c.transportErrors)
 	}
 
+	if id := RequestID(ctx); id != "" {
+		connLog.WithField("request_id", id).Tracef("Request %d", apiNumber)
+	}
+
 	if err := msg.WriteContext(ctx, c.transport, request, requestBuf, c.protocol, "RODS_API_REQ", int32(apiNumber)); err != nil {
 		c.transportErrors++
 
@@ -853,6 +932,7 @@ func (c *conn) API() *api.API {
 			return &dummyCloser{c}, nil
 		},
 		// DefaultResource: c.env.DefaultResource,
+		URLTemplate: c.env.URLTemplate,
 	}
 }
 
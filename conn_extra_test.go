@@ -1,6 +1,8 @@
 package iron
 
 import (
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -511,3 +513,50 @@ func TestBuildErrorNativeNoError(t *testing.T) {
 		t.Errorf("expected 'native error msg', got %q", result)
 	}
 }
+
+func TestParseTLSVersion(t *testing.T) {
+	for version, expected := range map[string]uint16{
+		"":        tls.VersionTLS12,
+		"1.2":     tls.VersionTLS12,
+		"TLS1.2":  tls.VersionTLS12,
+		"1.3":     tls.VersionTLS13,
+		"TLSv1.3": tls.VersionTLS13,
+		"tlsv1.3": tls.VersionTLS13,
+	} {
+		v, err := parseTLSVersion(version)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", version, err)
+		}
+
+		if v != expected {
+			t.Errorf("%q: expected %x, got %x", version, expected, v)
+		}
+	}
+
+	for _, version := range []string{"1.0", "1.1", "SSLv3", "1.4", "latest"} {
+		if _, err := parseTLSVersion(version); !errors.Is(err, ErrUnknownTLSVersion) {
+			t.Errorf("%q: expected ErrUnknownTLSVersion, got %v", version, err)
+		}
+	}
+}
+
+func TestParseTLSCipherSuites(t *testing.T) {
+	ids, err := parseTLSCipherSuites([]string{"TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384", "TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ids) != 2 || ids[0] != tls.TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384 || ids[1] != tls.TLS_ECDHE_ECDSA_WITH_CHACHA20_POLY1305_SHA256 {
+		t.Fatalf("unexpected cipher suites: %v", ids)
+	}
+
+	if ids, err := parseTLSCipherSuites(nil); err != nil || ids != nil {
+		t.Fatalf("expected default cipher suites, got %v (%v)", ids, err)
+	}
+
+	for _, name := range []string{"TLS_RSA_WITH_RC4_128_SHA", "UNKNOWN"} {
+		if _, err := parseTLSCipherSuites([]string{name}); !errors.Is(err, ErrUnknownTLSCipherSuite) {
+			t.Errorf("%q: expected ErrUnknownTLSCipherSuite, got %v", name, err)
+		}
+	}
+}
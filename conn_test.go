@@ -1,12 +1,16 @@
 package iron
 
 import (
+	"bytes"
 	"crypto/tls"
+	"crypto/x509"
 	"encoding/base64"
+	"encoding/pem"
 	"errors"
 	"io"
 	"net"
 	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 	"time"
@@ -185,6 +189,11 @@ EKTcWGekdmdDPsHloRNtsiCa697B2O9IFA==
 )
 
 func pamResponses(server net.Conn) {
+	pamResponsesWithConfig(server, nil)
+}
+
+// pamResponsesWithConfig is like pamResponses, but allows to adapt the TLS configuration of the server
+func pamResponsesWithConfig(server net.Conn, configure func(*tls.Config)) {
 	assert := func(args ...any) {
 		if err := args[len(args)-1]; err != nil {
 			panic(err)
@@ -204,10 +213,16 @@ func pamResponses(server net.Conn) {
 	cert, err := tls.X509KeyPair(certPem, keyPem)
 	assert(err)
 
-	serverTLS := tls.Server(server, &tls.Config{
+	tlsConfig := &tls.Config{
 		MinVersion:   tls.VersionTLS12,
 		Certificates: []tls.Certificate{cert},
-	})
+	}
+
+	if configure != nil {
+		configure(tlsConfig)
+	}
+
+	serverTLS := tls.Server(server, tlsConfig)
 
 	defer serverTLS.Close()
 
@@ -341,6 +356,84 @@ func TestConnPamPasswordTLS(t *testing.T) {
 	}
 }
 
+func TestConnPamPasswordMutualTLS(t *testing.T) {
+	ctx := t.Context()
+	transport, server := connPipe(mockVersion)
+
+	var clientCert []byte
+
+	go pamResponsesWithConfig(server, func(tlsConfig *tls.Config) {
+		tlsConfig.ClientAuth = tls.RequireAnyClientCert
+		tlsConfig.VerifyPeerCertificate = func(certificates [][]byte, _ [][]*x509.Certificate) error {
+			clientCert = certificates[0]
+
+			return nil
+		}
+	})
+
+	dir := t.TempDir()
+
+	for name, data := range map[string][]byte{"cert.pem": certPem, "key.pem": keyPem} {
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	env := Env{
+		Zone:              "testZone",
+		Username:          "testUser",
+		Password:          "testPassword",
+		AuthScheme:        "pam_password",
+		SSLVerifyServer:   "none",
+		SSLClientCertFile: filepath.Join(dir, "cert.pem"),
+		SSLClientKeyFile:  filepath.Join(dir, "key.pem"),
+	}
+
+	env.ApplyDefaults()
+
+	conn, err := NewConn(ctx, transport, env, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	block, _ := pem.Decode(certPem)
+
+	if !bytes.Equal(clientCert, block.Bytes) {
+		t.Fatal("expected the client certificate to be presented to the server")
+	}
+}
+
+func TestConnIncompleteClientKeyPair(t *testing.T) {
+	transport, server := connPipe(mockVersion)
+
+	defer server.Close()
+
+	go func() {
+		msg.Read(server, &msg.StartupPack{}, nil, msg.XML, "RODS_CONNECT")                                         //nolint:errcheck
+		msg.Write(server, msg.ClientServerNegotiation{Result: "CS_NEQ_REQUIRE"}, nil, msg.XML, "RODS_CS_NEG_T", 0) //nolint:errcheck
+		msg.Read(server, &msg.ClientServerNegotiation{}, nil, msg.XML, "RODS_CS_NEG_T")                            //nolint:errcheck
+		msg.Write(server, msg.Version{ReleaseVersion: releaseVersion}, nil, msg.XML, "RODS_VERSION", 0)            //nolint:errcheck
+	}()
+
+	env := Env{
+		Zone:              "testZone",
+		Username:          "testUser",
+		Password:          "testPassword",
+		AuthScheme:        "pam_password",
+		SSLClientCertFile: "cert.pem",
+	}
+
+	env.ApplyDefaults()
+
+	if _, err := NewConn(t.Context(), transport, env, "test"); !errors.Is(err, ErrIncompleteTLSClientKeyPair) {
+		t.Fatalf("expected ErrIncompleteTLSClientKeyPair, got %v", err)
+	}
+}
+
 func TestConnPamPasswordTLS2(t *testing.T) {
 	ctx := t.Context()
 	transport, server := connPipe(mockVersion)
@@ -651,6 +744,16 @@ func TestTLSRequired2(t *testing.T) {
 	}
 }
 
+func TestDialMissingHost(t *testing.T) {
+	env := Env{Zone: "testZone"}
+
+	env.ApplyDefaults()
+
+	if _, err := Dial(t.Context(), env, "test"); !errors.Is(err, ErrMissingSetting) {
+		t.Fatalf("expected ErrMissingSetting, got %v", err)
+	}
+}
+
 func TestOldVersion(t *testing.T) {
 	ctx := t.Context()
 	transport, server := connPipe(mockVersion)
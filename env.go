@@ -2,6 +2,8 @@ package iron
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
 	"time"
 )
@@ -27,6 +29,20 @@ type Env struct {
 	ProxyUsername                 string `json:"irods_proxy_user"` // Authenticate with proxy credentials
 	ProxyZone                     string `json:"irods_proxy_zone"` // Authenticate with proxy credentials
 	IrodsAuthenticationUID        *int   `json:"irods_authentication_uid,omitempty"`
+	AuthenticationFile            string `json:"irods_authentication_file,omitempty"` // Location of the cached password file, used by the CLI
+	URLTemplate                   string `json:"iron_url_template,omitempty"`         // Template to map paths to URLs of a web gateway, e.g. https://data.example.org{path}, used by the CLI
+
+	// TLS policy. SSLMinVersion is the minimum TLS version to accept, either "1.2" (default) or "1.3".
+	// SSLCipherSuites restricts the cipher suites used for TLS 1.2, given by their
+	// standard names, e.g. "TLS_ECDHE_RSA_WITH_AES_256_GCM_SHA384". The cipher suites for TLS 1.3
+	// are not configurable.
+	SSLMinVersion   string   `json:"irods_ssl_min_version,omitempty"`
+	SSLCipherSuites []string `json:"irods_ssl_cipher_suites,omitempty"`
+
+	// Client certificate and key in PEM format, presented to the server for mutual TLS.
+	// This operates at the transport layer and is independent of the authentication scheme.
+	SSLClientCertFile string `json:"irods_ssl_client_certificate_file,omitempty"`
+	SSLClientKeyFile  string `json:"irods_ssl_client_key_file,omitempty"`
 
 	// For pam authentication, request to generate a password that is valid for the given TTL.
 	// The server will determine the actual TTL based on the server thresholds.
@@ -120,6 +136,72 @@ func (env *Env) ApplyDefaults() {
 	}
 }
 
+var ErrMissingSetting = errors.New("required setting is missing")
+
+// Validate checks the environment for missing settings, invalid settings and invalid combinations
+// of settings, such as PAM authentication without TLS. All problems are reported at once, joined in
+// a single error that can be inspected using errors.Is. No files are read and the server is not
+// contacted, so a valid environment does not guarantee that a connection can be established.
+func (env *Env) Validate() error {
+	var errs []error
+
+	for _, setting := range []struct{ name, value string }{
+		{"irods_host", env.Host},
+		{"irods_zone_name", env.Zone},
+		{"irods_user_name", env.Username},
+	} {
+		if setting.value == "" {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrMissingSetting, setting.name))
+		}
+	}
+
+	return errors.Join(append(errs, env.validateSettings()...)...)
+}
+
+// validateSettings checks the settings that determine how a connection is set up, i.e. the
+// authentication scheme and TLS settings, as Validate does. It is used on every new connection,
+// for which the host, zone and user name are not required, as the caller may pass its own transport.
+func (env *Env) validateSettings() []error {
+	var errs []error
+
+	switch env.AuthScheme {
+	case native, pamPassword, pamInteractive:
+	default:
+		errs = append(errs, fmt.Errorf("%w: authentication scheme %q, set irods_authentication_scheme to %s, %s or %s", ErrNotImplemented, env.AuthScheme, native, pamPassword, pamInteractive))
+	}
+
+	useTLS := env.ClientServerNegotiation == requestServerNegotiationToken && env.ClientServerNegotiationPolicy != ClientServerRefuseTLS
+
+	// Make sure TLS is required when not using native authentication
+	if env.AuthScheme != native && !useTLS {
+		errs = append(errs, ErrTLSRequired)
+	}
+
+	if !useTLS {
+		return errs
+	}
+
+	switch env.SSLVerifyServer {
+	case "cert", "host", "none":
+	default:
+		errs = append(errs, fmt.Errorf("%w: %q, set irods_ssl_verify_server to cert, host or none", ErrUnknownSSLVerifyPolicy, env.SSLVerifyServer))
+	}
+
+	if _, err := parseTLSVersion(env.SSLMinVersion); err != nil {
+		errs = append(errs, err)
+	}
+
+	if _, err := parseTLSCipherSuites(env.SSLCipherSuites); err != nil {
+		errs = append(errs, err)
+	}
+
+	if (env.SSLClientCertFile == "") != (env.SSLClientKeyFile == "") {
+		errs = append(errs, fmt.Errorf("%w: set both irods_ssl_client_certificate_file and irods_ssl_client_key_file", ErrIncompleteTLSClientKeyPair))
+	}
+
+	return errs
+}
+
 // setDefaultValue sets the value of ptr to defaultValue if ptr is empty (i.e. has its zero value).
 // It is a helper function to set default values for environment fields.
 func setDefaultValue[S comparable](ptr *S, defaultValue S) {
@@ -3,7 +3,9 @@ package iron
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"os"
+	"strings"
 	"testing"
 )
 
@@ -73,3 +75,85 @@ func TestEnvMarshal(t *testing.T) {
 		}
 	}
 }
+
+func TestEnvValidate(t *testing.T) {
+	for i, testCase := range []struct {
+		modify   func(*Env)
+		expected error
+	}{
+		{func(*Env) {}, nil},
+		{func(env *Env) { env.AuthScheme = pamPassword }, nil},
+		{func(env *Env) { env.AuthScheme = "kerberos" }, ErrNotImplemented},
+		{func(env *Env) { env.AuthScheme = pamPassword; env.ClientServerNegotiation = "" }, ErrTLSRequired},
+		{func(env *Env) {
+			env.AuthScheme = pamInteractive
+			env.ClientServerNegotiationPolicy = ClientServerRefuseTLS
+		}, ErrTLSRequired},
+		{func(env *Env) { env.SSLVerifyServer = "always" }, ErrUnknownSSLVerifyPolicy},
+		{func(env *Env) { env.SSLVerifyServer = "always"; env.ClientServerNegotiation = "" }, nil},
+		{func(env *Env) { env.SSLMinVersion = "1.1" }, ErrUnknownTLSVersion},
+		{func(env *Env) { env.SSLCipherSuites = []string{"TLS_RSA_WITH_RC4_128_SHA"} }, ErrUnknownTLSCipherSuite},
+		{func(env *Env) { env.SSLClientCertFile = "client.crt" }, ErrIncompleteTLSClientKeyPair},
+	} {
+		env := Env{Host: "localhost", Zone: "testZone"}
+
+		env.ApplyDefaults()
+
+		testCase.modify(&env)
+
+		if err := env.Validate(); !errors.Is(err, testCase.expected) {
+			t.Errorf("[%d] expected %v, got %v", i, testCase.expected, err)
+		}
+	}
+}
+
+func TestEnvValidateMultiple(t *testing.T) {
+	env := Env{
+		AuthScheme:                    pamPassword,
+		ClientServerNegotiation:       requestServerNegotiationToken,
+		ClientServerNegotiationPolicy: ClientServerRefuseTLS,
+		SSLVerifyServer:               "always",
+	}
+
+	err := env.Validate()
+
+	for _, expected := range []error{ErrMissingSetting, ErrTLSRequired} {
+		if !errors.Is(err, expected) {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+	}
+
+	// The TLS settings are not used if TLS is refused
+	if errors.Is(err, ErrUnknownSSLVerifyPolicy) {
+		t.Errorf("did not expect %v", ErrUnknownSSLVerifyPolicy)
+	}
+
+	for _, setting := range []string{"irods_host", "irods_zone_name", "irods_user_name"} {
+		if !strings.Contains(err.Error(), setting) {
+			t.Errorf("expected %s to be reported, got %v", setting, err)
+		}
+	}
+
+	env = Env{
+		Host:                    "localhost",
+		Zone:                    "testZone",
+		Username:                "testUser",
+		AuthScheme:              native,
+		ClientServerNegotiation: requestServerNegotiationToken,
+		SSLVerifyServer:         "always",
+		SSLMinVersion:           "1.0",
+		SSLClientKeyFile:        "client.key",
+	}
+
+	err = env.Validate()
+
+	for _, expected := range []error{ErrUnknownSSLVerifyPolicy, ErrUnknownTLSVersion, ErrIncompleteTLSClientKeyPair} {
+		if !errors.Is(err, expected) {
+			t.Errorf("expected %v, got %v", expected, err)
+		}
+	}
+
+	if errors.Is(err, ErrMissingSetting) {
+		t.Errorf("did not expect %v", ErrMissingSetting)
+	}
+}
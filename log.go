@@ -0,0 +1,22 @@
+package iron
+
+import "github.com/sirupsen/logrus"
+
+// LogComponentField is the field of log entries that identifies the component that
+// logs a message, so that debug output can be filtered per component. The components are:
+//
+//   - conn: dialing, the handshake, TLS and authentication of connections (iron package)
+//   - pool: connection pools and the concurrency limits for overloaded servers (iron package)
+//   - msg: the messages that are sent to and received from the server, at trace level (msg package)
+//   - api: API calls and open data objects (api package)
+//   - transfer: transfers and the synchronization of directories (transfer package)
+//   - cli: the command line client (cmd/iron/cli package)
+const LogComponentField = "component"
+
+// LogComponents lists the components that log messages, see LogComponentField.
+var LogComponents = []string{"conn", "pool", "msg", "api", "transfer", "cli"}
+
+var (
+	connLog = logrus.WithField(LogComponentField, "conn")
+	poolLog = logrus.WithField(LogComponentField, "pool")
+)
@@ -0,0 +1,212 @@
+package msg
+
+import (
+	"strconv"
+	"strings"
+)
+
+// columnNames maps the column numbers to their canonical names, as listed in the
+// columnName table of rodsGenQueryNames.h and used by iquest and GenQuery2.
+// The fake procStatOut columns are not part of that table and are named after their constant.
+var columnNames = map[ColumnNumber]string{
+	ICAT_COLUMN_USER_ID:                        "USER_ID",
+	ICAT_COLUMN_USER_NAME:                      "USER_NAME",
+	ICAT_COLUMN_USER_TYPE:                      "USER_TYPE",
+	ICAT_COLUMN_USER_ZONE:                      "USER_ZONE",
+	ICAT_COLUMN_USER_INFO:                      "USER_INFO",
+	ICAT_COLUMN_USER_COMMENT:                   "USER_COMMENT",
+	ICAT_COLUMN_USER_CREATE_TIME:               "USER_CREATE_TIME",
+	ICAT_COLUMN_USER_MODIFY_TIME:               "USER_MODIFY_TIME",
+	ICAT_COLUMN_D_DATA_ID:                      "DATA_ID",
+	ICAT_COLUMN_D_COLL_ID:                      "DATA_COLL_ID",
+	ICAT_COLUMN_DATA_NAME:                      "DATA_NAME",
+	ICAT_COLUMN_DATA_REPL_NUM:                  "DATA_REPL_NUM",
+	ICAT_COLUMN_DATA_VERSION:                   "DATA_VERSION",
+	ICAT_COLUMN_DATA_TYPE_NAME:                 "DATA_TYPE_NAME",
+	ICAT_COLUMN_DATA_SIZE:                      "DATA_SIZE",
+	ICAT_COLUMN_D_RESC_NAME:                    "DATA_RESC_NAME",
+	ICAT_COLUMN_D_DATA_PATH:                    "DATA_PATH",
+	ICAT_COLUMN_D_OWNER_NAME:                   "DATA_OWNER_NAME",
+	ICAT_COLUMN_D_OWNER_ZONE:                   "DATA_OWNER_ZONE",
+	ICAT_COLUMN_D_REPL_STATUS:                  "DATA_REPL_STATUS",
+	ICAT_COLUMN_D_DATA_STATUS:                  "DATA_STATUS",
+	ICAT_COLUMN_D_DATA_CHECKSUM:                "DATA_CHECKSUM",
+	ICAT_COLUMN_D_EXPIRY:                       "DATA_EXPIRY",
+	ICAT_COLUMN_D_MAP_ID:                       "DATA_MAP_ID",
+	ICAT_COLUMN_D_COMMENTS:                     "DATA_COMMENTS",
+	ICAT_COLUMN_D_CREATE_TIME:                  "DATA_CREATE_TIME",
+	ICAT_COLUMN_D_MODIFY_TIME:                  "DATA_MODIFY_TIME",
+	ICAT_COLUMN_D_RESC_HIER:                    "DATA_RESC_HIER",
+	ICAT_COLUMN_D_RESC_ID:                      "DATA_RESC_ID",
+	ICAT_COLUMN_COLL_ID:                        "COLL_ID",
+	ICAT_COLUMN_COLL_NAME:                      "COLL_NAME",
+	ICAT_COLUMN_COLL_PARENT_NAME:               "COLL_PARENT_NAME",
+	ICAT_COLUMN_COLL_OWNER_NAME:                "COLL_OWNER_NAME",
+	ICAT_COLUMN_COLL_OWNER_ZONE:                "COLL_OWNER_ZONE",
+	ICAT_COLUMN_COLL_MAP_ID:                    "COLL_MAP_ID",
+	ICAT_COLUMN_COLL_INHERITANCE:               "COLL_INHERITANCE",
+	ICAT_COLUMN_COLL_COMMENTS:                  "COLL_COMMENTS",
+	ICAT_COLUMN_COLL_CREATE_TIME:               "COLL_CREATE_TIME",
+	ICAT_COLUMN_COLL_MODIFY_TIME:               "COLL_MODIFY_TIME",
+	ICAT_COLUMN_META_DATA_ATTR_NAME:            "META_DATA_ATTR_NAME",
+	ICAT_COLUMN_META_DATA_ATTR_VALUE:           "META_DATA_ATTR_VALUE",
+	ICAT_COLUMN_META_DATA_ATTR_UNITS:           "META_DATA_ATTR_UNITS",
+	ICAT_COLUMN_META_DATA_ATTR_ID:              "META_DATA_ATTR_ID",
+	ICAT_COLUMN_META_DATA_CREATE_TIME:          "META_DATA_CREATE_TIME",
+	ICAT_COLUMN_META_DATA_MODIFY_TIME:          "META_DATA_MODIFY_TIME",
+	ICAT_COLUMN_META_COLL_ATTR_NAME:            "META_COLL_ATTR_NAME",
+	ICAT_COLUMN_META_COLL_ATTR_VALUE:           "META_COLL_ATTR_VALUE",
+	ICAT_COLUMN_META_COLL_ATTR_UNITS:           "META_COLL_ATTR_UNITS",
+	ICAT_COLUMN_META_COLL_ATTR_ID:              "META_COLL_ATTR_ID",
+	ICAT_COLUMN_META_COLL_CREATE_TIME:          "META_COLL_CREATE_TIME",
+	ICAT_COLUMN_META_COLL_MODIFY_TIME:          "META_COLL_MODIFY_TIME",
+	ICAT_COLUMN_META_NAMESPACE_COLL:            "META_NAMESPACE_COLL",
+	ICAT_COLUMN_META_NAMESPACE_DATA:            "META_NAMESPACE_DATA",
+	ICAT_COLUMN_META_NAMESPACE_RESC:            "META_NAMESPACE_RESC",
+	ICAT_COLUMN_META_NAMESPACE_USER:            "META_NAMESPACE_USER",
+	ICAT_COLUMN_META_NAMESPACE_RESC_GROUP:      "META_NAMESPACE_RESC_GROUP",
+	ICAT_COLUMN_META_NAMESPACE_RULE:            "META_NAMESPACE_RULE",
+	ICAT_COLUMN_META_NAMESPACE_MSRVC:           "META_NAMESPACE_MSRVC",
+	ICAT_COLUMN_META_NAMESPACE_MET2:            "META_NAMESPACE_MET2",
+	ICAT_COLUMN_META_RESC_ATTR_NAME:            "META_RESC_ATTR_NAME",
+	ICAT_COLUMN_META_RESC_ATTR_VALUE:           "META_RESC_ATTR_VALUE",
+	ICAT_COLUMN_META_RESC_ATTR_UNITS:           "META_RESC_ATTR_UNITS",
+	ICAT_COLUMN_META_RESC_ATTR_ID:              "META_RESC_ATTR_ID",
+	ICAT_COLUMN_META_RESC_CREATE_TIME:          "META_RESC_CREATE_TIME",
+	ICAT_COLUMN_META_RESC_MODIFY_TIME:          "META_RESC_MODIFY_TIME",
+	ICAT_COLUMN_META_USER_ATTR_NAME:            "META_USER_ATTR_NAME",
+	ICAT_COLUMN_META_USER_ATTR_VALUE:           "META_USER_ATTR_VALUE",
+	ICAT_COLUMN_META_USER_ATTR_UNITS:           "META_USER_ATTR_UNITS",
+	ICAT_COLUMN_META_USER_ATTR_ID:              "META_USER_ATTR_ID",
+	ICAT_COLUMN_META_USER_CREATE_TIME:          "META_USER_CREATE_TIME",
+	ICAT_COLUMN_META_USER_MODIFY_TIME:          "META_USER_MODIFY_TIME",
+	ICAT_COLUMN_META_RESC_GROUP_ATTR_NAME:      "META_RESC_GROUP_ATTR_NAME",
+	ICAT_COLUMN_META_RESC_GROUP_ATTR_VALUE:     "META_RESC_GROUP_ATTR_VALUE",
+	ICAT_COLUMN_META_RESC_GROUP_ATTR_UNITS:     "META_RESC_GROUP_ATTR_UNITS",
+	ICAT_COLUMN_META_RESC_GROUP_ATTR_ID:        "META_RESC_GROUP_ATTR_ID",
+	ICAT_COLUMN_META_RESC_GROUP_CREATE_TIME:    "META_RESC_GROUP_CREATE_TIME",
+	ICAT_COLUMN_META_RESC_GROUP_MODIFY_TIME:    "META_RESC_GROUP_MODIFY_TIME",
+	ICAT_COLUMN_META_RULE_ATTR_NAME:            "META_RULE_ATTR_NAME",
+	ICAT_COLUMN_META_RULE_ATTR_VALUE:           "META_RULE_ATTR_VALUE",
+	ICAT_COLUMN_META_RULE_ATTR_UNITS:           "META_RULE_ATTR_UNITS",
+	ICAT_COLUMN_META_RULE_ATTR_ID:              "META_RULE_ATTR_ID",
+	ICAT_COLUMN_META_RULE_CREATE_TIME:          "META_RULE_CREATE_TIME",
+	ICAT_COLUMN_META_RULE_MODIFY_TIME:          "META_RULE_MODIFY_TIME",
+	ICAT_COLUMN_META_MSRVC_ATTR_NAME:           "META_MSRVC_ATTR_NAME",
+	ICAT_COLUMN_META_MSRVC_ATTR_VALUE:          "META_MSRVC_ATTR_VALUE",
+	ICAT_COLUMN_META_MSRVC_ATTR_UNITS:          "META_MSRVC_ATTR_UNITS",
+	ICAT_COLUMN_META_MSRVC_ATTR_ID:             "META_MSRVC_ATTR_ID",
+	ICAT_COLUMN_META_MSRVC_CREATE_TIME:         "META_MSRVC_CREATE_TIME",
+	ICAT_COLUMN_META_MSRVC_MODIFY_TIME:         "META_MSRVC_MODIFY_TIME",
+	ICAT_COLUMN_META_MET2_ATTR_NAME:            "META_MET2_ATTR_NAME",
+	ICAT_COLUMN_META_MET2_ATTR_VALUE:           "META_MET2_ATTR_VALUE",
+	ICAT_COLUMN_META_MET2_ATTR_UNITS:           "META_MET2_ATTR_UNITS",
+	ICAT_COLUMN_META_MET2_ATTR_ID:              "META_MET2_ATTR_ID",
+	ICAT_COLUMN_META_MET2_CREATE_TIME:          "META_MET2_CREATE_TIME",
+	ICAT_COLUMN_META_MET2_MODIFY_TIME:          "META_MET2_MODIFY_TIME",
+	ICAT_COLUMN_DATA_ACCESS_TYPE:               "DATA_ACCESS_TYPE",
+	ICAT_COLUMN_DATA_ACCESS_NAME:               "DATA_ACCESS_NAME",
+	ICAT_COLUMN_DATA_TOKEN_NAMESPACE:           "DATA_TOKEN_NAMESPACE",
+	ICAT_COLUMN_DATA_ACCESS_USER_ID:            "DATA_ACCESS_USER_ID",
+	ICAT_COLUMN_DATA_ACCESS_DATA_ID:            "DATA_ACCESS_DATA_ID",
+	ICAT_COLUMN_COLL_ACCESS_TYPE:               "COLL_ACCESS_TYPE",
+	ICAT_COLUMN_COLL_ACCESS_NAME:               "COLL_ACCESS_NAME",
+	ICAT_COLUMN_COLL_TOKEN_NAMESPACE:           "COLL_TOKEN_NAMESPACE",
+	ICAT_COLUMN_COLL_ACCESS_USER_ID:            "COLL_ACCESS_USER_ID",
+	ICAT_COLUMN_COLL_ACCESS_COLL_ID:            "COLL_ACCESS_COLL_ID",
+	ICAT_COLUMN_COLL_USER_GROUP_ID:             "USER_GROUP_ID",
+	ICAT_COLUMN_COLL_USER_GROUP_NAME:           "USER_GROUP_NAME",
+	ICAT_COLUMN_R_RESC_ID:                      "RESC_ID",
+	ICAT_COLUMN_R_RESC_NAME:                    "RESC_NAME",
+	ICAT_COLUMN_R_ZONE_NAME:                    "RESC_ZONE_NAME",
+	ICAT_COLUMN_R_TYPE_NAME:                    "RESC_TYPE_NAME",
+	ICAT_COLUMN_R_CLASS_NAME:                   "RESC_CLASS_NAME",
+	ICAT_COLUMN_R_LOC:                          "RESC_LOC",
+	ICAT_COLUMN_R_VAULT_PATH:                   "RESC_VAULT_PATH",
+	ICAT_COLUMN_R_FREE_SPACE:                   "RESC_FREE_SPACE",
+	ICAT_COLUMN_R_RESC_INFO:                    "RESC_INFO",
+	ICAT_COLUMN_R_RESC_COMMENT:                 "RESC_COMMENT",
+	ICAT_COLUMN_R_CREATE_TIME:                  "RESC_CREATE_TIME",
+	ICAT_COLUMN_R_MODIFY_TIME:                  "RESC_MODIFY_TIME",
+	ICAT_COLUMN_R_RESC_STATUS:                  "RESC_STATUS",
+	ICAT_COLUMN_R_FREE_SPACE_TIME:              "RESC_FREE_SPACE_TIME",
+	ICAT_COLUMN_R_RESC_CHILDREN:                "RESC_CHILDREN",
+	ICAT_COLUMN_R_RESC_CONTEXT:                 "RESC_CONTEXT",
+	ICAT_COLUMN_R_RESC_PARENT:                  "RESC_PARENT",
+	ICAT_COLUMN_R_RESC_PARENT_CONTEXT:          "RESC_PARENT_CONTEXT",
+	ICAT_COLUMN_QUOTA_USER_ID:                  "QUOTA_USER_ID",
+	ICAT_COLUMN_QUOTA_RESC_ID:                  "QUOTA_RESC_ID",
+	ICAT_COLUMN_QUOTA_LIMIT:                    "QUOTA_LIMIT",
+	ICAT_COLUMN_QUOTA_OVER:                     "QUOTA_OVER",
+	ICAT_COLUMN_QUOTA_MODIFY_TIME:              "QUOTA_MODIFY_TIME",
+	ICAT_COLUMN_QUOTA_USAGE_USER_ID:            "QUOTA_USAGE_USER_ID",
+	ICAT_COLUMN_QUOTA_USAGE_RESC_ID:            "QUOTA_USAGE_RESC_ID",
+	ICAT_COLUMN_QUOTA_USAGE:                    "QUOTA_USAGE",
+	ICAT_COLUMN_QUOTA_USAGE_MODIFY_TIME:        "QUOTA_USAGE_MODIFY_TIME",
+	ICAT_COLUMN_QUOTA_RESC_NAME:                "QUOTA_RESC_NAME",
+	ICAT_COLUMN_QUOTA_USER_NAME:                "QUOTA_USER_NAME",
+	ICAT_COLUMN_QUOTA_USER_ZONE:                "QUOTA_USER_ZONE",
+	ICAT_COLUMN_QUOTA_USER_TYPE:                "QUOTA_USER_TYPE",
+	ICAT_COLUMN_TICKET_ID:                      "TICKET_ID",
+	ICAT_COLUMN_TICKET_STRING:                  "TICKET_STRING",
+	ICAT_COLUMN_TICKET_TYPE:                    "TICKET_TYPE",
+	ICAT_COLUMN_TICKET_USER_ID:                 "TICKET_USER_ID",
+	ICAT_COLUMN_TICKET_OBJECT_ID:               "TICKET_OBJECT_ID",
+	ICAT_COLUMN_TICKET_OBJECT_TYPE:             "TICKET_OBJECT_TYPE",
+	ICAT_COLUMN_TICKET_USES_LIMIT:              "TICKET_USES_LIMIT",
+	ICAT_COLUMN_TICKET_USES_COUNT:              "TICKET_USES_COUNT",
+	ICAT_COLUMN_TICKET_EXPIRY_TS:               "TICKET_EXPIRY",
+	ICAT_COLUMN_TICKET_WRITE_FILE_COUNT:        "TICKET_WRITE_FILE_COUNT",
+	ICAT_COLUMN_TICKET_WRITE_FILE_LIMIT:        "TICKET_WRITE_FILE_LIMIT",
+	ICAT_COLUMN_TICKET_WRITE_BYTE_COUNT:        "TICKET_WRITE_BYTE_COUNT",
+	ICAT_COLUMN_TICKET_WRITE_BYTE_LIMIT:        "TICKET_WRITE_BYTE_LIMIT",
+	ICAT_COLUMN_TICKET_ALLOWED_HOST_TICKET_ID:  "TICKET_ALLOWED_HOST_TICKET_ID",
+	ICAT_COLUMN_TICKET_ALLOWED_HOST:            "TICKET_ALLOWED_HOST",
+	ICAT_COLUMN_TICKET_ALLOWED_USER_TICKET_ID:  "TICKET_ALLOWED_USER_TICKET_ID",
+	ICAT_COLUMN_TICKET_ALLOWED_USER_NAME:       "TICKET_ALLOWED_USER_NAME",
+	ICAT_COLUMN_TICKET_ALLOWED_GROUP_TICKET_ID: "TICKET_ALLOWED_GROUP_TICKET_ID",
+	ICAT_COLUMN_TICKET_ALLOWED_GROUP_NAME:      "TICKET_ALLOWED_GROUP_NAME",
+	ICAT_COLUMN_TICKET_DATA_NAME:               "TICKET_DATA_NAME",
+	ICAT_COLUMN_TICKET_DATA_COLL_NAME:          "TICKET_DATA_COLL_NAME",
+	ICAT_COLUMN_TICKET_COLL_NAME:               "TICKET_COLL_NAME",
+	ICAT_COLUMN_TICKET_OWNER_NAME:              "TICKET_OWNER_NAME",
+	ICAT_COLUMN_TICKET_OWNER_ZONE:              "TICKET_OWNER_ZONE",
+	ICAT_COLUMN_PROCESS_ID:                     "PROCESS_ID",
+	ICAT_COLUMN_STARTTIME:                      "STARTTIME",
+	ICAT_COLUMN_PROXY_NAME:                     "PROXY_NAME",
+	ICAT_COLUMN_PROXY_ZONE:                     "PROXY_ZONE",
+	ICAT_COLUMN_CLIENT_NAME:                    "CLIENT_NAME",
+	ICAT_COLUMN_CLIENT_ZONE:                    "CLIENT_ZONE",
+	ICAT_COLUMN_REMOTE_ADDR:                    "REMOTE_ADDR",
+	ICAT_COLUMN_PROG_NAME:                      "PROG_NAME",
+	ICAT_COLUMN_SERVER_ADDR:                    "SERVER_ADDR",
+}
+
+// columnsByName is the inverse of columnNames
+var columnsByName = func() map[string]ColumnNumber {
+	columns := make(map[string]ColumnNumber, len(columnNames))
+
+	for column, name := range columnNames {
+		columns[name] = column
+	}
+
+	return columns
+}()
+
+// String returns the canonical name of the column, e.g. DATA_NAME for
+// ICAT_COLUMN_DATA_NAME. Unknown columns are returned as their number.
+func (c ColumnNumber) String() string {
+	if name, ok := columnNames[c]; ok {
+		return name
+	}
+
+	return strconv.Itoa(int(c))
+}
+
+// ColumnByName returns the column with the given canonical name, e.g. DATA_NAME.
+// The name is case insensitive.
+func ColumnByName(name string) (ColumnNumber, bool) {
+	column, ok := columnsByName[strings.ToUpper(name)]
+
+	return column, ok
+}
@@ -0,0 +1,127 @@
+package msg
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// columnConstants returns the values of all ICAT_COLUMN_ constants in columns.go
+func columnConstants(t *testing.T) map[string]ColumnNumber {
+	f, err := parser.ParseFile(token.NewFileSet(), "columns.go", nil, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	constants := map[string]ColumnNumber{}
+
+	for _, decl := range f.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST {
+			continue
+		}
+
+		for _, spec := range gen.Specs {
+			value := spec.(*ast.ValueSpec)
+
+			for i, name := range value.Names {
+				if !strings.HasPrefix(name.Name, "ICAT_COLUMN_") {
+					continue
+				}
+
+				n, err := strconv.Atoi(value.Values[i].(*ast.BasicLit).Value)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				constants[name.Name] = ColumnNumber(n)
+			}
+		}
+	}
+
+	return constants
+}
+
+func TestColumnNamesRoundTrip(t *testing.T) {
+	constants := columnConstants(t)
+
+	if len(constants) != len(columnNames) {
+		t.Errorf("expected %d column names, got %d", len(constants), len(columnNames))
+	}
+
+	for constant, column := range constants {
+		name := column.String()
+
+		if name == strconv.Itoa(int(column)) {
+			t.Errorf("%s has no name", constant)
+
+			continue
+		}
+
+		if c, ok := ColumnByName(name); !ok || c != column {
+			t.Errorf("%s: expected %s to resolve to %d, got %d", constant, name, column, c)
+		}
+
+		if c, ok := ColumnByName(strings.ToLower(name)); !ok || c != column {
+			t.Errorf("%s: expected %s to resolve to %d, got %d", constant, strings.ToLower(name), column, c)
+		}
+	}
+}
+
+func TestColumnNamesCanonical(t *testing.T) {
+	// Taken from the columnName table in rodsGenQueryNames.h
+	for name, column := range map[string]ColumnNumber{
+		"USER_ID":                 201,
+		"USER_CREATE_TIME":        208,
+		"RESC_ZONE_NAME":          303,
+		"RESC_LOC":                306,
+		"RESC_PARENT_CONTEXT":     318,
+		"DATA_ID":                 401,
+		"DATA_COLL_ID":            402,
+		"DATA_NAME":               403,
+		"DATA_RESC_NAME":          409,
+		"DATA_CHECKSUM":           415,
+		"DATA_MODIFY_TIME":        420,
+		"DATA_RESC_ID":            423,
+		"COLL_ID":                 500,
+		"COLL_NAME":               501,
+		"META_DATA_ATTR_NAME":     600,
+		"META_COLL_ATTR_VALUE":    611,
+		"META_NAMESPACE_COLL":     620,
+		"META_RESC_ATTR_UNITS":    632,
+		"META_USER_ATTR_ID":       643,
+		"META_RESC_GROUP_ATTR_ID": 653,
+		"META_MET2_MODIFY_TIME":   685,
+		"DATA_ACCESS_TYPE":        700,
+		"COLL_TOKEN_NAMESPACE":    712,
+		"USER_GROUP_ID":           900,
+		"USER_GROUP_NAME":         901,
+		"QUOTA_USAGE":             2012,
+		"QUOTA_USER_TYPE":         2023,
+		"TICKET_STRING":           2201,
+		"TICKET_EXPIRY":           2208,
+		"TICKET_ALLOWED_HOST":     2221,
+		"TICKET_OWNER_ZONE":       2230,
+	} {
+		if c, ok := ColumnByName(name); !ok || c != column {
+			t.Errorf("expected %s to resolve to %d, got %d", name, column, c)
+		}
+
+		if column.String() != name {
+			t.Errorf("expected %d to be named %s, got %s", column, name, column.String())
+		}
+	}
+}
+
+func TestColumnNameUnknown(t *testing.T) {
+	if _, ok := ColumnByName("NO_SUCH_COLUMN"); ok {
+		t.Error("expected unknown column")
+	}
+
+	if name := ColumnNumber(12345).String(); name != "12345" {
+		t.Errorf("expected 12345, got %s", name)
+	}
+}
@@ -11,6 +11,7 @@ const (
 	DEST_RESC_NAME_KW     KeyWord = "destRescName"
 	DATA_TYPE_KW          KeyWord = "dataType"
 	DATA_SIZE_KW          KeyWord = "dataSize"
+	REPL_STATUS_KW        KeyWord = "replStatus"
 	NUM_THREADS_KW        KeyWord = "numThreads"
 	OPR_TYPE_KW           KeyWord = "oprType"
 	UPDATE_REPL_KW        KeyWord = "updateRepl"
@@ -34,4 +35,7 @@ const (
 	REG_REPL_KW           KeyWord = "regRepl"
 	FORCE_CHKSUM_KW       KeyWord = "forceChksum"
 	VERIFY_CHKSUM_KW      KeyWord = "verifyChksum"
+	DATA_COMMENTS_KW      KeyWord = "dataComments"
+	DATA_EXPIRY_KW        KeyWord = "dataExpiry"
+	ALL_KW                KeyWord = "all"
 )
@@ -1,10 +1,6 @@
 package msg
 
-import (
-	"errors"
-
-	"github.com/sirupsen/logrus"
-)
+import "errors"
 
 var ErrTypeAssertion = errors.New("type assertion failed")
 
@@ -22,7 +18,7 @@ func marshalBytes(body []byte, msgType string) (*Message, error) {
 
 func unmarshalBytes(msg Message, body *[]byte) error {
 	if msg.Header.ErrorLen > 0 {
-		logrus.Warnf("error is not empty: %s", string(msg.Body.Error))
+		logger.Warnf("error is not empty: %s", string(msg.Body.Error))
 	}
 
 	*body = msg.Body.Message
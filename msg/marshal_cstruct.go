@@ -10,8 +10,6 @@ import (
 	"reflect"
 	"strconv"
 	"strings"
-
-	"github.com/sirupsen/logrus"
 )
 
 func marshalCStruct(obj any, msgType string) (*Message, error) {
@@ -39,7 +37,7 @@ func unmarshalCStruct(msg Message, obj any) error {
 	}
 
 	if msg.Header.ErrorLen > 0 {
-		logrus.Warnf("error is not empty: %s", string(msg.Body.Error))
+		logger.Warnf("error is not empty: %s", string(msg.Body.Error))
 	}
 
 	return DecodeC(msg.Body.Message, obj)
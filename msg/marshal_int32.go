@@ -1,7 +1,5 @@
 package msg
 
-import "github.com/sirupsen/logrus"
-
 func marshalInt32(body int32, msgType string) (*Message, error) {
 	return &Message{
 		Header: Header{
@@ -14,7 +12,7 @@ func marshalInt32(body int32, msgType string) (*Message, error) {
 
 func unmarshalInt32(msg Message, body *int32) error {
 	if msg.Header.ErrorLen > 0 {
-		logrus.Warnf("error is not empty: %s", string(msg.Body.Error))
+		logger.Warnf("error is not empty: %s", string(msg.Body.Error))
 	}
 
 	*body = msg.Header.IntInfo
@@ -4,8 +4,6 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-
-	"github.com/sirupsen/logrus"
 )
 
 func marshalJSON(obj any, protocol Protocol, msgType string) (*Message, error) {
@@ -14,7 +12,7 @@ func marshalJSON(obj any, protocol Protocol, msgType string) (*Message, error) {
 		return nil, fmt.Errorf("failed to marshal irods message to json: %w", err)
 	}
 
-	logrus.Tracef("-> json: %s", jsonBody)
+	logger.Tracef("-> json: %s", jsonBody)
 
 	xmlObject := BinBytesBuf{
 		Length: len(jsonBody),
@@ -56,7 +54,7 @@ func unmarshalJSON(msg Message, protocol Protocol, obj any) error {
 		}
 	}
 
-	logrus.Tracef("<- json: %s", jsonBody)
+	logger.Tracef("<- json: %s", jsonBody)
 
 	return json.Unmarshal(jsonBody, obj)
 }
@@ -6,8 +6,6 @@ import (
 	"errors"
 	"fmt"
 	"unicode/utf8"
-
-	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -60,7 +58,7 @@ func unmarshalXML(msg Message, obj any) error {
 	}
 
 	if msg.Header.ErrorLen > 0 {
-		logrus.Warnf("error is not empty: %s", string(msg.Body.Error))
+		logger.Warnf("error is not empty: %s", string(msg.Body.Error))
 	}
 
 	body, err := PostprocessXML(msg.Body.Message)
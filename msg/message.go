@@ -14,6 +14,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// logger logs the messages of this package, see iron.LogComponentField
+var logger = logrus.WithField("component", "msg")
+
 type Header struct {
 	XMLName    xml.Name `xml:"MsgHeader_PI"`
 	Type       string   `xml:"type"`
@@ -60,7 +63,7 @@ func (msg Message) Write(w io.Writer) error {
 		return err
 	}
 
-	logrus.Tracef("-> bin: %d bytes", len(msg.Bin))
+	logger.Tracef("-> bin: %d bytes", len(msg.Bin))
 
 	_, err := w.Write(msg.Bin)
 
@@ -73,7 +76,7 @@ func (header Header) Write(w io.Writer) error {
 		return err
 	}
 
-	logrus.Tracef("-> %s", payload)
+	logger.Tracef("-> %s", payload)
 
 	// Write header
 	headerLenBuffer := make([]byte, 4)
@@ -92,9 +95,9 @@ func (header Header) Write(w io.Writer) error {
 
 func (body Body) Write(w io.Writer) error {
 	if toLog := strings.ReplaceAll(fmt.Sprintf("%s %s", body.Message, body.Error), "\n", ""); isPrintable(toLog) {
-		logrus.Tracef("-> %s", toLog)
+		logger.Tracef("-> %s", toLog)
 	} else {
-		logrus.Tracef("-> %d bytes", len(body.Message)+len(body.Error))
+		logger.Tracef("-> %d bytes", len(body.Message)+len(body.Error))
 	}
 
 	if _, err := w.Write(body.Message); err != nil {
@@ -143,12 +146,12 @@ func (msg *Message) Read(r io.Reader) error {
 	}
 
 	if len(msg.Bin) < int(msg.Header.BsLen) {
-		logrus.Warnf("expected %d bytes, got %d, cannot use provided buffer", msg.Header.BsLen, len(msg.Bin))
+		logger.Warnf("expected %d bytes, got %d, cannot use provided buffer", msg.Header.BsLen, len(msg.Bin))
 
 		msg.Bin = make([]byte, msg.Header.BsLen)
 	}
 
-	logrus.Tracef("<- bin: %d bytes", msg.Header.BsLen)
+	logger.Tracef("<- bin: %d bytes", msg.Header.BsLen)
 
 	_, err := io.ReadFull(r, msg.Bin[:msg.Header.BsLen])
 	if err != nil {
@@ -173,7 +176,7 @@ func (header *Header) Read(r io.Reader) error {
 		return err
 	}
 
-	logrus.Tracef("<- %s", bytes.ReplaceAll(headerBuffer, []byte("\n"), nil))
+	logger.Tracef("<- %s", bytes.ReplaceAll(headerBuffer, []byte("\n"), nil))
 
 	return xml.Unmarshal(headerBuffer, &header)
 }
@@ -191,9 +194,9 @@ func (body *Body) Read(r io.Reader, header Header) error {
 	}
 
 	if toLog := strings.ReplaceAll(fmt.Sprintf("%s %s", body.Message, body.Error), "\n", ""); isPrintable(toLog) {
-		logrus.Tracef("<- %s", toLog)
+		logger.Tracef("<- %s", toLog)
 	} else {
-		logrus.Tracef("<- %d bytes", len(body.Message)+len(body.Error))
+		logger.Tracef("<- %d bytes", len(body.Message)+len(body.Error))
 	}
 
 	return nil
@@ -208,7 +211,7 @@ func waitContext(ctx context.Context, ch chan error) error {
 	case err := <-ch:
 		return err
 	case <-ctx.Done():
-		logrus.Warnf("%s, but waiting %s for in-flight request to complete...", ctx.Err(), MinimumRequestWaitTime)
+		logger.Warnf("%s, but waiting %s for in-flight request to complete...", ctx.Err(), MinimumRequestWaitTime)
 
 		select {
 		case err := <-ch:
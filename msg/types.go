@@ -280,6 +280,17 @@ type ModifyAccessRequest struct {
 	Path          string   `xml:"path"`
 }
 
+type TicketAdminRequest struct {
+	XMLName xml.Name `xml:"ticketAdminInp_PI"`
+	Arg1    string   `xml:"arg1"` // create, delete, mod
+	Arg2    string   `xml:"arg2"` // ticket string
+	Arg3    string   `xml:"arg3"` // ticket type (read, write) for create
+	Arg4    string   `xml:"arg4"` // path for create
+	Arg5    string   `xml:"arg5"`
+	Arg6    string   `xml:"arg6"`
+	KeyVals SSKeyVal `xml:"KeyValPair_PI"`
+}
+
 type ModifyMetadataRequest struct {
 	XMLName      xml.Name `xml:"ModAVUMetadataInp_PI"`
 	Operation    string   `xml:"arg0"` // add, adda, rm, rmw, rmi, cp, mod, set
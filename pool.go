@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
 	"go.uber.org/multierr"
 )
 
@@ -26,10 +27,17 @@ type Pool struct {
 	discardConnectionAge time.Duration
 
 	available, all, reused []Conn
+	dialing                int // Number of connections being established, see newConn
 	waiting                int
+	totalWaits             int64
+	maxWait                time.Duration
+	throttle               int
+	throttledAt            time.Time
+	backoffs               int64
 	ready                  chan Conn
 	closed                 bool
 	closeErr               error
+	idle                   chan struct{}
 	lock                   sync.Mutex
 
 	*api.API
@@ -52,6 +60,9 @@ func newPool(client *Client) *Pool {
 			return pool.Connect(ctx)
 		},
 		// DefaultResource: client.env.DefaultResource,
+		DefaultResourceFunc: client.defaultResourceOverride,
+		QueryBatchSize:      client.option.QueryBatchSize,
+		URLTemplate:         client.env.URLTemplate,
 	}
 
 	if client.option.Admin {
@@ -103,6 +114,16 @@ func (c *Client) ReplaceDefaultPool(pool *Pool) *Pool {
 // Pool returns a subpool of connections
 // It will block until the requested number of connections are available.
 func (p *Pool) Pool(size int) (*Pool, error) {
+	return p.pool(size, true)
+}
+
+// pool returns a subpool of connections. If wait is false, it fails with
+// ErrNoConnectionsAvailable instead of blocking if the connections are in use.
+func (p *Pool) pool(size int, wait bool) (*Pool, error) {
+	if p.client.shuttingDown.Load() {
+		return nil, ErrShuttingDown
+	}
+
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -114,10 +135,14 @@ func (p *Pool) Pool(size int) (*Pool, error) {
 		return nil, fmt.Errorf("%w: parent pool has %d connections, requested %d", ErrNoConnectionsAvailable, p.maxConns, size)
 	}
 
+	if !wait && p.inUse() > p.maxConns-size {
+		return nil, fmt.Errorf("%w: %d of %d connections are in use, requested %d", ErrNoConnectionsAvailable, p.inUse(), p.maxConns, size)
+	}
+
 	child := newChildPool(p, size)
 
 	// We need to shrink the parent pool
-	for len(p.all) > p.maxConns {
+	for len(p.all)+p.dialing > p.maxConns {
 		if len(p.available) > 0 {
 			conn := p.available[0]
 			p.available = p.available[1:]
@@ -155,6 +180,24 @@ func (p *Pool) Pool(size int) (*Pool, error) {
 // If the maximum number of connections has been reached, it will block until a connection becomes available,
 // or reuse an existing connection in case AllowConcurrentUse is enabled.
 func (p *Pool) Connect(ctx context.Context) (Conn, error) {
+	if p.client.tracer == nil {
+		return p.connect(ctx)
+	}
+
+	ctx, span := p.client.tracer.Start(ctx, "iron.pool.connect")
+
+	conn, err := p.connect(ctx)
+
+	endSpan(span, err)
+
+	return conn, err
+}
+
+func (p *Pool) connect(ctx context.Context) (Conn, error) {
+	if p.client.shuttingDown.Load() {
+		return nil, ErrShuttingDown
+	}
+
 	p.lock.Lock()
 
 	if conn, err := p.tryConnect(ctx); err != ErrNoConnectionsAvailable {
@@ -163,7 +206,8 @@ func (p *Pool) Connect(ctx context.Context) (Conn, error) {
 		return conn, err
 	}
 
-	if p.allowConcurrentUse {
+	// Connections that are still being established can't be reused yet
+	if p.allowConcurrentUse && len(p.all) > 0 {
 		defer p.lock.Unlock()
 
 		first := p.all[0]
@@ -181,13 +225,19 @@ func (p *Pool) Connect(ctx context.Context) (Conn, error) {
 	p.waiting++
 	p.lock.Unlock()
 
-	if conn := <-p.ready; conn != nil {
-		return &returnOnClose{Conn: conn, pool: p}, nil
-	}
+	waitStart := time.Now()
+	conn := <-p.ready
 
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
+	p.totalWaits++
+	p.maxWait = max(p.maxWait, time.Since(waitStart))
+
+	if conn != nil {
+		return &returnOnClose{Conn: conn, pool: p}, nil
+	}
+
 	// We received a token from a returned connection that was closed
 	// In this case we are allowed to create a new connection
 	conn, err := p.newConn(ctx)
@@ -198,11 +248,47 @@ func (p *Pool) Connect(ctx context.Context) (Conn, error) {
 	return &returnOnClose{Conn: conn, pool: p}, nil
 }
 
+// PoolStats is a snapshot of the usage of a pool.
+type PoolStats struct {
+	MaxConns   int           // Maximum number of connections in the pool
+	Total      int           // Number of established connections
+	Available  int           // Number of idle connections
+	InUse      int           // Number of connections in use, including concurrently reused connections and connections being established
+	Waiting    int           // Number of callers currently waiting for a connection
+	TotalWaits int64         // Number of times a caller had to wait for a connection
+	MaxWait    time.Duration // Longest time a caller had to wait for a connection
+	Limit      int           // Number of connections that may be in use, lower than MaxConns if the server is overloaded
+	Backoffs   int64         // Number of times the limit was reduced because the server is overloaded
+}
+
+// Stats returns a snapshot of the usage of the pool.
+// Connections of subpools are not included.
+func (p *Pool) Stats() PoolStats {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return PoolStats{
+		MaxConns:   p.maxConns,
+		Total:      len(p.all),
+		Available:  len(p.available),
+		InUse:      p.inUse(),
+		Waiting:    p.waiting,
+		TotalWaits: p.totalWaits,
+		MaxWait:    p.maxWait,
+		Limit:      p.limit(),
+		Backoffs:   p.backoffs,
+	}
+}
+
 // ConnectAvailable returns a list of available connections to the iRODS server,
 // up to the specified number. If no connections are available, it will return
 // an empty list. Retrieved connections must be closed by the caller.
 // If n is negative, it will return all available connections.
 func (p *Pool) ConnectAvailable(ctx context.Context, n int) ([]Conn, error) {
+	if p.client.shuttingDown.Load() {
+		return nil, ErrShuttingDown
+	}
+
 	p.lock.Lock()
 	defer p.lock.Unlock()
 
@@ -234,15 +320,27 @@ func closeAll(pool []Conn) error {
 	return err
 }
 
-var ErrNoConnectionsAvailable = errors.New("no connections available")
+var (
+	ErrNoConnectionsAvailable = errors.New("no connections available")
+	ErrShuttingDown           = errors.New("client is shutting down")
+	ErrPoolClosed             = errors.New("pool is closed")
+)
 
 func (p *Pool) tryConnect(ctx context.Context) (Conn, error) {
 	p.discardOldConnections()
 
-	if len(p.available) > 0 {
+	if p.throttle > 0 && p.inUse() >= p.limit() {
+		return nil, ErrNoConnectionsAvailable
+	}
+
+	for len(p.available) > 0 {
 		conn := p.available[0]
 		p.available = p.available[1:]
 
+		if !p.healthy(conn) {
+			continue
+		}
+
 		p.client.firstUse.Do(func() {
 			if p.client.option.AtFirstUse != nil {
 				p.client.option.AtFirstUse(conn.API())
@@ -252,7 +350,7 @@ func (p *Pool) tryConnect(ctx context.Context) (Conn, error) {
 		return &returnOnClose{Conn: conn, pool: p}, nil
 	}
 
-	if len(p.all) < p.maxConns {
+	if len(p.all)+p.dialing < p.maxConns {
 		conn, err := p.newConn(ctx)
 		if err != nil {
 			return nil, err
@@ -270,20 +368,96 @@ func (p *Pool) tryConnect(ctx context.Context) (Conn, error) {
 	return nil, ErrNoConnectionsAvailable
 }
 
+// healthy runs the health check on an idle connection that was taken from the available list.
+// It must be called with the lock held. The lock is released while the check runs, so that
+// a slow check does not block the pool. Unhealthy connections are closed and unregistered,
+// so that a replacement can be created.
+func (p *Pool) healthy(conn Conn) bool {
+	if p.client.option.HealthCheck == nil {
+		return true
+	}
+
+	p.lock.Unlock()
+
+	ok := p.client.option.HealthCheck(conn)
+
+	p.lock.Lock()
+
+	if ok {
+		return true
+	}
+
+	if !p.unregister(conn) && p.closed && p.parent != nil {
+		// The pool was closed during the check and the connection was handed over to the parent
+		p.parent.lock.Lock()
+
+		if p.parent.unregister(conn) {
+			p.parent.releaseSlot()
+		}
+
+		p.parent.lock.Unlock()
+	}
+
+	conn.Close()
+
+	return false
+}
+
+// newConn establishes a new connection and registers it in the pool.
+// It must be called with the lock held. A slot is reserved for the connection,
+// and the lock is released while dialing, so that the pool can be used
+// while the client backs off according to the ReconnectPolicy.
 func (p *Pool) newConn(ctx context.Context) (Conn, error) {
+	p.dialing++
+	p.lock.Unlock()
+
 	conn, err := p.client.newConn(ctx)
+
+	p.lock.Lock()
+	p.dialing--
+
+	if overloaded(err) {
+		p.backoff(err)
+	}
+
 	if err != nil {
+		p.releaseSlot()
+
 		return nil, err
 	}
 
+	if p.closed && p.parent != nil {
+		// The connections of the pool were handed over to the parent pool,
+		// hand over the new connection too, it will be returned to the parent
+		p.parent.lock.Lock()
+		p.parent.all = append(p.parent.all, conn)
+		p.parent.lock.Unlock()
+
+		return conn, nil
+	} else if p.closed {
+		return nil, multierr.Append(ErrPoolClosed, conn.Close())
+	}
+
 	p.all = append(p.all, conn)
 
 	return conn, nil
 }
 
+// releaseSlot informs a waiting caller that a reserved slot has become
+// free, so that it is allowed to call newConn().
+func (p *Pool) releaseSlot() {
+	defer p.notifyIdle()
+
+	if p.waiting > 0 {
+		p.waiting--
+		p.ready <- nil
+	}
+}
+
 func (p *Pool) returnConn(conn Conn) error {
 	p.lock.Lock()
 	defer p.lock.Unlock()
+	defer p.notifyIdle()
 
 	// If the pool is closed, return the connection to the parent pool
 	if p.closed && p.parent != nil {
@@ -315,8 +489,11 @@ func (p *Pool) returnConn(conn Conn) error {
 		return conn.Close()
 	}
 
-	// If someone is waiting for a connection, pass the connection to them
-	if p.waiting > 0 {
+	p.recover()
+
+	// If someone is waiting for a connection, pass the connection to them,
+	// unless the limit was reduced and the connection needs to become idle
+	if p.waiting > 0 && (p.throttle == 0 || p.inUse() <= p.limit()) {
 		p.waiting--
 		p.ready <- conn
 
@@ -328,6 +505,57 @@ func (p *Pool) returnConn(conn Conn) error {
 	return nil
 }
 
+// inUse returns the number of connections that have not been returned to the pool,
+// including connections that are being established.
+func (p *Pool) inUse() int {
+	return len(p.all) - len(p.available) + len(p.reused) + p.dialing
+}
+
+// notifyIdle wakes up drain() if all connections have been returned.
+func (p *Pool) notifyIdle() {
+	if p.idle != nil && p.inUse() == 0 {
+		close(p.idle)
+
+		p.idle = nil
+	}
+}
+
+// drain closes all subpools and waits until all connections
+// have been returned to the pool, or until the context is done.
+func (p *Pool) drain(ctx context.Context) error {
+	p.lock.Lock()
+	children := p.children
+	p.lock.Unlock()
+
+	for _, child := range children {
+		child.Close() //nolint:errcheck // The error is reported again by p.Close()
+	}
+
+	for {
+		p.lock.Lock()
+
+		if p.inUse() == 0 {
+			p.lock.Unlock()
+
+			return nil
+		}
+
+		if p.idle == nil {
+			p.idle = make(chan struct{})
+		}
+
+		idle := p.idle
+
+		p.lock.Unlock()
+
+		select {
+		case <-idle:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
 func (p *Pool) unregister(conn Conn) bool {
 	for i := range p.all {
 		if p.all[i] != conn {
@@ -435,6 +663,52 @@ type returnOnClose struct {
 	pool     *Pool
 }
 
+// Request sends an API request, recording a span if the client has a tracer.
+func (r *returnOnClose) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	return r.RequestWithBuffers(ctx, apiNumber, request, response, nil, nil)
+}
+
+// RequestWithBuffers sends an API request with buffers, recording a span if the client has a tracer.
+// If the server reports to be overloaded, the concurrency limit of the pool is reduced.
+func (r *returnOnClose) RequestWithBuffers(ctx context.Context, apiNumber msg.APINumber, request, response any, requestBuf, responseBuf []byte) error {
+	err := r.request(ctx, apiNumber, request, response, requestBuf, responseBuf)
+	if overloaded(err) {
+		r.pool.lock.Lock()
+		r.pool.backoff(err)
+		r.pool.lock.Unlock()
+	}
+
+	return err
+}
+
+func (r *returnOnClose) request(ctx context.Context, apiNumber msg.APINumber, request, response any, requestBuf, responseBuf []byte) error {
+	if r.pool.client.tracer == nil {
+		return r.Conn.RequestWithBuffers(ctx, apiNumber, request, response, requestBuf, responseBuf)
+	}
+
+	attributes := []Attribute{
+		{AttributeAPINumber, int(apiNumber)},
+		{AttributeRequestBytes, len(requestBuf)},
+	}
+
+	if path := requestPath(request); path != "" {
+		attributes = append(attributes, Attribute{AttributePath, path})
+	}
+
+	ctx, span := r.pool.client.tracer.Start(ctx, "iron.request", attributes...)
+
+	err := r.Conn.RequestWithBuffers(ctx, apiNumber, request, response, requestBuf, responseBuf)
+
+	// The response buffer is only filled up to the number of bytes that were read
+	if n, ok := response.(*msg.ReadResponse); ok && err == nil {
+		span.SetAttributes(Attribute{AttributeResponseBytes, int(*n)})
+	}
+
+	endSpan(span, err)
+
+	return err
+}
+
 func (r *returnOnClose) Close() error {
 	r.once.Do(func() {
 		r.closeErr = r.pool.returnConn(r.Conn)
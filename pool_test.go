@@ -4,6 +4,7 @@ package iron
 import (
 	"context"
 	"errors"
+	"io"
 	"net"
 	"sync"
 	"testing"
@@ -254,6 +255,65 @@ func TestPoolConcurrentUse(t *testing.T) {
 	conn2.Close()
 }
 
+func TestPoolConcurrentUseWhileDialing(t *testing.T) {
+	client := newTestClient(1)
+	defer client.Close()
+
+	client.option.AllowConcurrentUse = true
+	client.defaultPool.allowConcurrentUse = true
+
+	dialing := make(chan struct{})
+	proceed := make(chan struct{})
+
+	client.option.HandshakeFunc = func(ctx context.Context) (Conn, error) {
+		close(dialing)
+		<-proceed
+
+		return newMockPoolConn(), nil
+	}
+
+	first := make(chan error, 1)
+
+	go func() {
+		conn, err := client.Connect(t.Context())
+		if err == nil {
+			err = conn.Close()
+		}
+
+		first <- err
+	}()
+
+	<-dialing
+
+	// No connection can be reused while the only slot is dialing, wait for it instead
+	second := make(chan error, 1)
+
+	go func() {
+		conn, err := client.Connect(t.Context())
+		if err == nil {
+			err = conn.Close()
+		}
+
+		second <- err
+	}()
+
+	for client.Stats().Waiting == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	close(proceed)
+
+	for _, result := range []chan error{first, second} {
+		if err := <-result; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if stats := client.Stats(); stats.Total != 1 || stats.InUse != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
 func TestPoolSubpool(t *testing.T) {
 	client := newTestClient(4)
 	defer client.Close()
@@ -674,3 +734,389 @@ func TestClientOption(t *testing.T) {
 		t.Errorf("expected ClientName='test', got %q", opt.ClientName)
 	}
 }
+
+func TestClientShutdown(t *testing.T) {
+	client := newTestClient(2)
+
+	conn, err := client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+
+		conn.Close()
+	}()
+
+	if err := client.Shutdown(t.Context()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !conn.(*returnOnClose).Conn.(*mockPoolConn).closed {
+		t.Error("expected connection to be closed")
+	}
+
+	if _, err := client.Connect(t.Context()); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown, got %v", err)
+	}
+}
+
+func TestClientShutdownDeadline(t *testing.T) {
+	client := newTestClient(2)
+
+	pool, err := client.Pool(1)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn, err := pool.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	if err := client.Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected deadline exceeded, got %v", err)
+	}
+
+	if !conn.(*returnOnClose).Conn.(*mockPoolConn).closed {
+		t.Error("expected outstanding connection to be closed forcibly")
+	}
+
+	if _, err := client.Pool(1); !errors.Is(err, ErrShuttingDown) {
+		t.Errorf("expected ErrShuttingDown, got %v", err)
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Errorf("unexpected error returning connection: %v", err)
+	}
+}
+
+func TestPoolStats(t *testing.T) {
+	client := newTestClient(1)
+	defer client.Close()
+
+	conn, err := client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stats := client.Stats()
+
+	if stats.MaxConns != 1 || stats.Total != 1 || stats.Available != 0 || stats.InUse != 1 || stats.TotalWaits != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+
+		conn.Close()
+	}()
+
+	conn, err = client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Close()
+
+	stats = client.Stats()
+
+	if stats.Total != 1 || stats.Available != 1 || stats.InUse != 0 || stats.Waiting != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	if stats.TotalWaits != 1 || stats.MaxWait < 40*time.Millisecond {
+		t.Fatalf("expected one wait of at least 40ms, got %+v", stats)
+	}
+}
+
+func TestReconnectPolicy(t *testing.T) {
+	client := newTestClient(1)
+	defer client.Close()
+
+	var attempts int
+
+	client.option.ReconnectPolicy = ReconnectPolicy{
+		MaxRetries:     5,
+		InitialBackoff: time.Millisecond,
+		MaxBackoff:     4 * time.Millisecond,
+	}
+
+	client.option.HandshakeFunc = func(ctx context.Context) (Conn, error) {
+		attempts++
+
+		if attempts <= 3 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return newMockPoolConn(), nil
+	}
+
+	conn, err := client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if attempts != 4 {
+		t.Fatalf("expected 4 attempts, got %d", attempts)
+	}
+}
+
+func TestReconnectPolicyGiveUp(t *testing.T) {
+	client := newTestClient(1)
+	defer client.Close()
+
+	var attempts int
+
+	client.option.ReconnectPolicy = ReconnectPolicy{
+		MaxRetries:     2,
+		InitialBackoff: time.Millisecond,
+	}
+
+	client.option.HandshakeFunc = func(ctx context.Context) (Conn, error) {
+		attempts++
+
+		if attempts == 1 {
+			return nil, io.ErrUnexpectedEOF
+		}
+
+		return nil, &msg.IRODSError{Code: msg.CAT_INVALID_AUTHENTICATION}
+	}
+
+	if _, err := client.Connect(t.Context()); !api.Is(err, msg.CAT_INVALID_AUTHENTICATION) {
+		t.Fatalf("expected CAT_INVALID_AUTHENTICATION, got %v", err)
+	}
+
+	if attempts != 2 {
+		t.Fatalf("expected server errors not to be retried, got %d attempts", attempts)
+	}
+
+	attempts = 0
+
+	client.option.HandshakeFunc = func(ctx context.Context) (Conn, error) {
+		attempts++
+
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	if _, err := client.Connect(t.Context()); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Fatalf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	if attempts != 3 {
+		t.Fatalf("expected 3 attempts, got %d", attempts)
+	}
+}
+
+func TestReconnectPolicyUnlocked(t *testing.T) {
+	client := newTestClient(2)
+	defer client.Close()
+
+	conn, err := client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	client.option.ReconnectPolicy = ReconnectPolicy{
+		MaxRetries:     1,
+		InitialBackoff: 500 * time.Millisecond,
+	}
+
+	failed := make(chan struct{})
+
+	client.option.HandshakeFunc = func(ctx context.Context) (Conn, error) {
+		select {
+		case <-failed:
+			return newMockPoolConn(), nil
+		default:
+			close(failed)
+
+			return nil, io.ErrUnexpectedEOF
+		}
+	}
+
+	result := make(chan error, 1)
+
+	go func() {
+		conn, err := client.Connect(t.Context())
+		if err == nil {
+			err = conn.Close()
+		}
+
+		result <- err
+	}()
+
+	<-failed
+
+	// While the second connection is backing off, the pool must remain usable
+	done := make(chan PoolStats)
+
+	go func() {
+		conn.Close()
+
+		done <- client.Stats()
+	}()
+
+	select {
+	case stats := <-done:
+		if stats.Total != 1 || stats.Available != 1 || stats.InUse != 1 {
+			t.Fatalf("unexpected stats: %+v", stats)
+		}
+	case <-time.After(250 * time.Millisecond):
+		t.Fatal("expected the pool not to be locked during the backoff")
+	}
+
+	if err := <-result; err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := client.Stats(); stats.Total != 2 || stats.InUse != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestReconnectPolicyBackoff(t *testing.T) {
+	policy := ReconnectPolicy{
+		InitialBackoff: time.Second,
+		MaxBackoff:     5 * time.Second,
+	}
+
+	for retry, expected := range []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second} {
+		if backoff := policy.backoff(retry); backoff != expected {
+			t.Errorf("retry %d: expected %s, got %s", retry, expected, backoff)
+		}
+	}
+
+	if backoff := (ReconnectPolicy{}).backoff(0); backoff != 100*time.Millisecond {
+		t.Errorf("expected default backoff of 100ms, got %s", backoff)
+	}
+}
+
+func TestPoolHealthCheck(t *testing.T) {
+	client := newTestClient(1)
+	defer client.Close()
+
+	stale := map[Conn]bool{}
+
+	client.option.HealthCheck = func(conn Conn) bool {
+		return !stale[conn]
+	}
+
+	ctx := t.Context()
+
+	conn1, err := client.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := conn1.(*returnOnClose).Conn
+
+	if err = conn1.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A healthy connection is reused
+	conn2, err := client.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if conn2.(*returnOnClose).Conn != first {
+		t.Fatal("expected healthy connection to be reused")
+	}
+
+	if err = conn2.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A stale connection is replaced
+	stale[first] = true
+
+	conn3, err := client.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn3.Close()
+
+	if conn3.(*returnOnClose).Conn == first {
+		t.Fatal("expected stale connection to be replaced")
+	}
+
+	if !first.(*mockPoolConn).closed {
+		t.Fatal("expected stale connection to be closed")
+	}
+
+	if stats := client.Stats(); stats.Total != 1 {
+		t.Fatalf("expected 1 connection, got %d", stats.Total)
+	}
+}
+
+func TestPoolHealthCheckUnlocked(t *testing.T) {
+	client := newTestClient(1)
+	defer client.Close()
+
+	ctx := t.Context()
+
+	conn, err := client.Connect(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = conn.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	checking := make(chan struct{})
+	proceed := make(chan struct{})
+
+	client.option.HealthCheck = func(Conn) bool {
+		close(checking)
+		<-proceed
+
+		return true
+	}
+
+	done := make(chan error, 1)
+
+	go func() {
+		conn, err := client.Connect(ctx)
+		if err == nil {
+			err = conn.Close()
+		}
+
+		done <- err
+	}()
+
+	<-checking
+
+	// The pool must remain usable while the health check runs
+	stats := make(chan PoolStats, 1)
+
+	go func() {
+		stats <- client.Stats()
+	}()
+
+	select {
+	case s := <-stats:
+		if s.InUse != 1 {
+			t.Errorf("expected the checked connection to be in use, got %d", s.InUse)
+		}
+	case <-time.After(time.Second):
+		t.Error("pool is locked during the health check")
+	}
+
+	close(proceed)
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+}
@@ -9,7 +9,6 @@ import (
 	"runtime"
 	"strings"
 
-	"github.com/sirupsen/logrus"
 	"golang.org/x/term"
 )
 
@@ -39,7 +38,7 @@ func (p *prompt) Print(message string) error {
 	// If we need to open an URL, print a newline
 	if result := AuthenticateURL.FindStringSubmatch(message); len(result) > 1 {
 		if err := SystemOpenBrowser(result[1]); err != nil {
-			logrus.Debugf("could not open browser: %s", err)
+			connLog.Debugf("could not open browser: %s", err)
 		}
 	}
 
@@ -0,0 +1,35 @@
+package iron
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type requestIDKey struct{}
+
+// WithRequestID returns a copy of ctx that carries the given request ID, to correlate
+// client operations with server logs. The request ID is included in log messages, and
+// in the option string of the startup pack of connections that are established using
+// the returned context, where it ends up in the server logs. As the option string is
+// limited in length by the server, the request ID should be short.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID returns the request ID attached to ctx by WithRequestID,
+// or an empty string if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+
+	return id
+}
+
+// logger returns a logger that includes the request ID of ctx, if there is one.
+func logger(ctx context.Context) logrus.FieldLogger {
+	if id := RequestID(ctx); id != "" {
+		return connLog.WithField("request_id", id)
+	}
+
+	return connLog
+}
@@ -0,0 +1,67 @@
+package iron
+
+import (
+	"io"
+	"net"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestRequestID(t *testing.T) {
+	if id := RequestID(t.Context()); id != "" {
+		t.Fatalf("expected no request ID, got %q", id)
+	}
+
+	ctx := WithRequestID(t.Context(), "abc123")
+
+	if id := RequestID(ctx); id != "abc123" {
+		t.Fatalf("expected request ID abc123, got %q", id)
+	}
+}
+
+func TestStartupRequestID(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	options := make(chan string, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			t.Error(err)
+
+			return
+		}
+
+		var pack msg.StartupPack
+
+		if _, err := msg.Read(conn, &pack, nil, msg.XML, "RODS_CONNECT"); err != nil {
+			t.Error(err)
+		}
+
+		options <- pack.Option
+
+		conn.Close()
+	}()
+
+	tcpAddr, ok := listener.Addr().(*net.TCPAddr)
+	if !ok {
+		t.Fatalf("expected TCP address, got %T", listener.Addr())
+	}
+
+	env := Env{Host: "127.0.0.1", Port: tcpAddr.Port}
+
+	env.ApplyDefaults()
+
+	_, err = Dial(WithRequestID(t.Context(), "abc123"), env, "test")
+	if err != io.EOF {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+
+	if option := <-options; option != "test[abc123]"+requestServerNegotiationToken {
+		t.Fatalf("expected option test[abc123]%s, got %q", requestServerNegotiationToken, option)
+	}
+}
@@ -28,3 +28,34 @@ func TestEncodeDecodeA(t *testing.T) {
 		}
 	}
 }
+
+func TestIrodsAKnownVectors(t *testing.T) {
+	mtime := time.Unix(1700000000, 0)
+
+	vectors := []struct {
+		password string
+		uid      int
+		encoded  string
+	}{
+		{"passw0rd", 1000, ".jis*fezt-)0Hzv\x00"},
+		{"rods", 0, ".qpz1me)/%0\x00"},
+		{"!-@#%&", 501, ".87HY4eZu@hml\x00"},
+	}
+
+	for i, v := range vectors {
+		if encoded := EncodeIrodsA(v.password, v.uid, mtime); string(encoded) != v.encoded {
+			t.Errorf("[%d] expected %q, got %q", i, v.encoded, encoded)
+		}
+
+		decoded, err := DecodeIrodsA([]byte(v.encoded), v.uid)
+		if err != nil {
+			t.Errorf("[%d] %v", i, err)
+
+			continue
+		}
+
+		if decoded != v.password {
+			t.Errorf("[%d] expected %q, got %q", i, v.password, decoded)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package iron
+
+import (
+	"time"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+)
+
+// If the server reports to be overloaded, the number of connections of a pool that may be in use
+// is halved, at most once per backoffInterval. The limit is raised again by one connection per
+// recoveryInterval, as long as connections are returned without errors.
+const (
+	backoffInterval  = time.Second
+	recoveryInterval = 10 * time.Second
+)
+
+// overloaded returns true if the error indicates that the server is overloaded.
+func overloaded(err error) bool {
+	return api.Is(err, msg.CAT_STATEMENT_TABLE_FULL) || api.Is(err, msg.SYS_MAX_CONNECT_COUNT_EXCEEDED)
+}
+
+// limit returns the number of connections that may be in use.
+func (p *Pool) limit() int {
+	return max(1, p.maxConns-p.throttle)
+}
+
+// backoff halves the number of connections that may be in use.
+// It must be called with the lock held.
+func (p *Pool) backoff(err error) {
+	if p.throttle > 0 && time.Since(p.throttledAt) < backoffInterval {
+		return
+	}
+
+	limit := max(1, p.limit()/2)
+
+	p.throttle = p.maxConns - limit
+	p.throttledAt = time.Now()
+	p.backoffs++
+
+	poolLog.Warnf("server is overloaded: %v, reducing concurrency to %d connections", err, limit)
+}
+
+// recover raises the number of connections that may be in use by one,
+// if the limit was not changed during the last recoveryInterval.
+// It must be called with the lock held.
+func (p *Pool) recover() {
+	if p.throttle == 0 || time.Since(p.throttledAt) < recoveryInterval {
+		return
+	}
+
+	p.throttle--
+	p.throttledAt = time.Now()
+
+	poolLog.Infof("increasing concurrency to %d connections", p.limit())
+}
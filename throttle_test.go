@@ -0,0 +1,130 @@
+package iron
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+// overloadedConn is a mock connection of which all requests fail with the given error code.
+type overloadedConn struct {
+	*mockPoolConn
+	code msg.ErrorCode
+}
+
+func (o *overloadedConn) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	return o.RequestWithBuffers(ctx, apiNumber, request, response, nil, nil)
+}
+
+func (o *overloadedConn) RequestWithBuffers(_ context.Context, _ msg.APINumber, _, _ any, _, _ []byte) error {
+	return &msg.IRODSError{Code: o.code}
+}
+
+func TestPoolBackoff(t *testing.T) {
+	client := newTestClient(4)
+	client.option.HandshakeFunc = func(ctx context.Context) (Conn, error) {
+		return &overloadedConn{mockPoolConn: newMockPoolConn(), code: msg.CAT_STATEMENT_TABLE_FULL}, nil
+	}
+
+	defer client.Close()
+
+	conn1, err := client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = conn1.Request(t.Context(), msg.GEN_QUERY_AN, msg.QueryRequest{}, &msg.QueryResponse{}); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if stats := client.Stats(); stats.Limit != 2 || stats.Backoffs != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// A second failure right after the first one does not reduce the limit further
+	conn1.Request(t.Context(), msg.GEN_QUERY_AN, msg.QueryRequest{}, &msg.QueryResponse{}) //nolint:errcheck
+
+	if stats := client.Stats(); stats.Limit != 2 || stats.Backoffs != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	conn2, err := client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The third connection must wait until one is returned
+	done := make(chan Conn)
+
+	go func() {
+		conn3, err := client.Connect(t.Context())
+		if err != nil {
+			t.Error(err)
+		}
+
+		done <- conn3
+	}()
+
+	time.Sleep(50 * time.Millisecond)
+
+	if stats := client.Stats(); stats.Waiting != 1 || stats.InUse != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	// Pretend the limit was reduced a while ago, so that returning a connection recovers one slot
+	client.defaultPool.lock.Lock()
+	client.defaultPool.throttledAt = time.Now().Add(-recoveryInterval)
+	client.defaultPool.lock.Unlock()
+
+	conn1.Close()
+
+	conn3 := <-done
+
+	if stats := client.Stats(); stats.Limit != 3 || stats.InUse != 2 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+
+	conn2.Close()
+	conn3.Close()
+}
+
+func TestPoolBackoffHandshake(t *testing.T) {
+	client := newTestClient(4)
+	client.option.HandshakeFunc = func(ctx context.Context) (Conn, error) {
+		return nil, &msg.IRODSError{Code: msg.SYS_MAX_CONNECT_COUNT_EXCEEDED}
+	}
+
+	defer client.Close()
+
+	if _, err := client.Connect(t.Context()); err == nil {
+		t.Fatal("expected error")
+	}
+
+	if stats := client.Stats(); stats.Limit != 2 || stats.Backoffs != 1 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
+
+func TestPoolNoBackoff(t *testing.T) {
+	client := newTestClient(4)
+	client.option.HandshakeFunc = func(ctx context.Context) (Conn, error) {
+		return &overloadedConn{mockPoolConn: newMockPoolConn(), code: msg.CAT_NO_ROWS_FOUND}, nil
+	}
+
+	defer client.Close()
+
+	conn, err := client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	conn.Request(t.Context(), msg.GEN_QUERY_AN, msg.QueryRequest{}, &msg.QueryResponse{}) //nolint:errcheck
+
+	if stats := client.Stats(); stats.Limit != 4 || stats.Backoffs != 0 {
+		t.Fatalf("unexpected stats: %+v", stats)
+	}
+}
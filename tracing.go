@@ -0,0 +1,100 @@
+package iron
+
+import (
+	"context"
+	"errors"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+// TracerProvider creates tracers to instrument requests, pool acquisition and transfers.
+// It mirrors the OpenTelemetry API, so that an OpenTelemetry tracer provider can be plugged
+// in using a small adapter, without this module depending on OpenTelemetry.
+type TracerProvider interface {
+	Tracer(name string) Tracer
+}
+
+// Tracer starts spans. The returned context carries the span,
+// so that nested operations become child spans.
+type Tracer interface {
+	Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attributes ...Attribute)
+
+	// End completes the span. If err is not nil, the span is marked as failed.
+	End(err error)
+}
+
+// Attribute is a key-value pair that describes a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attribute keys used in spans
+const (
+	AttributeAPINumber     = "irods.api_number"
+	AttributePath          = "irods.path"
+	AttributeTargetPath    = "irods.target_path"
+	AttributeLocalPath     = "irods.local_path"
+	AttributeRequestBytes  = "irods.request_bytes"
+	AttributeResponseBytes = "irods.response_bytes"
+	AttributeBytes         = "irods.bytes"
+	AttributeErrorCode     = "irods.error_code"
+)
+
+// tracerName is the name of the tracer that is requested from the TracerProvider
+const tracerName = "github.com/kuleuven/iron"
+
+// trace runs fn in a new span if the client has a tracer. The returned attributes
+// of fn are added to the span.
+func (c *Client) trace(ctx context.Context, name string, attributes []Attribute, fn func(ctx context.Context) ([]Attribute, error)) error {
+	if c.tracer == nil {
+		_, err := fn(ctx)
+
+		return err
+	}
+
+	ctx, span := c.tracer.Start(ctx, name, attributes...)
+
+	extra, err := fn(ctx)
+
+	span.SetAttributes(extra...)
+
+	endSpan(span, err)
+
+	return err
+}
+
+// endSpan ends the span, adding the iRODS error code if the error has one.
+func endSpan(span Span, err error) {
+	var irodsErr *msg.IRODSError
+
+	if errors.As(err, &irodsErr) {
+		span.SetAttributes(Attribute{AttributeErrorCode, int(irodsErr.Code)})
+	}
+
+	span.End(err)
+}
+
+// requestPath returns the path targeted by a request, for the most common requests.
+func requestPath(request any) string {
+	switch r := request.(type) {
+	case msg.DataObjectRequest:
+		return r.Path
+	case *msg.DataObjectRequest:
+		return r.Path
+	case msg.CreateCollectionRequest:
+		return r.Name
+	case msg.ModifyAccessRequest:
+		return r.Path
+	case msg.TouchDataObjectReplicaRequest:
+		return r.Path
+	default:
+		return ""
+	}
+}
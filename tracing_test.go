@@ -0,0 +1,182 @@
+package iron
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/kuleuven/iron/msg"
+)
+
+type recordedSpan struct {
+	name       string
+	attributes map[string]any
+	err        error
+	ended      bool
+}
+
+type recordingTracer struct {
+	spans []*recordedSpan
+	lock  sync.Mutex
+}
+
+func (r *recordingTracer) Tracer(name string) Tracer {
+	return r
+}
+
+func (r *recordingTracer) Start(ctx context.Context, name string, attributes ...Attribute) (context.Context, Span) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	span := &recordedSpan{
+		name:       name,
+		attributes: map[string]any{},
+	}
+
+	span.SetAttributes(attributes...)
+
+	r.spans = append(r.spans, span)
+
+	return ctx, span
+}
+
+func (s *recordedSpan) SetAttributes(attributes ...Attribute) {
+	for _, attr := range attributes {
+		s.attributes[attr.Key] = attr.Value
+	}
+}
+
+func (s *recordedSpan) End(err error) {
+	s.err = err
+	s.ended = true
+}
+
+func TestTracing(t *testing.T) {
+	tracer := &recordingTracer{}
+
+	client := newTestClient(1)
+	client.tracer = tracer.Tracer(tracerName)
+
+	defer client.Close()
+
+	conn, err := client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	request := msg.CreateCollectionRequest{Name: "/testZone/home/test"}
+
+	if err = conn.Request(t.Context(), msg.COLL_CREATE_AN, request, &msg.EmptyResponse{}); err != nil {
+		t.Fatal(err)
+	}
+
+	conn.Close()
+
+	if len(tracer.spans) != 2 {
+		t.Fatalf("expected 2 spans, got %d", len(tracer.spans))
+	}
+
+	if span := tracer.spans[0]; span.name != "iron.pool.connect" || !span.ended || span.err != nil {
+		t.Errorf("unexpected span: %+v", span)
+	}
+
+	span := tracer.spans[1]
+
+	if span.name != "iron.request" || !span.ended || span.err != nil {
+		t.Errorf("unexpected span: %+v", span)
+	}
+
+	if span.attributes[AttributeAPINumber] != int(msg.COLL_CREATE_AN) {
+		t.Errorf("expected api number %d, got %v", msg.COLL_CREATE_AN, span.attributes[AttributeAPINumber])
+	}
+
+	if span.attributes[AttributePath] != "/testZone/home/test" {
+		t.Errorf("expected path /testZone/home/test, got %v", span.attributes[AttributePath])
+	}
+}
+
+func TestTracingResponseBytes(t *testing.T) {
+	tracer := &recordingTracer{}
+
+	client := newTestClient(1)
+	client.tracer = tracer.Tracer(tracerName)
+
+	defer client.Close()
+
+	conn, err := client.Connect(t.Context())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer conn.Close()
+
+	// The mock connection leaves the response untouched, as if 5 bytes were read
+	response := msg.ReadResponse(5)
+
+	if err = conn.RequestWithBuffers(t.Context(), msg.DATA_OBJ_READ_AN, msg.OpenedDataObjectRequest{Size: 100}, &response, nil, make([]byte, 100)); err != nil {
+		t.Fatal(err)
+	}
+
+	span := tracer.spans[1]
+
+	if span.attributes[AttributeResponseBytes] != 5 {
+		t.Errorf("expected 5 response bytes, got %v", span.attributes[AttributeResponseBytes])
+	}
+}
+
+func TestTracingVerify(t *testing.T) {
+	tracer := &recordingTracer{}
+
+	client := newTestClient(1)
+	client.tracer = tracer.Tracer(tracerName)
+
+	defer client.Close()
+
+	if err := client.Verify(t.Context(), "/nonexistent/file", "/testZone/home/test/file"); err == nil {
+		t.Fatal("expected error")
+	}
+
+	span := tracer.spans[0]
+
+	if span.name != "iron.verify" || !span.ended || span.err == nil {
+		t.Errorf("unexpected span: %+v", span)
+	}
+
+	if span.attributes[AttributeLocalPath] != "/nonexistent/file" {
+		t.Errorf("expected local path /nonexistent/file, got %v", span.attributes[AttributeLocalPath])
+	}
+}
+
+func TestEndSpanErrorCode(t *testing.T) {
+	span := &recordedSpan{attributes: map[string]any{}}
+
+	err := &msg.IRODSError{Code: msg.CAT_NO_ROWS_FOUND}
+
+	endSpan(span, errors.Join(errors.New("wrapped"), err))
+
+	if span.attributes[AttributeErrorCode] != int(msg.CAT_NO_ROWS_FOUND) {
+		t.Errorf("expected error code %d, got %v", msg.CAT_NO_ROWS_FOUND, span.attributes[AttributeErrorCode])
+	}
+
+	if !span.ended || span.err == nil {
+		t.Errorf("expected span to be ended with an error")
+	}
+}
+
+func TestNoTracer(t *testing.T) {
+	client := newTestClient(1)
+
+	defer client.Close()
+
+	var called bool
+
+	err := client.trace(t.Context(), "test", nil, func(ctx context.Context) ([]Attribute, error) {
+		called = true
+
+		return nil, nil
+	})
+	if err != nil || !called {
+		t.Fatalf("expected fn to be called without error, got %v", err)
+	}
+}
@@ -2,15 +2,18 @@ package iron
 
 import (
 	"context"
+	"fmt"
 	"io"
+	"time"
 
 	"github.com/kuleuven/iron/transfer"
+	"go.uber.org/multierr"
 )
 
 // Upload uploads a local file to the iRODS server using parallel transfers.
 // The local file refers to the local file system. The remote file refers to an iRODS path.
 func (c *Client) Upload(ctx context.Context, local, remote string, options transfer.Options) error {
-	return c.runWorker(options, func(worker *transfer.Worker) {
+	return c.runWorker(ctx, "iron.upload", []Attribute{{AttributeLocalPath, local}, {AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
 		worker.Upload(ctx, local, remote)
 	})
 }
@@ -18,15 +21,24 @@ func (c *Client) Upload(ctx context.Context, local, remote string, options trans
 // UploadDir uploads a local directory to the iRODS server using parallel transfers.
 // The local file refers to the local file system. The remote file refers to an iRODS path.
 func (c *Client) UploadDir(ctx context.Context, local, remote string, options transfer.Options) error {
-	return c.runWorker(options, func(worker *transfer.Worker) {
+	return c.runWorker(ctx, "iron.upload_dir", []Attribute{{AttributeLocalPath, local}, {AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
 		worker.UploadDir(ctx, local, remote)
 	})
 }
 
+// VerifyDir verifies that a remote collection on the iRODS server matches a local directory,
+// e.g. after UploadDir, and fails if any file is missing, different or extra.
+// The local file refers to the local file system. The remote file refers to an iRODS path.
+func (c *Client) VerifyDir(ctx context.Context, local, remote string, options transfer.Options) error {
+	return c.runWorker(ctx, "iron.verify_dir", []Attribute{{AttributeLocalPath, local}, {AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
+		worker.VerifyDir(ctx, local, remote)
+	})
+}
+
 // FromReader streams an io.Reader to a remote file on the iRODS server using parallel transfers.
 // The remote file refers to an iRODS path.
 func (c *Client) FromReader(ctx context.Context, r io.Reader, remote string, appendToFile bool, options transfer.Options) error {
-	return c.runWorker(options, func(worker *transfer.Worker) {
+	return c.runWorker(ctx, "iron.from_reader", []Attribute{{AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
 		worker.FromStream(ctx, remote, r, remote, appendToFile)
 	})
 }
@@ -34,7 +46,7 @@ func (c *Client) FromReader(ctx context.Context, r io.Reader, remote string, app
 // Download downloads a remote file from the iRODS server using parallel transfers.
 // The local file refers to the local file system. The remote file refers to an iRODS path.
 func (c *Client) Download(ctx context.Context, local, remote string, options transfer.Options) error {
-	return c.runWorker(options, func(worker *transfer.Worker) {
+	return c.runWorker(ctx, "iron.download", []Attribute{{AttributeLocalPath, local}, {AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
 		worker.Download(ctx, local, remote)
 	})
 }
@@ -42,15 +54,24 @@ func (c *Client) Download(ctx context.Context, local, remote string, options tra
 // DownloadDir downloads a remote directory from the iRODS server using parallel transfers.
 // The local file refers to the local file system. The remote file refers to an iRODS path.
 func (c *Client) DownloadDir(ctx context.Context, local, remote string, options transfer.Options) error {
-	return c.runWorker(options, func(worker *transfer.Worker) {
+	return c.runWorker(ctx, "iron.download_dir", []Attribute{{AttributeLocalPath, local}, {AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
 		worker.DownloadDir(ctx, local, remote)
 	})
 }
 
+// DownloadModifiedSince downloads the data objects in a remote directory that were modified after
+// the given time, using parallel transfers. Unchanged files are not compared and local files are never removed.
+// The local file refers to the local file system. The remote file refers to an iRODS path.
+func (c *Client) DownloadModifiedSince(ctx context.Context, local, remote string, since time.Time, options transfer.Options) error {
+	return c.runWorker(ctx, "iron.download_modified_since", []Attribute{{AttributeLocalPath, local}, {AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
+		worker.DownloadModifiedSince(ctx, local, remote, since)
+	})
+}
+
 // ToWriter streams a remote file from the iRODS server to an io.Writer using parallel transfers.
 // The remote file refers to an iRODS path.
 func (c *Client) ToWriter(ctx context.Context, w io.Writer, remote string, options transfer.Options) error {
-	return c.runWorker(options, func(worker *transfer.Worker) {
+	return c.runWorker(ctx, "iron.to_writer", []Attribute{{AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
 		worker.ToStream(ctx, remote, w, remote)
 	})
 }
@@ -58,15 +79,23 @@ func (c *Client) ToWriter(ctx context.Context, w io.Writer, remote string, optio
 // RemoveDir removes a remote directory from the iRODS server using client recursion.
 // The remote file refers to an iRODS path.
 func (c *Client) RemoveDir(ctx context.Context, remote string, options transfer.Options) error {
-	return c.runWorker(options, func(worker *transfer.Worker) {
+	return c.runWorker(ctx, "iron.remove_dir", []Attribute{{AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
 		worker.RemoveDir(ctx, remote)
 	})
 }
 
+// Copy copies a remote file to another remote file on the iRODS server.
+// The remote files refers to an iRODS path.
+func (c *Client) Copy(ctx context.Context, remote1, remote2 string, options transfer.Options) error {
+	return c.runWorker(ctx, "iron.copy", []Attribute{{AttributePath, remote1}, {AttributeTargetPath, remote2}}, options, func(ctx context.Context, worker *transfer.Worker) {
+		worker.Copy(ctx, remote1, remote2)
+	})
+}
+
 // CopyDir copies a remote directory to another remote directory from the iRODS server using client recursion.
 // The remote files refers to an iRODS path.
 func (c *Client) CopyDir(ctx context.Context, remote1, remote2 string, options transfer.Options) error {
-	return c.runWorker(options, func(worker *transfer.Worker) {
+	return c.runWorker(ctx, "iron.copy_dir", []Attribute{{AttributePath, remote1}, {AttributeTargetPath, remote2}}, options, func(ctx context.Context, worker *transfer.Worker) {
 		worker.CopyDir(ctx, remote1, remote2)
 	})
 }
@@ -74,7 +103,7 @@ func (c *Client) CopyDir(ctx context.Context, remote1, remote2 string, options t
 // ComputeChecksums computes the checksums of a remote directory on the iRODS server using client recursion.
 // The remote file refers to an iRODS path.
 func (c *Client) ComputeChecksums(ctx context.Context, remote string, options transfer.Options) error {
-	return c.runWorker(options, func(worker *transfer.Worker) {
+	return c.runWorker(ctx, "iron.compute_checksums", []Attribute{{AttributePath, remote}}, options, func(ctx context.Context, worker *transfer.Worker) {
 		worker.ComputeChecksums(ctx, remote)
 	})
 }
@@ -83,24 +112,115 @@ func (c *Client) ComputeChecksums(ctx context.Context, remote string, options tr
 // creates a new transfer.Worker with it. The callback function is called
 // with the created worker. The worker is started and the error returned
 // is the error returned by the worker's Wait() function.
-func (c *Client) runWorker(options transfer.Options, callback func(worker *transfer.Worker)) error {
-	pool, err := c.defaultPool.Pool(options.MaxThreads)
-	if err != nil {
-		return err
+// If the client has a tracer, the transfer is recorded as a span with the given name.
+func (c *Client) runWorker(ctx context.Context, name string, attributes []Attribute, options transfer.Options, callback func(ctx context.Context, worker *transfer.Worker)) error {
+	return c.trace(ctx, name, attributes, func(ctx context.Context) ([]Attribute, error) {
+		pool, err := c.defaultPool.Pool(c.transferPoolSize(options))
+		if err != nil {
+			return nil, err
+		}
+
+		defer pool.Close()
+
+		worker := transfer.New(c.API, pool.API, options)
+
+		callback(ctx, worker)
+
+		err = worker.Wait()
+
+		return []Attribute{{AttributeBytes, worker.Transferred()}}, err
+	})
+}
+
+// transferPoolSize returns the number of connections to use for a transfer. If the number of
+// files that are transferred at the same time is limited, the pool is sized to transfer that
+// many files with MaxThreads threads each, as far as the default pool allows, keeping one
+// connection for the index pool.
+func (c *Client) transferPoolSize(options transfer.Options) int {
+	if options.MaxThreads <= 0 || options.MaxOutstanding <= 0 {
+		return options.MaxThreads
+	}
+
+	return max(options.MaxThreads, min(options.MaxThreads*options.MaxOutstanding, c.defaultPool.Stats().MaxConns-1))
+}
+
+// DefaultMaxQueued is the number of scanned files that a Transfer queues by default, see NewTransfer.
+const DefaultMaxQueued = 10000
+
+// Transfer is a transfer.Worker that runs on a transfer pool of its own, see Client.NewTransfer.
+type Transfer struct {
+	*transfer.Worker
+	pool *Pool
+}
+
+// Wait waits for all scheduled operations to complete, and returns the connections
+// of the transfer pool to the client. The Transfer can't be used afterwards.
+func (t *Transfer) Wait() error {
+	return multierr.Append(t.Worker.Wait(), t.pool.Close())
+}
+
+// NewTransfer creates a Transfer, to schedule any number of uploads, downloads and other
+// operations of a transfer.Worker on, without having to set up its pools:
+//
+//	t, err := client.NewTransfer(transfer.Options{})
+//	if err != nil {
+//		return err
+//	}
+//
+//	t.UploadDir(ctx, "/local/dir", "/zone/home/user/dir")
+//	t.DownloadDir(ctx, "/local/other", "/zone/home/user/other")
+//
+//	return t.Wait()
+//
+// The connections of the client are split: the transfers use a subpool of their own,
+// and index operations, such as listing collections, use the remaining connections,
+// of which at least one is kept. The following defaults apply to options that are zero:
+//
+//   - MaxThreads: all connections of the client but one, so that each file is transferred
+//     using as many connections as possible.
+//   - MaxQueued: DefaultMaxQueued scanned files.
+//
+// If MaxOutstanding is set, the subpool is sized to MaxOutstanding * MaxThreads connections,
+// as far as the client allows, otherwise all transfers share MaxThreads connections.
+// MaxThreads is lowered to the size of the subpool, as a file is transferred using
+// MaxThreads connections at the same time. NewTransfer does not wait for connections:
+// it fails with ErrNoConnectionsAvailable if the client has less than two connections,
+// or if the connections for the subpool are in use.
+func (c *Client) NewTransfer(options transfer.Options) (*Transfer, error) {
+	maxConns := c.defaultPool.Stats().MaxConns
+
+	if maxConns < 2 {
+		return nil, fmt.Errorf("%w: a transfer needs at least 2 connections, the client has %d", ErrNoConnectionsAvailable, maxConns)
 	}
 
-	defer pool.Close()
+	if options.MaxThreads <= 0 {
+		options.MaxThreads = maxConns - 1
+	}
+
+	if options.MaxQueued <= 0 {
+		options.MaxQueued = DefaultMaxQueued
+	}
 
-	worker := transfer.New(c.API, pool.API, options)
+	size := min(c.transferPoolSize(options), maxConns-1)
 
-	callback(worker)
+	options.MaxThreads = min(options.MaxThreads, size)
 
-	return worker.Wait()
+	pool, err := c.defaultPool.pool(size, false)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Transfer{
+		Worker: transfer.New(c.API, pool.API, options),
+		pool:   pool,
+	}, nil
 }
 
 // Verify checks the checksum of a local file against the checksum of a remote file
 func (c *Client) Verify(ctx context.Context, local, remote string) error {
-	_, _, err := transfer.VerifyLocalToRemote(c.API, nil)(ctx, local, remote, nil, nil)
+	return c.trace(ctx, "iron.verify", []Attribute{{AttributeLocalPath, local}, {AttributePath, remote}}, func(ctx context.Context) ([]Attribute, error) {
+		_, _, err := transfer.VerifyLocalToRemote(c.API, nil)(ctx, local, remote, nil, nil)
 
-	return err
+		return nil, err
+	})
 }
@@ -0,0 +1,21 @@
+//go:build linux
+
+package transfer
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// preAllocate reserves disk space for the given file, so that writing
+// up to size bytes does not fail because the disk is full. If the file
+// system does not support fallocate, the file is truncated to the size.
+func preAllocate(f *os.File, size int64) error {
+	err := syscall.Fallocate(int(f.Fd()), 0, 0, size)
+	if errors.Is(err, syscall.EOPNOTSUPP) || errors.Is(err, syscall.ENOSYS) {
+		return f.Truncate(size)
+	}
+
+	return err
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package transfer
+
+import "os"
+
+// preAllocate truncates the file to the given size. Disk space
+// is not reserved on this platform.
+func preAllocate(f *os.File, size int64) error {
+	return f.Truncate(size)
+}
@@ -0,0 +1,94 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+)
+
+func TestPreAllocate(t *testing.T) {
+	f, err := os.Create(filepath.Join(t.TempDir(), "file"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	defer f.Close()
+
+	if err := preAllocate(f, 1024); err != nil {
+		t.Fatal(err)
+	}
+
+	if fi, err := f.Stat(); err != nil {
+		t.Fatal(err)
+	} else if fi.Size() != 1024 {
+		t.Errorf("expected size 1024, got %d", fi.Size())
+	}
+}
+
+type fullDiskWriter struct {
+	fileWriter
+}
+
+func (w fullDiskWriter) PreAllocate(size int64) error {
+	return syscall.ENOSPC
+}
+
+func TestPreAllocateFullDisk(t *testing.T) {
+	testConn := &api.MockConn{}
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	kv := msg.SSKeyVal{}
+	kv.Add(msg.DATA_TYPE_KW, "generic")
+	kv.Add(msg.DEST_RESC_NAME_KW, "demoResc")
+	testConn.Add(msg.DATA_OBJ_OPEN_AN, msg.DataObjectRequest{
+		Path:       "/test/file1",
+		CreateMode: 420,
+		KeyVals:    kv,
+	}, msg.FileDescriptor(1))
+	testConn.Add(msg.DATA_OBJ_LSEEK_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+		Whence:         2,
+	}, msg.SeekResponse{Offset: 4})
+	testConn.Add(msg.DATA_OBJ_LSEEK_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+	}, msg.SeekResponse{Offset: 0})
+	testConn.Add(msg.DATA_OBJ_CLOSE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+	}, msg.EmptyResponse{})
+
+	local := filepath.Join(t.TempDir(), "file1")
+
+	f, err := os.Create(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	worker := New(nil, testAPI, Options{
+		MaxThreads:  1,
+		PreAllocate: true,
+	})
+
+	worker.ToWriter(t.Context(), fullDiskWriter{fileWriter{name: local, File: f}}, "/test/file1")
+
+	if err := worker.Wait(); !errors.Is(err, syscall.ENOSPC) {
+		t.Fatalf("expected ENOSPC, got %v", err)
+	}
+
+	if _, err := os.Stat(local); !os.IsNotExist(err) {
+		t.Errorf("expected local file to be removed, got %v", err)
+	}
+}
@@ -58,17 +58,36 @@ type PB struct {
 	scanCompleted    bool
 	outputBuffer     *bytes.Buffer
 	errors           int
+	noColors         bool
 	w                io.Writer
 	sync.Mutex
 }
 
+// DisableColors disables ANSI colors in the output of the progress bar.
+func (pb *PB) DisableColors() {
+	pb.Lock()
+	defer pb.Unlock()
+
+	pb.noColors = true
+}
+
 func (pb *PB) Handler(progress Progress) {
 	pb.Lock()
 	defer pb.Unlock()
 
 	switch {
 	case progress.Action == ComputeChecksum:
-		fmt.Fprintf(pb.outputBuffer, "%s\n", progress.Action.Format(progress.Label))
+		fmt.Fprintf(pb.outputBuffer, "%s\n", progress.Action.FormatColors(progress.Label, !pb.noColors))
+
+		return
+
+	case progress.Action == VerifyChecksum:
+		if progress.Verification == VerificationMismatch {
+			// Reported by the error handler
+			return
+		}
+
+		fmt.Fprintf(pb.outputBuffer, "%s (%s)\n", progress.Action.FormatColors(progress.Label, !pb.noColors), progress.Verification)
 
 		return
 
@@ -104,7 +123,13 @@ func (pb *PB) Handler(progress Progress) {
 			return
 		}
 
-		fmt.Fprintf(pb.outputBuffer, "%s\n", progress.Action.Format(progress.Label))
+		if progress.Removal != NoRemoval {
+			fmt.Fprintf(pb.outputBuffer, "%s (%s)\n", progress.Action.FormatColors(progress.Label, !pb.noColors), progress.Removal)
+
+			return
+		}
+
+		fmt.Fprintf(pb.outputBuffer, "%s\n", progress.Action.FormatColors(progress.Label, !pb.noColors))
 	}
 }
 
@@ -114,7 +139,7 @@ func (pb *PB) ErrorHandler(path, irodsPath string, err error) error {
 
 	pb.errors++
 
-	fmt.Fprintf(pb.outputBuffer, "\x1B[31m%s FAILED: %s\x1B[0m\n", ProgressLabel(path, irodsPath), err.Error())
+	fmt.Fprintf(pb.outputBuffer, "%s\n", colorize(!pb.noColors, "31", ProgressLabel(path, irodsPath)+" FAILED: "+err.Error()))
 
 	return nil
 }
@@ -158,7 +183,13 @@ func (pb *PB) print(t time.Time) {
 func (pb *PB) bar(t time.Time) string {
 	elapsed := t.Sub(pb.started)
 
-	percent := float64(pb.bytesTransferred) / float64(pb.bytesTotal) * 100
+	// If there is nothing to transfer, e.g. only empty files, we are done
+	percent := 100.0
+
+	if pb.bytesTotal > 0 {
+		percent = float64(pb.bytesTransferred) / float64(pb.bytesTotal) * 100
+	}
+
 	speed := float64(pb.bytesTransferred) / elapsed.Seconds()
 	eta := time.Duration(float64(pb.bytesTotal-pb.bytesTransferred)/speed) * time.Second
 
@@ -2,6 +2,7 @@ package transfer
 
 import (
 	"bytes"
+	"strings"
 	"testing"
 	"time"
 )
@@ -180,6 +181,44 @@ func TestPBHandlerComputeChecksum(t *testing.T) {
 	}
 }
 
+func TestPBHandlerVerifyChecksum(t *testing.T) {
+	buf := &bytes.Buffer{}
+	pb := &PB{
+		actual:       map[string]Progress{},
+		done:         make(chan struct{}),
+		wait:         make(chan struct{}),
+		started:      time.Now(),
+		outputBuffer: buf,
+		w:            &bytes.Buffer{},
+	}
+
+	pb.Handler(Progress{
+		Action:       VerifyChecksum,
+		Label:        "test.txt",
+		Verification: Verified,
+	})
+
+	if result := stripANSI(buf.String()); result != "v test.txt (verified)\n" {
+		t.Errorf("unexpected output %q", result)
+	}
+
+	buf.Reset()
+
+	pb.Handler(Progress{
+		Action:       VerifyChecksum,
+		Label:        "test.txt",
+		Verification: VerificationMismatch,
+	})
+
+	if buf.Len() != 0 {
+		t.Errorf("expected mismatch to be left to the error handler, got %q", buf.String())
+	}
+
+	if len(pb.actual) != 0 || pb.bytesTotal != 0 {
+		t.Error("expected verification not to be registered as transfer")
+	}
+}
+
 func TestPBHandlerFromStream(t *testing.T) {
 	pb := &PB{
 		actual:       map[string]Progress{},
@@ -360,6 +399,7 @@ func TestActionFormat(t *testing.T) {
 	}{
 		{ComputeChecksum, "file.txt", "c file.txt"},
 		{SetModificationTime, "file.txt", "t file.txt"},
+		{VerifyChecksum, "file.txt", "v file.txt"},
 		{CreateDirectory, "dir", "+ dir/"},
 		{TransferFile, "file.txt", "+ file.txt"},
 		{RemoveFile, "file.txt", "- file.txt"},
@@ -381,6 +421,30 @@ func TestActionFormat(t *testing.T) {
 	}
 }
 
+func TestActionFormatNoColors(t *testing.T) {
+	if result := RemoveDirectory.FormatColors("dir", false); result != "- dir/" {
+		t.Errorf("expected no ANSI codes, got %q", result)
+	}
+}
+
+func TestPBNoColors(t *testing.T) {
+	w := &bytes.Buffer{}
+	pb := ProgressBar(w)
+
+	pb.DisableColors()
+
+	pb.ErrorHandler("test.txt", "remote.txt", bytes.ErrTooLarge)
+	pb.Close() //nolint:errcheck // The error reports the failure above
+
+	if strings.Contains(w.String(), "\x1B[31m") {
+		t.Errorf("expected no ANSI colors, got %q", w.String())
+	}
+
+	if !strings.Contains(w.String(), "test.txt FAILED: "+bytes.ErrTooLarge.Error()) {
+		t.Errorf("expected error in output, got %q", w.String())
+	}
+}
+
 func stripANSI(s string) string {
 	var result []byte
 
@@ -401,3 +465,38 @@ func stripANSI(s string) string {
 
 	return string(result)
 }
+
+func TestPBEmptyFile(t *testing.T) {
+	pb := &PB{
+		actual:        map[string]Progress{},
+		done:          make(chan struct{}),
+		wait:          make(chan struct{}),
+		started:       time.Now(),
+		outputBuffer:  &bytes.Buffer{},
+		w:             &bytes.Buffer{},
+		scanCompleted: true,
+	}
+
+	started := time.Now()
+
+	pb.Handler(Progress{
+		Action:    TransferFile,
+		Label:     "empty.txt",
+		StartedAt: started,
+	})
+
+	pb.Handler(Progress{
+		Action:     TransferFile,
+		Label:      "empty.txt",
+		StartedAt:  started,
+		FinishedAt: time.Now(),
+	})
+
+	if !strings.Contains(pb.outputBuffer.String(), "empty.txt") {
+		t.Errorf("expected empty.txt to be reported as done, got %q", pb.outputBuffer.String())
+	}
+
+	if bar := pb.bar(time.Now()); !strings.HasPrefix(bar, "100.00%") {
+		t.Errorf("expected 100%% progress, got %q", bar)
+	}
+}
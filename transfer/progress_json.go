@@ -0,0 +1,132 @@
+package transfer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// ProgressEvent is a single line emitted by JSONProgress.
+// The JSON field names form a stable schema for front-ends that
+// render their own progress, and will not change.
+type ProgressEvent struct {
+	Type         string    `json:"type"`                   // progress, error or done
+	Time         time.Time `json:"time"`                   // Time at which the event was emitted
+	Action       string    `json:"action,omitempty"`       // See Action.String, only for progress events
+	Label        string    `json:"label,omitempty"`        // Path of the file or directory
+	Size         int64     `json:"size"`                   // Total size, zero if unknown
+	Transferred  int64     `json:"transferred"`            // Number of bytes transferred, for done events in total
+	Rate         float64   `json:"rate"`                   // Average rate in bytes per second
+	Finished     bool      `json:"finished"`               // Whether the action has finished
+	Verification string    `json:"verification,omitempty"` // See Verification.String, only for the verify_checksum action
+	Removal      string    `json:"removal,omitempty"`      // See Removal.String, only for finished removals on the iRODS server
+	Error        string    `json:"error,omitempty"`        // Error message, only for error events
+	Errors       int       `json:"errors"`                 // Number of errors so far
+}
+
+// JSONProgress writes one JSON encoded ProgressEvent per line
+// for each progress update and each error.
+func JSONProgress(w io.Writer) *JSONP {
+	return &JSONP{
+		encoder: json.NewEncoder(w),
+		started: time.Now(),
+	}
+}
+
+type JSONP struct {
+	encoder          *json.Encoder
+	started          time.Time
+	bytesTransferred int64
+	errors           int
+	sync.Mutex
+}
+
+func (p *JSONP) Handler(progress Progress) {
+	p.Lock()
+	defer p.Unlock()
+
+	if progress.Action == TransferFile {
+		p.bytesTransferred += progress.Increment
+	}
+
+	event := ProgressEvent{
+		Type:        "progress",
+		Time:        time.Now(),
+		Action:      progress.Action.String(),
+		Label:       progress.Label,
+		Size:        progress.Size,
+		Transferred: progress.Transferred,
+		Finished:    !progress.FinishedAt.IsZero(),
+		Errors:      p.errors,
+	}
+
+	if progress.Action == VerifyChecksum {
+		event.Verification = progress.Verification.String()
+	}
+
+	if progress.Removal != NoRemoval {
+		event.Removal = progress.Removal.String()
+	}
+
+	if !progress.StartedAt.IsZero() {
+		end := event.Time
+
+		if event.Finished {
+			end = progress.FinishedAt
+		}
+
+		event.Rate = rate(progress.Transferred, end.Sub(progress.StartedAt))
+	}
+
+	p.encoder.Encode(event) //nolint:errcheck
+}
+
+func (p *JSONP) ErrorHandler(path, irodsPath string, err error) error {
+	p.Lock()
+	defer p.Unlock()
+
+	p.errors++
+
+	p.encoder.Encode(ProgressEvent{ //nolint:errcheck
+		Type:   "error",
+		Time:   time.Now(),
+		Label:  ProgressLabel(path, irodsPath),
+		Error:  err.Error(),
+		Errors: p.errors,
+	})
+
+	return nil
+}
+
+// Close emits a final done event, and returns an error if errors occurred.
+func (p *JSONP) Close() error {
+	p.Lock()
+	defer p.Unlock()
+
+	now := time.Now()
+
+	p.encoder.Encode(ProgressEvent{ //nolint:errcheck
+		Type:        "done",
+		Time:        now,
+		Transferred: p.bytesTransferred,
+		Rate:        rate(p.bytesTransferred, now.Sub(p.started)),
+		Finished:    true,
+		Errors:      p.errors,
+	})
+
+	if p.errors > 0 {
+		return fmt.Errorf("%d errors", p.errors)
+	}
+
+	return nil
+}
+
+func rate(transferred int64, elapsed time.Duration) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+
+	return float64(transferred) / elapsed.Seconds()
+}
@@ -0,0 +1,87 @@
+package transfer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJSONProgress(t *testing.T) {
+	var buf bytes.Buffer
+
+	p := JSONProgress(&buf)
+
+	started := time.Now().Add(-time.Second)
+
+	p.Handler(Progress{Action: TransferFile, Label: "file", Size: 100})
+	p.Handler(Progress{Action: TransferFile, Label: "file", Size: 100, Transferred: 50, Increment: 50, StartedAt: started})
+	p.Handler(Progress{Action: TransferFile, Label: "file", Size: 100, Transferred: 100, Increment: 50, StartedAt: started, FinishedAt: started.Add(time.Second)})
+	p.Handler(Progress{Action: VerifyChecksum, Label: "file", Verification: Verified})
+	p.Handler(Progress{Action: RemoveFile, Label: "/zone/old", StartedAt: started, FinishedAt: started, Removal: PermanentlyDeleted})
+
+	if err := p.ErrorHandler("other", "/zone/other", errors.New("failed")); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := p.Close(); err == nil {
+		t.Fatal("expected error")
+	}
+
+	var events []ProgressEvent
+
+	scanner := bufio.NewScanner(&buf)
+
+	for scanner.Scan() {
+		var event ProgressEvent
+
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			t.Fatal(err)
+		}
+
+		events = append(events, event)
+	}
+
+	if len(events) != 7 {
+		t.Fatalf("expected 7 events, got %d", len(events))
+	}
+
+	if events[2].Action != "transfer_file" || !events[2].Finished || events[2].Rate != 100 {
+		t.Errorf("unexpected event %+v", events[2])
+	}
+
+	if events[3].Verification != "verified" {
+		t.Errorf("unexpected event %+v", events[3])
+	}
+
+	if events[4].Action != "remove_file" || events[4].Removal != "permanently deleted" {
+		t.Errorf("unexpected event %+v", events[4])
+	}
+
+	if events[5].Type != "error" || events[5].Label != "other" || events[5].Error != "failed" || events[5].Errors != 1 {
+		t.Errorf("unexpected event %+v", events[5])
+	}
+
+	if events[6].Type != "done" || events[6].Transferred != 100 || events[6].Errors != 1 {
+		t.Errorf("unexpected event %+v", events[6])
+	}
+}
+
+func TestActionString(t *testing.T) {
+	for action, expected := range map[Action]string{
+		CreateDirectory:     "create_directory",
+		TransferFile:        "transfer_file",
+		RemoveFile:          "remove_file",
+		RemoveDirectory:     "remove_directory",
+		ComputeChecksum:     "compute_checksum",
+		SetModificationTime: "set_modification_time",
+		VerifyChecksum:      "verify_checksum",
+		Action(42):          "action_42",
+	} {
+		if action.String() != expected {
+			t.Errorf("expected %s, got %s", expected, action.String())
+		}
+	}
+}
@@ -1,9 +1,14 @@
 package transfer
 
 import (
+	"context"
+	"errors"
 	"io"
+	"slices"
 	"sync"
+	"time"
 
+	"go.uber.org/multierr"
 	"golang.org/x/sync/errgroup"
 )
 
@@ -19,9 +24,15 @@ func (r *ReaderAtRangeReader) Range(offset, length int64) io.Reader {
 	return io.NewSectionReader(r, offset, length)
 }
 
+// ReopenRangeReader reads ranges of a data object in parallel, by reopening the
+// data object for every range after the first one. If Retry is set, a range is
+// reopened at the offset of a failed read, so that a transient failure of the
+// resource doesn't fail the whole range.
 type ReopenRangeReader struct {
 	io.ReadSeekCloser
-	Reopen func() (io.ReadSeekCloser, error)
+	Reopen  func() (io.ReadSeekCloser, error)
+	Retry   RetryPolicy
+	Context context.Context // Context that ends the retries of failed ranges, if set
 
 	// Unexported fields
 	needsClose []io.Closer
@@ -30,29 +41,69 @@ type ReopenRangeReader struct {
 
 func (r *ReopenRangeReader) Range(offset, length int64) io.Reader {
 	r.Lock()
-	defer r.Unlock()
-
-	var f io.ReadSeekCloser
 
-	if r.ReadSeekCloser != nil {
-		f = r.ReadSeekCloser
-		r.ReadSeekCloser = nil
-	} else {
-		var err error
+	f := r.ReadSeekCloser
+	r.ReadSeekCloser = nil
 
-		f, err = r.Reopen()
-		if err != nil {
-			return errorReader{err}
-		}
+	r.Unlock()
 
-		r.needsClose = append(r.needsClose, f)
+	if f == nil {
+		return r.reopenRange(offset, length)
 	}
 
 	if _, err := f.Seek(offset, io.SeekStart); err != nil {
 		return errorReader{err}
 	}
 
-	return io.LimitReader(f, length)
+	if r.Retry.MaxReopens == 0 {
+		return io.LimitReader(f, length)
+	}
+
+	return &retryReader{parent: r, f: f, offset: offset, limit: length}
+}
+
+func (r *ReopenRangeReader) reopenRange(offset, length int64) io.Reader {
+	f, err := r.reopen(offset)
+	if err != nil {
+		return errorReader{err}
+	}
+
+	if r.Retry.MaxReopens == 0 {
+		return io.LimitReader(f, length)
+	}
+
+	return &retryReader{parent: r, f: f, offset: offset, limit: length}
+}
+
+// reopen reopens the data object, and seeks to the given offset.
+func (r *ReopenRangeReader) reopen(offset int64) (io.ReadSeekCloser, error) {
+	f, err := r.Reopen()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Lock()
+	r.needsClose = append(r.needsClose, f)
+	r.Unlock()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// discard closes a reopened handle that failed, ignoring the error.
+// The initial handle is left open, as it is closed by the caller.
+func (r *ReopenRangeReader) discard(f io.Closer) {
+	r.Lock()
+	defer r.Unlock()
+
+	if i := slices.Index(r.needsClose, f); i >= 0 {
+		r.needsClose = slices.Delete(r.needsClose, i, i+1)
+
+		f.Close() //nolint:errcheck
+	}
 }
 
 func (r *ReopenRangeReader) Close() error {
@@ -117,9 +168,15 @@ func (w *sectionWriter) Write(b []byte) (int, error) {
 	return n, err
 }
 
+// ReopenRangeWriter writes ranges of a data object in parallel, by reopening the
+// data object for every range after the first one. If Retry is set, a range is
+// reopened at the offset of a failed write, so that a transient failure of the
+// resource doesn't fail the whole range.
 type ReopenRangeWriter struct {
 	WriteSeekCloser
-	Reopen func() (WriteSeekCloser, error)
+	Reopen  func() (WriteSeekCloser, error)
+	Retry   RetryPolicy
+	Context context.Context // Context that ends the retries of failed ranges, if set
 
 	// Unexported fields
 	needsClose []io.Closer
@@ -133,29 +190,69 @@ type WriteSeekCloser interface {
 
 func (r *ReopenRangeWriter) Range(offset, length int64) io.Writer {
 	r.Lock()
-	defer r.Unlock()
-
-	var f WriteSeekCloser
 
-	if r.WriteSeekCloser != nil {
-		f = r.WriteSeekCloser
-		r.WriteSeekCloser = nil
-	} else {
-		var err error
+	f := r.WriteSeekCloser
+	r.WriteSeekCloser = nil
 
-		f, err = r.Reopen()
-		if err != nil {
-			return errorWriter{err}
-		}
+	r.Unlock()
 
-		r.needsClose = append(r.needsClose, f)
+	if f == nil {
+		return r.reopenRange(offset, length)
 	}
 
 	if _, err := f.Seek(offset, io.SeekStart); err != nil {
 		return errorWriter{err}
 	}
 
-	return &limitWriter{f, length}
+	if r.Retry.MaxReopens == 0 {
+		return &limitWriter{f, length}
+	}
+
+	return &retryWriter{parent: r, f: f, offset: offset, limit: length}
+}
+
+func (r *ReopenRangeWriter) reopenRange(offset, length int64) io.Writer {
+	f, err := r.reopen(offset)
+	if err != nil {
+		return errorWriter{err}
+	}
+
+	if r.Retry.MaxReopens == 0 {
+		return &limitWriter{f, length}
+	}
+
+	return &retryWriter{parent: r, f: f, offset: offset, limit: length}
+}
+
+// reopen reopens the data object, and seeks to the given offset.
+func (r *ReopenRangeWriter) reopen(offset int64) (WriteSeekCloser, error) {
+	f, err := r.Reopen()
+	if err != nil {
+		return nil, err
+	}
+
+	r.Lock()
+	r.needsClose = append(r.needsClose, f)
+	r.Unlock()
+
+	if _, err := f.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// discard closes a reopened handle that failed, ignoring the error.
+// The initial handle is left open, as it is closed by the caller.
+func (r *ReopenRangeWriter) discard(f io.Closer) {
+	r.Lock()
+	defer r.Unlock()
+
+	if i := slices.Index(r.needsClose, f); i >= 0 {
+		r.needsClose = slices.Delete(r.needsClose, i, i+1)
+
+		f.Close() //nolint:errcheck
+	}
 }
 
 func (r *ReopenRangeWriter) Close() error {
@@ -205,3 +302,150 @@ type errorWriter struct {
 func (r errorWriter) Write([]byte) (int, error) {
 	return 0, r.err
 }
+
+// RetryPolicy controls how a range of a ReopenRangeReader or ReopenRangeWriter
+// is retried after a failed read or write. The failed handle is replaced by a
+// reopened one, positioned at the offset of the failure, at most MaxReopens times
+// per range. Before every attempt, Backoff is waited, doubling after every attempt.
+// Failures caused by a canceled or expired context are not retried.
+// The zero value disables retries.
+type RetryPolicy struct {
+	MaxReopens int
+	Backoff    time.Duration
+}
+
+// retry waits before the given attempt to reopen after err, and returns err,
+// together with the context error, if no attempt should be made.
+func (p RetryPolicy) retry(ctx context.Context, attempt int, err error) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if attempt >= p.MaxReopens || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return multierr.Append(err, ctx.Err())
+	case <-time.After(p.Backoff << attempt):
+		return nil
+	}
+}
+
+// retryReader reads a range, and reopens the data object if a read fails
+type retryReader struct {
+	parent   *ReopenRangeReader
+	f        io.ReadSeekCloser
+	offset   int64
+	limit    int64
+	attempts int
+}
+
+func (r *retryReader) Read(b []byte) (int, error) {
+	if r.limit <= 0 {
+		return 0, io.EOF
+	}
+
+	if int64(len(b)) > r.limit {
+		b = b[:r.limit]
+	}
+
+	n, err := r.f.Read(b)
+
+	r.offset += int64(n)
+	r.limit -= int64(n)
+
+	if err == nil || err == io.EOF {
+		return n, err
+	}
+
+	return n, r.reopen(err)
+}
+
+// reopen replaces the handle after a failed read, according to the retry policy.
+func (r *retryReader) reopen(err error) error {
+	for {
+		if err := r.parent.Retry.retry(r.parent.Context, r.attempts, err); err != nil {
+			return err
+		}
+
+		r.attempts++
+
+		f, reopenErr := r.parent.reopen(r.offset)
+		if reopenErr != nil {
+			err = reopenErr
+
+			continue
+		}
+
+		r.parent.discard(r.f)
+		r.f = f
+
+		return nil
+	}
+}
+
+// retryWriter writes a range, and reopens the data object if a write fails
+type retryWriter struct {
+	parent   *ReopenRangeWriter
+	f        WriteSeekCloser
+	offset   int64
+	limit    int64
+	attempts int
+}
+
+func (w *retryWriter) Write(b []byte) (int, error) {
+	if w.limit == 0 {
+		return 0, io.EOF
+	}
+
+	var defErr error
+
+	if int64(len(b)) > w.limit {
+		b = b[:w.limit]
+
+		defErr = io.ErrShortWrite
+	}
+
+	var written int
+
+	for {
+		n, err := w.f.Write(b[written:])
+
+		written += n
+		w.offset += int64(n)
+		w.limit -= int64(n)
+
+		if err == nil {
+			return written, defErr
+		}
+
+		if err = w.reopen(err); err != nil {
+			return written, err
+		}
+	}
+}
+
+// reopen replaces the handle after a failed write, according to the retry policy.
+func (w *retryWriter) reopen(err error) error {
+	for {
+		if err := w.parent.Retry.retry(w.parent.Context, w.attempts, err); err != nil {
+			return err
+		}
+
+		w.attempts++
+
+		f, reopenErr := w.parent.reopen(w.offset)
+		if reopenErr != nil {
+			err = reopenErr
+
+			continue
+		}
+
+		w.parent.discard(w.f)
+		w.f = f
+
+		return nil
+	}
+}
@@ -2,10 +2,12 @@ package transfer
 
 import (
 	"bytes"
+	"context"
 	"errors"
 	"io"
 	"strings"
 	"testing"
+	"time"
 )
 
 // Tests for RangeReader implementations
@@ -300,3 +302,190 @@ func (w *bytesWriterAt) Write(p []byte) (int, error) {
 func (w *bytesWriterAt) Close() error {
 	return nil
 }
+
+var errFlaky = errors.New("flaky resource")
+
+// flakyWriter fails after writing the given number of bytes
+type flakyWriter struct {
+	*bytesWriterAt
+	remaining int
+	closed    bool
+}
+
+func (w *flakyWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.remaining {
+		w.remaining -= len(p)
+
+		return w.bytesWriterAt.Write(p)
+	}
+
+	n, _ := w.bytesWriterAt.Write(p[:w.remaining]) //nolint:errcheck
+
+	w.remaining = 0
+
+	return n, errFlaky
+}
+
+func (w *flakyWriter) Close() error {
+	w.closed = true
+
+	return errFlaky
+}
+
+func TestReopenRangeWriterRetry(t *testing.T) {
+	buf := make([]byte, 20)
+
+	flaky := &flakyWriter{bytesWriterAt: &bytesWriterAt{buf, 0}, remaining: 3}
+
+	var reopens int
+
+	writer := &ReopenRangeWriter{
+		WriteSeekCloser: &bytesWriterAt{buf, 0},
+		Reopen: func() (WriteSeekCloser, error) {
+			reopens++
+
+			if reopens == 1 {
+				return flaky, nil
+			}
+
+			return &bytesWriterAt{buf, 0}, nil
+		},
+		Retry: RetryPolicy{MaxReopens: 1, Backoff: time.Millisecond},
+	}
+
+	if _, err := io.Copy(writer.Range(0, 10), strings.NewReader("0123456789")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := io.Copy(writer.Range(10, 10), strings.NewReader("abcdefghij")); err != nil {
+		t.Fatal(err)
+	}
+
+	if string(buf) != "0123456789abcdefghij" {
+		t.Fatalf("unexpected contents %q", buf)
+	}
+
+	if reopens != 2 || !flaky.closed {
+		t.Fatalf("expected the failed handle to be replaced and closed, got %d reopens", reopens)
+	}
+
+	// The failed handle is discarded, so its close error is ignored
+	if err := writer.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Without retries, the range fails
+	writer = &ReopenRangeWriter{
+		WriteSeekCloser: &flakyWriter{bytesWriterAt: &bytesWriterAt{buf, 0}, remaining: 3},
+	}
+
+	if _, err := io.Copy(writer.Range(0, 10), strings.NewReader("0123456789")); !errors.Is(err, errFlaky) {
+		t.Fatalf("expected errFlaky, got %v", err)
+	}
+}
+
+// flakyReader fails after reading the given number of bytes
+type flakyReader struct {
+	io.ReadSeeker
+	remaining int
+}
+
+func (r *flakyReader) Read(p []byte) (int, error) {
+	if r.remaining == 0 {
+		return 0, errFlaky
+	}
+
+	if len(p) > r.remaining {
+		p = p[:r.remaining]
+	}
+
+	n, err := r.ReadSeeker.Read(p)
+
+	r.remaining -= n
+
+	return n, err
+}
+
+func (r *flakyReader) Close() error {
+	return nil
+}
+
+func TestReopenRangeReaderRetry(t *testing.T) {
+	data := "Hello, World!"
+
+	reader := &ReopenRangeReader{
+		ReadSeekCloser: &flakyReader{strings.NewReader(data), 4},
+		Reopen: func() (io.ReadSeekCloser, error) {
+			return &flakyReader{strings.NewReader(data), 4}, nil
+		},
+		Retry: RetryPolicy{MaxReopens: 2},
+	}
+
+	defer reader.Close()
+
+	result, err := io.ReadAll(reader.Range(1, 11))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(result) != "ello, World" {
+		t.Fatalf("unexpected result %q", result)
+	}
+
+	// A third failure exceeds the number of reopens
+	if _, err := io.ReadAll(reader.Range(0, 13)); !errors.Is(err, errFlaky) {
+		t.Fatalf("expected errFlaky, got %v", err)
+	}
+}
+
+func TestReopenRangeReaderRetryCanceled(t *testing.T) {
+	data := "Hello, World!"
+
+	var reopens int
+
+	ctx, cancel := context.WithCancel(t.Context())
+
+	reader := &ReopenRangeReader{
+		ReadSeekCloser: &flakyReader{strings.NewReader(data), 4},
+		Reopen: func() (io.ReadSeekCloser, error) {
+			reopens++
+
+			return &flakyReader{strings.NewReader(data), 4}, nil
+		},
+		Retry:   RetryPolicy{MaxReopens: 2, Backoff: time.Hour},
+		Context: ctx,
+	}
+
+	defer reader.Close()
+
+	cancel()
+
+	// The backoff is interrupted by the context
+	if _, err := io.ReadAll(reader.Range(0, 13)); !errors.Is(err, errFlaky) || !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected errFlaky and context.Canceled, got %v", err)
+	}
+
+	// A failure caused by the context is final
+	reader.ReadSeekCloser = nopCloser{ReadSeeker: errorReadSeeker{context.DeadlineExceeded}}
+	reader.Context = t.Context()
+
+	if _, err := io.ReadAll(reader.Range(0, 13)); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+
+	if reopens != 0 {
+		t.Fatalf("expected no reopens, got %d", reopens)
+	}
+}
+
+type errorReadSeeker struct {
+	err error
+}
+
+func (r errorReadSeeker) Read([]byte) (int, error) {
+	return 0, r.err
+}
+
+func (r errorReadSeeker) Seek(int64, int) (int64, error) {
+	return 0, nil
+}
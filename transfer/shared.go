@@ -0,0 +1,78 @@
+package transfer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/kuleuven/iron/api"
+)
+
+// NewShared creates a Worker that uses a single pool of maxConns connections both for
+// index operations, such as listing collections and creating directories, and for
+// transfers. This suffices for lightweight use, e.g. to transfer a few files at a time.
+//
+// At most maxConns-1 connections are used for transfers at the same time, so that
+// index operations never wait for transfers to release their connections. As a
+// consequence, maxConns must be at least 2; smaller values are raised to 2. Parallel
+// transfers of large files and directory synchronizations keep many connections busy
+// for a long time; for these, use New with separate pools, so that the index
+// operations don't slow down the transfers and vice versa.
+//
+// A transfer opens a connection for each of its ranges before any range completes, so
+// options.MaxThreads is lowered to maxConns-1 if needed, and options.MaxOutstanding is
+// lowered so that the outstanding transfers together use at most maxConns-1 connections.
+func NewShared(pool *api.API, maxConns int, options Options) *Worker {
+	n := max(maxConns, 2) - 1
+
+	options.MaxThreads = min(max(options.MaxThreads, 1), n)
+
+	if outstanding := n / options.MaxThreads; options.MaxOutstanding <= 0 || options.MaxOutstanding > outstanding {
+		options.MaxOutstanding = outstanding
+	}
+
+	return New(pool, limitConns(pool, n), options)
+}
+
+// limitConns returns a copy of the API that uses at most n connections at the same time.
+// Connect blocks until a connection is closed if the limit is reached.
+func limitConns(a *api.API, n int) *api.API {
+	slots := make(chan struct{}, n)
+
+	limited := *a
+	limited.Connect = func(ctx context.Context) (api.Conn, error) {
+		select {
+		case slots <- struct{}{}:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		conn, err := a.Connect(ctx)
+		if err != nil {
+			<-slots
+
+			return nil, err
+		}
+
+		return &limitedConn{
+			Conn: conn,
+			release: sync.OnceFunc(func() {
+				<-slots
+			}),
+		}, nil
+	}
+
+	return &limited
+}
+
+// limitedConn is a connection obtained from an API returned by limitConns,
+// that releases its slot when it is closed.
+type limitedConn struct {
+	api.Conn
+	release func()
+}
+
+func (c *limitedConn) Close() error {
+	defer c.release()
+
+	return c.Conn.Close()
+}
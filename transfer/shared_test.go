@@ -0,0 +1,124 @@
+package transfer
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+)
+
+// handleConn answers the requests to open, seek, write and close data objects,
+// and counts the connections that are open at the same time.
+type handleConn struct {
+	api.MockConn
+	open *atomic.Int32
+}
+
+func (c *handleConn) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	return c.RequestWithBuffers(ctx, apiNumber, request, response, nil, nil)
+}
+
+func (c *handleConn) RequestWithBuffers(ctx context.Context, apiNumber msg.APINumber, request, response any, requestBuf, responseBuf []byte) error {
+	time.Sleep(time.Millisecond)
+
+	switch r := response.(type) {
+	case *msg.FileDescriptor:
+		*r = 1
+	case *msg.GetDescriptorInfoResponse:
+		*r = msg.GetDescriptorInfoResponse{
+			DataObjectInfo: map[string]any{
+				"replica_number":     1,
+				"resource_hierarchy": "demoResc",
+			},
+			ReplicaToken: "testToken",
+		}
+	case *msg.SeekResponse:
+		r.Offset = request.(msg.OpenedDataObjectRequest).Offset
+	case *msg.EmptyResponse:
+	default:
+		return fmt.Errorf("unexpected request %d", apiNumber)
+	}
+
+	return nil
+}
+
+func (c *handleConn) Close() error {
+	c.open.Add(-1)
+
+	return nil
+}
+
+func TestNewShared(t *testing.T) {
+	var open, maxOpen atomic.Int32
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			if n := open.Add(1); n > maxOpen.Load() {
+				maxOpen.Store(n)
+			}
+
+			return &handleConn{open: &open}, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	BufferSize = 100
+	MinimumRangeSize = 200
+
+	// Three connections leave two for transfers, too few for the requested
+	// number of threads and outstanding transfers
+	worker := NewShared(testAPI, 3, Options{
+		MaxThreads:     4,
+		MaxOutstanding: 4,
+	})
+
+	if worker.IndexPool != testAPI || worker.TransferPool.DefaultResource != "demoResc" {
+		t.Fatal("expected the index pool to be shared and the transfer pool to be a copy")
+	}
+
+	if worker.options.MaxThreads != 2 || worker.options.MaxOutstanding != 1 {
+		t.Fatalf("expected 2 threads and 1 outstanding transfer, got %d and %d", worker.options.MaxThreads, worker.options.MaxOutstanding)
+	}
+
+	ctx, cancel := context.WithTimeout(t.Context(), 5*time.Second)
+	defer cancel()
+
+	dir := t.TempDir()
+
+	for i := range 4 {
+		path := filepath.Join(dir, fmt.Sprintf("file%d", i))
+
+		if err := os.WriteFile(path, bytes.Repeat([]byte("test"), 200), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		worker.uploadAction(ctx, Task{
+			Action:    TransferFile,
+			Path:      path,
+			IrodsPath: fmt.Sprintf("/test/file%d", i),
+			Size:      800,
+		})
+	}
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Each file is transferred in two ranges, on two connections
+	if n := maxOpen.Load(); n != 2 {
+		t.Errorf("expected 2 transfer connections at the same time, got %d", n)
+	}
+
+	if n := open.Load(); n != 0 {
+		t.Errorf("expected all connections to be closed, got %d", n)
+	}
+}
@@ -0,0 +1,80 @@
+package transfer
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+var ErrSymlinkLoop = errors.New("symbolic link loop")
+
+// walkLocal walks the local file tree rooted at root, like filepath.Walk.
+// If followSymlinks is set, symbolic links are resolved and the walk descends into
+// linked directories. A link to a directory that is already being walked, i.e. one
+// of its ancestors, is reported to walkFn as ErrSymlinkLoop and not descended into.
+func walkLocal(root string, followSymlinks bool, walkFn filepath.WalkFunc) error {
+	if !followSymlinks {
+		return filepath.Walk(root, walkFn)
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		err = walkFn(root, nil, err)
+	} else {
+		err = walkFollow(root, info, nil, walkFn)
+	}
+
+	if errors.Is(err, filepath.SkipDir) || errors.Is(err, filepath.SkipAll) {
+		return nil
+	}
+
+	return err
+}
+
+// walkFollow walks the file tree rooted at path, following symbolic links.
+// The ancestors are the resolved directories that contain path.
+func walkFollow(path string, info os.FileInfo, ancestors []os.FileInfo, walkFn filepath.WalkFunc) error {
+	if !info.IsDir() {
+		return walkFn(path, info, nil)
+	}
+
+	for _, ancestor := range ancestors {
+		if os.SameFile(ancestor, info) {
+			return walkFn(path, nil, fmt.Errorf("%w: %s", ErrSymlinkLoop, path))
+		}
+	}
+
+	if err := walkFn(path, info, nil); err != nil {
+		return err
+	}
+
+	// os.ReadDir returns the entries sorted by name, as filepath.Walk does
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return walkFn(path, info, err)
+	}
+
+	ancestors = append(slices.Clip(ancestors), info)
+
+	for _, entry := range entries {
+		name := filepath.Join(path, entry.Name())
+
+		fileInfo, err := os.Stat(name)
+		if err != nil {
+			if err = walkFn(name, nil, err); err != nil && !errors.Is(err, filepath.SkipDir) {
+				return err
+			}
+
+			continue
+		}
+
+		err = walkFollow(name, fileInfo, ancestors, walkFn)
+		if err != nil && (!fileInfo.IsDir() || !errors.Is(err, filepath.SkipDir)) {
+			return err
+		}
+	}
+
+	return nil
+}
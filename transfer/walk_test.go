@@ -0,0 +1,188 @@
+package transfer
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/kuleuven/iron/api"
+	"github.com/kuleuven/iron/msg"
+)
+
+// symlinkTree creates a local tree with a linked file, a linked directory and a loop
+func symlinkTree(t *testing.T) string {
+	root := t.TempDir()
+	target := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(root, "dir", "sub"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, file := range []string{filepath.Join(root, "dir", "file"), filepath.Join(target, "linked")} {
+		if err := os.WriteFile(file, []byte("test"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for link, dest := range map[string]string{
+		filepath.Join(root, "dir", "filelink"):  filepath.Join(root, "dir", "file"),
+		filepath.Join(root, "dir", "sub", "up"): filepath.Join(root, "dir"),
+		filepath.Join(root, "target"):           target,
+	} {
+		if err := os.Symlink(dest, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return root
+}
+
+type walked struct {
+	paths []string
+	modes map[string]os.FileMode
+	errs  map[string]error
+}
+
+func walkTree(t *testing.T, root string, followSymlinks bool) *walked {
+	w := &walked{
+		modes: map[string]os.FileMode{},
+		errs:  map[string]error{},
+	}
+
+	err := walkLocal(root, followSymlinks, func(path string, info os.FileInfo, err error) error {
+		rel, relErr := filepath.Rel(root, path)
+		if relErr != nil {
+			return relErr
+		}
+
+		if err != nil {
+			w.errs[rel] = err
+
+			return nil
+		}
+
+		w.paths = append(w.paths, rel)
+		w.modes[rel] = info.Mode().Type()
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return w
+}
+
+func TestWalkLocal(t *testing.T) {
+	root := symlinkTree(t)
+
+	w := walkTree(t, root, false)
+
+	expected := []string{".", "dir", "dir/file", "dir/filelink", "dir/sub", "dir/sub/up", "target"}
+
+	if !slices.Equal(w.paths, expected) {
+		t.Errorf("expected %v, got %v", expected, w.paths)
+	}
+
+	if w.modes["target"] != os.ModeSymlink || w.modes["dir/filelink"] != os.ModeSymlink {
+		t.Errorf("expected symbolic links not to be followed, got %v", w.modes)
+	}
+
+	if len(w.errs) > 0 {
+		t.Errorf("expected no errors, got %v", w.errs)
+	}
+}
+
+func TestWalkLocalFollowSymlinks(t *testing.T) {
+	root := symlinkTree(t)
+
+	w := walkTree(t, root, true)
+
+	expected := []string{".", "dir", "dir/file", "dir/filelink", "dir/sub", "target", "target/linked"}
+
+	if !slices.Equal(w.paths, expected) {
+		t.Errorf("expected %v, got %v", expected, w.paths)
+	}
+
+	if !w.modes["dir/filelink"].IsRegular() || !w.modes["target"].IsDir() {
+		t.Errorf("expected symbolic links to be followed, got %v", w.modes)
+	}
+
+	if err := w.errs["dir/sub/up"]; !errors.Is(err, ErrSymlinkLoop) {
+		t.Errorf("expected %v, got %v", ErrSymlinkLoop, err)
+	}
+
+	if len(w.errs) != 1 {
+		t.Errorf("expected a single error, got %v", w.errs)
+	}
+}
+
+func TestWalkLocalDanglingSymlink(t *testing.T) {
+	root := t.TempDir()
+
+	if err := os.Symlink(filepath.Join(root, "missing"), filepath.Join(root, "dangling")); err != nil {
+		t.Fatal(err)
+	}
+
+	w := walkTree(t, root, true)
+
+	if !slices.Equal(w.paths, []string{"."}) {
+		t.Errorf("expected only the root, got %v", w.paths)
+	}
+
+	if err := w.errs["dangling"]; !os.IsNotExist(err) {
+		t.Errorf("expected a not exist error, got %v", err)
+	}
+}
+
+func TestSynchronizeDirSymlinkLoop(t *testing.T) {
+	root := symlinkTree(t)
+
+	testConn := &api.MockConn{}
+	testConn.AddResponses([]any{msg.QueryResponse{}, msg.QueryResponse{}}) // remote collection does not exist
+
+	var errs []error
+
+	worker := New(&api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+	}, nil, Options{
+		FollowSymlinks: true,
+		ErrorHandler: func(path, irodsPath string, err error) error {
+			if !errors.Is(err, api.ErrNoRowFound) {
+				errs = append(errs, err)
+			}
+
+			return nil
+		},
+	})
+
+	queue := make(chan Task, 100)
+
+	if err := worker.SynchronizeDir(t.Context(), root, "/test", LocalToRemote, queue, SynchronizeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	close(queue)
+
+	var paths []string
+
+	for task := range queue {
+		paths = append(paths, task.IrodsPath)
+	}
+
+	// The loop is skipped with a warning, and not passed to the error handler
+	if len(errs) > 0 {
+		t.Errorf("expected no errors, got %v", errs)
+	}
+
+	if slices.Contains(paths, "/test/dir/sub/up") {
+		t.Errorf("expected the loop to be skipped, got %v", paths)
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/kuleuven/iron/api"
@@ -20,6 +23,9 @@ import (
 	"golang.org/x/sync/errgroup"
 )
 
+// logger logs the messages of this package, see iron.LogComponentField
+var logger = logrus.WithField("component", "transfer")
+
 type Options struct {
 	// Do not overwrite existing files
 	Exclusive bool
@@ -37,25 +43,94 @@ type Options struct {
 	// MaxQueued indicates the maximum number of queued files
 	// when uploading or downloading a directory
 	MaxQueued int
+	// MaxOutstanding indicates the maximum number of files that are being transferred
+	// at the same time when uploading, downloading or copying a directory (UploadDir,
+	// DownloadDir, CopyDir). If the limit is reached, the directory scan blocks until
+	// transfers complete, so that at most MaxQueued + MaxOutstanding scanned files are
+	// kept in memory. Zero means no limit. Together with MaxThreads, this allows to tune
+	// the file level and the range level concurrency independently: the transfer pool
+	// of iron.Client is sized to MaxOutstanding * MaxThreads connections if possible.
+	MaxOutstanding int
 	// OnlyIfNewer indicates whether files should only be transferred
 	// if the source file is newer than the destination file,
 	// based on the modification time, when syncing directories (UploadDir, DownloadDir, CopyDir).
+	// Destination files that are smaller than the source are considered to be the result
+	// of an interrupted transfer, and are always retransferred.
 	OnlyIfNewer bool
 	// CompareChecksums indicates whether checksums should be verified
 	// to compare two existing file when syncing directories (UploadDir, DownloadDir, CopyDir).
 	CompareChecksums bool
+	// ChecksumOnModTimeChange indicates whether checksums should be compared for existing
+	// files of the same size that only differ in modification time, when syncing directories
+	// (UploadDir, DownloadDir, CopyDir). If the checksums match, the file is not transferred
+	// again, and only the modification time is synced if SyncModTime is set.
+	// This option has no effect if CompareChecksums is set.
+	ChecksumOnModTimeChange bool
+	// PreserveACLs indicates whether the access permissions and the inheritance flag of
+	// the source should be applied to the collections and data objects that are created
+	// when copying a collection (CopyDir). Existing permissions of the target are kept.
+	PreserveACLs bool
+	// MaxReopens indicates how many times a range of a parallel upload or download is
+	// reopened after a failed write or read, continuing at the offset of the failure,
+	// before the transfer fails. Zero disables retries. See RetryPolicy.
+	MaxReopens int
+	// ReopenBackoff is the time to wait before the first reopen of a range,
+	// it is doubled for every subsequent attempt of the same range.
+	ReopenBackoff time.Duration
 	// IntegrityChecksums indicates whether checksums should be computed before
 	// and after the transfer to verify the integrity of the transfer (Upload, Download, UploadDir, DownloadDir, CopyDir).
 	IntegrityChecksums bool
+	// PreAllocate indicates whether local files should be allocated to their final size
+	// before downloading (Download, DownloadDir), so that the download fails early if
+	// there is not enough disk space. It has no effect for writers that are not local files.
+	PreAllocate bool
+	// CheckResource indicates whether the status of the default resource of the transfer
+	// pool should be checked before uploading (Upload, UploadDir, FromReader, FromStream),
+	// so that an upload to a resource that is marked down fails early with ErrResourceDown,
+	// instead of failing halfway. Only the status of the root of a resource hierarchy is checked.
+	CheckResource bool
+	// DetectContentType indicates whether the MIME type of uploaded files should be
+	// detected from their first bytes, using http.DetectContentType, and recorded
+	// as the ContentTypeAttribute AVU of the data object (Upload, UploadDir, FromReader).
+	// The data type of the data object is not changed, as iRODS only accepts
+	// data types that are registered in the catalog.
+	DetectContentType bool
+	// ForceChecksums indicates whether the checksums of data objects that already have
+	// a checksum should be recomputed (ComputeChecksums). Without it, only missing
+	// checksums are computed if IntegrityChecksums is set.
+	ForceChecksums bool
+	// ChecksumHandler will, if set, be called by ComputeChecksums for each data object
+	// of which the checksum was computed (computed is true), or of which a checksum
+	// was already present (computed is false). It is called concurrently.
+	ChecksumHandler func(irodsPath string, computed bool)
+	// ReportVerification indicates whether the outcome of the checksum verification
+	// of each transferred file should be passed to the progress handler,
+	// using the VerifyChecksum action. See Progress.Verification.
+	ReportVerification bool
 	// DryRun will only print actions for directory operations (UploadDir, DownloadDir, RemoveDir, CopyDir).
 	// It does not apply to file operations (Upload, Download, ToStream, FromStream)!
 	DryRun bool
-	// IgnorePatterns indicates patterns to ignore when uploading, downloading or copying a directory (UploadDir, DownloadDir, CopyDir).
-	// The pattern syntax is the same as filepath.Match.
+	// DryRunHandler will, if set, be called for each action of a dry run instead of printing it.
+	// It is called concurrently, and can be used to count the files and bytes that would be transferred.
+	DryRunHandler func(task Task)
+	// FollowSymlinks indicates whether symbolic links in the local directory should be
+	// followed when uploading or downloading a directory (UploadDir, DownloadDir), so that
+	// linked files and directories are transferred as if they were regular files and directories.
+	// Links that point to one of their parent directories would loop forever, and are skipped with a warning.
+	// Without it, symbolic links are skipped.
+	FollowSymlinks bool
+	// IgnorePatterns indicates patterns to ignore when uploading, downloading or copying a directory (UploadDir, DownloadDir, CopyDir,
+	// DownloadModifiedSince). The patterns are matched against the names of files and directories, and the contents of ignored
+	// directories are ignored as well. The pattern syntax is the same as filepath.Match.
 	IgnorePatterns []string
 	// Output will, if set, display a progress bar and occurring errors
 	// If ErrorHandler or ProgressHandler is set, this option is ignored
 	Output io.Writer
+	// NoColors disables ANSI colors in the progress bar and in the output of a dry run
+	NoColors bool
+	// JSONOutput will, if set, emit progress updates and errors as JSON lines, see ProgressEvent.
+	// It takes precedence over Output. If ErrorHandler or ProgressHandler is set, this option is ignored
+	JSONOutput io.Writer
 	// Progress handler, can be used to track the progress of the transfers
 	ProgressHandler func(progress Progress)
 	// Error handler, called when an error occurs
@@ -73,20 +148,44 @@ type Worker struct {
 	// Internal waitgroup
 	wg errgroup.Group
 
+	// Semaphore for MaxOutstanding
+	outstanding chan struct{}
+
 	// Hooks for Wait() function
 	onwait func()
 	closer func() error
+
+	// Number of bytes transferred
+	transferred atomic.Int64
+
+	// Result of the resource status check, see CheckResource
+	checkResourceOnce sync.Once
+	checkResourceErr  error
 }
 
+// New creates a Worker that uses indexPool for index operations, such as listing
+// collections and creating directories, and transferPool for transfers. The pools
+// should not share connections, otherwise transfers can hold all connections while
+// an index operation waits for one; use NewShared to use a single pool for both.
 func New(indexPool, transferPool *api.API, options Options) *Worker {
 	var (
 		onwait func()
 		closer func() error
 	)
 
-	if options.Output != nil && options.ProgressHandler == nil && options.ErrorHandler == nil {
+	if options.JSONOutput != nil && options.ProgressHandler == nil && options.ErrorHandler == nil {
+		p := JSONProgress(options.JSONOutput)
+
+		options.ProgressHandler = p.Handler
+		options.ErrorHandler = p.ErrorHandler
+		closer = p.Close
+	} else if options.Output != nil && options.ProgressHandler == nil && options.ErrorHandler == nil {
 		p := ProgressBar(options.Output)
 
+		if options.NoColors {
+			p.DisableColors()
+		}
+
 		options.ProgressHandler = p.Handler
 		options.ErrorHandler = p.ErrorHandler
 		onwait = p.ScanCompleted
@@ -109,13 +208,67 @@ func New(indexPool, transferPool *api.API, options Options) *Worker {
 		options.MaxThreads = 1
 	}
 
-	return &Worker{
+	var outstanding chan struct{}
+
+	if options.MaxOutstanding > 0 {
+		outstanding = make(chan struct{}, options.MaxOutstanding)
+	}
+
+	worker := &Worker{
 		IndexPool:    indexPool,
 		TransferPool: transferPool,
 		options:      options,
+		outstanding:  outstanding,
 		onwait:       onwait,
 		closer:       closer,
 	}
+
+	handler := options.ProgressHandler
+
+	worker.options.ProgressHandler = func(progress Progress) {
+		if progress.Action == TransferFile {
+			worker.transferred.Add(progress.Increment)
+		}
+
+		handler(progress)
+	}
+
+	return worker
+}
+
+// Transferred returns the number of bytes of file contents transferred so far.
+func (worker *Worker) Transferred() int64 {
+	return worker.transferred.Load()
+}
+
+// retryPolicy returns the policy for retrying failed ranges of parallel transfers.
+func (worker *Worker) retryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxReopens: worker.options.MaxReopens,
+		Backoff:    worker.options.ReopenBackoff,
+	}
+}
+
+// acquire blocks until a transfer is allowed to start according to MaxOutstanding.
+// It returns false if the context is done first.
+func (worker *Worker) acquire(ctx context.Context) bool {
+	if worker.outstanding == nil {
+		return true
+	}
+
+	select {
+	case worker.outstanding <- struct{}{}:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// release marks a transfer started after acquire() as completed.
+func (worker *Worker) release() {
+	if worker.outstanding != nil {
+		<-worker.outstanding
+	}
 }
 
 type Progress struct {
@@ -126,6 +279,69 @@ type Progress struct {
 	Increment   int64
 	StartedAt   time.Time
 	FinishedAt  time.Time
+	// Verification is only set for the VerifyChecksum action
+	Verification Verification
+	// Removal is only set for the RemoveFile and RemoveDirectory actions on the iRODS server
+	Removal Removal
+}
+
+// Verification is the outcome of the checksum verification of a transferred file
+type Verification int
+
+const (
+	NotVerified Verification = iota
+	Verified
+	VerificationMismatch
+	VerificationSkipped
+)
+
+func (v Verification) String() string {
+	switch v {
+	case Verified:
+		return "verified"
+	case VerificationMismatch:
+		return "checksum mismatch"
+	case VerificationSkipped:
+		return "not verified"
+	default:
+		return ""
+	}
+}
+
+// Removal tells whether a data object or collection that was removed from the
+// iRODS server was moved to the trash, or deleted permanently
+type Removal int
+
+const (
+	NoRemoval Removal = iota
+	MovedToTrash
+	PermanentlyDeleted
+)
+
+// RemovalOf returns how the data object or collection at the given iRODS path
+// is removed. Removing something that is already in the trash of a zone deletes
+// it permanently, even if skipTrash is not set.
+func RemovalOf(irodsPath string, skipTrash bool) Removal {
+	if skipTrash {
+		return PermanentlyDeleted
+	}
+
+	if parts := strings.Split(strings.TrimPrefix(irodsPath, "/"), "/"); len(parts) > 1 && parts[1] == "trash" {
+		return PermanentlyDeleted
+	}
+
+	return MovedToTrash
+}
+
+func (r Removal) String() string {
+	switch r {
+	case MovedToTrash:
+		return "moved to trash"
+	case PermanentlyDeleted:
+		return "permanently deleted"
+	default:
+		return ""
+	}
 }
 
 type progressWriter struct {
@@ -175,10 +391,40 @@ func (worker *Worker) Wait() error {
 	return err
 }
 
+// ErrResourceDown is returned when uploading to a resource that is marked down, see CheckResource
+var ErrResourceDown = errors.New("resource is down")
+
+// checkResource checks the status of the default resource if CheckResource is set.
+// The status is only queried once per worker.
+func (worker *Worker) checkResource(ctx context.Context) error {
+	resource := worker.TransferPool.ResolveDefaultResource()
+
+	if !worker.options.CheckResource || resource == "" {
+		return nil
+	}
+
+	worker.checkResourceOnce.Do(func() {
+		status, err := worker.IndexPool.ResourceStatus(ctx, resource)
+		if err != nil {
+			worker.checkResourceErr = fmt.Errorf("failed to check status of resource %s: %w", resource, err)
+		} else if strings.EqualFold(status, api.ResourceDown) {
+			worker.checkResourceErr = fmt.Errorf("%w: %s", ErrResourceDown, resource)
+		}
+	})
+
+	return worker.checkResourceErr
+}
+
 // Upload schedules the upload of a local file to the iRODS server using parallel transfers.
 // The local file refers to the local file system. The remote file refers to an iRODS path.
 // The call blocks until the transfer of all chunks has started.
 func (worker *Worker) Upload(ctx context.Context, local, remote string) {
+	if err := worker.checkResource(ctx); err != nil {
+		worker.Error(local, remote, err)
+
+		return
+	}
+
 	r, err := os.Open(local)
 	if err != nil {
 		worker.Error(local, remote, err)
@@ -244,6 +490,25 @@ func (r fileReader) Checksum(ctx context.Context) ([]byte, error) {
 // The remote file refers to an iRODS path.
 // The call blocks until the transfer of all chunks has started.
 func (worker *Worker) FromReader(ctx context.Context, r Reader, remote string) { //nolint:funlen
+	if err := worker.checkResource(ctx); err != nil {
+		worker.Error(r.Name(), remote, multierr.Append(err, r.Close()))
+
+		return
+	}
+
+	var contentType string
+
+	if worker.options.DetectContentType {
+		var err error
+
+		contentType, err = detectContentType(r)
+		if err != nil {
+			worker.Error(r.Name(), remote, multierr.Append(err, r.Close()))
+
+			return
+		}
+	}
+
 	mode := api.O_CREAT | api.O_WRONLY | api.O_TRUNC
 
 	if worker.options.Exclusive {
@@ -277,6 +542,8 @@ func (worker *Worker) FromReader(ctx context.Context, r Reader, remote string) {
 		Reopen: func() (WriteSeekCloser, error) {
 			return w.Reopen(nil, api.O_WRONLY)
 		},
+		Retry:   worker.retryPolicy(),
+		Context: ctx,
 	}
 
 	var wg errgroup.Group
@@ -303,12 +570,21 @@ func (worker *Worker) FromReader(ctx context.Context, r Reader, remote string) {
 		}
 
 		if cr, ok := r.(ChecksumReader); ok {
-			err = multierr.Append(err, worker.verifyChecksumAndClose(ctx, cr.Checksum, w))
+			err = multierr.Append(err, worker.verifyChecksumAndClose(ctx, r.Name(), cr.Checksum, w))
 		} else {
 			err = multierr.Append(err, w.Close())
+
+			worker.reportVerification(r.Name(), VerificationSkipped)
 		}
 
 		err = multierr.Append(err, r.Close())
+		if err == nil && contentType != "" {
+			err = worker.IndexPool.SetMetadata(ctx, remote, api.DataObjectType, api.Metadata{
+				Name:  ContentTypeAttribute,
+				Value: contentType,
+			})
+		}
+
 		if err != nil {
 			err = multierr.Append(err, worker.IndexPool.DeleteDataObject(ctx, remote, true))
 
@@ -319,13 +595,31 @@ func (worker *Worker) FromReader(ctx context.Context, r Reader, remote string) {
 	})
 }
 
-func (worker *Worker) verifyChecksumAndClose(ctx context.Context, callback func(ctx context.Context) ([]byte, error), remote api.File) error {
+// ContentTypeAttribute is the name of the AVU in which the detected
+// MIME type of an uploaded file is recorded, see Options.DetectContentType.
+const ContentTypeAttribute = "content_type"
+
+// detectContentType sniffs the MIME type of the first bytes of a reader
+func detectContentType(r io.ReaderAt) (string, error) {
+	buf := make([]byte, 512)
+
+	n, err := r.ReadAt(buf, 0)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return http.DetectContentType(buf[:n]), nil
+}
+
+func (worker *Worker) verifyChecksumAndClose(ctx context.Context, label string, callback func(ctx context.Context) ([]byte, error), remote api.File) error {
 	conn, err := remote.CloseReturnConnection()
 	if err != nil {
 		return multierr.Append(err, conn.Close())
 	}
 
 	if !worker.options.IntegrityChecksums {
+		worker.reportVerification(label, VerificationSkipped)
+
 		return conn.Close()
 	}
 
@@ -353,12 +647,30 @@ func (worker *Worker) verifyChecksumAndClose(ctx context.Context, callback func(
 	}
 
 	if !bytes.Equal(localChecksum, remoteChecksum) {
+		worker.reportVerification(label, VerificationMismatch)
+
 		return fmt.Errorf("%w: local: %s remote: %s", ErrChecksumMismatch, base64.StdEncoding.EncodeToString(localChecksum), base64.StdEncoding.EncodeToString(remoteChecksum))
 	}
 
+	worker.reportVerification(label, Verified)
+
 	return nil
 }
 
+// reportVerification passes the outcome of a checksum verification
+// to the progress handler, if enabled
+func (worker *Worker) reportVerification(label string, verification Verification) {
+	if !worker.options.ReportVerification {
+		return
+	}
+
+	worker.Progress(Progress{
+		Action:       VerifyChecksum,
+		Label:        label,
+		Verification: verification,
+	})
+}
+
 func (worker *Worker) tryOpenDataObject(ctx context.Context, remote string, mode int) (api.File, error) {
 	w, err := worker.TransferPool.OpenDataObject(ctx, remote, mode)
 	if err == nil {
@@ -387,6 +699,12 @@ func (worker *Worker) tryOpenDataObject(ctx context.Context, remote string, mode
 // In contrast to FromReader, FromStream will block until the full file has been uploaded.
 // The remote file refers to an iRODS path.
 func (worker *Worker) FromStream(ctx context.Context, name string, r io.Reader, remote string, appendToFile bool) {
+	if err := worker.checkResource(ctx); err != nil {
+		worker.Error(name, remote, err)
+
+		return
+	}
+
 	mode := api.O_CREAT | api.O_WRONLY | api.O_TRUNC
 
 	if appendToFile {
@@ -435,6 +753,11 @@ func (worker *Worker) FromStream(ctx context.Context, name string, r io.Reader,
 // The local file refers to the local file system. The remote file refers to an iRODS path.
 // The call blocks until the transfer of all chunks has started.
 func (worker *Worker) Download(ctx context.Context, local, remote string) {
+	worker.download(ctx, local, remote, nil)
+}
+
+// download behaves as Download, and calls release when the local file is closed
+func (worker *Worker) download(ctx context.Context, local, remote string, release func()) {
 	mode := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
 
 	if worker.options.Exclusive {
@@ -442,6 +765,10 @@ func (worker *Worker) Download(ctx context.Context, local, remote string) {
 	}
 
 	w, err := os.OpenFile(local, mode, 0o600)
+	if err != nil && release != nil {
+		release()
+	}
+
 	if errors.Is(err, os.ErrExist) && worker.options.Exclusive {
 		worker.Error(local, remote, fmt.Errorf("cannot download exclusively: %w", os.ErrExist))
 
@@ -453,8 +780,9 @@ func (worker *Worker) Download(ctx context.Context, local, remote string) {
 	}
 
 	worker.ToWriter(ctx, &fileWriter{
-		name: local,
-		File: w,
+		name:    local,
+		File:    w,
+		release: release,
 	}, remote)
 }
 
@@ -471,9 +799,23 @@ type ChecksumWriter interface {
 	Checksum(ctx context.Context) ([]byte, error)
 }
 
+type PreAllocateWriter interface {
+	Writer
+	PreAllocate(size int64) error
+}
+
 type fileWriter struct {
 	name string
 	*os.File
+	release func()
+}
+
+func (w fileWriter) Close() error {
+	if w.release != nil {
+		defer w.release()
+	}
+
+	return w.File.Close()
 }
 
 func (w fileWriter) Name() string {
@@ -492,6 +834,10 @@ func (w fileWriter) Checksum(ctx context.Context) ([]byte, error) {
 	return Sha256Checksum(ctx, w.name)
 }
 
+func (w fileWriter) PreAllocate(size int64) error {
+	return preAllocate(w.File, size)
+}
+
 // Download schedules the download of a remote file from the iRODS server using parallel transfers.
 // The remote file refers to an iRODS path.
 // The call blocks until the transfer of all chunks has started.
@@ -516,6 +862,19 @@ func (worker *Worker) ToWriter(ctx context.Context, w Writer, remote string) { /
 		return
 	}
 
+	if aw, ok := w.(PreAllocateWriter); ok && worker.options.PreAllocate && size > 0 {
+		if err = aw.PreAllocate(size); err != nil {
+			err = fmt.Errorf("cannot allocate %d bytes: %w", size, err)
+			err = multierr.Append(err, r.Close())
+			err = multierr.Append(err, w.Close())
+			err = multierr.Append(err, w.Remove())
+
+			worker.Error(w.Name(), remote, err)
+
+			return
+		}
+	}
+
 	// Schedule the download
 	pw := &progressWriter{
 		progress: Progress{
@@ -536,6 +895,8 @@ func (worker *Worker) ToWriter(ctx context.Context, w Writer, remote string) { /
 		Reopen: func() (io.ReadSeekCloser, error) {
 			return r.Reopen(nil, api.O_RDONLY)
 		},
+		Retry:   worker.retryPolicy(),
+		Context: ctx,
 	}
 
 	var wg errgroup.Group
@@ -559,9 +920,11 @@ func (worker *Worker) ToWriter(ctx context.Context, w Writer, remote string) { /
 		err = multierr.Append(err, w.Close())
 
 		if cw, ok := w.(ChecksumWriter); ok {
-			err = multierr.Append(err, worker.verifyChecksumAndClose(ctx, cw.Checksum, r))
+			err = multierr.Append(err, worker.verifyChecksumAndClose(ctx, w.Name(), cw.Checksum, r))
 		} else {
 			err = multierr.Append(err, r.Close())
+
+			worker.reportVerification(w.Name(), VerificationSkipped)
 		}
 
 		if err != nil {
@@ -656,6 +1019,12 @@ func (worker *Worker) ToStream(ctx context.Context, name string, w io.Writer, re
 // The local file refers to the local file system. The remote file refers to an iRODS path.
 // The call blocks until the source directory has been completely scanned.
 func (worker *Worker) UploadDir(ctx context.Context, local, remote string) {
+	if err := worker.checkResource(ctx); err != nil {
+		worker.Error(local, remote, err)
+
+		return
+	}
+
 	if err := worker.IndexPool.CreateCollectionAll(ctx, remote); err != nil {
 		worker.Error(local, remote, err)
 
@@ -681,10 +1050,10 @@ func (worker *Worker) UploadDir(ctx context.Context, local, remote string) {
 				worker.uploadAction(ctx, u)
 
 			case RemoveFile:
-				worker.action(u, func() error { return worker.TransferPool.DeleteDataObject(ctx, u.IrodsPath, worker.options.SkipTrash) })
+				worker.removeAction(u, func() error { return worker.TransferPool.DeleteDataObject(ctx, u.IrodsPath, worker.options.SkipTrash) })
 
 			case RemoveDirectory:
-				worker.action(u, func() error { return worker.TransferPool.DeleteCollection(ctx, u.IrodsPath, worker.options.SkipTrash) })
+				worker.removeAction(u, func() error { return worker.TransferPool.DeleteCollection(ctx, u.IrodsPath, worker.options.SkipTrash) })
 
 			case CreateDirectory:
 				worker.action(u, func() error { return worker.TransferPool.CreateCollection(ctx, u.IrodsPath) })
@@ -703,6 +1072,65 @@ func (worker *Worker) UploadDir(ctx context.Context, local, remote string) {
 	}
 }
 
+// ErrNotInSync is reported for each file or directory that differs between
+// a local directory and a remote collection, see VerifyDir.
+var ErrNotInSync = errors.New("not in sync")
+
+// VerifyDir verifies that a remote collection on the iRODS server matches a local directory,
+// e.g. after UploadDir. Both sides are compared in the same way as by UploadDir, but checksums
+// are always compared, and nothing is transferred: each file or directory that is missing in the
+// remote collection, that differs, or that does not exist locally is passed to the ErrorHandler
+// as ErrNotInSync. Files that match IgnorePatterns are not compared.
+// The call blocks until both sides have been completely compared.
+func (worker *Worker) VerifyDir(ctx context.Context, local, remote string) {
+	options := worker.options
+
+	options.Delete = true
+	options.Exclusive = false
+	options.OnlyIfNewer = false
+	options.CompareChecksums = true
+	options.SyncModTime = false
+	options.ProgressHandler = nil
+
+	verifier := &Worker{
+		IndexPool:    worker.IndexPool,
+		TransferPool: worker.TransferPool,
+		options:      options,
+	}
+
+	queue := make(chan Task, worker.options.MaxQueued)
+
+	// Report the differences
+	worker.wg.Go(func() error {
+		for u := range queue {
+			var reason string
+
+			switch u.Action { //nolint:exhaustive
+			case TransferFile:
+				reason = "missing or different in " + remote
+			case CreateDirectory:
+				reason = "collection missing in " + remote
+			case RemoveFile, RemoveDirectory:
+				reason = "does not exist in " + local
+			default:
+				continue
+			}
+
+			worker.Error(u.Path, u.IrodsPath, fmt.Errorf("%w: %s", ErrNotInSync, reason))
+		}
+
+		return nil
+	})
+
+	defer close(queue)
+
+	if err := verifier.SynchronizeDir(ctx, local, remote, LocalToRemote, queue, SynchronizeOptions{}); err != nil {
+		worker.wg.Go(func() error {
+			return err
+		})
+	}
+}
+
 func (worker *Worker) uploadAction(ctx context.Context, u Task) {
 	if worker.options.DryRun {
 		worker.log(u)
@@ -710,22 +1138,36 @@ func (worker *Worker) uploadAction(ctx context.Context, u Task) {
 		return
 	}
 
+	if !worker.acquire(ctx) {
+		return
+	}
+
 	r, err := os.Open(u.Path)
 	if err != nil {
+		worker.release()
 		worker.Error(u.Path, u.IrodsPath, err)
 
 		return
 	}
 
 	worker.FromReader(ctx, &taskReader{
-		task: u,
-		File: r,
+		task:    u,
+		File:    r,
+		release: worker.release,
 	}, u.IrodsPath)
 }
 
 type taskReader struct {
 	task Task
 	*os.File
+	release func()
+}
+
+// Close closes the file and releases the slot acquired for the transfer
+func (tr *taskReader) Close() error {
+	defer tr.release()
+
+	return tr.File.Close()
 }
 
 func (tr *taskReader) Name() string {
@@ -803,7 +1245,116 @@ func (worker *Worker) downloadAction(ctx context.Context, u Task) {
 		return
 	}
 
-	worker.Download(ctx, u.Path, u.IrodsPath)
+	if !worker.acquire(ctx) {
+		return
+	}
+
+	worker.download(ctx, u.Path, u.IrodsPath, worker.release)
+}
+
+// DownloadModifiedSince downloads the data objects in a remote collection tree that were modified
+// after the given time to the corresponding paths in the local directory, and creates the local
+// directories for collections that were modified after the given time. As opposed to DownloadDir,
+// the complete collection tree is not listed and unchanged files are not compared, and local files
+// are never removed. This makes it suited for incremental backups of mostly static collections.
+// A modified collection without a local directory, e.g. one that was moved into the collection tree,
+// is downloaded completely, as its data objects keep their original modification times.
+func (worker *Worker) DownloadModifiedSince(ctx context.Context, local, remote string, since time.Time) {
+	records, err := worker.IndexPool.ListModifiedSince(ctx, remote, since)
+	if err != nil {
+		worker.Error(local, remote, err)
+
+		return
+	}
+
+	worker.downloadRecords(ctx, local, remote, records, true)
+}
+
+// downloadRecords downloads the given data objects and creates the directories for the given
+// collections, see DownloadModifiedSince. If expand is set, the complete subtree is downloaded
+// for collections that don't exist locally.
+func (worker *Worker) downloadRecords(ctx context.Context, local, remote string, records []api.Record, expand bool) {
+	// Parents sort before their children, so that a complete subtree is only downloaded once
+	slices.SortStableFunc(records, func(a, b api.Record) int {
+		return strings.Compare(recordPath(a), recordPath(b))
+	})
+
+	var expanded []string
+
+	for _, record := range records {
+		if ctx.Err() != nil {
+			return
+		}
+
+		irodsPath := recordPath(record)
+
+		path := filepath.Join(local, filepath.FromSlash(strings.TrimPrefix(irodsPath, remote)))
+
+		if worker.shouldIgnorePath(strings.TrimPrefix(irodsPath, remote)) {
+			continue
+		}
+
+		if slices.ContainsFunc(expanded, func(coll string) bool {
+			return strings.HasPrefix(irodsPath, coll+"/")
+		}) {
+			continue
+		}
+
+		if record.IsDir() && expand && irodsPath != remote {
+			if _, err := os.Stat(path); os.IsNotExist(err) {
+				subtree, err := worker.IndexPool.ListModifiedSince(ctx, irodsPath, time.Unix(0, 0))
+				if err != nil {
+					worker.Error(path, irodsPath, err)
+
+					continue
+				}
+
+				worker.downloadRecords(ctx, local, remote, subtree, false)
+
+				expanded = append(expanded, irodsPath)
+
+				continue
+			}
+		}
+
+		if record.IsDir() {
+			worker.action(Task{
+				Action:    CreateDirectory,
+				Path:      path,
+				IrodsPath: irodsPath,
+			}, func() error { return os.MkdirAll(path, 0o755) })
+
+			continue
+		}
+
+		if !worker.options.DryRun {
+			if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+				worker.Error(path, irodsPath, err)
+
+				continue
+			}
+		}
+
+		worker.downloadAction(ctx, Task{
+			Action:    TransferFile,
+			Path:      path,
+			IrodsPath: irodsPath,
+			Size:      record.Size(),
+			ModTime:   record.ModTime(),
+		})
+	}
+}
+
+// recordPath returns the iRODS path of a record returned by ListModifiedSince
+func recordPath(record api.Record) string {
+	switch v := record.Sys().(type) {
+	case *api.DataObject:
+		return v.Path
+	case *api.Collection:
+		return v.Path
+	default:
+		return ""
+	}
 }
 
 type Direction int
@@ -822,33 +1373,77 @@ const (
 	RemoveDirectory
 	ComputeChecksum
 	SetModificationTime
+	VerifyChecksum
 )
 
+func (a Action) String() string {
+	switch a {
+	case CreateDirectory:
+		return "create_directory"
+	case TransferFile:
+		return "transfer_file"
+	case RemoveFile:
+		return "remove_file"
+	case RemoveDirectory:
+		return "remove_directory"
+	case ComputeChecksum:
+		return "compute_checksum"
+	case SetModificationTime:
+		return "set_modification_time"
+	case VerifyChecksum:
+		return "verify_checksum"
+	default:
+		return fmt.Sprintf("action_%d", int(a))
+	}
+}
+
+// Format formats a label for the action, using ANSI colors.
 func (a Action) Format(label string) string {
+	return a.FormatColors(label, true)
+}
+
+// FormatColors formats a label for the action, using ANSI colors if colors is set.
+func (a Action) FormatColors(label string, colors bool) string {
+	colorize := func(color, s string) string {
+		return colorize(colors, color, s)
+	}
+
 	switch a {
 	case ComputeChecksum:
-		return fmt.Sprintf("\x1B[36mc %s\x1B[0m", label)
+		return colorize("36", "c "+label)
 
 	case SetModificationTime:
-		return fmt.Sprintf("\x1B[35mt %s\x1B[0m", label)
+		return colorize("35", "t "+label)
+
+	case VerifyChecksum:
+		return colorize("32", "v "+label)
 
 	case CreateDirectory:
-		return fmt.Sprintf("\x1B[34m+ %s/\x1B[0m", label)
+		return colorize("34", "+ "+label+"/")
 
 	case TransferFile:
 		return fmt.Sprintf("+ %s", label)
 
 	case RemoveFile:
-		return fmt.Sprintf("\x1B[31m- %s\x1B[0m", label)
+		return colorize("31", "- "+label)
 
 	case RemoveDirectory:
-		return fmt.Sprintf("\x1B[33m- %s/\x1B[0m", label)
+		return colorize("33", "- "+label+"/")
 
 	default:
 		return label
 	}
 }
 
+// colorize wraps s in the ANSI escape sequence for the given color, if enabled is set.
+func colorize(enabled bool, color, s string) string {
+	if !enabled {
+		return s
+	}
+
+	return "\x1B[" + color + "m" + s + "\x1B[0m"
+}
+
 type Task struct {
 	Action          Action
 	Path, IrodsPath string
@@ -901,7 +1496,7 @@ func (worker *Worker) SynchronizeDir(ctx context.Context, local, remote string,
 	wg.Go(func() error {
 		defer close(lch)
 
-		return filepath.Walk(local, func(path string, info os.FileInfo, err error) error {
+		return walkLocal(local, worker.options.FollowSymlinks, func(path string, info os.FileInfo, err error) error {
 			if ctx.Err() != nil {
 				return ctx.Err()
 			}
@@ -913,6 +1508,12 @@ func (worker *Worker) SynchronizeDir(ctx context.Context, local, remote string,
 
 			irodsPath := toIrodsPath(remote, relpath)
 
+			if errors.Is(err, ErrSymlinkLoop) {
+				logger.Warnf("skipping %s", err)
+
+				return nil
+			}
+
 			if err != nil {
 				return worker.options.ErrorHandler(path, irodsPath, err)
 			}
@@ -945,7 +1546,7 @@ func checkOrder(ch <-chan *object) <-chan *object {
 
 		for obj := range ch {
 			if prev != nil && api.ComparePaths(prev.irodsPath, obj.irodsPath) >= 0 {
-				logrus.Errorf("expected lexicographical order: %s >= %s [%s]", prev.irodsPath, obj.irodsPath, obj.info)
+				logger.Errorf("expected lexicographical order: %s >= %s [%s]", prev.irodsPath, obj.irodsPath, obj.info)
 			}
 
 			prev = obj
@@ -1090,9 +1691,13 @@ func (worker *Worker) merge(ctx context.Context, left, right <-chan *object, que
 }
 
 func (worker *Worker) shouldIgnore(obj *object) bool {
+	return worker.shouldIgnoreName(obj.info.Name())
+}
+
+func (worker *Worker) shouldIgnoreName(name string) bool {
 	// Ignore files from ignore globs
 	for _, pattern := range worker.options.IgnorePatterns {
-		if matched, matchErr := filepath.Match(pattern, obj.info.Name()); matchErr != nil {
+		if matched, matchErr := filepath.Match(pattern, name); matchErr != nil {
 			continue
 		} else if matched {
 			return true
@@ -1102,6 +1707,19 @@ func (worker *Worker) shouldIgnore(obj *object) bool {
 	return false
 }
 
+// shouldIgnorePath checks the names of all components of a slash separated path relative to
+// the root of a transfer, so that the contents of ignored collections are ignored as well,
+// as they are skipped by SynchronizeDir.
+func (worker *Worker) shouldIgnorePath(rel string) bool {
+	for name := range strings.SplitSeq(rel, "/") {
+		if name != "" && worker.shouldIgnoreName(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
 func (worker *Worker) compareAndTransferObject(ctx context.Context, left, right *object, queue chan<- Task, opts mergeOptions) error { //nolint:funlen
 	var checksum []byte
 
@@ -1111,13 +1729,17 @@ func (worker *Worker) compareAndTransferObject(ctx context.Context, left, right
 	case left.info.IsDir(), !left.info.Mode().IsRegular():
 		return nil
 
+	case isPartial(left, right, modTimeCompare):
+		// Retransfer, an earlier transfer was interrupted
+		logger.Debugf("partial file %s [%s], retransferring", right.irodsPath, right.info)
+
 	case worker.options.OnlyIfNewer && modTimeCompare < 0:
 		return nil
 
 	case left.info.Size() != right.info.Size():
 		// Retransfer
 
-	case worker.options.CompareChecksums:
+	case worker.options.CompareChecksums, worker.options.ChecksumOnModTimeChange && modTimeCompare != 0:
 		var err error
 
 		checksum, _, err = opts.ChecksumVerify(ctx, left.path, left.irodsPath, left.info, right.info)
@@ -1183,6 +1805,13 @@ func (worker *Worker) compareAndTransferObject(ctx context.Context, left, right
 	return nil
 }
 
+// isPartial returns whether the target looks like the result of an interrupted transfer
+// of the source: it has been written after the source was last modified, but it is smaller
+// than the source. Such a target must be retransferred, even if it is newer than the source.
+func isPartial(source, target *object, modTimeCompare int) bool {
+	return modTimeCompare < 0 && target.info.Size() < source.info.Size()
+}
+
 func (worker *Worker) removeAll(ch <-chan *object, obj *object, queue chan<- Task) (*object, bool) {
 	if obj.info.IsDir() {
 		next, ok := <-ch
@@ -1222,13 +1851,13 @@ func (worker *Worker) removeAll(ch <-chan *object, obj *object, queue chan<- Tas
 }
 
 func skipAll(ch <-chan *object, obj *object) (*object, bool) {
-	logrus.Debugf("skipping %s [%s]", obj.irodsPath, obj.info)
+	logger.Debugf("skipping %s [%s]", obj.irodsPath, obj.info)
 
 	next, ok := <-ch
 
 	if obj.info.IsDir() {
 		for ok && strings.HasPrefix(next.irodsPath, obj.irodsPath+"/") {
-			logrus.Debugf("skipping %s [%s]", next.irodsPath, next.info)
+			logger.Debugf("skipping %s [%s]", next.irodsPath, next.info)
 
 			next, ok = <-ch
 		}
@@ -1255,6 +1884,10 @@ func (worker *Worker) transferNewCollectionOrObject(obj *object, queue chan<- Ta
 
 	// Ignore non-regular files
 	if !obj.info.Mode().IsRegular() {
+		if obj.info.Mode()&os.ModeSymlink != 0 {
+			logger.Infof("skipping symbolic link %s, it is only followed with FollowSymlinks", obj.path)
+		}
+
 		return
 	}
 
@@ -1290,10 +1923,10 @@ func (worker *Worker) RemoveDir(ctx context.Context, remote string) {
 
 			switch u.Action { //nolint:exhaustive
 			case RemoveFile:
-				worker.action(u, func() error { return worker.TransferPool.DeleteDataObject(ctx, u.IrodsPath, worker.options.SkipTrash) })
+				worker.removeAction(u, func() error { return worker.TransferPool.DeleteDataObject(ctx, u.IrodsPath, worker.options.SkipTrash) })
 
 			case RemoveDirectory:
-				worker.action(u, func() error { return worker.TransferPool.DeleteCollection(ctx, u.IrodsPath, worker.options.SkipTrash) })
+				worker.removeAction(u, func() error { return worker.TransferPool.DeleteCollection(ctx, u.IrodsPath, worker.options.SkipTrash) })
 			}
 		}
 
@@ -1331,35 +1964,26 @@ func (worker *Worker) RemoveDir(ctx context.Context, remote string) {
 }
 
 // ComputeChecksums computes the checksums of all files in a directory on the iRODS server.
-// It handles the recursion client side, but individual files are processed server-side only.
+// It handles the recursion client side, but individual files are processed server-side only,
+// using up to MaxThreads files at the same time.
 // The call blocks until the source directory has been completely scanned.
 func (worker *Worker) ComputeChecksums(ctx context.Context, remote string) {
 	queue := make(chan Task, worker.options.MaxQueued)
 
 	// Execute the tasks
-	worker.wg.Go(func() error {
-		for u := range queue {
-			if ctx.Err() != nil {
-				continue
-			}
-
-			switch u.Action { //nolint:exhaustive
-			case ComputeChecksum:
-				worker.action(u, func() error {
-					_, err := worker.TransferPool.Checksum(ctx, u.IrodsPath, true)
-
-					return err
-				})
+	for range worker.options.MaxThreads {
+		worker.wg.Go(func() error {
+			for u := range queue {
+				if ctx.Err() != nil {
+					continue
+				}
 
-			case TransferFile: // Actually we do VerifyChecksums here, but we want to report the progress as a transfer
-				worker.action(u, func() error {
-					return worker.TransferPool.VerifyChecksum(ctx, u.IrodsPath)
-				})
+				worker.checksumAction(ctx, u)
 			}
-		}
 
-		return ctx.Err()
-	})
+			return ctx.Err()
+		})
+	}
 
 	// Walk the directory
 	defer close(queue)
@@ -1385,33 +2009,116 @@ func (worker *Worker) computeChecksum(irodsPath string, record api.Record, queue
 		return
 	}
 
-	if _, hasChecksum := parseChecksum(record); hasChecksum && worker.options.CompareChecksums {
+	_, hasChecksum := parseChecksum(record)
+
+	switch {
+	case hasChecksum && worker.options.CompareChecksums:
 		queue <- Task{
 			Action:    TransferFile, // TransferFile = verify checksum
 			IrodsPath: irodsPath,
 		}
+	case hasChecksum && worker.options.ForceChecksums:
+		queue <- Task{
+			Action:    ComputeChecksum,
+			IrodsPath: irodsPath,
+		}
+	case hasChecksum:
+		worker.reportChecksum(irodsPath, false)
+	case worker.options.IntegrityChecksums || worker.options.ForceChecksums:
+		queue <- Task{
+			Action:    ComputeChecksum,
+			IrodsPath: irodsPath,
+		}
+	}
+}
 
-		return
-	} else if hasChecksum || !worker.options.IntegrityChecksums {
-		return
+func (worker *Worker) checksumAction(ctx context.Context, u Task) {
+	switch u.Action { //nolint:exhaustive
+	case ComputeChecksum:
+		worker.action(u, func() error {
+			_, err := worker.TransferPool.Checksum(ctx, u.IrodsPath, true)
+			if err == nil {
+				worker.reportChecksum(u.IrodsPath, true)
+			}
+
+			return err
+		})
+
+	case TransferFile: // Actually we do VerifyChecksums here, but we want to report the progress as a transfer
+		worker.action(u, func() error {
+			err := worker.TransferPool.VerifyChecksum(ctx, u.IrodsPath)
+			if !api.Is(err, msg.CAT_NO_CHECKSUM_FOR_REPLICA) || !worker.options.IntegrityChecksums {
+				if err == nil {
+					worker.reportChecksum(u.IrodsPath, false)
+				}
+
+				return err
+			}
+
+			// The replica that was verified has no checksum yet
+			_, err = worker.TransferPool.Checksum(ctx, u.IrodsPath, false)
+			if err == nil {
+				worker.reportChecksum(u.IrodsPath, true)
+			}
+
+			return err
+		})
 	}
+}
 
-	queue <- Task{
-		Action:    ComputeChecksum,
-		IrodsPath: irodsPath,
+func (worker *Worker) reportChecksum(irodsPath string, computed bool) {
+	if worker.options.ChecksumHandler != nil {
+		worker.options.ChecksumHandler(irodsPath, computed)
 	}
 }
 
+// Copy copies a data object to another path on the iRODS server.
+// The copy is performed server-side, but progress is reported as for
+// the data objects in CopyDir, using the size of the source data object.
+func (worker *Worker) Copy(ctx context.Context, remote1, remote2 string) {
+	// Use the transfer pool, so that a transfer pool of a single connection suffices
+	obj, err := worker.TransferPool.GetDataObject(ctx, remote1)
+	if err != nil {
+		worker.Error(remote1, remote2, err)
+
+		return
+	}
+
+	worker.copyAction(ctx, Task{
+		Action:    TransferFile,
+		Path:      remote1,
+		IrodsPath: remote2,
+		Size:      obj.Size(),
+	})
+}
+
+// ErrRecursiveCopy is returned when the target of a directory copy lies within the source,
+// which would make the copy recurse into its own output.
+var ErrRecursiveCopy = errors.New("cannot copy a collection into itself")
+
 // CopyDir copies one directory to another on the iRODS server.
 // It handles the recursion client side, but individual files are copied server-side only.
 // The call blocks until the source directory has been completely scanned.
+// If the target directory lies within the source directory, ErrRecursiveCopy is reported.
 func (worker *Worker) CopyDir(ctx context.Context, remote1, remote2 string) {
+	if api.IsSubPath(remote2, remote1) {
+		worker.Error(remote1, remote2, fmt.Errorf("%w: %s is inside %s", ErrRecursiveCopy, remote2, remote1))
+
+		return
+	}
+
 	if err := worker.IndexPool.CreateCollectionAll(ctx, remote2); err != nil {
 		worker.Error(remote1, remote2, err)
 
 		return
 	}
 
+	if worker.options.PreserveACLs && !worker.options.DryRun {
+		if err := worker.copyAccess(ctx, worker.TransferPool, remote1, remote2, api.CollectionType); err != nil {
+			worker.Error(remote1, remote2, err)
+		}
+	}
+
 	queue := make(chan Task, worker.options.MaxQueued)
 
 	// Execute the uploads
@@ -1431,13 +2138,19 @@ func (worker *Worker) CopyDir(ctx context.Context, remote1, remote2 string) {
 				worker.copyAction(ctx, u)
 
 			case RemoveFile:
-				worker.action(u, func() error { return worker.TransferPool.DeleteDataObject(ctx, u.IrodsPath, worker.options.SkipTrash) })
+				worker.removeAction(u, func() error { return worker.TransferPool.DeleteDataObject(ctx, u.IrodsPath, worker.options.SkipTrash) })
 
 			case RemoveDirectory:
-				worker.action(u, func() error { return worker.TransferPool.DeleteCollection(ctx, u.IrodsPath, worker.options.SkipTrash) })
+				worker.removeAction(u, func() error { return worker.TransferPool.DeleteCollection(ctx, u.IrodsPath, worker.options.SkipTrash) })
 
 			case CreateDirectory:
-				worker.action(u, func() error { return worker.TransferPool.CreateCollection(ctx, u.IrodsPath) })
+				worker.action(u, func() error {
+					if err := worker.TransferPool.CreateCollection(ctx, u.IrodsPath); err != nil || !worker.options.PreserveACLs {
+						return err
+					}
+
+					return worker.copyAccess(ctx, worker.TransferPool, u.Path, u.IrodsPath, api.CollectionType)
+				})
 			}
 		}
 
@@ -1463,8 +2176,13 @@ func (worker *Worker) copyAction(ctx context.Context, u Task) {
 	remote1 := u.Path
 	remote2 := u.IrodsPath
 
+	if !worker.acquire(ctx) {
+		return
+	}
+
 	conn, err := worker.TransferPool.Connect(ctx)
 	if err != nil {
+		worker.release()
 		worker.Error(remote1, remote2, err)
 
 		return
@@ -1480,10 +2198,20 @@ func (worker *Worker) copyAction(ctx context.Context, u Task) {
 	})
 
 	worker.wg.Go(func() error {
-		connAPI := *worker.TransferPool
-		connAPI.Connect = func(ctx context.Context) (api.Conn, error) { return conn, nil }
+		defer worker.release()
 
-		if err := connAPI.CopyDataObject(ctx, remote1, remote2); err != nil {
+		err := worker.TransferPool.WithConn(conn, func(connAPI *api.API) error {
+			if err := connAPI.CopyDataObject(ctx, remote1, remote2); err != nil {
+				return err
+			}
+
+			if !worker.options.PreserveACLs {
+				return nil
+			}
+
+			return worker.copyAccess(ctx, connAPI, remote1, remote2, api.DataObjectType)
+		})
+		if err != nil {
 			return worker.options.ErrorHandler(remote1, remote2, err)
 		}
 
@@ -1498,11 +2226,100 @@ func (worker *Worker) copyAction(ctx context.Context, u Task) {
 		})
 
 		// Verify the checksum after copying if integrity checksums are enabled
-		if worker.options.IntegrityChecksums {
-			_, _, err := VerifyRemoteToRemote(worker.TransferPool, worker.options.ProgressHandler)(ctx, remote1, remote2, nil, nil)
-			if err != nil {
-				return worker.options.ErrorHandler(remote1, remote2, err)
-			}
+		if !worker.options.IntegrityChecksums {
+			worker.reportVerification(ProgressLabel(remote1, remote2), VerificationSkipped)
+
+			return nil
+		}
+
+		_, _, err = VerifyRemoteToRemote(worker.TransferPool, worker.options.ProgressHandler)(ctx, remote1, remote2, nil, nil)
+		if errors.Is(err, ErrChecksumMismatch) {
+			worker.reportVerification(ProgressLabel(remote1, remote2), VerificationMismatch)
+		} else if err == nil {
+			worker.reportVerification(ProgressLabel(remote1, remote2), Verified)
+		}
+
+		if err != nil {
+			return worker.options.ErrorHandler(remote1, remote2, err)
+		}
+
+		return nil
+	})
+}
+
+// copyAccess applies the access permissions of remote1 to remote2 using the given API,
+// and for collections, the inheritance flag as well.
+func (worker *Worker) copyAccess(ctx context.Context, target *api.API, remote1, remote2 string, itemType api.ObjectType) error {
+	acl, err := worker.IndexPool.ListAccess(ctx, remote1, itemType)
+	if err != nil {
+		return err
+	}
+
+	for _, access := range acl {
+		if err := target.ModifyAccess(ctx, remote2, access.User.Name+"#"+access.User.Zone, access.Permission, false); err != nil {
+			return err
+		}
+	}
+
+	if itemType != api.CollectionType {
+		return nil
+	}
+
+	coll, err := worker.IndexPool.GetCollection(ctx, remote1)
+	if err != nil || !coll.Inheritance {
+		return err
+	}
+
+	return target.SetCollectionInheritance(ctx, remote2, true, false)
+}
+
+// CrossZoneCopy schedules a copy of a data object to a data object that is accessed through
+// another API, e.g. in another federated zone, for cases where a server-side copy is not available.
+// The contents are read using the TransferPool and streamed to the target through the client,
+// without using a local temporary file, and progress is reported to the progress handler.
+// The call blocks until the transfer has started.
+func (worker *Worker) CrossZoneCopy(ctx context.Context, remote string, dst *api.API, dstRemote string) {
+	label := ProgressLabel(remote, dstRemote)
+
+	r, err := worker.TransferPool.OpenDataObject(ctx, remote, api.O_RDONLY)
+	if err != nil {
+		worker.Error(remote, dstRemote, err)
+
+		return
+	}
+
+	size, err := findSize(r)
+	if err != nil {
+		worker.Error(remote, dstRemote, multierr.Append(err, r.Close()))
+
+		return
+	}
+
+	w, err := dst.OpenDataObject(ctx, dstRemote, api.O_WRONLY|api.O_CREAT|api.O_TRUNC)
+	if err != nil {
+		worker.Error(remote, dstRemote, multierr.Append(err, r.Close()))
+
+		return
+	}
+
+	pw := &progressWriter{
+		progress: Progress{
+			Action:    TransferFile,
+			Label:     label,
+			Size:      size,
+			StartedAt: time.Now(),
+		},
+		handler: worker.options.ProgressHandler,
+	}
+
+	pw.handler(pw.progress)
+
+	worker.wg.Go(func() error {
+		err := multierr.Combine(copyBuffer(w, r, pw), w.Close(), r.Close(), pw.Close())
+		if err != nil {
+			err = multierr.Append(err, dst.DeleteDataObject(ctx, dstRemote, true))
+
+			return worker.options.ErrorHandler(remote, dstRemote, err)
 		}
 
 		return nil
@@ -1511,13 +2328,39 @@ func (worker *Worker) copyAction(ctx context.Context, u Task) {
 
 // log logs a task without performing it, for dry-run mode.
 func (worker *Worker) log(u Task) {
-	fmt.Printf("\rwould %s\n", u.Action.Format(ProgressLabel(u.Path, u.IrodsPath)))
+	worker.logRemoval(u, NoRemoval)
+}
+
+func (worker *Worker) logRemoval(u Task, removal Removal) {
+	if worker.options.DryRunHandler != nil {
+		worker.options.DryRunHandler(u)
+
+		return
+	}
+
+	if removal != NoRemoval {
+		fmt.Printf("\rwould %s (%s)\n", u.Action.FormatColors(ProgressLabel(u.Path, u.IrodsPath), !worker.options.NoColors), removal)
+
+		return
+	}
+
+	fmt.Printf("\rwould %s\n", u.Action.FormatColors(ProgressLabel(u.Path, u.IrodsPath), !worker.options.NoColors))
 }
 
 // action runs a simple action and schedules an error
 func (worker *Worker) action(u Task, callback func() error) {
+	worker.trackAction(u, NoRemoval, callback)
+}
+
+// removeAction runs the removal of a data object or collection on the iRODS server,
+// and reports whether it was moved to the trash or deleted permanently.
+func (worker *Worker) removeAction(u Task, callback func() error) {
+	worker.trackAction(u, RemovalOf(u.IrodsPath, worker.options.SkipTrash), callback)
+}
+
+func (worker *Worker) trackAction(u Task, removal Removal, callback func() error) {
 	if worker.options.DryRun {
-		worker.log(u)
+		worker.logRemoval(u, removal)
 
 		return
 	}
@@ -1541,6 +2384,7 @@ func (worker *Worker) action(u Task, callback func() error) {
 		Label:      ProgressLabel(u.Path, u.IrodsPath),
 		StartedAt:  startTime,
 		FinishedAt: time.Now(),
+		Removal:    removal,
 	})
 }
 
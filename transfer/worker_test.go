@@ -3,9 +3,13 @@ package transfer
 import (
 	"bytes"
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
@@ -272,6 +276,64 @@ func TestFromStream(t *testing.T) { //nolint:funlen
 	}
 }
 
+func TestVerifyDir(t *testing.T) {
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "file1"), []byte("hello"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "file3"), []byte("test"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	testConn := &api.MockConn{}
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	testConn.AddResponses(responses) // walk
+
+	var (
+		reported []string
+		lock     sync.Mutex
+	)
+
+	worker := New(testAPI, testAPI, Options{
+		MaxQueued: 10,
+		ErrorHandler: func(local, remote string, err error) error {
+			if !errors.Is(err, ErrNotInSync) {
+				return err
+			}
+
+			lock.Lock()
+			defer lock.Unlock()
+
+			reported = append(reported, remote)
+
+			return nil
+		},
+	})
+
+	worker.VerifyDir(t.Context(), dir, "/test")
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	slices.Sort(reported)
+
+	if !slices.Equal(reported, []string{"/test/file1", "/test/file3"}) {
+		t.Fatalf("expected /test/file1 and /test/file3 to be reported, got %v", reported)
+	}
+}
+
 func TestClientDownload(t *testing.T) { //nolint:funlen
 	dir := t.TempDir()
 
@@ -570,8 +632,21 @@ func TestClientRemoveDir(t *testing.T) {
 		Name: "/test",
 	}, msg.CollectionOperationStat{})
 
+	var (
+		removals []Removal
+		mu       sync.Mutex
+	)
+
 	worker := New(testIndexAPI, testTransferAPI, Options{
 		MaxThreads: 1,
+		ProgressHandler: func(progress Progress) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			if !progress.FinishedAt.IsZero() {
+				removals = append(removals, progress.Removal)
+			}
+		},
 	})
 
 	worker.RemoveDir(t.Context(), "/test")
@@ -579,6 +654,27 @@ func TestClientRemoveDir(t *testing.T) {
 	if err := worker.Wait(); err != nil {
 		t.Error(err)
 	}
+
+	if !slices.Equal(removals, []Removal{MovedToTrash, MovedToTrash}) {
+		t.Errorf("expected two removals to the trash, got %v", removals)
+	}
+}
+
+func TestRemovalOf(t *testing.T) {
+	for path, expected := range map[string]Removal{
+		"/zone/home/user/file":       MovedToTrash,
+		"/zone/home/user/trash":      MovedToTrash,
+		"/zone/trash":                PermanentlyDeleted,
+		"/zone/trash/home/user/file": PermanentlyDeleted,
+	} {
+		if removal := RemovalOf(path, false); removal != expected {
+			t.Errorf("%s: expected %s, got %s", path, expected, removal)
+		}
+
+		if removal := RemovalOf(path, true); removal != PermanentlyDeleted {
+			t.Errorf("%s: expected %s with skip trash, got %s", path, PermanentlyDeleted, removal)
+		}
+	}
 }
 
 func TestClientComputeChecksums(t *testing.T) {
@@ -623,9 +719,16 @@ func TestClientComputeChecksums(t *testing.T) {
 		String: "sha2:jMuGXraweIxVs1RAFTHRM8Nbk/mrfSZwERQ3YzMHvy8=",
 	})
 
+	var computed []string
+
 	worker := New(testIndexAPI, testTransferAPI, Options{
 		MaxThreads:         1,
 		IntegrityChecksums: true,
+		ChecksumHandler: func(irodsPath string, isComputed bool) {
+			if isComputed {
+				computed = append(computed, irodsPath)
+			}
+		},
 	})
 
 	worker.ComputeChecksums(t.Context(), "/test")
@@ -633,6 +736,219 @@ func TestClientComputeChecksums(t *testing.T) {
 	if err := worker.Wait(); err != nil {
 		t.Error(err)
 	}
+
+	if !slices.Equal(computed, []string{"/test/file1"}) {
+		t.Errorf("expected the checksum of /test/file1 to be computed, got %v", computed)
+	}
+}
+
+func TestUploadResourceDown(t *testing.T) {
+	testConn := &api.MockConn{}
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	testConn.AddResponse(msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 1,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 313, ResultLen: 1, Values: []string{"down"}},
+		},
+	})
+
+	worker := New(testAPI, testAPI, Options{
+		MaxThreads:    1,
+		CheckResource: true,
+	})
+
+	worker.FromStream(t.Context(), "stdin", bytes.NewReader([]byte("test")), "/test/file1", false)
+	worker.FromStream(t.Context(), "stdin", bytes.NewReader([]byte("test")), "/test/file2", false)
+
+	if err := worker.Wait(); !errors.Is(err, ErrResourceDown) {
+		t.Fatalf("expected ErrResourceDown, got %v", err)
+	}
+}
+
+func TestUploadResourceDownFunc(t *testing.T) {
+	testConn := &api.MockConn{}
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+		DefaultResourceFunc: func() string {
+			return "demoResc"
+		},
+	}
+
+	testConn.AddResponse(msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 1,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 313, ResultLen: 1, Values: []string{"down"}},
+		},
+	})
+
+	worker := New(testAPI, testAPI, Options{
+		MaxThreads:    1,
+		CheckResource: true,
+	})
+
+	worker.FromStream(t.Context(), "stdin", bytes.NewReader([]byte("test")), "/test/file1", false)
+
+	if err := worker.Wait(); !errors.Is(err, ErrResourceDown) {
+		t.Fatalf("expected ErrResourceDown, got %v", err)
+	}
+}
+
+type bytesReader struct {
+	*bytes.Reader
+}
+
+func (r bytesReader) Name() string {
+	return "reader"
+}
+
+func (r bytesReader) ModTime() time.Time {
+	return time.Time{}
+}
+
+func (r bytesReader) Close() error {
+	return nil
+}
+
+func TestDetectContentType(t *testing.T) {
+	indexConn := &api.MockConn{}
+	transferConn := &api.MockConn{}
+
+	indexAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return indexConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	transferAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return transferConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	content := []byte("<html><body>test</body></html>")
+
+	kv := msg.SSKeyVal{}
+	kv.Add(msg.DATA_TYPE_KW, "generic")
+	kv.Add(msg.DEST_RESC_NAME_KW, "demoResc")
+	transferConn.Add(msg.DATA_OBJ_OPEN_AN, msg.DataObjectRequest{
+		Path:       "/testzone/index.html",
+		CreateMode: 420,
+		OpenFlags:  577,
+		KeyVals:    kv,
+	}, msg.FileDescriptor(1))
+	transferConn.AddBuffer(msg.DATA_OBJ_WRITE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+		Size:           len(content),
+	}, msg.EmptyResponse{}, content, nil)
+	transferConn.Add(msg.DATA_OBJ_CLOSE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+	}, msg.EmptyResponse{})
+
+	indexConn.Add(msg.MOD_AVU_METADATA_AN, msg.ModifyMetadataRequest{
+		Operation: "set",
+		ItemType:  "-d",
+		ItemName:  "/testzone/index.html",
+		AttrName:  ContentTypeAttribute,
+		AttrValue: "text/html; charset=utf-8",
+	}, msg.EmptyResponse{})
+
+	BufferSize = 100
+	CopyBufferDelay = 0
+
+	worker := New(indexAPI, transferAPI, Options{
+		DetectContentType: true,
+	})
+
+	worker.FromReader(t.Context(), bytesReader{bytes.NewReader(content)}, "/testzone/index.html")
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(indexConn.Dialog) > 0 || len(transferConn.Dialog) > 0 {
+		t.Fatal("expected all requests to be consumed")
+	}
+}
+
+func TestWorkerCopy(t *testing.T) {
+	testConn := &api.MockConn{}
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	testConn.AddResponses([]any{
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 14,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+				{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"4"}},
+				{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+				{AttributeIndex: 412, ResultLen: 1, Values: []string{"testzone"}},
+				{AttributeIndex: 415, ResultLen: 1, Values: []string{""}},
+				{AttributeIndex: 413, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 409, ResultLen: 1, Values: []string{"resc1"}},
+				{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path1"}},
+				{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc;resc1"}},
+				{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 420, ResultLen: 1, Values: []string{"20000"}},
+			},
+		},
+		msg.EmptyResponse{}, // copy
+	})
+
+	var progress []Progress
+
+	worker := New(testAPI, testAPI, Options{
+		MaxThreads: 1,
+		ProgressHandler: func(p Progress) {
+			progress = append(progress, p)
+		},
+	})
+
+	worker.Copy(t.Context(), "/test/file1", "/test/file2")
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(progress) != 2 || progress[1].Transferred != 4 || progress[1].FinishedAt.IsZero() {
+		t.Fatalf("unexpected progress: %v", progress)
+	}
 }
 
 func TestClientCopyDir(t *testing.T) {
@@ -686,3 +1002,903 @@ func TestClientCopyDir(t *testing.T) {
 		}
 	}
 }
+
+func TestClientCopyDirIntoSelf(t *testing.T) {
+	testConn := &api.MockConn{}
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	worker := New(testAPI, testAPI, Options{
+		MaxThreads: 1,
+	})
+
+	worker.CopyDir(t.Context(), "/test", "/test/sub")
+
+	if err := worker.Wait(); !errors.Is(err, ErrRecursiveCopy) {
+		t.Fatalf("expected ErrRecursiveCopy, got %v", err)
+	}
+}
+
+func TestResumeInterruptedUploadDir(t *testing.T) { //nolint:funlen
+	dir := t.TempDir()
+
+	source := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	for _, name := range []string{"complete", "partial", "missing"} {
+		path := filepath.Join(dir, name)
+
+		if err := os.WriteFile(path, bytes.Repeat([]byte("test"), 25), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.Chtimes(path, source, source); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// State of the remote collection after an interrupted upload:
+	// complete has been transferred and its modification time synced,
+	// partial was being written when the upload was interrupted
+	remote := map[string]*api.DataObject{
+		"complete": {
+			Path:     "/test/complete",
+			Replicas: []api.Replica{{Size: 100, ModifiedAt: source}},
+		},
+		"partial": {
+			Path:     "/test/partial",
+			Replicas: []api.Replica{{Size: 40, ModifiedAt: time.Now()}},
+		},
+	}
+
+	worker := New(nil, nil, Options{
+		SyncModTime: true,
+		OnlyIfNewer: true,
+	})
+
+	left := make(chan *object, 3)
+	right := make(chan *object, 2)
+
+	for _, name := range []string{"complete", "missing", "partial"} {
+		info, err := os.Stat(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		left <- &object{filepath.Join(dir, name), "/test/" + name, info}
+
+		if obj, ok := remote[name]; ok {
+			right <- &object{filepath.Join(dir, name), obj.Path, obj}
+		}
+	}
+
+	close(left)
+	close(right)
+
+	queue := make(chan Task, 10)
+
+	if err := worker.merge(t.Context(), left, right, queue, mergeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	close(queue)
+
+	var transferred []string
+
+	for task := range queue {
+		if task.Action != TransferFile {
+			t.Fatalf("unexpected action %v for %s", task.Action, task.IrodsPath)
+		}
+
+		transferred = append(transferred, task.IrodsPath)
+	}
+
+	if len(transferred) != 2 || transferred[0] != "/test/missing" || transferred[1] != "/test/partial" {
+		t.Fatalf("expected missing and partial file to be transferred, got %v", transferred)
+	}
+}
+
+func TestReportVerification(t *testing.T) {
+	var reported []Progress
+
+	for _, enabled := range []bool{false, true} {
+		worker := New(nil, nil, Options{
+			ReportVerification: enabled,
+			ProgressHandler: func(progress Progress) {
+				reported = append(reported, progress)
+			},
+		})
+
+		worker.reportVerification("file1", Verified)
+		worker.reportVerification("file2", VerificationSkipped)
+	}
+
+	if len(reported) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(reported))
+	}
+
+	if reported[0].Action != VerifyChecksum || reported[0].Label != "file1" || reported[0].Verification != Verified {
+		t.Errorf("unexpected report %+v", reported[0])
+	}
+
+	if reported[1].Verification.String() != "not verified" {
+		t.Errorf("unexpected report %+v", reported[1])
+	}
+}
+
+type slowConn struct {
+	*api.MockConn
+	open *atomic.Int32
+}
+
+func (c *slowConn) Request(ctx context.Context, apiNumber msg.APINumber, request, response any) error {
+	return c.RequestWithBuffers(ctx, apiNumber, request, response, nil, nil)
+}
+
+func (c *slowConn) RequestWithBuffers(ctx context.Context, apiNumber msg.APINumber, request, response any, requestBuf, responseBuf []byte) error {
+	time.Sleep(time.Millisecond)
+
+	return c.MockConn.RequestWithBuffers(ctx, apiNumber, request, response, requestBuf, responseBuf)
+}
+
+func (c *slowConn) Close() error {
+	c.open.Add(-1)
+
+	return nil
+}
+
+func TestMaxOutstanding(t *testing.T) {
+	dir := t.TempDir()
+
+	var open, maxOpen atomic.Int32
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			conn := &slowConn{MockConn: &api.MockConn{}, open: &open}
+
+			conn.AddResponses([]any{
+				msg.FileDescriptor(1), // open
+				msg.EmptyResponse{},   // write
+				msg.EmptyResponse{},   // close
+			})
+
+			if n := open.Add(1); n > maxOpen.Load() {
+				maxOpen.Store(n)
+			}
+
+			return conn, nil
+		},
+	}
+
+	worker := New(testAPI, testAPI, Options{
+		MaxOutstanding: 2,
+	})
+
+	for i := range 20 {
+		path := filepath.Join(dir, fmt.Sprintf("file%d", i))
+
+		if err := os.WriteFile(path, []byte("test"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		worker.uploadAction(t.Context(), Task{
+			Action:    TransferFile,
+			Path:      path,
+			IrodsPath: fmt.Sprintf("/test/file%d", i),
+			Size:      4,
+		})
+
+		if n := len(worker.outstanding); n > 2 {
+			t.Fatalf("expected at most 2 outstanding transfers, got %d", n)
+		}
+	}
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := maxOpen.Load(); n > 2 {
+		t.Errorf("expected at most 2 concurrent transfers, got %d", n)
+	}
+
+	if n := len(worker.outstanding); n != 0 {
+		t.Errorf("expected all transfers to be released, got %d", n)
+	}
+}
+
+func TestCrossZoneCopy(t *testing.T) { //nolint:funlen
+	srcConn := &api.MockConn{}
+	dstConn := &api.MockConn{}
+
+	srcAPI := &api.API{
+		Username: "testuser",
+		Zone:     "zone1",
+		Connect: func(context.Context) (api.Conn, error) {
+			return srcConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	dstAPI := &api.API{
+		Username: "testuser",
+		Zone:     "zone2",
+		Connect: func(context.Context) (api.Conn, error) {
+			return dstConn, nil
+		},
+		DefaultResource: "otherResc",
+	}
+
+	kv := msg.SSKeyVal{}
+	kv.Add(msg.DATA_TYPE_KW, "generic")
+	kv.Add(msg.DEST_RESC_NAME_KW, "demoResc")
+	srcConn.Add(msg.DATA_OBJ_OPEN_AN, msg.DataObjectRequest{
+		Path:       "/zone1/file1",
+		CreateMode: 420,
+		KeyVals:    kv,
+	}, msg.FileDescriptor(1))
+	srcConn.Add(msg.DATA_OBJ_LSEEK_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+		Whence:         2,
+	}, msg.SeekResponse{Offset: 11})
+	srcConn.Add(msg.DATA_OBJ_LSEEK_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+	}, msg.SeekResponse{Offset: 0})
+	srcConn.AddBuffer(msg.DATA_OBJ_READ_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+		Size:           100,
+	}, msg.ReadResponse(11), nil, []byte("testcontent"))
+	srcConn.Add(msg.DATA_OBJ_CLOSE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+	}, msg.EmptyResponse{})
+
+	kv = msg.SSKeyVal{}
+	kv.Add(msg.DATA_TYPE_KW, "generic")
+	kv.Add(msg.DEST_RESC_NAME_KW, "otherResc")
+	dstConn.Add(msg.DATA_OBJ_OPEN_AN, msg.DataObjectRequest{
+		Path:       "/zone2/file1",
+		CreateMode: 420,
+		OpenFlags:  577,
+		KeyVals:    kv,
+	}, msg.FileDescriptor(2))
+	dstConn.AddBuffer(msg.DATA_OBJ_WRITE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 2,
+		Size:           11,
+	}, msg.EmptyResponse{}, []byte("testcontent"), nil)
+	dstConn.Add(msg.DATA_OBJ_CLOSE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 2,
+	}, msg.EmptyResponse{})
+
+	BufferSize = 100
+	CopyBufferDelay = 0
+
+	var last Progress
+
+	worker := New(nil, srcAPI, Options{
+		ProgressHandler: func(progress Progress) {
+			last = progress
+		},
+	})
+
+	worker.CrossZoneCopy(t.Context(), "/zone1/file1", dstAPI, "/zone2/file1")
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if last.Size != 11 || last.Transferred != 11 || last.FinishedAt.IsZero() {
+		t.Fatalf("unexpected progress: %+v", last)
+	}
+
+	if len(srcConn.Dialog) > 0 || len(dstConn.Dialog) > 0 {
+		t.Fatal("expected all requests to be consumed")
+	}
+}
+
+func TestCrossZoneCopyReadError(t *testing.T) { //nolint:funlen
+	srcConn := &api.MockConn{}
+	dstConn := &api.MockConn{}
+
+	srcAPI := &api.API{
+		Username: "testuser",
+		Zone:     "zone1",
+		Connect: func(context.Context) (api.Conn, error) {
+			return srcConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	dstAPI := &api.API{
+		Username: "testuser",
+		Zone:     "zone2",
+		Connect: func(context.Context) (api.Conn, error) {
+			return dstConn, nil
+		},
+		DefaultResource: "otherResc",
+	}
+
+	errRead := errors.New("read failed")
+
+	srcConn.AddResponses([]any{
+		msg.FileDescriptor(1),        // open
+		msg.SeekResponse{Offset: 11}, // seek to end
+		msg.SeekResponse{Offset: 0},  // seek to start
+		errRead,                      // read
+		msg.EmptyResponse{},          // close
+	})
+
+	kv := msg.SSKeyVal{}
+	kv.Add(msg.DATA_TYPE_KW, "generic")
+	kv.Add(msg.DEST_RESC_NAME_KW, "otherResc")
+	dstConn.Add(msg.DATA_OBJ_OPEN_AN, msg.DataObjectRequest{
+		Path:       "/zone2/file1",
+		CreateMode: 420,
+		OpenFlags:  577,
+		KeyVals:    kv,
+	}, msg.FileDescriptor(2))
+	dstConn.Add(msg.DATA_OBJ_CLOSE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 2,
+	}, msg.EmptyResponse{})
+
+	// The partial copy is removed
+	kv = msg.SSKeyVal{}
+	kv.Add(msg.FORCE_FLAG_KW, "")
+	dstConn.Add(msg.DATA_OBJ_UNLINK_AN, msg.DataObjectRequest{
+		Path:    "/zone2/file1",
+		KeyVals: kv,
+	}, msg.EmptyResponse{})
+
+	BufferSize = 100
+	CopyBufferDelay = 0
+
+	worker := New(nil, srcAPI, Options{})
+
+	worker.CrossZoneCopy(t.Context(), "/zone1/file1", dstAPI, "/zone2/file1")
+
+	if err := worker.Wait(); !errors.Is(err, errRead) {
+		t.Fatalf("expected %v, got %v", errRead, err)
+	}
+
+	if len(srcConn.Dialog) > 0 || len(dstConn.Dialog) > 0 {
+		t.Fatal("expected all requests to be consumed")
+	}
+}
+
+func TestDownloadModifiedSince(t *testing.T) { //nolint:funlen
+	dir := t.TempDir()
+
+	testConn0 := &api.MockConn{}
+
+	testIndexAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn0, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	sub := msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 7,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 500, ResultLen: 1, Values: []string{"2"}},
+			{AttributeIndex: 501, ResultLen: 1, Values: []string{"/test/sub"}},
+			{AttributeIndex: 503, ResultLen: 1, Values: []string{"rods"}},
+			{AttributeIndex: 504, ResultLen: 1, Values: []string{"zone"}},
+			{AttributeIndex: 508, ResultLen: 1, Values: []string{"10000"}},
+			{AttributeIndex: 509, ResultLen: 1, Values: []string{"20000"}},
+			{AttributeIndex: 506, ResultLen: 1, Values: []string{"0"}},
+		},
+	}
+
+	testConn0.AddResponses([]any{
+		msg.QueryResponse{}, // no modified collections
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 16,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"4"}},
+				{AttributeIndex: 501, ResultLen: 1, Values: []string{"/test"}},
+				{AttributeIndex: 403, ResultLen: 1, Values: []string{"file1"}},
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+				{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"4"}},
+				{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+				{AttributeIndex: 412, ResultLen: 1, Values: []string{"zone"}},
+				{AttributeIndex: 415, ResultLen: 1, Values: []string{""}},
+				{AttributeIndex: 413, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 409, ResultLen: 1, Values: []string{"resc1"}},
+				{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path1"}},
+				{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc;resc1"}},
+				{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 420, ResultLen: 1, Values: []string{"20000"}},
+			},
+		},
+		sub,
+		msg.QueryResponse{}, // no modified data objects in subcollections
+		sub,                 // sub has no local directory yet, so it is listed completely
+		msg.QueryResponse{},
+		msg.QueryResponse{},
+		msg.QueryResponse{},
+	})
+
+	testConn1 := &api.MockConn{}
+
+	testTransferAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn1, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	kv := msg.SSKeyVal{}
+	kv.Add(msg.DATA_TYPE_KW, "generic")
+	kv.Add(msg.DEST_RESC_NAME_KW, "demoResc")
+	testConn1.Add(msg.DATA_OBJ_OPEN_AN, msg.DataObjectRequest{
+		Path:       "/test/file1",
+		CreateMode: 420,
+		KeyVals:    kv,
+	}, msg.FileDescriptor(1))
+	testConn1.Add(msg.DATA_OBJ_LSEEK_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+		Whence:         2,
+	}, msg.SeekResponse{Offset: 4})
+	testConn1.Add(msg.DATA_OBJ_LSEEK_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+	}, msg.SeekResponse{Offset: 0})
+	testConn1.AddBuffer(msg.DATA_OBJ_READ_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+		Size:           100,
+	}, msg.ReadResponse(4), nil, []byte("test"))
+	testConn1.Add(msg.DATA_OBJ_CLOSE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+	}, msg.EmptyResponse{})
+
+	BufferSize = 100
+	MinimumRangeSize = 200
+
+	worker := New(testIndexAPI, testTransferAPI, Options{
+		MaxThreads: 1,
+	})
+
+	worker.DownloadModifiedSince(t.Context(), dir, "/test", time.Unix(15000, 0))
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if contents, err := os.ReadFile(filepath.Join(dir, "file1")); err != nil {
+		t.Fatal(err)
+	} else if string(contents) != "test" {
+		t.Errorf("expected 'test', got '%s'", string(contents))
+	}
+
+	if fi, err := os.Stat(filepath.Join(dir, "sub")); err != nil || !fi.IsDir() {
+		t.Errorf("expected directory sub to be created: %v", err)
+	}
+}
+
+func TestDownloadModifiedSinceMovedCollection(t *testing.T) { //nolint:funlen
+	dir := t.TempDir()
+
+	testConn := &api.MockConn{}
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	moved := msg.QueryResponse{
+		RowCount:       1,
+		AttributeCount: 7,
+		TotalRowCount:  1,
+		SQLResult: []msg.SQLResult{
+			{AttributeIndex: 500, ResultLen: 1, Values: []string{"2"}},
+			{AttributeIndex: 501, ResultLen: 1, Values: []string{"/test/moved"}},
+			{AttributeIndex: 503, ResultLen: 1, Values: []string{"rods"}},
+			{AttributeIndex: 504, ResultLen: 1, Values: []string{"zone"}},
+			{AttributeIndex: 508, ResultLen: 1, Values: []string{"10000"}},
+			{AttributeIndex: 509, ResultLen: 1, Values: []string{"20000"}},
+			{AttributeIndex: 506, ResultLen: 1, Values: []string{"0"}},
+		},
+	}
+
+	testConn.AddResponses([]any{
+		msg.QueryResponse{}, // no modified collections
+		msg.QueryResponse{}, // no modified data objects
+		moved,               // the collection was moved into /test after the last backup
+		msg.QueryResponse{}, // its data object kept its modification time
+		moved,               // the moved collection is listed completely
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 16,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"4"}},
+				{AttributeIndex: 501, ResultLen: 1, Values: []string{"/test/moved"}},
+				{AttributeIndex: 403, ResultLen: 1, Values: []string{"file2"}},
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"2"}},
+				{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+				{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"4"}},
+				{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+				{AttributeIndex: 412, ResultLen: 1, Values: []string{"zone"}},
+				{AttributeIndex: 415, ResultLen: 1, Values: []string{""}},
+				{AttributeIndex: 413, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 409, ResultLen: 1, Values: []string{"resc1"}},
+				{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path1"}},
+				{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc;resc1"}},
+				{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 420, ResultLen: 1, Values: []string{"10000"}},
+			},
+		},
+		msg.QueryResponse{},
+		msg.QueryResponse{},
+	})
+
+	var (
+		tasks []Task
+		mu    sync.Mutex
+	)
+
+	worker := New(testAPI, testAPI, Options{
+		MaxThreads: 1,
+		DryRun:     true,
+		DryRunHandler: func(task Task) {
+			mu.Lock()
+			defer mu.Unlock()
+
+			tasks = append(tasks, task)
+		},
+	})
+
+	worker.DownloadModifiedSince(t.Context(), dir, "/test", time.Unix(15000, 0))
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	var transfers []string
+
+	for _, task := range tasks {
+		if task.Action == TransferFile {
+			transfers = append(transfers, task.IrodsPath)
+		}
+	}
+
+	if !slices.Equal(transfers, []string{"/test/moved/file2"}) {
+		t.Errorf("expected the data object of the moved collection to be downloaded, got %v", tasks)
+	}
+}
+
+func TestDownloadModifiedSinceIgnore(t *testing.T) {
+	dir := t.TempDir()
+
+	testConn := &api.MockConn{}
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	testConn.AddResponses([]any{
+		msg.QueryResponse{}, // no modified collections
+		msg.QueryResponse{}, // no modified data objects
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 7,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"2"}},
+				{AttributeIndex: 501, ResultLen: 1, Values: []string{"/test/sub"}},
+				{AttributeIndex: 503, ResultLen: 1, Values: []string{"rods"}},
+				{AttributeIndex: 504, ResultLen: 1, Values: []string{"zone"}},
+				{AttributeIndex: 508, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 509, ResultLen: 1, Values: []string{"20000"}},
+				{AttributeIndex: 506, ResultLen: 1, Values: []string{"0"}},
+			},
+		},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 16,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 401, ResultLen: 1, Values: []string{"4"}},
+				{AttributeIndex: 501, ResultLen: 1, Values: []string{"/test/sub"}},
+				{AttributeIndex: 403, ResultLen: 1, Values: []string{"file2"}},
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"2"}},
+				{AttributeIndex: 406, ResultLen: 1, Values: []string{"generic"}},
+				{AttributeIndex: 404, ResultLen: 1, Values: []string{"0"}},
+				{AttributeIndex: 407, ResultLen: 1, Values: []string{"4"}},
+				{AttributeIndex: 411, ResultLen: 1, Values: []string{"rods"}},
+				{AttributeIndex: 412, ResultLen: 1, Values: []string{"zone"}},
+				{AttributeIndex: 415, ResultLen: 1, Values: []string{""}},
+				{AttributeIndex: 413, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 409, ResultLen: 1, Values: []string{"resc1"}},
+				{AttributeIndex: 410, ResultLen: 1, Values: []string{"/path1"}},
+				{AttributeIndex: 422, ResultLen: 1, Values: []string{"demoResc;resc1"}},
+				{AttributeIndex: 419, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 420, ResultLen: 1, Values: []string{"20000"}},
+			},
+		},
+	})
+
+	// The data object is ignored because its collection is, as in DownloadDir,
+	// no transfer is started as the connection has no responses left
+	worker := New(testAPI, testAPI, Options{
+		MaxThreads:     1,
+		IgnorePatterns: []string{"sub"},
+	})
+
+	worker.DownloadModifiedSince(t.Context(), dir, "/test", time.Unix(15000, 0))
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "sub")); !os.IsNotExist(err) {
+		t.Errorf("expected directory sub to be ignored: %v", err)
+	}
+}
+
+func TestEmptyFile(t *testing.T) { //nolint:funlen
+	testConn := &api.MockConn{}
+
+	testAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn, nil
+		},
+		DefaultResource: "demoResc",
+	}
+
+	kv := msg.SSKeyVal{}
+	kv.Add(msg.DATA_TYPE_KW, "generic")
+	kv.Add(msg.DEST_RESC_NAME_KW, "demoResc")
+	testConn.Add(msg.DATA_OBJ_OPEN_AN, msg.DataObjectRequest{
+		Path:       "/test/empty",
+		CreateMode: 420,
+		OpenFlags:  577,
+		KeyVals:    kv,
+	}, msg.FileDescriptor(1))
+	testConn.Add(msg.DATA_OBJ_CLOSE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 1,
+	}, msg.EmptyResponse{})
+	testConn.Add(msg.DATA_OBJ_OPEN_AN, msg.DataObjectRequest{
+		Path:       "/test/empty",
+		CreateMode: 420,
+		KeyVals:    kv,
+	}, msg.FileDescriptor(2))
+	testConn.Add(msg.DATA_OBJ_LSEEK_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 2,
+		Whence:         2,
+	}, msg.SeekResponse{Offset: 0})
+	testConn.Add(msg.DATA_OBJ_CLOSE_AN, msg.OpenedDataObjectRequest{
+		FileDescriptor: 2,
+	}, msg.EmptyResponse{})
+
+	dir := t.TempDir()
+
+	if err := os.WriteFile(filepath.Join(dir, "empty"), nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	var finished []Progress
+
+	worker := New(testAPI, testAPI, Options{
+		MaxThreads: 2,
+		ProgressHandler: func(progress Progress) {
+			if !progress.FinishedAt.IsZero() {
+				finished = append(finished, progress)
+			}
+		},
+	})
+
+	worker.Upload(t.Context(), filepath.Join(dir, "empty"), "/test/empty")
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	worker.Download(t.Context(), filepath.Join(dir, "download"), "/test/empty")
+
+	if err := worker.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fi, err := os.Stat(filepath.Join(dir, "download")); err != nil {
+		t.Fatal(err)
+	} else if fi.Size() != 0 {
+		t.Errorf("expected empty file, got %d bytes", fi.Size())
+	}
+
+	if len(finished) != 2 {
+		t.Fatalf("expected two finished progress events, got %d", len(finished))
+	}
+
+	for _, progress := range finished {
+		if progress.Size != 0 || progress.Transferred != 0 {
+			t.Errorf("unexpected progress: %+v", progress)
+		}
+	}
+}
+
+func TestChecksumOnModTimeChange(t *testing.T) { //nolint:funlen
+	dir := t.TempDir()
+
+	left := filepath.Join(dir, "left")
+	right := filepath.Join(dir, "right")
+
+	for _, path := range []string{left, right} {
+		if err := os.WriteFile(path, []byte("test"), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := os.Chtimes(right, time.Time{}, time.Now().Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	leftInfo, err := os.Stat(left)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rightInfo, err := os.Stat(right)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, verifyErr := range []error{nil, ErrChecksumMismatch} {
+		var verified int
+
+		worker := New(nil, nil, Options{
+			ChecksumOnModTimeChange: true,
+			SyncModTime:             true,
+		})
+
+		queue := make(chan Task, 1)
+
+		opts := mergeOptions{
+			ChecksumVerify: func(ctx context.Context, source, target string, sourceInfo, targetInfo os.FileInfo) ([]byte, []byte, error) {
+				verified++
+
+				return []byte("checksum"), []byte("checksum"), verifyErr
+			},
+		}
+
+		leftObject := &object{left, "/test/file", leftInfo}
+
+		// Identical modification time, no checksum needed
+		if err := worker.compareAndTransferObject(t.Context(), leftObject, &object{left, "/test/file", leftInfo}, queue, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if verified != 0 || len(queue) != 0 {
+			t.Fatalf("expected no checksum verification and no task, got %d verifications and %d tasks", verified, len(queue))
+		}
+
+		// Different modification time, checksum is compared
+		if err := worker.compareAndTransferObject(t.Context(), leftObject, &object{right, "/test/file", rightInfo}, queue, opts); err != nil {
+			t.Fatal(err)
+		}
+
+		if verified != 1 || len(queue) != 1 {
+			t.Fatalf("expected one checksum verification and one task, got %d verifications and %d tasks", verified, len(queue))
+		}
+
+		expected := SetModificationTime
+		if verifyErr != nil {
+			expected = TransferFile
+		}
+
+		if task := <-queue; task.Action != expected {
+			t.Errorf("expected action %v, got %v", expected, task.Action)
+		}
+	}
+}
+
+func TestCopyAccess(t *testing.T) { //nolint:funlen
+	testConn0 := &api.MockConn{}
+
+	testIndexAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn0, nil
+		},
+	}
+
+	testConn0.AddResponses([]any{
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 2,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 711, ResultLen: 1, Values: []string{"read_object"}},
+				{AttributeIndex: 713, ResultLen: 1, Values: []string{"2"}},
+			},
+		},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 6,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 201, ResultLen: 1, Values: []string{"2"}},
+				{AttributeIndex: 202, ResultLen: 1, Values: []string{"otheruser"}},
+				{AttributeIndex: 204, ResultLen: 1, Values: []string{"testzone"}},
+				{AttributeIndex: 203, ResultLen: 1, Values: []string{"rodsuser"}},
+				{AttributeIndex: 208, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 209, ResultLen: 1, Values: []string{"10000"}},
+			},
+		},
+		msg.QueryResponse{
+			RowCount:       1,
+			AttributeCount: 6,
+			TotalRowCount:  1,
+			SQLResult: []msg.SQLResult{
+				{AttributeIndex: 500, ResultLen: 1, Values: []string{"1"}},
+				{AttributeIndex: 503, ResultLen: 1, Values: []string{"testuser"}},
+				{AttributeIndex: 504, ResultLen: 1, Values: []string{"testzone"}},
+				{AttributeIndex: 508, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 509, ResultLen: 1, Values: []string{"10000"}},
+				{AttributeIndex: 506, ResultLen: 1, Values: []string{"1"}},
+			},
+		},
+	})
+
+	testConn1 := &api.MockConn{}
+
+	testTransferAPI := &api.API{
+		Username: "testuser",
+		Zone:     "testzone",
+		Connect: func(context.Context) (api.Conn, error) {
+			return testConn1, nil
+		},
+	}
+
+	testConn1.Add(msg.MOD_ACCESS_CONTROL_AN, msg.ModifyAccessRequest{
+		Path:        "/test/copy",
+		UserName:    "otheruser",
+		Zone:        "testzone",
+		AccessLevel: "read_object",
+	}, msg.EmptyResponse{})
+	testConn1.Add(msg.MOD_ACCESS_CONTROL_AN, msg.ModifyAccessRequest{
+		Path:        "/test/copy",
+		AccessLevel: "inherit",
+	}, msg.EmptyResponse{})
+
+	worker := New(testIndexAPI, testTransferAPI, Options{
+		PreserveACLs: true,
+	})
+
+	if err := worker.copyAccess(t.Context(), testTransferAPI, "/test/source", "/test/copy", api.CollectionType); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(testConn0.Dialog) != 0 || len(testConn1.Dialog) != 0 {
+		t.Errorf("expected all requests to be sent")
+	}
+}
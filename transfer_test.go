@@ -0,0 +1,99 @@
+package iron
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kuleuven/iron/transfer"
+	"go.uber.org/multierr"
+)
+
+func TestNewTransfer(t *testing.T) {
+	client := newTestClient(1)
+	defer client.Close()
+
+	if _, err := client.NewTransfer(transfer.Options{}); !errors.Is(err, ErrNoConnectionsAvailable) {
+		t.Fatalf("expected ErrNoConnectionsAvailable, got %v", err)
+	}
+
+	client = newTestClient(4)
+	defer client.Close()
+
+	tr, err := client.NewTransfer(transfer.Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// All connections but one are used for transfers
+	if stats := client.defaultPool.Stats(); stats.MaxConns != 1 {
+		t.Fatalf("expected 1 connection for index operations, got %d", stats.MaxConns)
+	}
+
+	if err = tr.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := client.defaultPool.Stats(); stats.MaxConns != 4 {
+		t.Fatalf("expected the connections to be returned, got %d", stats.MaxConns)
+	}
+
+	// The pool is sized to MaxOutstanding * MaxThreads connections, as far as the client allows
+	tr, err = client.NewTransfer(transfer.Options{MaxThreads: 1, MaxOutstanding: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if stats := client.defaultPool.Stats(); stats.MaxConns != 2 {
+		t.Fatalf("expected 2 connections for index operations, got %d", stats.MaxConns)
+	}
+
+	if err = tr.Wait(); err != nil {
+		t.Fatal(err)
+	}
+
+	// MaxThreads is lowered to the size of the subpool
+	tr, err = client.NewTransfer(transfer.Options{MaxThreads: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if tr.pool.maxConns != 3 {
+		t.Fatalf("expected a subpool of 3 connections, got %d", tr.pool.maxConns)
+	}
+
+	if err = tr.Wait(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewTransferInUse(t *testing.T) {
+	client := newTestClient(4)
+	defer client.Close()
+
+	conns, err := client.ConnectAvailable(t.Context(), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Three connections are needed, but only two are free
+	if _, err = client.NewTransfer(transfer.Options{}); !errors.Is(err, ErrNoConnectionsAvailable) {
+		t.Fatalf("expected ErrNoConnectionsAvailable, got %v", err)
+	}
+
+	if stats := client.defaultPool.Stats(); stats.MaxConns != 4 {
+		t.Fatalf("expected the pool not to be shrunk, got %d connections", stats.MaxConns)
+	}
+
+	if err = closeAll(conns[1:]); err != nil {
+		t.Fatal(err)
+	}
+
+	tr, err := client.NewTransfer(transfer.Options{MaxThreads: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = multierr.Append(tr.Wait(), conns[0].Close()); err != nil {
+		t.Fatal(err)
+	}
+}